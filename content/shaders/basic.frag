@@ -1,14 +1,25 @@
 #version 460
 
+#include "inc_globals.inl"
+
 layout(location = 0) in vec4 fragColor;
 layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec3 fragWorldPos;
+layout(location = 3) in vec3 fragNormal;
 
 layout(binding = 1) uniform sampler2D texSampler;
+layout(binding = 3) uniform samplerCube environment;
 
 layout(location = 0) out vec4 outColor;
 layout(location = 1) out float reveal;
 
 void main() {
     vec4 unWeightedColor = texture(texSampler, fragTexCoords) * fragColor;
+    vec3 N = normalize(fragNormal);
+    if (!gl_FrontFacing)
+        N = -N;
+    vec3 R = reflect(normalize(fragWorldPos - cameraPosition), N);
+    vec3 reflection = texture(environment, R).rgb;
+    unWeightedColor.rgb = mix(unWeightedColor.rgb, reflection, environmentIntensity);
 #include "inc_fragment_oit_block.inl"
 }
\ No newline at end of file
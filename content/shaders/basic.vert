@@ -6,9 +6,14 @@ layout(location = LOCATION_START) in vec4 color;
 
 layout(location = 0) out vec4 fragColor;
 layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec3 fragWorldPos;
+layout(location = 3) out vec3 fragNormal;
 
 void main() {
+	vec4 worldPos = model * vec4(Position, 1.0);
 	fragColor = Color * color;
 	fragTexCoords = UV0;
-	gl_Position = projection * view * model * vec4(Position, 1.0);
+	fragWorldPos = worldPos.xyz;
+	fragNormal = mat3(model) * Normal;
+	gl_Position = projection * view * worldPos;
 }
@@ -14,6 +14,8 @@ layout(location = LOCATION_START+1) in int skinIndex;
 
 layout(location = 0) out vec4 fragColor;
 layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec3 fragWorldPos;
+layout(location = 3) out vec3 fragNormal;
 
 void main() {
 	vec4 pos = vec4(Position, 1.0);
@@ -21,7 +23,11 @@ void main() {
 					+ JointWeights.y * jointTransforms[skinIndex][JointIds.y]
 					+ JointWeights.z * jointTransforms[skinIndex][JointIds.z]
 					+ JointWeights.w * jointTransforms[skinIndex][JointIds.w];
+	mat4 skinnedModel = model * skinMatrix;
+	vec4 worldPos = skinnedModel * pos;
 	fragColor = Color * color;
 	fragTexCoords = UV0;
-	gl_Position = projection * view * model * skinMatrix * pos;
+	fragWorldPos = worldPos.xyz;
+	fragNormal = mat3(skinnedModel) * Normal;
+	gl_Position = projection * view * worldPos;
 }
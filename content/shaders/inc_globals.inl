@@ -0,0 +1,43 @@
+#ifndef INC_GLOBALS
+#define INC_GLOBALS
+
+#define MAX_LIGHTS				16
+#define MAX_SHADOW_MAPS			8
+#define MAX_SHADOW_CASCADES		4
+#define SHADOW_ATLAS_COLUMNS	4
+#define SHADOW_ATLAS_ROWS		2
+#define LIGHT_DIRECTIONAL		0
+#define LIGHT_POINT				1
+#define LIGHT_SPOT				2
+
+struct Light {
+	vec3 position;
+	int type;
+	vec3 direction;
+	float range;
+	vec3 color;
+	float intensity;
+	float cosInner;
+	float cosOuter;
+	int shadowIndex;
+};
+
+layout(set = 0, binding = 0) readonly uniform UniformBufferObject {
+	mat4 view;
+	mat4 projection;
+	mat4 uiView;
+	mat4 uiProjection;
+	vec3 cameraPosition;
+	vec3 uiCameraPosition;
+	vec2 screenSize;
+	float time;
+	int lightCount;
+	vec3 ambientColor;
+	Light lights[MAX_LIGHTS];
+	mat4 shadowViewProjections[MAX_SHADOW_MAPS];
+	vec4 shadowSplits;
+	vec4 shadowParams;
+	float environmentIntensity;
+};
+
+#endif
@@ -0,0 +1,113 @@
+#include "inc_globals.inl"
+
+#define PI						3.14159265359
+
+layout(binding = 2) uniform sampler2D shadowMap;
+
+float distributionGGX(vec3 N, vec3 H, float roughness) {
+	float a = roughness * roughness;
+	float a2 = a * a;
+	float NdotH = max(dot(N, H), 0.0);
+	float denom = NdotH * NdotH * (a2 - 1.0) + 1.0;
+	return a2 / (PI * denom * denom);
+}
+
+float geometrySchlickGGX(float NdotV, float roughness) {
+	float r = roughness + 1.0;
+	float k = (r * r) / 8.0;
+	return NdotV / (NdotV * (1.0 - k) + k);
+}
+
+float geometrySmith(vec3 N, vec3 V, vec3 L, float roughness) {
+	float NdotV = max(dot(N, V), 0.0);
+	float NdotL = max(dot(N, L), 0.0);
+	return geometrySchlickGGX(NdotV, roughness) * geometrySchlickGGX(NdotL, roughness);
+}
+
+vec3 fresnelSchlick(float cosTheta, vec3 F0) {
+	return F0 + (1.0 - F0) * pow(clamp(1.0 - cosTheta, 0.0, 1.0), 5.0);
+}
+
+float lightAttenuation(Light light, vec3 worldPos, out vec3 L) {
+	if (light.type == LIGHT_DIRECTIONAL) {
+		L = normalize(-light.direction);
+		return 1.0;
+	}
+	vec3 toLight = light.position - worldPos;
+	float dist = length(toLight);
+	L = toLight / max(dist, 0.0001);
+	float window = clamp(1.0 - pow(dist / light.range, 4.0), 0.0, 1.0);
+	float attenuation = (window * window) / (dist * dist + 1.0);
+	if (light.type == LIGHT_SPOT) {
+		float theta = dot(-L, normalize(light.direction));
+		float epsilon = max(light.cosInner - light.cosOuter, 0.0001);
+		attenuation *= clamp((theta - light.cosOuter) / epsilon, 0.0, 1.0);
+	}
+	return attenuation;
+}
+
+float sampleShadow(int index, vec3 worldPos) {
+	vec4 lightPos = shadowViewProjections[index] * vec4(worldPos, 1.0);
+	vec3 ndc = lightPos.xyz / lightPos.w;
+	if (ndc.z > 1.0 || abs(ndc.x) > 1.0 || abs(ndc.y) > 1.0)
+		return 1.0;
+	vec2 tile = vec2(index % SHADOW_ATLAS_COLUMNS, index / SHADOW_ATLAS_COLUMNS);
+	vec2 tileScale = vec2(1.0 / SHADOW_ATLAS_COLUMNS, 1.0 / SHADOW_ATLAS_ROWS);
+	vec2 uv = ndc.xy * 0.5 + 0.5;
+	float texel = shadowParams.z;
+	float depth = ndc.z - shadowParams.x;
+	float lit = 0.0;
+	for (int x = -1; x <= 1; ++x) {
+		for (int y = -1; y <= 1; ++y) {
+			vec2 offset = clamp(uv + vec2(x, y) * texel, 0.0, 1.0);
+			float closest = texture(shadowMap, (tile + offset) * tileScale).r;
+			lit += depth > closest ? 0.0 : 1.0;
+		}
+	}
+	return lit / 9.0;
+}
+
+float shadowFactor(Light light, vec3 worldPos, vec3 N, vec3 L) {
+	if (light.shadowIndex < 0)
+		return 1.0;
+	vec3 offsetPos = worldPos + N * shadowParams.y * (1.0 - max(dot(N, L), 0.0));
+	if (light.type != LIGHT_DIRECTIONAL)
+		return sampleShadow(light.shadowIndex, offsetPos);
+	float viewDepth = -(view * vec4(worldPos, 1.0)).z;
+	int cascades = int(shadowParams.w);
+	for (int c = 0; c < cascades; ++c) {
+		if (viewDepth <= shadowSplits[c])
+			return sampleShadow(light.shadowIndex + c, offsetPos);
+	}
+	return 1.0;
+}
+
+vec3 pbrLighting(vec3 albedo, float metallic, float roughness, vec3 N, vec3 worldPos,
+		int count, ivec4 indexes)
+{
+	vec3 V = normalize(cameraPosition - worldPos);
+	vec3 F0 = mix(vec3(0.04), albedo, metallic);
+	vec3 Lo = vec3(0.0);
+	roughness = clamp(roughness, 0.04, 1.0);
+	for (int i = 0; i < count && i < 4; ++i) {
+		int idx = indexes[i];
+		if (idx < 0 || idx >= lightCount)
+			continue;
+		Light light = lights[idx];
+		vec3 L;
+		float attenuation = lightAttenuation(light, worldPos, L);
+		if (attenuation <= 0.0)
+			continue;
+		vec3 H = normalize(V + L);
+		float shadow = shadowFactor(light, worldPos, N, L);
+		vec3 radiance = light.color * light.intensity * attenuation * shadow;
+		float NDF = distributionGGX(N, H, roughness);
+		float G = geometrySmith(N, V, L, roughness);
+		vec3 F = fresnelSchlick(max(dot(H, V), 0.0), F0);
+		vec3 specular = (NDF * G * F) / (4.0 * max(dot(N, V), 0.0) * max(dot(N, L), 0.0) + 0.0001);
+		vec3 kD = (vec3(1.0) - F) * (1.0 - metallic);
+		float NdotL = max(dot(N, L), 0.0);
+		Lo += (kD * albedo / PI + specular) * radiance * NdotL;
+	}
+	return ambientColor * albedo + Lo;
+}
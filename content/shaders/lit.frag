@@ -0,0 +1,27 @@
+#version 460
+
+#include "inc_lighting.inl"
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec3 fragWorldPos;
+layout(location = 3) in vec3 fragNormal;
+layout(location = 4) in vec2 fragMaterial;
+layout(location = 5) flat in int fragLightCount;
+layout(location = 6) flat in ivec4 fragLightIndexes;
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+void main() {
+	vec4 albedo = texture(texSampler, fragTexCoords) * fragColor;
+	vec3 N = normalize(fragNormal);
+	if (!gl_FrontFacing)
+		N = -N;
+	vec3 lit = pbrLighting(albedo.rgb, fragMaterial.x, fragMaterial.y, N,
+		fragWorldPos, fragLightCount, fragLightIndexes);
+	vec4 unWeightedColor = vec4(lit, albedo.a);
+#include "inc_fragment_oit_block.inl"
+}
@@ -0,0 +1,29 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 color;
+layout(location = LOCATION_START+1) in float metallic;
+layout(location = LOCATION_START+2) in float roughness;
+layout(location = LOCATION_START+3) in int lightCount;
+layout(location = LOCATION_START+4) in ivec4 lightIndexes;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec3 fragWorldPos;
+layout(location = 3) out vec3 fragNormal;
+layout(location = 4) out vec2 fragMaterial;
+layout(location = 5) flat out int fragLightCount;
+layout(location = 6) flat out ivec4 fragLightIndexes;
+
+void main() {
+	vec4 worldPos = model * vec4(Position, 1.0);
+	fragColor = Color * color;
+	fragTexCoords = UV0;
+	fragWorldPos = worldPos.xyz;
+	fragNormal = normalize(mat3(transpose(inverse(model))) * Normal);
+	fragMaterial = vec2(metallic, roughness);
+	fragLightCount = lightCount;
+	fragLightIndexes = lightIndexes;
+	gl_Position = projection * view * worldPos;
+}
@@ -0,0 +1,30 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 uvs;
+layout(location = 13) in vec4 fgColor;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 4) out vec2 fragTexCoord;
+
+void main() {
+	// The model holds the position, size, and roll of the particle, the quad
+	// is turned to face the camera using the axes of the view
+	vec3 center = model[3].xyz;
+	float size = length(model[0].xyz);
+	float roll = atan(model[0].y, model[0].x);
+	float c = cos(roll);
+	float s = sin(roll);
+	vec2 corner = mat2(c, s, -s, c) * (Position.xy * size);
+	vec3 right = vec3(view[0][0], view[1][0], view[2][0]);
+	vec3 up = vec3(view[0][1], view[1][1], view[2][1]);
+	vec3 vPos = center + right * corner.x + up * corner.y;
+	gl_Position = projection * view * vec4(vPos, 1.0);
+	vec2 uv = UV0;
+	uv *= uvs.zw;
+	uv.y += (1.0 - uvs.w) - uvs.y;
+	uv.x += uvs.x;
+	fragColor = Color * fgColor;
+	fragTexCoord = uv;
+}
@@ -0,0 +1,35 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams;
+layout(location = 2) flat in vec4 fragColor;
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+
+// x = threshold, y = intensity, z = radius in pixels
+const int TAPS = 6;
+
+vec3 brightPass(vec2 uv) {
+	vec3 c = texture(texSampler, uv).rgb;
+	float luma = dot(c, vec3(0.2126, 0.7152, 0.0722));
+	return c * smoothstep(fragParams.x, fragParams.x + 0.1, luma);
+}
+
+void main() {
+	vec4 color = texture(texSampler, fragTexCoords);
+	vec2 texel = fragParams.z / vec2(textureSize(texSampler, 0));
+	vec3 bloom = vec3(0.0);
+	float weights = 0.0;
+	for (int y = -TAPS; y <= TAPS; y++) {
+		for (int x = -TAPS; x <= TAPS; x++) {
+			vec2 o = vec2(x, y) / float(TAPS);
+			float w = exp(-2.0 * dot(o, o));
+			bloom += brightPass(fragTexCoords + o * texel * float(TAPS)) * w;
+			weights += w;
+		}
+	}
+	bloom /= weights;
+	outColor = vec4(color.rgb + bloom * fragParams.y, color.a);
+}
@@ -0,0 +1,28 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams;
+layout(location = 2) flat in vec4 fragColor;
+
+// [0] is the color being graded, [1] is the LUT laid out as a horizontal
+// strip of lutSize slices that are each lutSize x lutSize
+layout(binding = 1) uniform sampler2D texSamplers[2];
+
+layout(location = 0) out vec4 outColor;
+
+// x = strength, y = lutSize
+vec3 sampleLUT(vec3 c, float size) {
+	c = clamp(c, 0.0, 1.0) * (size - 1.0);
+	float slice = floor(c.b);
+	float t = c.b - slice;
+	vec2 uv = (vec2(c.r, c.g) + 0.5) / vec2(size * size, size);
+	vec2 a = uv + vec2(slice / size, 0.0);
+	vec2 b = uv + vec2(min(slice + 1.0, size - 1.0) / size, 0.0);
+	return mix(texture(texSamplers[1], a).rgb, texture(texSamplers[1], b).rgb, t);
+}
+
+void main() {
+	vec4 color = texture(texSamplers[0], fragTexCoords);
+	vec3 graded = sampleLUT(color.rgb, max(fragParams.y, 2.0));
+	outColor = vec4(mix(color.rgb, graded, fragParams.x), color.a);
+}
@@ -0,0 +1,44 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams;
+layout(location = 2) flat in vec4 fragColor;
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+
+// x = subpixel blending, y = edge threshold, z = minimum edge threshold
+float luma(vec3 c) {
+	return dot(c, vec3(0.299, 0.587, 0.114));
+}
+
+void main() {
+	vec2 texel = 1.0 / vec2(textureSize(texSampler, 0));
+	vec4 center = texture(texSampler, fragTexCoords);
+	float lumaM = luma(center.rgb);
+	float lumaNW = luma(texture(texSampler, fragTexCoords + vec2(-1.0, -1.0) * texel).rgb);
+	float lumaNE = luma(texture(texSampler, fragTexCoords + vec2(1.0, -1.0) * texel).rgb);
+	float lumaSW = luma(texture(texSampler, fragTexCoords + vec2(-1.0, 1.0) * texel).rgb);
+	float lumaSE = luma(texture(texSampler, fragTexCoords + vec2(1.0, 1.0) * texel).rgb);
+	float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+	float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+	if (lumaMax - lumaMin < max(fragParams.z, lumaMax * fragParams.y)) {
+		outColor = center;
+		return;
+	}
+	vec2 dir = vec2(-((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+		(lumaNW + lumaSW) - (lumaNE + lumaSE));
+	float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * (1.0 / 8.0), 1.0 / 128.0);
+	float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+	dir = clamp(dir * rcpDirMin, vec2(-8.0), vec2(8.0)) * texel;
+	vec3 rgbA = 0.5 * (
+		texture(texSampler, fragTexCoords + dir * (1.0 / 3.0 - 0.5)).rgb +
+		texture(texSampler, fragTexCoords + dir * (2.0 / 3.0 - 0.5)).rgb);
+	vec3 rgbB = rgbA * 0.5 + 0.25 * (
+		texture(texSampler, fragTexCoords + dir * -0.5).rgb +
+		texture(texSampler, fragTexCoords + dir * 0.5).rgb);
+	float lumaB = luma(rgbB);
+	vec3 aa = (lumaB < lumaMin || lumaB > lumaMax) ? rgbA : rgbB;
+	outColor = vec4(mix(center.rgb, aa, clamp(fragParams.x * 1.33, 0.0, 1.0)), center.a);
+}
@@ -0,0 +1,27 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams;
+layout(location = 2) flat in vec4 fragColor;
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+
+// x = exposure, y = gamma, the swap chain encodes sRGB when it is blitted so
+// the gamma is left at 1 unless the target does not
+vec3 acesFilm(vec3 x) {
+	const float a = 2.51;
+	const float b = 0.03;
+	const float c = 2.43;
+	const float d = 0.59;
+	const float e = 0.14;
+	return clamp((x * (a * x + b)) / (x * (c * x + d) + e), 0.0, 1.0);
+}
+
+void main() {
+	vec4 color = texture(texSampler, fragTexCoords);
+	vec3 mapped = acesFilm(color.rgb * fragParams.x);
+	mapped = pow(mapped, vec3(1.0 / max(fragParams.y, 0.0001)));
+	outColor = vec4(mapped, color.a);
+}
@@ -0,0 +1,20 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams;
+layout(location = 2) flat in vec4 fragColor;
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+
+// x = intensity, y = smoothness, z = roundness, the color is the vignette color
+void main() {
+	vec4 color = texture(texSampler, fragTexCoords);
+	vec2 size = vec2(textureSize(texSampler, 0));
+	vec2 d = abs(fragTexCoords - 0.5) * 2.0;
+	d.x *= mix(1.0, size.x / size.y, fragParams.z);
+	float dist = length(d) * 0.7071;
+	float v = smoothstep(1.0 - fragParams.x - fragParams.y, 1.0 - fragParams.x + fragParams.y, dist);
+	outColor = vec4(mix(color.rgb, fragColor.rgb, v * fragColor.a), color.a);
+}
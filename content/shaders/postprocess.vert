@@ -0,0 +1,18 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 params;
+layout(location = LOCATION_START + 1) in vec4 color;
+
+layout(location = 0) out vec2 fragTexCoords;
+layout(location = 1) flat out vec4 fragParams;
+layout(location = 2) flat out vec4 fragColor;
+
+void main() {
+	fragTexCoords = UV0;
+	fragParams = params;
+	fragColor = color;
+	vec3 pos = vec3(Position.x, -Position.y, Position.z) * 2.0;
+	gl_Position = vec4(pos, 1.0);
+}
@@ -0,0 +1,4 @@
+#version 460
+
+void main() {
+}
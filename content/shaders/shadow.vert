@@ -0,0 +1,17 @@
+#version 460
+
+#include "inc_globals.inl"
+
+layout (location = 0) in vec3 Position;
+
+#define LOCATION_HEAD   8
+
+layout(location = LOCATION_HEAD) in mat4 model;
+
+layout(push_constant) uniform ShadowPush {
+	int shadowIndex;
+};
+
+void main() {
+	gl_Position = shadowViewProjections[shadowIndex] * model * vec4(Position, 1.0);
+}
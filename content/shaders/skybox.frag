@@ -0,0 +1,14 @@
+#version 460
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec3 fragDirection;
+
+layout(binding = 1) uniform samplerCube skybox;
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+void main() {
+	vec4 unWeightedColor = texture(skybox, normalize(fragDirection)) * fragColor;
+#include "inc_fragment_oit_block.inl"
+}
@@ -0,0 +1,17 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 color;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec3 fragDirection;
+
+void main() {
+	// Only the rotation of the view is used so the skybox stays around the
+	// camera, and it is drawn on the far plane behind everything else
+	fragColor = Color * color;
+	fragDirection = Position;
+	vec4 pos = projection * mat4(mat3(view)) * vec4(Position, 1.0);
+	gl_Position = pos.xyww;
+}
@@ -0,0 +1,241 @@
+/******************************************************************************/
+/* archive.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package archive
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"kaiju/assets/vfs"
+	"kaiju/klib"
+	"os"
+	"sync"
+)
+
+// Extension is the file extension of asset archives
+const Extension = ".karc"
+
+const (
+	version    = 1
+	headerSize = 8
+	footerSize = 21
+)
+
+const (
+	flagEncrypted = 1 << iota
+)
+
+var magic = [4]byte{'K', 'A', 'R', 'C'}
+
+var (
+	ErrNotFound      = errors.New("the key is not in the archive")
+	ErrNotAnArchive  = errors.New("the file is not an asset archive")
+	ErrEncrypted     = errors.New("the archive is encrypted and no key was given")
+	ErrArchiveClosed = errors.New("the archive is closed")
+)
+
+// entry is where the data of a single asset is within the archive
+type entry struct {
+	Key        string
+	Offset     int64
+	Size       int64
+	RawSize    int64
+	Compressed bool
+}
+
+// Archive is an asset archive that was opened for reading, the data of the
+// assets is read from the file as it is requested. An archive can be read
+// from multiple goroutines at the same time and can be mounted as a
+// vfs.Layer.
+type Archive struct {
+	path    string
+	file    *os.File
+	key     []byte
+	entries map[string]entry
+	mutex   sync.RWMutex
+}
+
+// KeyFromPassphrase turns a passphrase of any length into a key that can be
+// used to encrypt an archive
+func KeyFromPassphrase(passphrase string) []byte {
+	sum := sha256.Sum256([]byte(passphrase))
+	return sum[:]
+}
+
+// Open opens the archive at the path and reads its index, the key is needed
+// if the archive was encrypted and is otherwise ignored
+func Open(filePath string, key []byte) (*Archive, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{path: filePath, file: f, key: key}
+	if err := a.readIndex(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Archive) Path() string { return a.path }
+
+// Keys returns the keys of all of the assets in the archive
+func (a *Archive) Keys() []string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	keys := make([]string, 0, len(a.entries))
+	for k := range a.entries {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (a *Archive) Exists(key string) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	_, ok := a.entries[vfs.NormalizeKey(key)]
+	return ok
+}
+
+// Read reads, decrypts, and decompresses the data of the asset
+func (a *Archive) Read(key string) ([]byte, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.file == nil {
+		return nil, ErrArchiveClosed
+	}
+	e, ok := a.entries[vfs.NormalizeKey(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	data := make([]byte, e.Size)
+	if _, err := a.file.ReadAt(data, e.Offset); err != nil {
+		return nil, err
+	}
+	return unpack(data, e, a.key)
+}
+
+func (a *Archive) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+func (a *Archive) readIndex() error {
+	stat, err := a.file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() < headerSize+footerSize {
+		return ErrNotAnArchive
+	}
+	header := make([]byte, headerSize)
+	if _, err := a.file.ReadAt(header, 0); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:4], magic[:]) {
+		return ErrNotAnArchive
+	} else if v := binary.LittleEndian.Uint32(header[4:]); v != version {
+		return errors.New("the archive version is not supported")
+	}
+	footer := make([]byte, footerSize)
+	if _, err := a.file.ReadAt(footer, stat.Size()-footerSize); err != nil {
+		return err
+	}
+	if !bytes.Equal(footer[17:], magic[:]) {
+		return ErrNotAnArchive
+	}
+	indexOffset := int64(binary.LittleEndian.Uint64(footer[0:]))
+	indexSize := int64(binary.LittleEndian.Uint64(footer[8:]))
+	flags := footer[16]
+	if flags&flagEncrypted == 0 {
+		a.key = nil
+	} else if len(a.key) == 0 {
+		return ErrEncrypted
+	}
+	if indexOffset < headerSize || indexOffset+indexSize > stat.Size()-footerSize {
+		return ErrNotAnArchive
+	}
+	index := make([]byte, indexSize)
+	if _, err := a.file.ReadAt(index, indexOffset); err != nil {
+		return err
+	}
+	if len(a.key) > 0 {
+		if index, err = klib.Decrypt(index, a.key); err != nil {
+			return err
+		}
+	}
+	var entries []entry
+	if err := gob.NewDecoder(bytes.NewReader(index)).Decode(&entries); err != nil {
+		return errors.New("failed to read the archive index, the key may be wrong")
+	}
+	a.entries = make(map[string]entry, len(entries))
+	for i := range entries {
+		a.entries[entries[i].Key] = entries[i]
+	}
+	return nil
+}
+
+func unpack(data []byte, e entry, key []byte) ([]byte, error) {
+	var err error
+	if len(key) > 0 {
+		if data, err = klib.Decrypt(data, key); err != nil {
+			return nil, err
+		}
+	}
+	if !e.Compressed {
+		return data, nil
+	}
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	out := make([]byte, 0, e.RawSize)
+	buf := bytes.NewBuffer(out)
+	if _, err := io.Copy(buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+/******************************************************************************/
+/* archive_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testAssets = map[string][]byte{
+	"textures/a.png":             bytes.Repeat([]byte{1, 2, 3, 4}, 256),
+	"shaders/definitions/b.json": []byte(`{"Name": "b"}`),
+	"empty.txt":                  {},
+}
+
+func testWriteArchive(t *testing.T, options Options) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "test"+Extension)
+	w, err := Create(p, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range testAssets {
+		if err := w.Add(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func testReadAll(t *testing.T, a *Archive) {
+	t.Helper()
+	for k, v := range testAssets {
+		if !a.Exists(k) {
+			t.Errorf("expected %s to exist", k)
+			continue
+		}
+		data, err := a.Read(k)
+		if err != nil {
+			t.Errorf("failed to read %s: %v", k, err)
+		} else if !bytes.Equal(data, v) {
+			t.Errorf("the data of %s does not match", k)
+		}
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, options := range []Options{
+		{},
+		{Compress: true},
+		{Key: KeyFromPassphrase("secret")},
+		{Compress: true, Key: KeyFromPassphrase("secret")},
+	} {
+		a, err := Open(testWriteArchive(t, options), options.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testReadAll(t, a)
+		if len(a.Keys()) != len(testAssets) {
+			t.Errorf("expected %d keys but got %d", len(testAssets), len(a.Keys()))
+		}
+		a.Close()
+	}
+}
+
+func TestArchiveCompressionShrinks(t *testing.T) {
+	plain := testWriteArchive(t, Options{})
+	compressed := testWriteArchive(t, Options{Compress: true})
+	ps, _ := os.Stat(plain)
+	cs, _ := os.Stat(compressed)
+	if cs.Size() >= ps.Size() {
+		t.Errorf("expected the compressed archive (%d) to be smaller than %d", cs.Size(), ps.Size())
+	}
+}
+
+func TestArchiveNormalizesKeys(t *testing.T) {
+	a, err := Open(testWriteArchive(t, Options{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if !a.Exists(`textures\a.png`) || !a.Exists("/textures/a.png") {
+		t.Error("expected the key to be found with other separators")
+	}
+	if _, err := a.Read("missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestArchiveEncryptionNeedsKey(t *testing.T) {
+	p := testWriteArchive(t, Options{Key: KeyFromPassphrase("secret")})
+	if _, err := Open(p, nil); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted but got %v", err)
+	}
+	if _, err := Open(p, KeyFromPassphrase("wrong")); err == nil {
+		t.Error("expected the wrong key to fail")
+	}
+	raw, _ := os.ReadFile(p)
+	if bytes.Contains(raw, []byte("textures/a.png")) {
+		t.Error("expected the index of an encrypted archive to be encrypted")
+	}
+}
+
+func TestArchiveRejectsOtherFiles(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "not"+Extension)
+	os.WriteFile(p, bytes.Repeat([]byte{7}, 64), os.ModePerm)
+	if _, err := Open(p, nil); !errors.Is(err, ErrNotAnArchive) {
+		t.Errorf("expected ErrNotAnArchive but got %v", err)
+	}
+}
+
+func TestArchiveDuplicateKey(t *testing.T) {
+	w, err := Create(filepath.Join(t.TempDir(), "dup"+Extension), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Add("a.txt", []byte("a"))
+	if err := w.Add("./a.txt", []byte("b")); err == nil {
+		t.Error("expected adding the same key twice to fail")
+	}
+}
+
+func TestPackSplitsArchives(t *testing.T) {
+	dir := t.TempDir()
+	keys := []string{"textures/a.png", "shaders/definitions/b.json", "empty.txt"}
+	read := func(key string) ([]byte, error) { return testAssets[key], nil }
+	paths, err := Pack(dir, "content", keys, read, Options{MaxSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected the large asset to start a new archive, got %d archives", len(paths))
+	}
+	found := 0
+	for _, p := range paths {
+		a, err := Open(p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found += len(a.Keys())
+		a.Close()
+	}
+	if found != len(keys) {
+		t.Errorf("expected %d assets across the archives but found %d", len(keys), found)
+	}
+}
@@ -0,0 +1,202 @@
+/******************************************************************************/
+/* writer.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package archive
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"kaiju/assets/vfs"
+	"kaiju/klib"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Options are the settings used when writing archives
+type Options struct {
+	// Compress deflates the data of each asset, assets that do not get
+	// smaller, like already compressed images, are stored as they are
+	Compress bool
+	// Key encrypts the data and the index of the archive when it is set, it
+	// must be 16, 24, or 32 bytes long, see #KeyFromPassphrase
+	Key []byte
+	// MaxSize is the size after which #Pack starts a new archive, a single
+	// asset is never split so an archive can be larger when an asset is
+	// larger than this. There is no limit when it is 0.
+	MaxSize int64
+}
+
+// Writer writes assets into a new archive file, the archive can only be read
+// once the writer is closed
+type Writer struct {
+	file    *os.File
+	options Options
+	entries []entry
+	keys    map[string]struct{}
+	offset  int64
+}
+
+func Create(filePath string, options Options) (*Writer, error) {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic[:])
+	binary.LittleEndian.PutUint32(header[4:], version)
+	if _, err := f.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Writer{
+		file:    f,
+		options: options,
+		keys:    make(map[string]struct{}),
+		offset:  headerSize,
+	}, nil
+}
+
+// Size returns the number of bytes written to the archive so far
+func (w *Writer) Size() int64 { return w.offset }
+
+// Add writes the data of the asset to the archive
+func (w *Writer) Add(key string, data []byte) error {
+	key = vfs.NormalizeKey(key)
+	if _, ok := w.keys[key]; ok {
+		return errors.New("the key " + key + " was already added to the archive")
+	}
+	e := entry{Key: key, Offset: w.offset, RawSize: int64(len(data))}
+	if w.options.Compress {
+		buf := bytes.Buffer{}
+		fw, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			return err
+		}
+		fw.Write(data)
+		if err := fw.Close(); err != nil {
+			return err
+		}
+		if buf.Len() < len(data) {
+			data = buf.Bytes()
+			e.Compressed = true
+		}
+	}
+	if len(w.options.Key) > 0 {
+		var err error
+		if data, err = klib.Encrypt(data, w.options.Key); err != nil {
+			return err
+		}
+	}
+	if _, err := w.file.Write(data); err != nil {
+		return err
+	}
+	e.Size = int64(len(data))
+	w.offset += e.Size
+	w.entries = append(w.entries, e)
+	w.keys[key] = struct{}{}
+	return nil
+}
+
+// Close writes the index of the archive and closes the file
+func (w *Writer) Close() error {
+	defer w.file.Close()
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(w.entries); err != nil {
+		return err
+	}
+	index := buf.Bytes()
+	var flags byte
+	if len(w.options.Key) > 0 {
+		var err error
+		if index, err = klib.Encrypt(index, w.options.Key); err != nil {
+			return err
+		}
+		flags |= flagEncrypted
+	}
+	if _, err := w.file.Write(index); err != nil {
+		return err
+	}
+	footer := make([]byte, footerSize)
+	binary.LittleEndian.PutUint64(footer[0:], uint64(w.offset))
+	binary.LittleEndian.PutUint64(footer[8:], uint64(len(index)))
+	footer[16] = flags
+	copy(footer[17:], magic[:])
+	_, err := w.file.Write(footer)
+	return err
+}
+
+// Pack writes the assets with the keys into archives in the folder, the data
+// of each asset is given by the read function. The archives are named after
+// the name with an index, a new archive is started whenever one grows past
+// the max size in the options. The paths of the archives are returned.
+func Pack(folder, name string, keys []string, read func(key string) ([]byte, error), options Options) ([]string, error) {
+	var paths []string
+	var w *Writer
+	for _, key := range keys {
+		if w != nil && options.MaxSize > 0 && w.Size() >= options.MaxSize {
+			if err := w.Close(); err != nil {
+				return paths, err
+			}
+			w = nil
+		}
+		if w == nil {
+			p := filepath.Join(folder, name+"_"+strconv.Itoa(len(paths))+Extension)
+			var err error
+			if w, err = Create(p, options); err != nil {
+				return paths, err
+			}
+			paths = append(paths, p)
+		}
+		data, err := read(key)
+		if err != nil {
+			w.Close()
+			return paths, err
+		}
+		if err := w.Add(key, data); err != nil {
+			w.Close()
+			return paths, err
+		}
+	}
+	if w != nil {
+		return paths, w.Close()
+	}
+	return paths, nil
+}
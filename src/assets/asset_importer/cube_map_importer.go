@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* cube_map_importer.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"kaiju/assets/asset_info"
+	"kaiju/editor/editor_config"
+	"kaiju/filesystem"
+	"kaiju/rendering"
+	"path/filepath"
+	"strings"
+)
+
+type CubeMapImporter struct{}
+
+func (m CubeMapImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionCubeMap
+}
+
+func (m CubeMapImporter) Version() int { return 1 }
+
+// Import reads the cube map definition, when the definition only has an
+// equirectangular image, the faces are generated next to the definition and
+// the definition is updated to use them so they are not generated at runtime
+func (m CubeMapImporter) Import(path string) error {
+	src, err := filesystem.ReadTextFile(path)
+	if err != nil {
+		return err
+	}
+	def, err := rendering.CubeMapDefFromJson(src)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if !def.HasFaces() {
+		if err := generateCubeMapFaces(path, &def); err != nil {
+			return err
+		}
+	}
+	for i := range def.Faces {
+		if err := noMutationImport(filepath.Join(dir, def.Faces[i]),
+			editor_config.AssetTypeImage); err != nil {
+			return err
+		}
+	}
+	adi, err := createADI(path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeCubeMap
+	adi.Dependencies = adi.Dependencies[:0]
+	if def.Equirectangular != "" {
+		adi.Metadata["equirectangular"] = def.Equirectangular
+		if id, ok := dependencyID(filepath.Join(dir, def.Equirectangular)); ok {
+			adi.AddDependency(id)
+		}
+	}
+	for i := range def.Faces {
+		if id, ok := dependencyID(filepath.Join(dir, def.Faces[i])); ok {
+			adi.AddDependency(id)
+		}
+	}
+	return asset_info.Write(adi)
+}
+
+func generateCubeMapFaces(path string, def *rendering.CubeMapDef) error {
+	dir := filepath.Dir(path)
+	mem, err := filesystem.ReadFile(filepath.Join(dir, def.Equirectangular))
+	if err != nil {
+		return err
+	}
+	img := rendering.ReadRawTextureData(mem, rendering.TextureFileFormatPng)
+	faces, err := rendering.EquirectangularToCubeMap(img, def.Size)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for i := range faces {
+		rgba := image.NewRGBA(image.Rect(0, 0, faces[i].Width, faces[i].Height))
+		copy(rgba.Pix, faces[i].Mem)
+		buf := bytes.Buffer{}
+		if err := png.Encode(&buf, rgba); err != nil {
+			return err
+		}
+		def.Faces[i] = name + rendering.CubeMapFaceSuffixes[i] + editor_config.FileExtensionPng
+		if err := filesystem.WriteFile(filepath.Join(dir, def.Faces[i]), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	json, err := def.ToJson()
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteTextFile(path, json)
+}
@@ -0,0 +1,243 @@
+/******************************************************************************/
+/* gltf_importer.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"kaiju/assets"
+	"kaiju/assets/asset_info"
+	"kaiju/assets/vfs"
+	"kaiju/cache/project_cache"
+	"kaiju/editor/editor_config"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders"
+	"kaiju/rendering/loaders/load_result"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/KaijuEngine/uuid"
+)
+
+// GLTFImporter imports .gltf and .glb files, as well as .fbx and .dae files
+// which are read into the same load result. The file is split into child
+// assets for each of its embedded images, materials, meshes, animations,
+// and its skeleton, which are stored in the project cache. The node
+// hierarchy of the file is cached with the file itself.
+type GLTFImporter struct{}
+
+func (m GLTFImporter) Handles(path string) bool {
+	switch filepath.Ext(path) {
+	case editor_config.FileExtensionGltf, editor_config.FileExtensionGlb,
+		editor_config.FileExtensionFbx, editor_config.FileExtensionDae:
+		return true
+	}
+	return false
+}
+
+func (m GLTFImporter) Version() int { return 1 }
+
+func (m GLTFImporter) Import(path string) error {
+	adi, err := createADI(path, nil)
+	if err != nil {
+		return err
+	}
+	// The IDs of the children are kept between imports so that stages and
+	// other assets using them are not broken when the file changes
+	ids := gltfChildIDs(adi)
+	if err := project_cache.DeleteModel(adi); err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeGltf
+	adi.Children = adi.Children[:0]
+	adi.Metadata = make(map[string]string)
+	adi.Dependencies = adi.Dependencies[:0]
+	db := assets.NewMemoryDatabase(nil)
+	defer db.Destroy()
+	db.Mount("model", vfs.NewDirectoryLayer(filepath.Dir(path)), vfs.PriorityContent)
+	var res load_result.Result
+	switch filepath.Ext(path) {
+	case editor_config.FileExtensionFbx:
+		res, err = loaders.FBX(filepath.Base(path), &db)
+	case editor_config.FileExtensionDae:
+		res, err = loaders.Collada(filepath.Base(path), &db)
+	default:
+		res, err = loaders.GLTF(filepath.Base(path), &db)
+	}
+	if err != nil {
+		return err
+	}
+	if err := importGLTFToCache(&adi, res, ids); err != nil {
+		return err
+	}
+	return asset_info.Write(adi)
+}
+
+func gltfChildKey(childType, index string) string {
+	return childType + "/" + index
+}
+
+func gltfChildIDs(adi asset_info.AssetDatabaseInfo) map[string]string {
+	ids := make(map[string]string, len(adi.Children))
+	for i := range adi.Children {
+		c := &adi.Children[i]
+		ids[gltfChildKey(c.Type, c.MetaValue("index"))] = c.ID
+	}
+	return ids
+}
+
+func importGLTFToCache(adi *asset_info.AssetDatabaseInfo, res load_result.Result, ids map[string]string) error {
+	spawn := func(childType string, index int, name string) asset_info.AssetDatabaseInfo {
+		id, ok := ids[gltfChildKey(childType, strconv.Itoa(index))]
+		if !ok {
+			id = uuid.New().String()
+		}
+		info := adi.SpawnChild(id)
+		info.Type = childType
+		info.Metadata["index"] = strconv.Itoa(index)
+		info.Metadata["name"] = name
+		return info
+	}
+	dir := filepath.Dir(adi.Path)
+	if err := project_cache.CacheModelNodes(*adi, res.Nodes); err != nil {
+		return err
+	}
+	imageKeys := make([]string, len(res.Images))
+	for i := range res.Images {
+		img := &res.Images[i]
+		if len(img.Data) == 0 {
+			imageKeys[i] = contentKey(filepath.Join(dir, filepath.FromSlash(img.URI)))
+			if id, ok := dependencyID(imageKeys[i]); ok {
+				adi.AddDependency(id)
+			}
+			continue
+		}
+		info := spawn(editor_config.AssetTypeImage, i, img.Name)
+		data, err := decodeEmbeddedImage(img.Data)
+		if err != nil {
+			return err
+		}
+		if err := cacheTextureData(&info, data); err != nil {
+			return err
+		}
+		imageKeys[i] = info.ID + rendering.ProcessedTextureExtension
+		adi.Children = append(adi.Children, info)
+	}
+	materialIds := make([]string, len(res.Materials))
+	for i := range res.Materials {
+		def := res.Materials[i]
+		if i < len(res.MaterialImages) && res.MaterialImages[i] >= 0 {
+			def.Textures[0].Texture = imageKeys[res.MaterialImages[i]]
+		}
+		info := spawn(editor_config.AssetTypeMaterial, i, "material_"+strconv.Itoa(i))
+		if err := project_cache.CacheMaterial(info, def); err != nil {
+			return err
+		}
+		materialIds[i] = info.ID
+		adi.Children = append(adi.Children, info)
+	}
+	for i := range res.Meshes {
+		o := &res.Meshes[i]
+		info := spawn(editor_config.AssetTypeMesh, i, o.MeshName)
+		info.Metadata["node"] = strconv.Itoa(o.Node)
+		if o.Material >= 0 {
+			info.Metadata["material"] = materialIds[o.Material]
+		}
+		if err := project_cache.CacheMesh(info, *o); err != nil {
+			return err
+		}
+		if err := project_cache.CacheMeshLODs(info, *o); err != nil {
+			return err
+		}
+		adi.Children = append(adi.Children, info)
+	}
+	for i := range res.Animations {
+		info := spawn(editor_config.AssetTypeAnimation, i, res.Animations[i].Name)
+		if err := project_cache.CacheAnimation(info, res.Animations[i]); err != nil {
+			return err
+		}
+		adi.Children = append(adi.Children, info)
+	}
+	if len(res.Joints) > 0 {
+		info := spawn(editor_config.AssetTypeSkeleton, 0, "skeleton")
+		if err := project_cache.CacheSkeleton(info, res.Joints); err != nil {
+			return err
+		}
+		adi.Children = append(adi.Children, info)
+	}
+	adi.Metadata["name"] = strings.TrimSuffix(filepath.Base(adi.Path), filepath.Ext(adi.Path))
+	return nil
+}
+
+// contentKey turns the path of a file into its asset key, which is the
+// path relative to the content folder
+func contentKey(path string) string {
+	if rel, err := filepath.Rel(contentFolder, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// decodeEmbeddedImage decodes a png or jpeg image that was embedded in a
+// model file into RGBA pixels
+func decodeEmbeddedImage(data []byte) (rendering.TextureData, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return rendering.TextureData{}, err
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return rendering.TextureData{}, errors.New("the embedded image is empty")
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rendering.TextureData{
+		Mem:            rgba.Pix,
+		InternalFormat: rendering.TextureInputTypeRgba8,
+		Format:         rendering.TextureColorFormatRgbaUnorm,
+		Type:           rendering.TextureMemTypeUnsignedByte,
+		Width:          b.Dx(),
+		Height:         b.Dy(),
+		InputType:      rendering.TextureFileFormatRaw,
+	}, nil
+}
@@ -49,6 +49,8 @@ func (m HTMLImporter) Handles(path string) bool {
 	return filepath.Ext(path) == editor_config.FileExtensionHTML
 }
 
+func (m HTMLImporter) Version() int { return 1 }
+
 func (m HTMLImporter) Import(path string) error {
 	adi, err := createADI(path, nil)
 	if err != nil {
@@ -41,6 +41,7 @@ import (
 	"errors"
 	"kaiju/assets/asset_info"
 	"kaiju/editor/editor_config"
+	"kaiju/filesystem"
 	"path/filepath"
 
 	"github.com/KaijuEngine/uuid"
@@ -70,6 +71,14 @@ func (r *ImportRegistry) Register(importer Importer) {
 	r.importers = append(r.importers, importer)
 }
 
+// VersionedImporter is an #Importer that has a version, the version is
+// raised whenever the importer changes how it imports files so that the
+// files it has already imported are imported again
+type VersionedImporter interface {
+	Importer
+	Version() int
+}
+
 func (r *ImportRegistry) ImportIfNew(path string) error {
 	if filepath.Ext(path) == asset_info.InfoExtension {
 		return nil
@@ -80,15 +89,40 @@ func (r *ImportRegistry) ImportIfNew(path string) error {
 	return nil
 }
 
+// NeedsImport returns true if the file has not been imported yet, if the
+// file changed since it was imported, or if the importer for the file has a
+// different version than the one that imported it
+func (r *ImportRegistry) NeedsImport(path string) bool {
+	if filepath.Ext(path) == asset_info.InfoExtension {
+		return false
+	}
+	importer, ok := r.importer(path)
+	if !ok {
+		return false
+	}
+	adi, err := asset_info.Read(path)
+	if err != nil || adi.ImporterVersion != importerVersion(importer) {
+		return true
+	}
+	hash, err := asset_info.Hash(path)
+	return err != nil || hash != adi.Hash
+}
+
+// ImportIfChanged imports the file if #ImportRegistry.NeedsImport says it
+// should be, true is returned if the file was imported
+func (r *ImportRegistry) ImportIfChanged(path string) (bool, error) {
+	if !r.NeedsImport(path) {
+		return false, nil
+	}
+	return true, r.Import(path)
+}
+
 func (r *ImportRegistry) Import(path string) error {
 	if filepath.Ext(path) == asset_info.InfoExtension {
 		return nil
 	}
-	// We go back to front so devs can override default importers
-	for i := len(r.importers) - 1; i >= 0; i-- {
-		if r.importers[i].Handles(path) {
-			return r.importers[i].Import(path)
-		}
+	if importer, ok := r.importer(path); ok {
+		return importWith(importer, path)
 	}
 	return ErrNoImporter
 }
@@ -96,19 +130,119 @@ func (r *ImportRegistry) Import(path string) error {
 func (r *ImportRegistry) ImportUsingDefault(path string) error {
 	for i := range r.importers {
 		if r.importers[i].Handles(path) {
-			return r.importers[i].Import(path)
+			return importWith(r.importers[i], path)
 		}
 	}
 	return ErrNoImporter
 }
 
-func createADI(path string, cleanup func(adi asset_info.AssetDatabaseInfo)) (asset_info.AssetDatabaseInfo, error) {
+// ImportDependents imports every asset within the root folder that uses one
+// of the assets at the paths again, directly or through other assets, along
+// with the assets that are made from the files of a folder holding one of the
+// paths. The paths may be files that were removed. The assets are imported
+// before the assets that depend on them.
+func (r *ImportRegistry) ImportDependents(root string, paths []string) error {
+	infos, err := asset_info.ReadAll(root)
+	if err != nil {
+		return err
+	}
+	graph := asset_info.NewDependencyGraph(infos)
+	imported := make(map[string]struct{}, len(paths))
+	for i := range paths {
+		imported[paths[i]] = struct{}{}
+	}
+	var errs []error
+	for i := range paths {
+		deps := graph.FolderDependents(paths[i])
+		if id, err := asset_info.ID(paths[i]); err == nil {
+			deps = append(deps, graph.TransitiveDependents(id)...)
+		}
+		for _, dep := range deps {
+			info, _ := graph.Info(dep)
+			if _, ok := imported[info.Path]; ok {
+				continue
+			}
+			imported[info.Path] = struct{}{}
+			if err := r.Import(info.Path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importer returns the importer for the path, the importers are searched
+// back to front so devs can override default importers
+func (r *ImportRegistry) importer(path string) (Importer, bool) {
+	for i := len(r.importers) - 1; i >= 0; i-- {
+		if r.importers[i].Handles(path) {
+			return r.importers[i], true
+		}
+	}
+	return nil, false
+}
+
+func importerVersion(importer Importer) int {
+	if v, ok := importer.(VersionedImporter); ok {
+		return v.Version()
+	}
+	return 0
+}
+
+// importWith imports the file and then records the content hash of the file
+// and the version of the importer in the ADI file
+func importWith(importer Importer, path string) error {
+	if err := importer.Import(path); err != nil {
+		return err
+	}
+	adi, err := asset_info.Read(path)
+	if err != nil {
+		return err
+	}
+	if adi.Hash, err = asset_info.Hash(path); err != nil {
+		return err
+	}
+	adi.ImporterVersion = importerVersion(importer)
+	return asset_info.Write(adi)
+}
+
+// dependencyID returns the ID of the asset for the key, the key can be the ID
+// itself or the path of the asset within the content folder. False is
+// returned for keys that are not assets of the project, like the assets that
+// come with the engine. Files that were not imported yet are given their ADI
+// file now so that they already have their ID when they are imported.
+func dependencyID(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	if _, err := asset_info.Lookup(key); err == nil {
+		return key, true
+	}
+	for _, path := range []string{key, filepath.Join(contentFolder, key)} {
+		if !filesystem.FileExists(path) {
+			continue
+		}
+		adi, err := createADI(path, nil)
+		if err != nil {
+			return "", false
+		}
+		if !asset_info.Exists(path) {
+			if err := asset_info.Write(adi); err != nil {
+				return "", false
+			}
+		}
+		return adi.ID, true
+	}
+	return "", false
+}
+
+func createADI(path string, cleanup func(adi *asset_info.AssetDatabaseInfo)) (asset_info.AssetDatabaseInfo, error) {
 	adi, err := asset_info.Read(path)
 	if errors.Is(err, asset_info.ErrNoInfo) {
 		adi = asset_info.New(path, uuid.New().String())
 		err = nil
 	} else if err == nil && cleanup != nil {
-		cleanup(adi)
+		cleanup(&adi)
 	}
 	return adi, err
 }
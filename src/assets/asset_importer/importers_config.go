@@ -43,6 +43,10 @@ import (
 
 type ImportType = string
 
+// contentFolder is the folder of the project that the asset keys are
+// relative to
+const contentFolder = "content"
+
 var (
 	ErrNoImporter = errors.New("no importer found for file")
 )
@@ -44,7 +44,7 @@ import (
 	"kaiju/cache/project_cache"
 	"kaiju/editor/editor_config"
 	"kaiju/filesystem"
-	"kaiju/rendering/loaders"
+	"kaiju/rendering/loaders/obj"
 	"path/filepath"
 
 	"github.com/KaijuEngine/uuid"
@@ -56,10 +56,13 @@ func (m OBJImporter) Handles(path string) bool {
 	return filepath.Ext(path) == editor_config.FileExtensionObj
 }
 
-func cleanupOBJ(adi asset_info.AssetDatabaseInfo) {
-	project_cache.DeleteMesh(adi)
+func (m OBJImporter) Version() int { return 1 }
+
+func cleanupOBJ(adi *asset_info.AssetDatabaseInfo) {
+	project_cache.DeleteMesh(*adi)
 	adi.Children = adi.Children[:0]
 	adi.Metadata = make(map[string]string)
+	adi.Dependencies = adi.Dependencies[:0]
 }
 
 func (m OBJImporter) Import(path string) error {
@@ -79,7 +82,13 @@ func importMeshToCache(adi *asset_info.AssetDatabaseInfo) error {
 	if err != nil {
 		return err
 	}
-	res := loaders.OBJ(src)
+	dir := filepath.Dir(adi.Path)
+	res, err := obj.Load(src, func(name string) (string, error) {
+		return filesystem.ReadTextFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+	if err != nil {
+		return err
+	}
 	if len(res.Meshes) == 0 {
 		return errors.New("no meshes found in OBJ file")
 	}
@@ -91,9 +100,19 @@ func importMeshToCache(adi *asset_info.AssetDatabaseInfo) error {
 		if err := project_cache.CacheMesh(info, o); err != nil {
 			return err
 		}
-		// TODO:  Write the correct material to the adi
+		if err := project_cache.CacheMeshLODs(info, o); err != nil {
+			return err
+		}
 		info.Metadata["shader"] = assets.ShaderDefinitionBasic
 		info.Metadata["texture"] = assets.TextureSquare
+		if o.Material >= 0 && res.MaterialImages[o.Material] >= 0 {
+			uri := res.Images[res.MaterialImages[o.Material]].URI
+			key := contentKey(filepath.Join(dir, filepath.FromSlash(uri)))
+			info.Metadata["texture"] = key
+			if id, ok := dependencyID(key); ok {
+				adi.AddDependency(id)
+			}
+		}
 		info.Metadata["name"] = o.MeshName
 		adi.Children = append(adi.Children, info)
 	}
@@ -38,16 +38,88 @@
 package asset_importer
 
 import (
+	"errors"
+	"kaiju/assets/asset_info"
+	"kaiju/cache/project_cache"
 	"kaiju/editor/editor_config"
+	"kaiju/filesystem"
+	"kaiju/rendering"
 	"path/filepath"
+	"strconv"
 )
 
+// pngCompressionKey is the metadata of the ADI that selects which compression
+// the image is encoded to, it can be changed in the ADI and is kept when the
+// image is imported again
+const pngCompressionKey = "compression"
+
 type PNGImporter struct{}
 
 func (m PNGImporter) Handles(path string) bool {
 	return filepath.Ext(path) == ".png"
 }
 
+func (m PNGImporter) Version() int { return 1 }
+
+func cleanupPNG(adi *asset_info.AssetDatabaseInfo) {
+	project_cache.DeleteImageTexture(contentKey(adi.Path))
+}
+
 func (m PNGImporter) Import(path string) error {
-	return noMutationImport(path, editor_config.AssetTypeImage)
+	adi, err := createADI(path, cleanupPNG)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeImage
+	if err := importTextureToCache(&adi); err != nil {
+		return err
+	}
+	return asset_info.Write(adi)
+}
+
+// importTextureToCache generates the mips of the image and encodes them to
+// the compression chosen in the metadata, the result is stored in the cache
+// under the content key of the image so that it is read in place of the image
+func importTextureToCache(adi *asset_info.AssetDatabaseInfo) error {
+	mem, err := filesystem.ReadFile(adi.Path)
+	if err != nil {
+		return err
+	}
+	data := rendering.ReadRawTextureData(mem, rendering.TextureFileFormatPng)
+	if data.Width == 0 || data.Height == 0 {
+		return errors.New("failed to decode the png image " + adi.Path)
+	}
+	tex, err := processTextureData(adi, data)
+	if err != nil {
+		return err
+	}
+	return project_cache.CacheImageTexture(contentKey(adi.Path), tex)
+}
+
+// cacheTextureData processes the decoded image of the asset and stores the
+// result in the cache under the asset ID
+func cacheTextureData(adi *asset_info.AssetDatabaseInfo, data rendering.TextureData) error {
+	tex, err := processTextureData(adi, data)
+	if err != nil {
+		return err
+	}
+	return project_cache.CacheTexture(*adi, tex)
+}
+
+// processTextureData generates the mips and compressed formats for the
+// decoded image of the asset and records them in its metadata
+func processTextureData(adi *asset_info.AssetDatabaseInfo, data rendering.TextureData) (rendering.ProcessedTexture, error) {
+	compression := adi.MetaValue(pngCompressionKey)
+	if compression == "" {
+		compression = rendering.TextureCompressionNone
+	}
+	tex, err := rendering.ProcessTexture(data, compression)
+	if err != nil {
+		return tex, err
+	}
+	adi.Metadata[pngCompressionKey] = compression
+	adi.Metadata["mips"] = strconv.Itoa(len(tex.Variants[0].Mips))
+	adi.Metadata["width"] = strconv.Itoa(tex.Width)
+	adi.Metadata["height"] = strconv.Itoa(tex.Height)
+	return tex, nil
 }
@@ -0,0 +1,195 @@
+/******************************************************************************/
+/* sprite_atlas_importer.go                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"io/fs"
+	"kaiju/assets/asset_info"
+	"kaiju/cache/project_cache"
+	"kaiju/editor/editor_config"
+	"kaiju/filesystem"
+	"kaiju/systems/visual2d/sprite_atlas"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// spriteAtlasPagesKey is the metadata of the ADI that holds the number of
+// pages the atlas was packed into, so that pages left over from a larger
+// packing are removed
+const spriteAtlasPagesKey = "pages"
+
+type SpriteAtlasImporter struct{}
+
+func (m SpriteAtlasImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionSpriteAtlas
+}
+
+func (m SpriteAtlasImporter) Version() int { return 1 }
+
+// Import packs the PNG images within the folder of the atlas definition and
+// writes the pages next to the definition, "hero.atlas" is packed into
+// "hero.png" and the sprite sheet "hero.json", further pages are numbered
+// like "hero_1.png" and "hero_1.json". The atlas depends on the folder, so it
+// is packed again when an image is added to or removed from the folder.
+func (m SpriteAtlasImporter) Import(path string) error {
+	src, err := filesystem.ReadTextFile(path)
+	if err != nil {
+		return err
+	}
+	def, err := sprite_atlas.DefFromJson(src)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	folder := filepath.Join(dir, def.Folder)
+	if filepath.Clean(folder) == filepath.Clean(dir) {
+		// The pages would be packed into the atlas the next time it is imported
+		return errors.New("the images of the sprite atlas must be in a sub folder")
+	}
+	images, files, err := readSpriteAtlasImages(folder)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return errors.New("the sprite atlas folder has no png images " + folder)
+	}
+	pages, err := sprite_atlas.Pack(images, def)
+	if err != nil {
+		return err
+	}
+	adi, err := createADI(path, nil)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	oldPages, _ := strconv.Atoi(adi.MetaValue(spriteAtlasPagesKey))
+	for i := range pages {
+		if err := writeSpriteAtlasPage(filepath.Join(dir, spriteAtlasPageName(name, i)), &pages[i]); err != nil {
+			return err
+		}
+	}
+	for i := len(pages); i < oldPages; i++ {
+		removeSpriteAtlasPage(filepath.Join(dir, spriteAtlasPageName(name, i)))
+	}
+	adi.Type = editor_config.AssetTypeSpriteAtlas
+	adi.Metadata[spriteAtlasPagesKey] = strconv.Itoa(len(pages))
+	adi.Folders = []string{folder}
+	adi.Dependencies = adi.Dependencies[:0]
+	for i := range files {
+		if id, ok := dependencyID(files[i]); ok {
+			adi.AddDependency(id)
+		}
+	}
+	return asset_info.Write(adi)
+}
+
+func spriteAtlasPageName(name string, page int) string {
+	if page > 0 {
+		name += "_" + strconv.Itoa(page)
+	}
+	return name
+}
+
+// readSpriteAtlasImages decodes the PNG images within the folder and its sub
+// folders, the images are named by their path relative to the folder
+func readSpriteAtlasImages(folder string) ([]sprite_atlas.Image, []string, error) {
+	images := []sprite_atlas.Image{}
+	files := []string{}
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != editor_config.FileExtensionPng {
+			return err
+		}
+		mem, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(bytes.NewReader(mem))
+		if err != nil {
+			return errors.New("failed to decode the png image " + path + ": " + err.Error())
+		}
+		rel, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+		images = append(images, sprite_atlas.Image{Name: rel, Image: img})
+		files = append(files, path)
+		return nil
+	})
+	return images, files, err
+}
+
+// writeSpriteAtlasPage writes the image and sprite sheet of the page at the
+// path without an extension, the image is imported as a texture
+func writeSpriteAtlasPage(path string, page *sprite_atlas.Page) error {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, page.Image); err != nil {
+		return err
+	}
+	imagePath := path + editor_config.FileExtensionPng
+	if err := filesystem.WriteFile(imagePath, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := importWith(PNGImporter{}, imagePath); err != nil {
+		return err
+	}
+	json, err := page.Data.ToJson()
+	if err != nil {
+		return err
+	}
+	sheetPath := path + ".json"
+	if err := filesystem.WriteTextFile(sheetPath, json); err != nil {
+		return err
+	}
+	return noMutationImport(sheetPath, editor_config.AssetTypeSpriteSheet)
+}
+
+// removeSpriteAtlasPage removes the files of a page that is no longer used,
+// the page may have already been removed by hand so errors are ignored
+func removeSpriteAtlasPage(path string) {
+	imagePath := path + editor_config.FileExtensionPng
+	project_cache.DeleteImageTexture(contentKey(imagePath))
+	for _, p := range []string{imagePath, path + ".json"} {
+		os.Remove(p)
+		os.Remove(p + asset_info.InfoExtension)
+	}
+}
@@ -49,6 +49,8 @@ func (m StageImporter) Handles(path string) bool {
 	return filepath.Ext(path) == editor_config.FileExtensionStage
 }
 
+func (m StageImporter) Version() int { return 1 }
+
 func (m StageImporter) Import(path string) error {
 	adi, err := createADI(path, nil)
 	if err != nil {
@@ -57,3 +59,20 @@ func (m StageImporter) Import(path string) error {
 	adi.Type = editor_config.AssetTypeStage
 	return asset_info.Write(adi)
 }
+
+// RecordStageDependencies replaces the dependencies of the stage with the
+// assets for the keys, it is called by the editor when the stage is saved as
+// the stage file can only be read with a running host
+func RecordStageDependencies(path string, keys []string) error {
+	adi, err := asset_info.Read(path)
+	if err != nil {
+		return err
+	}
+	adi.Dependencies = adi.Dependencies[:0]
+	for i := range keys {
+		if id, ok := dependencyID(keys[i]); ok {
+			adi.AddDependency(id)
+		}
+	}
+	return asset_info.Write(adi)
+}
@@ -62,6 +62,17 @@ type AssetDatabaseInfo struct {
 	ParentID string
 	Children []AssetDatabaseInfo
 	Metadata map[string]string
+	// Hash is the content hash of the file when it was last imported
+	Hash string `json:",omitempty"`
+	// ImporterVersion is the version of the importer that last imported the
+	// file, the file is imported again when the importer version changes
+	ImporterVersion int `json:",omitempty"`
+	// Dependencies are the IDs of the assets that this asset uses
+	Dependencies []string `json:",omitempty"`
+	// Folders are the folders of the files that this asset is made from, the
+	// asset is imported again when a file within them is added, changed, or
+	// removed, even if it did not depend on the file before
+	Folders []string `json:",omitempty"`
 }
 
 func InitForCurrentProject() error {
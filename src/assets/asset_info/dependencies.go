@@ -0,0 +1,224 @@
+/******************************************************************************/
+/* dependencies.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_info
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"kaiju/filesystem"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DependencyGraph is the graph of which assets use which other assets, it is
+// built from the dependencies recorded in the ADI files
+type DependencyGraph struct {
+	infos      map[string]AssetDatabaseInfo
+	parents    map[string]string
+	dependents map[string][]string
+}
+
+// Hash returns the content hash of the file at the path, it is stored in the
+// ADI file when the file is imported to know if the file changed since
+func Hash(path string) (string, error) {
+	data, err := filesystem.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AddDependency records that this asset uses the asset with the given id,
+// the same id is only recorded once
+func (a *AssetDatabaseInfo) AddDependency(id string) {
+	if id == "" || id == a.ID || slices.Contains(a.Dependencies, id) {
+		return
+	}
+	a.Dependencies = append(a.Dependencies, id)
+}
+
+// ReadAll reads all of the ADI files within the folder
+func ReadAll(root string) ([]AssetDatabaseInfo, error) {
+	infos := []AssetDatabaseInfo{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != InfoExtension {
+			return err
+		}
+		adi, err := Read(strings.TrimSuffix(path, InfoExtension))
+		if err != nil {
+			return err
+		}
+		infos = append(infos, adi)
+		return nil
+	})
+	return infos, err
+}
+
+// NewDependencyGraph creates the graph for the assets, the children of the
+// assets are part of the graph as well
+func NewDependencyGraph(infos []AssetDatabaseInfo) DependencyGraph {
+	g := DependencyGraph{
+		infos:      make(map[string]AssetDatabaseInfo),
+		parents:    make(map[string]string),
+		dependents: make(map[string][]string),
+	}
+	for i := range infos {
+		g.add(infos[i])
+	}
+	return g
+}
+
+func (g *DependencyGraph) add(info AssetDatabaseInfo) {
+	g.infos[info.ID] = info
+	for _, dep := range info.Dependencies {
+		g.dependents[dep] = append(g.dependents[dep], info.ID)
+	}
+	for i := range info.Children {
+		g.parents[info.Children[i].ID] = info.ID
+		g.add(info.Children[i])
+	}
+}
+
+// Info returns the info of the asset with the id
+func (g *DependencyGraph) Info(id string) (AssetDatabaseInfo, bool) {
+	info, ok := g.infos[id]
+	return info, ok
+}
+
+// Dependencies returns the ids of the assets that the asset uses directly
+func (g *DependencyGraph) Dependencies(id string) []string {
+	return g.infos[id].Dependencies
+}
+
+// Dependents returns the ids of the assets that use the asset or one of its
+// children directly
+func (g *DependencyGraph) Dependents(id string) []string {
+	found := []string{}
+	g.eachDependent(id, func(dep string) {
+		if !slices.Contains(found, dep) {
+			found = append(found, dep)
+		}
+	})
+	return found
+}
+
+// TransitiveDependents returns the ids of every asset that would need to be
+// imported again when the asset changes, these are the assets that use the
+// asset directly or through other assets. Assets come before the assets
+// that depend on them.
+func (g *DependencyGraph) TransitiveDependents(id string) []string {
+	order := []string{}
+	visited := map[string]bool{id: true}
+	var visit func(id string)
+	visit = func(id string) {
+		g.eachDependent(id, func(dep string) {
+			dep = g.root(dep)
+			if !visited[dep] {
+				visited[dep] = true
+				visit(dep)
+				order = append(order, dep)
+			}
+		})
+	}
+	visit(id)
+	slices.Reverse(order)
+	return order
+}
+
+// FolderDependents returns the ids of the assets that are made from the files
+// of a folder holding the path (see #AssetDatabaseInfo.Folders), each one is
+// followed by the assets that depend on it. The file at the path does not
+// need to exist, so that the assets are imported again when it is removed.
+func (g *DependencyGraph) FolderDependents(path string) []string {
+	owners := []string{}
+	for id, info := range g.infos {
+		if slices.ContainsFunc(info.Folders, func(folder string) bool {
+			return inFolder(folder, path)
+		}) {
+			owners = append(owners, id)
+		}
+	}
+	slices.Sort(owners)
+	order := []string{}
+	for _, id := range owners {
+		for _, dep := range append([]string{id}, g.TransitiveDependents(id)...) {
+			if !slices.Contains(order, dep) {
+				order = append(order, dep)
+			}
+		}
+	}
+	return order
+}
+
+// IsUsed returns true if any asset uses the asset or one of its children, it
+// should be checked before deleting an asset
+func (g *DependencyGraph) IsUsed(id string) bool {
+	return len(g.Dependents(id)) > 0
+}
+
+func (g *DependencyGraph) eachDependent(id string, call func(dep string)) {
+	for _, dep := range g.dependents[id] {
+		call(dep)
+	}
+	info := g.infos[id]
+	for i := range info.Children {
+		g.eachDependent(info.Children[i].ID, call)
+	}
+}
+
+// root returns the id of the top most parent of the asset, it is the asset
+// that has the file that is imported
+func (g *DependencyGraph) root(id string) string {
+	for {
+		parent, ok := g.parents[id]
+		if !ok {
+			return id
+		}
+		id = parent
+	}
+}
+
+// inFolder returns true if the path is within the folder or its sub folders
+func inFolder(folder, path string) bool {
+	rel, err := filepath.Rel(folder, path)
+	return err == nil && rel != "." && rel != ".." &&
+		!strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,152 @@
+/******************************************************************************/
+/* dependencies_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_info
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testDependencyGraph is a texture used by a material, a model with a mesh
+// child, and a stage that uses the material and the mesh of the model
+func testDependencyGraph() DependencyGraph {
+	texture := AssetDatabaseInfo{ID: "texture", Path: "content/texture.png"}
+	material := AssetDatabaseInfo{ID: "material", Path: "content/a.material",
+		Dependencies: []string{"texture"}}
+	model := AssetDatabaseInfo{ID: "model", Path: "content/model.obj"}
+	model.Children = append(model.Children, model.SpawnChild("mesh"))
+	stage := AssetDatabaseInfo{ID: "stage", Path: "content/a.stg",
+		Dependencies: []string{"material", "mesh", "texture"}}
+	return NewDependencyGraph([]AssetDatabaseInfo{stage, material, model, texture})
+}
+
+func TestAddDependency(t *testing.T) {
+	adi := AssetDatabaseInfo{ID: "a"}
+	adi.AddDependency("b")
+	adi.AddDependency("b")
+	adi.AddDependency("a")
+	adi.AddDependency("")
+	if !slices.Equal(adi.Dependencies, []string{"b"}) {
+		t.Errorf("expected the dependencies to be [b] but were %v", adi.Dependencies)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	g := testDependencyGraph()
+	deps := g.Dependents("texture")
+	slices.Sort(deps)
+	if !slices.Equal(deps, []string{"material", "stage"}) {
+		t.Errorf("expected the texture to be used by the material and stage but was used by %v", deps)
+	}
+	if deps := g.Dependents("model"); !slices.Equal(deps, []string{"stage"}) {
+		t.Errorf("expected the model to be used through its mesh by the stage but was used by %v", deps)
+	}
+	if g.IsUsed("stage") {
+		t.Error("expected nothing to use the stage")
+	}
+	if !g.IsUsed("mesh") {
+		t.Error("expected the mesh to be used")
+	}
+}
+
+func TestTransitiveDependents(t *testing.T) {
+	g := testDependencyGraph()
+	deps := g.TransitiveDependents("texture")
+	if !slices.Equal(deps, []string{"material", "stage"}) {
+		t.Errorf("expected the material to come before the stage but got %v", deps)
+	}
+	if deps := g.TransitiveDependents("mesh"); !slices.Equal(deps, []string{"stage"}) {
+		t.Errorf("expected only the stage to depend on the mesh but got %v", deps)
+	}
+	if deps := g.TransitiveDependents("stage"); len(deps) != 0 {
+		t.Errorf("expected nothing to depend on the stage but got %v", deps)
+	}
+}
+
+func TestTransitiveDependentsCycle(t *testing.T) {
+	g := NewDependencyGraph([]AssetDatabaseInfo{
+		{ID: "a", Dependencies: []string{"b"}},
+		{ID: "b", Dependencies: []string{"a"}},
+	})
+	if deps := g.TransitiveDependents("a"); !slices.Equal(deps, []string{"b"}) {
+		t.Errorf("expected a cycle to only list b once but got %v", deps)
+	}
+}
+
+func TestFolderDependents(t *testing.T) {
+	atlas := AssetDatabaseInfo{ID: "atlas", Path: "content/hero.atlas",
+		Folders: []string{filepath.Join("content", "hero")}}
+	stage := AssetDatabaseInfo{ID: "stage", Path: "content/a.stg",
+		Dependencies: []string{"atlas"}}
+	g := NewDependencyGraph([]AssetDatabaseInfo{stage, atlas})
+	added := filepath.Join("content", "hero", "run", "run_0.png")
+	if deps := g.FolderDependents(added); !slices.Equal(deps, []string{"atlas", "stage"}) {
+		t.Errorf("expected the atlas and then the stage for a new image but got %v", deps)
+	}
+	if deps := g.FolderDependents(filepath.Join("content", "heroes", "a.png")); len(deps) != 0 {
+		t.Errorf("expected a file of a sibling folder to not match but got %v", deps)
+	}
+	if deps := g.FolderDependents(filepath.Join("content", "hero")); len(deps) != 0 {
+		t.Errorf("expected the folder itself to not match but got %v", deps)
+	}
+}
+
+func TestHash(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	os.WriteFile(a, []byte("same"), os.ModePerm)
+	os.WriteFile(b, []byte("same"), os.ModePerm)
+	ha, err := Hash(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hb, _ := Hash(b)
+	if ha != hb {
+		t.Error("expected files with the same content to have the same hash")
+	}
+	os.WriteFile(b, []byte("changed"), os.ModePerm)
+	if hb, _ = Hash(b); ha == hb {
+		t.Error("expected the hash to change with the content")
+	}
+	if _, err := Hash(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected hashing a missing file to fail")
+	}
+}
@@ -42,6 +42,12 @@ const (
 	TextureSquare = "textures/square.png"
 )
 
+// Materials
+const (
+	MaterialBasic = "materials/basic.material"
+	MaterialLit   = "materials/lit.material"
+)
+
 // Shader definitions
 const (
 	ShaderDefinitionGrid         = "shaders/definitions/grid.json"
@@ -55,4 +61,19 @@ const (
 	ShaderDefinitionUI           = "shaders/definitions/ui.json"
 	ShaderDefinitionSprite       = "shaders/definitions/sprite.json"
 	ShaderDefinitionOutline      = "shaders/definitions/outline.json"
+	ShaderDefinitionLit          = "shaders/definitions/lit.json"
+	ShaderDefinitionShadow       = "shaders/definitions/shadow.json"
+	ShaderDefinitionParticle     = "shaders/definitions/particle.json"
+	ShaderDefinitionSkybox       = "shaders/definitions/skybox.json"
+
+	ShaderDefinitionPostTonemap      = "shaders/definitions/post_tonemap.json"
+	ShaderDefinitionPostBloom        = "shaders/definitions/post_bloom.json"
+	ShaderDefinitionPostFXAA         = "shaders/definitions/post_fxaa.json"
+	ShaderDefinitionPostColorGrading = "shaders/definitions/post_color_grading.json"
+	ShaderDefinitionPostVignette     = "shaders/definitions/post_vignette.json"
+)
+
+// Post processing
+const (
+	PostProcessDefault = "postprocess/default.postprocess"
 )
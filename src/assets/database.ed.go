@@ -46,20 +46,36 @@ import (
 	"strings"
 )
 
+const contentPath = "content"
+
 type EditorContext struct {
 	EditorPath string
 }
 
-func (a *Database) toContentPath(key string) string {
-	const contentPath = "content"
+func (a *Database) editorContentPath() string {
 	if a.EditorContext.EditorPath == "" {
 		a.EditorContext.EditorPath = filepath.Clean(filepath.Dir(klib.MustReturn(os.Executable())) + "/..")
 	}
+	return filepath.Join(a.EditorContext.EditorPath, contentPath)
+}
+
+func (a *Database) toContentPath(key string) string {
 	key = strings.ReplaceAll(key, "\\", "/")
-	edKey := filepath.Join(a.EditorContext.EditorPath, contentPath, key)
+	edKey := filepath.Join(a.editorContentPath(), key)
 	if _, err := os.Stat(edKey); err == nil {
 		return edKey
 	} else {
 		return filepath.Join(contentPath, key)
 	}
 }
+
+// contentFolders returns the content folder of the editor and the content
+// folder of the working directory, in the order #Database.toContentPath
+// searches them
+func (a *Database) contentFolders() []string {
+	return []string{a.editorContentPath(), contentPath}
+}
+
+// mountShippedArchives does nothing in the editor, the editor always works
+// with the loose content files
+func (a *Database) mountShippedArchives() {}
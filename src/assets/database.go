@@ -38,29 +38,171 @@
 package assets
 
 import (
+	"kaiju/assets/archive"
+	"kaiju/assets/vfs"
 	"kaiju/filesystem"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
+// ArchiveKey is the passphrase that the shipped archives were encrypted with,
+// it can be set when building with -ldflags "-X kaiju/assets.ArchiveKey=..."
+var ArchiveKey string
+
+// Database reads assets by their key from the layers mounted into its virtual
+// file system, searching from the highest priority layer to the lowest. When
+// none of the layers have the asset it is read from the loose content files.
 type Database struct {
 	EditorContext EditorContext
+	files         *vfs.FileSystem
+	loader        *loader
+	looseContent  bool
 }
 
 func NewDatabase() Database {
-	return Database{}
+	db := Database{
+		files:        vfs.New(),
+		loader:       newLoader(),
+		looseContent: true,
+	}
+	db.mountShippedArchives()
+	return db
+}
+
+// NewMemoryDatabase creates a database that only reads the assets held in
+// memory, the loose content files are never read. It is mostly useful for
+// tests. More layers can be mounted on top of the memory layer, which is
+// mounted as "memory" with #vfs.PriorityContent.
+func NewMemoryDatabase(files map[string][]byte) Database {
+	db := Database{files: vfs.New(), loader: newLoader()}
+	db.files.Mount("memory", vfs.NewMemoryLayer(files), vfs.PriorityContent)
+	return db
+}
+
+// FileSystem returns the virtual file system that the assets are read from
+func (a *Database) FileSystem() *vfs.FileSystem {
+	if a.files == nil {
+		a.files = vfs.New()
+	}
+	return a.files
+}
+
+// Mount adds the layer to the file system of the database, the name is used
+// to unmount it later. Layers with a higher priority are searched first, see
+// #vfs.PriorityOverlay for downloadable content, patches, and mods.
+func (a *Database) Mount(name string, layer vfs.Layer, priority int) error {
+	return a.FileSystem().Mount(name, layer, priority)
+}
+
+// Unmount removes the layer with the name, closing it if it can be closed
+func (a *Database) Unmount(name string) bool {
+	return a.FileSystem().Unmount(name)
 }
 
 func (a *Database) ReadText(key string) (string, error) {
-	return filesystem.ReadTextFile(a.toContentPath(key))
+	data, err := a.Read(key)
+	return string(data), err
 }
 
 func (a *Database) Read(key string) ([]byte, error) {
+	if l, ok := a.FileSystem().Find(key); ok {
+		return l.Read(key)
+	}
+	if !a.looseContent {
+		return nil, vfs.ErrNotFound
+	}
 	return filesystem.ReadFile(a.toContentPath(key))
 }
 
 func (a *Database) Exists(key string) bool {
-	return filesystem.FileExists(a.toContentPath(key))
+	if a.FileSystem().Exists(key) {
+		return true
+	}
+	return a.looseContent && filesystem.FileExists(a.toContentPath(key))
+}
+
+// Folders returns the folders on disk that the assets are read from, the
+// folders of the mounted directory layers from the highest priority to the
+// lowest, followed by the loose content folders
+func (a *Database) Folders() []string {
+	folders := []string{}
+	for _, name := range a.FileSystem().Names() {
+		l, _ := a.FileSystem().Layer(name)
+		switch d := l.(type) {
+		case vfs.DirectoryLayer:
+			folders = append(folders, d.Root)
+		case *vfs.DirectoryLayer:
+			folders = append(folders, d.Root)
+		}
+	}
+	if a.looseContent {
+		folders = append(folders, a.contentFolders()...)
+	}
+	return folders
+}
+
+// KeyForPath returns the key of the asset that is read from the file at the
+// path, false is returned when the file is not within any of the folders
+// from #Database.Folders
+func (a *Database) KeyForPath(path string) (string, bool) {
+	for _, folder := range a.Folders() {
+		rel, err := filepath.Rel(folder, path)
+		if err != nil || rel == "." || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return vfs.NormalizeKey(filepath.ToSlash(rel)), true
+	}
+	return "", false
+}
+
+// MountArchive opens the archive so that its assets are read from it rather
+// than from the loose content files. The archive is mounted under its path
+// with #vfs.PriorityArchive, archives that are mounted later take priority
+// over earlier ones, so they can be used to patch assets.
+func (a *Database) MountArchive(path string, key []byte) error {
+	arc, err := archive.Open(path, key)
+	if err != nil {
+		return err
+	}
+	if err := a.Mount(path, arc, vfs.PriorityArchive); err != nil {
+		arc.Close()
+		return err
+	}
+	return nil
+}
+
+// MountArchives mounts all of the archives in the folder in name order
+func (a *Database) MountArchives(folder string, key []byte) error {
+	paths, err := filepath.Glob(filepath.Join(folder, "*"+archive.Extension))
+	if err != nil {
+		return err
+	}
+	slices.Sort(paths)
+	for i := range paths {
+		if err := a.MountArchive(paths[i], key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmountArchive closes the archive at the path, the assets that were in it
+// are read from the other layers or the loose content files again
+func (a *Database) UnmountArchive(path string) bool {
+	return a.Unmount(path)
 }
 
 func (a *Database) Destroy() {
+	if a.files != nil {
+		a.files.Close()
+	}
+}
 
+func archiveKey() []byte {
+	if ArchiveKey == "" {
+		return nil
+	}
+	return archive.KeyFromPassphrase(ArchiveKey)
 }
@@ -39,11 +39,31 @@
 
 package assets
 
-import "path/filepath"
+import (
+	"kaiju/filesystem"
+	"log/slog"
+	"path/filepath"
+)
+
+const contentPath = "content"
 
 type EditorContext struct{}
 
 func (a *Database) toContentPath(key string) string {
-	const contentPath = "content"
 	return filepath.Join(contentPath, key)
 }
+
+func (a *Database) contentFolders() []string {
+	return []string{contentPath}
+}
+
+// mountShippedArchives mounts the archives that were packed into the content
+// folder, without any archives the loose content files are used
+func (a *Database) mountShippedArchives() {
+	if !filesystem.DirectoryExists(contentPath) {
+		return
+	}
+	if err := a.MountArchives(contentPath, archiveKey()); err != nil {
+		slog.Error("failed to mount the content archives", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,90 @@
+/******************************************************************************/
+/* database_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package assets
+
+import (
+	"kaiju/assets/vfs"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryDatabase(t *testing.T) {
+	db := NewMemoryDatabase(map[string][]byte{
+		"shaders/a.json": []byte("base"),
+		"shaders/b.json": []byte("base"),
+	})
+	defer db.Destroy()
+	overlay := vfs.NewMemoryLayer(map[string][]byte{"shaders/a.json": []byte("mod")})
+	if err := db.Mount("mod", overlay, vfs.PriorityOverlay); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := db.ReadText("shaders/a.json"); err != nil || text != "mod" {
+		t.Errorf("expected the overlay to replace the asset but read %q, %v", text, err)
+	}
+	if text, err := db.ReadText("shaders\\b.json"); err != nil || text != "base" {
+		t.Errorf("expected the base asset but read %q, %v", text, err)
+	}
+	if db.Exists("content/shaders/c.json") {
+		t.Error("expected a memory database to not read loose content files")
+	}
+	if _, err := db.Read("shaders/c.json"); err == nil {
+		t.Error("expected reading a missing asset to fail")
+	}
+	db.Unmount("mod")
+	if text, _ := db.ReadText("shaders/a.json"); text != "base" {
+		t.Errorf("expected the base asset once the overlay was unmounted but read %q", text)
+	}
+}
+
+func TestKeyForPath(t *testing.T) {
+	db := NewMemoryDatabase(nil)
+	defer db.Destroy()
+	root := t.TempDir()
+	if err := db.Mount("mod", vfs.NewDirectoryLayer(root), vfs.PriorityOverlay); err != nil {
+		t.Fatal(err)
+	}
+	if folders := db.Folders(); len(folders) != 1 || folders[0] != root {
+		t.Fatalf("expected only the folder of the directory layer, got %v", folders)
+	}
+	if key, ok := db.KeyForPath(filepath.Join(root, "textures", "a.png")); !ok || key != "textures/a.png" {
+		t.Errorf("expected the key of the file in the layer, got %q, %v", key, ok)
+	}
+	if key, ok := db.KeyForPath(filepath.Join(filepath.Dir(root), "a.png")); ok {
+		t.Errorf("expected a file outside of the layer to not have a key, got %q", key)
+	}
+}
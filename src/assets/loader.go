@@ -0,0 +1,264 @@
+/******************************************************************************/
+/* loader.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package assets
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// Handle is the future of an asset that is being loaded asynchronously, it
+// is done once the asset is loaded or has failed to load. Handles are shared,
+// every request for an asset that is already loading gets the same handle.
+type Handle[T any] struct {
+	key   string
+	value T
+	err   error
+	done  chan struct{}
+}
+
+// LoadProgress is the progress of the asynchronous loads, it is useful for
+// showing loading screens. Loads that have a main thread step through #Then
+// count as two steps. Once everything has finished, the next request starts
+// the progress over.
+type LoadProgress struct {
+	Total  int
+	Loaded int
+	Failed int
+}
+
+type loadId struct {
+	key  string
+	kind reflect.Type
+}
+
+type loader struct {
+	workers   chan struct{}
+	inflight  map[loadId]any
+	finishers []func()
+	progress  LoadProgress
+	mutex     sync.Mutex
+}
+
+func newHandle[T any](key string) *Handle[T] {
+	return &Handle[T]{key: key, done: make(chan struct{})}
+}
+
+// Ready creates a handle that is already done, it is used to return assets
+// that were already loaded through the same API as those that are not
+func Ready[T any](key string, value T, err error) *Handle[T] {
+	h := newHandle[T](key)
+	h.complete(value, err)
+	return h
+}
+
+func (h *Handle[T]) Key() string { return h.key }
+
+// Done returns a channel that is closed once the asset has loaded or failed
+func (h *Handle[T]) Done() <-chan struct{} { return h.done }
+
+func (h *Handle[T]) IsDone() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Value returns the loaded asset without blocking, false is returned if the
+// asset has not finished loading or failed to load
+func (h *Handle[T]) Value() (T, bool) {
+	if !h.IsDone() || h.err != nil {
+		var empty T
+		return empty, false
+	}
+	return h.value, true
+}
+
+// Err returns the error the asset failed to load with, it is nil if the asset
+// loaded or has not finished loading yet
+func (h *Handle[T]) Err() error {
+	if !h.IsDone() {
+		return nil
+	}
+	return h.err
+}
+
+// Wait blocks until the asset has loaded. Handles created through #Then are
+// finished on the main thread, so they must not be waited on from the main
+// thread, check #Handle.IsDone each frame instead.
+func (h *Handle[T]) Wait() (T, error) {
+	<-h.done
+	return h.value, h.err
+}
+
+func (h *Handle[T]) complete(value T, err error) {
+	h.value = value
+	h.err = err
+	close(h.done)
+}
+
+// Done returns true if all of the requested loads have finished
+func (p LoadProgress) Done() bool { return p.Loaded+p.Failed >= p.Total }
+
+// Percent returns how much of the requested loads have finished from 0 to 1
+func (p LoadProgress) Percent() float32 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float32(p.Loaded+p.Failed) / float32(p.Total)
+}
+
+func newLoader() *loader {
+	return &loader{
+		workers:  make(chan struct{}, runtime.NumCPU()),
+		inflight: make(map[loadId]any),
+	}
+}
+
+// begin is expected to be called while the mutex is locked
+func (l *loader) begin() {
+	if l.progress.Done() {
+		l.progress = LoadProgress{}
+	}
+	l.progress.Total++
+}
+
+// end is expected to be called while the mutex is locked
+func (l *loader) end(err error) {
+	if err != nil {
+		l.progress.Failed++
+	} else {
+		l.progress.Loaded++
+	}
+}
+
+func (a *Database) assetLoader() *loader {
+	if a.loader == nil {
+		a.loader = newLoader()
+	}
+	return a.loader
+}
+
+// Load reads the asset on a worker goroutine and decodes it there with the
+// decode function. Loading the same key into the same type while it is still
+// loading returns the handle of the first request. Anything that must happen
+// on the main thread, like creating GPU resources, goes in #Then.
+func Load[T any](db *Database, key string, decode func(data []byte) (T, error)) *Handle[T] {
+	l := db.assetLoader()
+	id := loadId{key, reflect.TypeFor[T]()}
+	l.mutex.Lock()
+	if h, ok := l.inflight[id]; ok {
+		l.mutex.Unlock()
+		return h.(*Handle[T])
+	}
+	h := newHandle[T](key)
+	l.inflight[id] = h
+	l.begin()
+	l.mutex.Unlock()
+	go func() {
+		l.workers <- struct{}{}
+		var value T
+		data, err := db.Read(key)
+		if err == nil {
+			value, err = decode(data)
+		}
+		<-l.workers
+		l.mutex.Lock()
+		delete(l.inflight, id)
+		l.end(err)
+		l.mutex.Unlock()
+		h.complete(value, err)
+	}()
+	return h
+}
+
+// Then calls the finish function with the asset of the handle on the main
+// thread, during #Database.FinishLoading, once the handle is done. If the
+// handle failed, finish is not called and the returned handle fails with the
+// same error.
+func Then[T, U any](db *Database, h *Handle[T], finish func(value T) (U, error)) *Handle[U] {
+	l := db.assetLoader()
+	next := newHandle[U](h.key)
+	l.mutex.Lock()
+	l.begin()
+	l.mutex.Unlock()
+	go func() {
+		value, err := h.Wait()
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		l.finishers = append(l.finishers, func() {
+			var out U
+			if err == nil {
+				out, err = finish(value)
+			}
+			l.mutex.Lock()
+			l.end(err)
+			l.mutex.Unlock()
+			next.complete(out, err)
+		})
+	}()
+	return next
+}
+
+// FinishLoading runs the main thread steps of the loads that are ready, the
+// host calls this at the start of every frame
+func (a *Database) FinishLoading() {
+	if a.loader == nil {
+		return
+	}
+	a.loader.mutex.Lock()
+	finishers := a.loader.finishers
+	a.loader.finishers = nil
+	a.loader.mutex.Unlock()
+	for _, finish := range finishers {
+		finish()
+	}
+}
+
+// LoadProgress returns the progress of the asynchronous loads
+func (a *Database) LoadProgress() LoadProgress {
+	if a.loader == nil {
+		return LoadProgress{}
+	}
+	a.loader.mutex.Lock()
+	defer a.loader.mutex.Unlock()
+	return a.loader.progress
+}
@@ -0,0 +1,179 @@
+/******************************************************************************/
+/* loader_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package assets
+
+import (
+	"errors"
+	"testing"
+)
+
+func testLoaderDatabase() Database {
+	return NewMemoryDatabase(map[string][]byte{
+		"a.txt": []byte("a"),
+		"b.txt": []byte("b"),
+	})
+}
+
+func TestLoad(t *testing.T) {
+	db := testLoaderDatabase()
+	h := Load(&db, "a.txt", func(data []byte) (string, error) {
+		return string(data), nil
+	})
+	if v, err := h.Wait(); err != nil || v != "a" {
+		t.Errorf("expected the loaded value to be a but was %q, %v", v, err)
+	}
+	if v, ok := h.Value(); !ok || v != "a" {
+		t.Errorf("expected the value of a done handle to be a but was %q", v)
+	}
+	missing := Load(&db, "c.txt", func(data []byte) (string, error) {
+		return string(data), nil
+	})
+	if _, err := missing.Wait(); err == nil {
+		t.Error("expected loading a missing asset to fail")
+	}
+	if _, ok := missing.Value(); ok {
+		t.Error("expected a failed handle to have no value")
+	}
+}
+
+func TestLoadDeduplicates(t *testing.T) {
+	db := testLoaderDatabase()
+	release := make(chan struct{})
+	decodes := 0
+	decode := func(data []byte) (string, error) {
+		<-release
+		decodes++
+		return string(data), nil
+	}
+	first := Load(&db, "a.txt", decode)
+	second := Load(&db, "a.txt", decode)
+	if first != second {
+		t.Error("expected requests for a loading key to share a handle")
+	}
+	other := Load(&db, "a.txt", func(data []byte) ([]byte, error) { return data, nil })
+	if any(other) == any(first) {
+		t.Error("expected loading the key into another type to be its own request")
+	}
+	close(release)
+	first.Wait()
+	other.Wait()
+	if decodes != 1 {
+		t.Errorf("expected the asset to be decoded once but it was decoded %d times", decodes)
+	}
+}
+
+func TestThen(t *testing.T) {
+	db := testLoaderDatabase()
+	h := Load(&db, "a.txt", func(data []byte) (string, error) {
+		return string(data), nil
+	})
+	finished := Then(&db, h, func(v string) (int, error) {
+		return len(v) + 1, nil
+	})
+	h.Wait()
+	failErr := errors.New("failed")
+	failed := Then(&db, finished, func(v int) (int, error) {
+		return 0, failErr
+	})
+	for !failed.IsDone() {
+		db.FinishLoading()
+	}
+	if v, err := finished.Wait(); err != nil || v != 2 {
+		t.Errorf("expected the finished value to be 2 but was %d, %v", v, err)
+	}
+	if _, err := failed.Wait(); !errors.Is(err, failErr) {
+		t.Errorf("expected the error of the finish function but got %v", err)
+	}
+	missing := Load(&db, "c.txt", func(data []byte) (string, error) {
+		return string(data), nil
+	})
+	called := false
+	propagated := Then(&db, missing, func(v string) (string, error) {
+		called = true
+		return v, nil
+	})
+	for !propagated.IsDone() {
+		db.FinishLoading()
+	}
+	if called {
+		t.Error("expected the finish function to not be called for a failed load")
+	}
+	if propagated.Err() == nil {
+		t.Error("expected the error of the load to be passed on")
+	}
+}
+
+func TestLoadProgress(t *testing.T) {
+	db := testLoaderDatabase()
+	if p := db.LoadProgress(); !p.Done() || p.Percent() != 1 {
+		t.Errorf("expected no loads to be done but was %+v", p)
+	}
+	release := make(chan struct{})
+	decode := func(data []byte) (string, error) {
+		<-release
+		return string(data), nil
+	}
+	a := Load(&db, "a.txt", decode)
+	b := Load(&db, "b.txt", decode)
+	c := Load(&db, "c.txt", decode)
+	if p := db.LoadProgress(); p.Total != 3 || p.Done() {
+		t.Errorf("expected 3 pending loads but was %+v", p)
+	}
+	close(release)
+	a.Wait()
+	b.Wait()
+	c.Wait()
+	p := db.LoadProgress()
+	if p.Loaded != 2 || p.Failed != 1 || !p.Done() || p.Percent() != 1 {
+		t.Errorf("expected 2 loaded and 1 failed but was %+v", p)
+	}
+	Load(&db, "a.txt", decode).Wait()
+	if p := db.LoadProgress(); p.Total != 1 || p.Loaded != 1 {
+		t.Errorf("expected the progress to start over after everything finished but was %+v", p)
+	}
+}
+
+func TestReady(t *testing.T) {
+	h := Ready("a.txt", 5, nil)
+	if !h.IsDone() {
+		t.Fatal("expected a ready handle to be done")
+	}
+	if v, ok := h.Value(); !ok || v != 5 {
+		t.Errorf("expected the value to be 5 but was %d", v)
+	}
+}
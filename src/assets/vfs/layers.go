@@ -0,0 +1,140 @@
+/******************************************************************************/
+/* layers.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// DirectoryLayer reads assets from a folder on disk, the key of an asset is
+// its path relative to the folder
+type DirectoryLayer struct {
+	Root string
+}
+
+func NewDirectoryLayer(root string) DirectoryLayer {
+	return DirectoryLayer{Root: root}
+}
+
+func (d DirectoryLayer) path(key string) string {
+	return filepath.Join(d.Root, filepath.FromSlash(NormalizeKey(key)))
+}
+
+func (d DirectoryLayer) Read(key string) ([]byte, error) {
+	return os.ReadFile(d.path(key))
+}
+
+func (d DirectoryLayer) Exists(key string) bool {
+	s, err := os.Stat(d.path(key))
+	return err == nil && !s.IsDir()
+}
+
+// FSLayer reads assets from an fs.FS, like an embed.FS compiled into the
+// game or the storage of a platform that exposes one
+type FSLayer struct {
+	FS fs.FS
+}
+
+// NewFSLayer creates a layer of the folder within the file system, the root
+// is usually the folder that was embedded, use "." for the whole file system
+func NewFSLayer(fsys fs.FS, root string) (FSLayer, error) {
+	if root == "" || root == "." {
+		return FSLayer{FS: fsys}, nil
+	}
+	sub, err := fs.Sub(fsys, root)
+	return FSLayer{FS: sub}, err
+}
+
+func (l FSLayer) Read(key string) ([]byte, error) {
+	return fs.ReadFile(l.FS, NormalizeKey(key))
+}
+
+func (l FSLayer) Exists(key string) bool {
+	s, err := fs.Stat(l.FS, NormalizeKey(key))
+	return err == nil && !s.IsDir()
+}
+
+// MemoryLayer holds assets in memory, it is useful for tests and for assets
+// that are generated while the game is running. The layer can be written to
+// and read from multiple goroutines at the same time.
+type MemoryLayer struct {
+	files map[string][]byte
+	mutex sync.RWMutex
+}
+
+// NewMemoryLayer creates a layer holding the files, the files can be nil
+func NewMemoryLayer(files map[string][]byte) *MemoryLayer {
+	m := &MemoryLayer{files: make(map[string][]byte, len(files))}
+	for k, v := range files {
+		m.files[NormalizeKey(k)] = v
+	}
+	return m
+}
+
+func (m *MemoryLayer) Read(key string) ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if data, ok := m.files[NormalizeKey(key)]; ok {
+		return slices.Clone(data), nil
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MemoryLayer) Exists(key string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, ok := m.files[NormalizeKey(key)]
+	return ok
+}
+
+// Write adds or replaces the asset
+func (m *MemoryLayer) Write(key string, data []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.files[NormalizeKey(key)] = data
+}
+
+func (m *MemoryLayer) Remove(key string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.files, NormalizeKey(key))
+}
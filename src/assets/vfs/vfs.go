@@ -0,0 +1,189 @@
+/******************************************************************************/
+/* vfs.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package vfs
+
+import (
+	"errors"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Layer is a source of assets that can be mounted into a #FileSystem, like a
+// folder on disk, an archive, or the storage of a platform
+type Layer interface {
+	Read(key string) ([]byte, error)
+	Exists(key string) bool
+}
+
+// The priorities that the engine mounts its own layers at, layers with a
+// higher priority are searched first
+const (
+	PriorityContent = 0
+	PriorityArchive = 100
+	// PriorityOverlay is for layers that replace shipped assets, like the
+	// content of downloads, patches, or mods
+	PriorityOverlay = 200
+)
+
+var ErrNotFound = errors.New("the asset was not found in any layer")
+
+// NormalizeKey cleans up the key of an asset so that the same asset is found
+// no matter which path separators were used to name it
+func NormalizeKey(key string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(key, "\\", "/")), "/")
+}
+
+type mount struct {
+	name     string
+	layer    Layer
+	priority int
+}
+
+// FileSystem searches its mounted layers for assets, from the highest
+// priority to the lowest. Layers of the same priority are searched from the
+// last mounted to the first. A file system is itself a layer so that a group
+// of layers, like the layers of a mod, can be mounted as one.
+type FileSystem struct {
+	mounts []mount
+	mutex  sync.RWMutex
+}
+
+func New() *FileSystem { return &FileSystem{} }
+
+// Mount adds the layer under the name, the name is used to unmount it
+func (f *FileSystem) Mount(name string, layer Layer, priority int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i := range f.mounts {
+		if f.mounts[i].name == name {
+			return errors.New("a layer named " + name + " is already mounted")
+		}
+	}
+	idx := len(f.mounts)
+	for i := range f.mounts {
+		if f.mounts[i].priority <= priority {
+			idx = i
+			break
+		}
+	}
+	f.mounts = slices.Insert(f.mounts, idx, mount{name, layer, priority})
+	return nil
+}
+
+// Unmount removes the layer with the name, the layer is closed if it is an
+// io.Closer. False is returned if there is no layer with the name.
+func (f *FileSystem) Unmount(name string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i := range f.mounts {
+		if f.mounts[i].name == name {
+			closeLayer(f.mounts[i].layer)
+			f.mounts = slices.Delete(f.mounts, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Layer returns the layer that was mounted under the name
+func (f *FileSystem) Layer(name string) (Layer, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	for i := range f.mounts {
+		if f.mounts[i].name == name {
+			return f.mounts[i].layer, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the mounted layers in the order they are
+// searched
+func (f *FileSystem) Names() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	names := make([]string, len(f.mounts))
+	for i := range f.mounts {
+		names[i] = f.mounts[i].name
+	}
+	return names
+}
+
+// Find returns the first layer that has the asset
+func (f *FileSystem) Find(key string) (Layer, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	for i := range f.mounts {
+		if f.mounts[i].layer.Exists(key) {
+			return f.mounts[i].layer, true
+		}
+	}
+	return nil, false
+}
+
+func (f *FileSystem) Read(key string) ([]byte, error) {
+	if l, ok := f.Find(key); ok {
+		return l.Read(key)
+	}
+	return nil, ErrNotFound
+}
+
+func (f *FileSystem) Exists(key string) bool {
+	_, ok := f.Find(key)
+	return ok
+}
+
+// Close unmounts all of the layers, closing those that are io.Closers
+func (f *FileSystem) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i := range f.mounts {
+		closeLayer(f.mounts[i].layer)
+	}
+	f.mounts = f.mounts[:0]
+	return nil
+}
+
+func closeLayer(layer Layer) {
+	if c, ok := layer.(io.Closer); ok {
+		c.Close()
+	}
+}
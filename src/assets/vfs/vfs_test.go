@@ -0,0 +1,214 @@
+/******************************************************************************/
+/* vfs_test.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package vfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+type testClosingLayer struct {
+	*MemoryLayer
+	closed bool
+}
+
+func (l *testClosingLayer) Close() error {
+	l.closed = true
+	return nil
+}
+
+func testRead(t *testing.T, l Layer, key, expected string) {
+	t.Helper()
+	data, err := l.Read(key)
+	if err != nil {
+		t.Errorf("failed to read %s: %v", key, err)
+	} else if string(data) != expected {
+		t.Errorf("expected %s to be %q but was %q", key, expected, data)
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := map[string]string{
+		"textures/a.png":      "textures/a.png",
+		"textures\\a.png":     "textures/a.png",
+		"/textures/a.png":     "textures/a.png",
+		"textures/../b/a.png": "b/a.png",
+		"./textures//a.png":   "textures/a.png",
+	}
+	for in, expected := range tests {
+		if got := NormalizeKey(in); got != expected {
+			t.Errorf("expected %q to normalize to %q but got %q", in, expected, got)
+		}
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	f := New()
+	f.Mount("content", NewMemoryLayer(map[string][]byte{
+		"a.txt": []byte("content"),
+		"b.txt": []byte("content"),
+		"c.txt": []byte("content"),
+	}), PriorityContent)
+	f.Mount("mod", NewMemoryLayer(map[string][]byte{
+		"a.txt": []byte("mod"),
+	}), PriorityOverlay)
+	f.Mount("patch_0", NewMemoryLayer(map[string][]byte{
+		"a.txt": []byte("patch_0"),
+		"b.txt": []byte("patch_0"),
+	}), PriorityArchive)
+	f.Mount("patch_1", NewMemoryLayer(map[string][]byte{
+		"b.txt": []byte("patch_1"),
+	}), PriorityArchive)
+	testRead(t, f, "a.txt", "mod")
+	testRead(t, f, "b.txt", "patch_1")
+	testRead(t, f, "c.txt", "content")
+	expected := []string{"mod", "patch_1", "patch_0", "content"}
+	if names := f.Names(); !slices.Equal(names, expected) {
+		t.Errorf("expected the layers to be searched in the order %v but was %v", expected, names)
+	}
+	if _, err := f.Read("d.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a missing asset to fail with ErrNotFound but got %v", err)
+	}
+	if f.Exists("d.txt") {
+		t.Error("expected a missing asset to not exist")
+	}
+}
+
+func TestMountDuplicateName(t *testing.T) {
+	f := New()
+	if err := f.Mount("a", NewMemoryLayer(nil), PriorityContent); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Mount("a", NewMemoryLayer(nil), PriorityOverlay); err == nil {
+		t.Error("expected mounting a second layer with the same name to fail")
+	}
+}
+
+func TestUnmount(t *testing.T) {
+	f := New()
+	base := NewMemoryLayer(map[string][]byte{"a.txt": []byte("base")})
+	overlay := &testClosingLayer{MemoryLayer: NewMemoryLayer(map[string][]byte{
+		"a.txt": []byte("overlay"),
+	})}
+	f.Mount("base", base, PriorityContent)
+	f.Mount("overlay", overlay, PriorityOverlay)
+	testRead(t, f, "a.txt", "overlay")
+	if !f.Unmount("overlay") {
+		t.Fatal("expected the overlay to be unmounted")
+	}
+	if !overlay.closed {
+		t.Error("expected the overlay to be closed when unmounted")
+	}
+	testRead(t, f, "a.txt", "base")
+	if f.Unmount("overlay") {
+		t.Error("expected unmounting a missing layer to return false")
+	}
+	if _, ok := f.Layer("base"); !ok {
+		t.Error("expected the base layer to still be mounted")
+	}
+}
+
+func TestNestedFileSystem(t *testing.T) {
+	mod := New()
+	mod.Mount("textures", NewMemoryLayer(map[string][]byte{
+		"a.png": []byte("mod"),
+	}), PriorityContent)
+	f := New()
+	f.Mount("content", NewMemoryLayer(map[string][]byte{
+		"a.png": []byte("content"),
+		"b.png": []byte("content"),
+	}), PriorityContent)
+	f.Mount("mod", mod, PriorityOverlay)
+	testRead(t, f, "a.png", "mod")
+	testRead(t, f, "b.png", "content")
+}
+
+func TestMemoryLayer(t *testing.T) {
+	m := NewMemoryLayer(nil)
+	m.Write("textures\\a.png", []byte("a"))
+	testRead(t, m, "textures/a.png", "a")
+	data, _ := m.Read("textures/a.png")
+	data[0] = 'b'
+	testRead(t, m, "textures/a.png", "a")
+	m.Remove("/textures/a.png")
+	if m.Exists("textures/a.png") {
+		t.Error("expected the removed asset to not exist")
+	}
+}
+
+func TestDirectoryLayer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "textures"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "textures", "a.png"), []byte("a"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDirectoryLayer(root)
+	testRead(t, d, "textures/a.png", "a")
+	testRead(t, d, "textures\\a.png", "a")
+	if d.Exists("textures") {
+		t.Error("expected a folder to not exist as an asset")
+	}
+	if d.Exists("textures/b.png") {
+		t.Error("expected a missing file to not exist")
+	}
+}
+
+func TestFSLayer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/textures/a.png": {Data: []byte("a")},
+		"other.txt":              {Data: []byte("other")},
+	}
+	l, err := NewFSLayer(fsys, "content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRead(t, l, "textures/a.png", "a")
+	testRead(t, l, "/textures/a.png", "a")
+	if l.Exists("other.txt") {
+		t.Error("expected files outside of the root to not exist")
+	}
+	if l.Exists("textures") {
+		t.Error("expected a folder to not exist as an asset")
+	}
+}
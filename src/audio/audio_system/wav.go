@@ -86,6 +86,17 @@ func LoadWav(assetDatabase *assets.Database, wavFile string) (*Wav, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ReadWav(data)
+}
+
+// LoadWavAsync reads and decodes the wav file on a worker goroutine, nothing
+// needs to happen on the main thread so the handle can be waited on anywhere
+func LoadWavAsync(assetDatabase *assets.Database, wavFile string) *assets.Handle[*Wav] {
+	return assets.Load(assetDatabase, wavFile, ReadWav)
+}
+
+// ReadWav decodes the data of a wav file
+func ReadWav(data []byte) (*Wav, error) {
 	if len(data) == 0 {
 		return nil, errors.New("empty file")
 	}
@@ -44,6 +44,7 @@ import (
 	"kaiju/assets/asset_info"
 	"kaiju/engine"
 	"kaiju/profiler"
+	"kaiju/systems/console"
 	"kaiju/systems/stages"
 	"log/slog"
 	"net"
@@ -52,7 +53,10 @@ import (
 )
 
 type cla struct {
-	stage string
+	stage       string
+	script      string
+	remote      string
+	remoteToken string
 }
 
 func logOps() *slog.HandlerOptions {
@@ -64,6 +68,19 @@ func logOps() *slog.HandlerOptions {
 func setupDebug(host *engine.Host) error {
 	cla := buildCLA()
 	connectLoggingServer(host)
+	if cla.remote != "" {
+		if _, err := console.For(host).StartRemote(cla.remote, cla.remoteToken); err != nil {
+			slog.Error("Failed to start the remote console", slog.String("error", err.Error()))
+		}
+	}
+	if cla.script != "" {
+		// Wait a frame so that the commands from the game are registered
+		host.RunAfterFrames(1, func() {
+			if err := console.For(host).RunScript(cla.script); err != nil {
+				slog.Error("Failed to run the console script", slog.String("error", err.Error()))
+			}
+		})
+	}
 	if cla.stage != "" {
 		path := strings.ReplaceAll(cla.stage, "\\", "/")
 		if !strings.HasPrefix(path, "content/") {
@@ -82,9 +99,15 @@ func setupDebug(host *engine.Host) error {
 func buildCLA() cla {
 	fs := flag.NewFlagSet("Kaiju Debug Args", flag.ContinueOnError)
 	stage := fs.String("stage", "", "The stage to immediately load into")
+	script := fs.String("script", "", "A file of console commands to run on startup")
+	remote := fs.String("remote", "", "The address to start the remote console on (tcp:host:port or unix:path)")
+	remoteToken := fs.String("remoteToken", "", "The token remote console clients must authenticate with")
 	fs.Parse(os.Args[1:])
 	return cla{
-		stage: *stage,
+		stage:       *stage,
+		script:      *script,
+		remote:      *remote,
+		remoteToken: *remoteToken,
 	}
 }
 
@@ -41,10 +41,13 @@ package bootstrap
 
 import (
 	"kaiju/engine"
+	"kaiju/filesystem"
 	"kaiju/host_container"
 	"kaiju/matrix"
 	"kaiju/source"
+	"kaiju/systems/console"
 	"kaiju/systems/logging"
+	"log/slog"
 )
 
 func Main() {
@@ -55,7 +58,22 @@ func Main() {
 	container.RunFunction(func() {
 		container.Host.Camera.SetPosition(matrix.Vec3{0, 0, 5})
 		setupDebug(container.Host)
+		loadCVars(container.Host)
 		source.Main(container.Host)
 	})
 	<-container.Host.Done()
 }
+
+func loadCVars(host *engine.Host) {
+	cvars := console.CVarsFor(host)
+	if filesystem.FileExists(console.CVarConfigFile) {
+		if err := cvars.Load(console.CVarConfigFile); err != nil {
+			slog.Error("Failed to load the console variables", slog.String("error", err.Error()))
+		}
+	}
+	host.OnClose.Add(func() {
+		if err := cvars.Save(console.CVarConfigFile); err != nil {
+			slog.Error("Failed to save the console variables", slog.String("error", err.Error()))
+		}
+	})
+}
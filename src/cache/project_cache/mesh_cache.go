@@ -39,7 +39,9 @@ package project_cache
 
 import (
 	"encoding/gob"
+	"errors"
 	"kaiju/assets/asset_info"
+	"kaiju/rendering"
 	"kaiju/rendering/loaders/load_result"
 	"os"
 	"path/filepath"
@@ -49,6 +51,10 @@ func toCachedMeshPath(path string, adi asset_info.AssetDatabaseInfo) string {
 	return filepath.Join(path, adi.ID+".msh")
 }
 
+func toCachedMeshLODPath(path string, adi asset_info.AssetDatabaseInfo) string {
+	return filepath.Join(path, adi.ID+".lod")
+}
+
 func CacheMesh(adi asset_info.AssetDatabaseInfo, mesh load_result.Mesh) error {
 	path := cachePath(meshCache)
 	f, err := os.Create(toCachedMeshPath(path, adi))
@@ -73,6 +79,59 @@ func LoadCachedMesh(adi asset_info.AssetDatabaseInfo) (load_result.Mesh, error)
 	return mesh, err
 }
 
+// CacheMeshLODs simplifies the mesh with the default level of detail settings
+// and stores the generated levels next to the cached mesh. Nothing is stored
+// when the mesh could not be simplified.
+func CacheMeshLODs(adi asset_info.AssetDatabaseInfo, mesh load_result.Mesh) error {
+	lods := rendering.GenerateMeshLODs(mesh.Verts, mesh.Indexes,
+		rendering.DefaultMeshLODSettings())
+	if len(lods) == 0 {
+		return nil
+	}
+	path := cachePath(meshCache)
+	f, err := os.Create(toCachedMeshLODPath(path, adi))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := gob.NewEncoder(f)
+	return enc.Encode(lods)
+}
+
+func LoadCachedMeshLODs(adi asset_info.AssetDatabaseInfo) ([]rendering.MeshLODData, error) {
+	path := cachePath(meshCache)
+	f, err := os.Open(toCachedMeshLODPath(path, adi))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lods []rendering.MeshLODData
+	dec := gob.NewDecoder(f)
+	err = dec.Decode(&lods)
+	return lods, err
+}
+
+// LoadMeshLODChain returns the level of detail chain for the cached mesh,
+// loading the cached levels into the mesh cache the first time. The mesh for
+// the asset must already be in the mesh cache. False is returned when the
+// mesh has no cached levels of detail.
+func LoadMeshLODChain(adi asset_info.AssetDatabaseInfo, cache *rendering.MeshCache) (*rendering.MeshLODChain, bool) {
+	chain, ok := cache.LODChain(adi.ID)
+	if !ok {
+		return nil, false
+	} else if len(chain.Levels) > 1 {
+		return chain, true
+	}
+	lods, err := LoadCachedMeshLODs(adi)
+	if err != nil || len(lods) == 0 {
+		return nil, false
+	}
+	for i := range lods {
+		cache.AddLOD(adi.ID, lods[i].ScreenSize, lods[i].Verts, lods[i].Indexes)
+	}
+	return chain, true
+}
+
 func DeleteMesh(adi asset_info.AssetDatabaseInfo) error {
 	path := cachePath(meshCache)
 	for i := range adi.Children {
@@ -85,5 +144,10 @@ func DeleteMesh(adi asset_info.AssetDatabaseInfo) error {
 			return err
 		}
 	}
+	if err := os.Remove(toCachedMeshLODPath(path, adi)); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
 	return nil
 }
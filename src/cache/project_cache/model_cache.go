@@ -0,0 +1,179 @@
+/******************************************************************************/
+/* model_cache.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package project_cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"kaiju/assets/asset_info"
+	"kaiju/assets/vfs"
+	"kaiju/filesystem"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders/load_result"
+	"os"
+	"path/filepath"
+)
+
+// ModelNode is a node of the hierarchy of an imported model file
+type ModelNode struct {
+	Name     string
+	Parent   int
+	Position matrix.Vec3
+	Rotation matrix.Vec3
+	Scale    matrix.Vec3
+}
+
+func toCachedPath(category string, adi asset_info.AssetDatabaseInfo, ext string) string {
+	return filepath.Join(cachePath(category), adi.ID+ext)
+}
+
+func cacheGob(category string, adi asset_info.AssetDatabaseInfo, ext string, value any) error {
+	f, err := os.Create(toCachedPath(category, adi, ext))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewEncoder(f).Encode(value)
+}
+
+func loadGob(category string, adi asset_info.AssetDatabaseInfo, ext string, value any) error {
+	f, err := os.Open(toCachedPath(category, adi, ext))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewDecoder(f).Decode(value)
+}
+
+func deleteCached(category string, adi asset_info.AssetDatabaseInfo, ext string) error {
+	err := os.Remove(toCachedPath(category, adi, ext))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// CacheModelNodes stores the node hierarchy of the model so that the model
+// can be opened as entities without reading the model file again
+func CacheModelNodes(adi asset_info.AssetDatabaseInfo, nodes []load_result.Node) error {
+	cached := make([]ModelNode, len(nodes))
+	for i := range nodes {
+		cached[i] = ModelNode{
+			Name:     nodes[i].Name,
+			Parent:   nodes[i].Parent,
+			Position: nodes[i].Transform.Position(),
+			Rotation: nodes[i].Transform.Rotation(),
+			Scale:    nodes[i].Transform.Scale(),
+		}
+	}
+	return cacheGob(modelCache, adi, ".nodes", cached)
+}
+
+func LoadCachedModelNodes(adi asset_info.AssetDatabaseInfo) ([]ModelNode, error) {
+	var nodes []ModelNode
+	err := loadGob(modelCache, adi, ".nodes", &nodes)
+	return nodes, err
+}
+
+// CacheMaterial stores a material that was read out of a model file, the
+// textures of the material are referenced by their asset keys
+func CacheMaterial(adi asset_info.AssetDatabaseInfo, def rendering.MaterialDef) error {
+	src, err := def.ToJson()
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteTextFile(toCachedPath(materialCache, adi,
+		".material"), src)
+}
+
+func LoadCachedMaterial(adi asset_info.AssetDatabaseInfo) (rendering.MaterialDef, error) {
+	src, err := filesystem.ReadTextFile(toCachedPath(materialCache, adi,
+		".material"))
+	if err != nil {
+		return rendering.MaterialDef{}, err
+	}
+	return rendering.MaterialDefFromJson(src)
+}
+
+func CacheAnimation(adi asset_info.AssetDatabaseInfo, anim load_result.Animation) error {
+	return cacheGob(animationCache, adi, ".anim", anim)
+}
+
+func LoadCachedAnimation(adi asset_info.AssetDatabaseInfo) (load_result.Animation, error) {
+	var anim load_result.Animation
+	err := loadGob(animationCache, adi, ".anim", &anim)
+	return anim, err
+}
+
+// CacheSkeleton stores the joints of the skin of a model, the ids of the
+// joints are the indexes of the nodes of the model
+func CacheSkeleton(adi asset_info.AssetDatabaseInfo, joints []load_result.Joint) error {
+	return cacheGob(skeletonCache, adi, ".skel", joints)
+}
+
+func LoadCachedSkeleton(adi asset_info.AssetDatabaseInfo) ([]load_result.Joint, error) {
+	var joints []load_result.Joint
+	err := loadGob(skeletonCache, adi, ".skel", &joints)
+	return joints, err
+}
+
+// DeleteModel removes everything that was cached for the model and its child
+// assets
+func DeleteModel(adi asset_info.AssetDatabaseInfo) error {
+	if err := DeleteMesh(adi); err != nil {
+		return err
+	}
+	errs := []error{deleteCached(modelCache, adi, ".nodes")}
+	for _, child := range adi.Children {
+		errs = append(errs,
+			DeleteTexture(child),
+			deleteCached(materialCache, child, ".material"),
+			deleteCached(animationCache, child, ".anim"),
+			deleteCached(skeletonCache, child, ".skel"))
+	}
+	return errors.Join(errs...)
+}
+
+// TextureLayer is a layer of the textures that were processed at import time,
+// a processed texture is read through it with #rendering.ProcessedTextureKey
+// of the content key of its image, or of its asset ID when it was split out
+// of a model
+func TextureLayer() vfs.Layer {
+	return vfs.NewDirectoryLayer(cachePath(textureCache))
+}
@@ -43,9 +43,14 @@ import (
 )
 
 const (
-	CacheFolder = ".cache"
-	editorFile  = "editor.json"
-	meshCache   = "meshes"
+	CacheFolder    = ".cache"
+	editorFile     = "editor.json"
+	meshCache      = "meshes"
+	textureCache   = "textures"
+	materialCache  = "materials"
+	animationCache = "animations"
+	skeletonCache  = "skeletons"
+	modelCache     = "models"
 )
 
 var createdCachePaths = make(map[string]bool)
@@ -0,0 +1,105 @@
+/******************************************************************************/
+/* texture_cache.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package project_cache
+
+import (
+	"errors"
+	"kaiju/assets/asset_info"
+	"kaiju/filesystem"
+	"kaiju/rendering"
+	"os"
+	"path/filepath"
+)
+
+// toCachedTexturePath is where the processed texture for the key is stored,
+// it is the path of the key within the #TextureLayer
+func toCachedTexturePath(key string) string {
+	return filepath.Join(cachePath(textureCache),
+		filepath.FromSlash(rendering.ProcessedTextureKey(key)))
+}
+
+func writeCachedTexture(key string, texture rendering.ProcessedTexture) error {
+	data, err := texture.Serialize()
+	if err != nil {
+		return err
+	}
+	path := toCachedTexturePath(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return filesystem.WriteFile(path, data)
+}
+
+func deleteCachedTexture(key string) error {
+	err := os.Remove(toCachedTexturePath(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// CacheTexture stores the texture that was processed at import time so that
+// the mips and compressed formats do not need to be generated again, it is
+// stored under the asset ID for textures that have no image of their own
+func CacheTexture(adi asset_info.AssetDatabaseInfo, texture rendering.ProcessedTexture) error {
+	return writeCachedTexture(adi.ID, texture)
+}
+
+// CacheImageTexture stores the processed texture of an image under the
+// content key of the image, the texture is then read in place of the image
+func CacheImageTexture(key string, texture rendering.ProcessedTexture) error {
+	return writeCachedTexture(key, texture)
+}
+
+func LoadCachedTexture(adi asset_info.AssetDatabaseInfo) (rendering.ProcessedTexture, error) {
+	data, err := filesystem.ReadFile(toCachedTexturePath(adi.ID))
+	if err != nil {
+		return rendering.ProcessedTexture{}, err
+	}
+	return rendering.ReadProcessedTexture(data)
+}
+
+func DeleteTexture(adi asset_info.AssetDatabaseInfo) error {
+	return deleteCachedTexture(adi.ID)
+}
+
+// DeleteImageTexture removes the processed texture stored for the content
+// key of an image by #CacheImageTexture
+func DeleteImageTexture(key string) error {
+	return deleteCachedTexture(key)
+}
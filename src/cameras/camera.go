@@ -65,6 +65,7 @@ type Camera interface {
 	Height() float32
 	View() matrix.Mat4
 	Projection() matrix.Mat4
+	Frustum() collision.Frustum
 	LookAt() matrix.Vec3
 	NearPlane() float32
 	FarPlane() float32
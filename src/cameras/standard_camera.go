@@ -238,6 +238,9 @@ func (c *StandardCamera) View() matrix.Mat4 { return c.view }
 // Projection will return the projection matrix of the camera.
 func (c *StandardCamera) Projection() matrix.Mat4 { return c.projection }
 
+// Frustum will return the view frustum of the camera in world space.
+func (c *StandardCamera) Frustum() collision.Frustum { return c.frustum }
+
 // LookAt will return the look at position of the camera.
 func (c *StandardCamera) LookAt() matrix.Vec3 { return c.lookAt }
 
@@ -280,6 +283,7 @@ func (c *StandardCamera) internalUpdateProjection() {
 	}
 	c.iProjection = c.projection
 	c.iProjection.Inverse()
+	c.updateFrustum()
 }
 
 func (c *StandardCamera) internalUpdateView() {
@@ -314,6 +314,19 @@ func (box *AABB) FromTriangle(triangle DetailedTriangle) AABB {
 	return AABB{mid, e}
 }
 
+// Transform returns the AABB that contains this AABB after it has been
+// transformed by the given matrix
+func (box AABB) Transform(m matrix.Mat4) AABB {
+	center := m.TransformPoint(box.Center)
+	var extent matrix.Vec3
+	for i := 0; i < 3; i++ {
+		extent[i] = matrix.Abs(m[i])*box.Extent.X() +
+			matrix.Abs(m[i+4])*box.Extent.Y() +
+			matrix.Abs(m[i+8])*box.Extent.Z()
+	}
+	return AABB{Center: center, Extent: extent}
+}
+
 // InFrustum returns whether the AABB is in the frustum
 func (box *AABB) InFrustum(frustum Frustum) bool {
 	min := box.Min()
@@ -123,3 +123,18 @@ func TestAABBUnion(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestAABBTransform(t *testing.T) {
+	box := AABB{matrix.Vec3Zero(), matrix.Vec3{1, 2, 3}}
+	m := matrix.Mat4Identity()
+	m.Scale(matrix.Vec3{2, 2, 2})
+	m.RotateY(90)
+	m.Translate(matrix.Vec3{10, 0, 0})
+	res := box.Transform(m)
+	if !matrix.Vec3ApproxTo(res.Center, matrix.Vec3{10, 0, 0}, 0.0001) {
+		t.Errorf("Expected center (10, 0, 0), got %v", res.Center)
+	}
+	if !matrix.Vec3ApproxTo(res.Extent, matrix.Vec3{6, 4, 2}, 0.0001) {
+		t.Errorf("Expected extent (6, 4, 2), got %v", res.Extent)
+	}
+}
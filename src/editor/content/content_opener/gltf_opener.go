@@ -0,0 +1,175 @@
+/******************************************************************************/
+/* gltf_opener.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package content_opener
+
+import (
+	"kaiju/assets"
+	"kaiju/assets/asset_info"
+	"kaiju/cache/project_cache"
+	"kaiju/collision"
+	"kaiju/editor/editor_config"
+	"kaiju/editor/interfaces"
+	"kaiju/engine"
+	"kaiju/rendering"
+	"strconv"
+)
+
+type GLTFOpener struct{}
+
+func (o GLTFOpener) Handles(adi asset_info.AssetDatabaseInfo) bool {
+	return adi.Type == editor_config.AssetTypeGltf
+}
+
+// gltfDefaultMaterial is the key of the material used by meshes that have no
+// material in the model file
+const gltfDefaultMaterial = "gltf_default"
+
+func gltfMaterial(host *engine.Host, adi asset_info.AssetDatabaseInfo, id string) (*rendering.Material, error) {
+	if mat, ok := host.MaterialCache().FindMaterial(id); ok {
+		return mat, nil
+	}
+	for i := range adi.Children {
+		if adi.Children[i].ID != id {
+			continue
+		}
+		def, err := project_cache.LoadCachedMaterial(adi.Children[i])
+		if err != nil {
+			return nil, err
+		}
+		return host.MaterialCache().AddMaterial(id, def)
+	}
+	return host.MaterialCache().AddMaterial(gltfDefaultMaterial, rendering.MaterialDef{
+		Shader:   assets.ShaderDefinitionLit,
+		Textures: []rendering.MaterialTextureDef{{Name: "baseColor", Texture: assets.TextureSquare}},
+	})
+}
+
+func loadGLTFMesh(host *engine.Host, model, adi asset_info.AssetDatabaseInfo, e *engine.Entity, bvh *collision.BVH) error {
+	mat, err := gltfMaterial(host, model, adi.MetaValue("material"))
+	if err != nil {
+		return err
+	}
+	mesh, ok := host.MeshCache().FindMesh(adi.ID)
+	if !ok {
+		m, err := project_cache.LoadCachedMesh(adi)
+		if err != nil {
+			return err
+		}
+		mesh = rendering.NewMesh(adi.ID, m.Verts, m.Indexes)
+		bvh.Insert(buildBVH(m, e))
+	}
+	host.MeshCache().AddMesh(mesh)
+	data := rendering.NewShaderDataLit()
+	drawing := mat.Drawing(host.Window.Renderer, mesh, data, &e.Transform)
+	drawing.CanvasId = "default"
+	if chain, ok := project_cache.LoadMeshLODChain(adi, host.MeshCache()); ok {
+		drawing.LOD = chain
+	}
+	host.Drawings.AddDrawing(&drawing)
+	e.EditorBindings.AddDrawing(drawing)
+	e.OnActivate.Add(func() { data.Activate() })
+	e.OnDeactivate.Add(func() { data.Deactivate() })
+	e.OnDestroy.Add(func() { data.Destroy() })
+	return nil
+}
+
+// Open creates an entity for each of the nodes of the model with the same
+// hierarchy as in the file, the meshes are drawn on the entity of their node
+func (o GLTFOpener) Open(adi asset_info.AssetDatabaseInfo, ed interfaces.Editor) error {
+	host := ed.Host()
+	nodes, err := project_cache.LoadCachedModelNodes(adi)
+	if err != nil {
+		return err
+	}
+	root := engine.NewEntity()
+	root.GenerateId()
+	host.AddEntity(root)
+	root.SetName(adi.MetaValue("name"))
+	entities := make([]*engine.Entity, len(nodes))
+	for i := range nodes {
+		e := engine.NewEntity()
+		e.GenerateId()
+		host.AddEntity(e)
+		e.SetName(nodes[i].Name)
+		e.Transform.SetPosition(nodes[i].Position)
+		e.Transform.SetRotation(nodes[i].Rotation)
+		e.Transform.SetScale(nodes[i].Scale)
+		entities[i] = e
+	}
+	for i := range nodes {
+		if p := nodes[i].Parent; p >= 0 && p < len(entities) {
+			entities[i].SetParent(entities[p])
+		} else {
+			entities[i].SetParent(root)
+		}
+	}
+	bvhs := make(map[*engine.Entity]*collision.BVH)
+	for i := range adi.Children {
+		child := adi.Children[i]
+		if child.Type != editor_config.AssetTypeMesh {
+			continue
+		}
+		e := root
+		if n, err := strconv.Atoi(child.MetaValue("node")); err == nil && n >= 0 && n < len(entities) {
+			e = entities[n]
+		}
+		bvh, ok := bvhs[e]
+		if !ok {
+			bvh = collision.NewBVH()
+			bvh.Transform = &e.Transform
+			bvhs[e] = bvh
+		}
+		if err := loadGLTFMesh(host, adi, child, e, bvh); err != nil {
+			return err
+		}
+	}
+	for e, bvh := range bvhs {
+		if !bvh.IsLeaf() {
+			e.EditorBindings.Set("bvh", bvh)
+			ed.BVH().Insert(bvh)
+			e.OnDestroy.Add(func() { bvh.RemoveNode() })
+		}
+	}
+	ed.History().Add(&modelOpenHistory{
+		host:   host,
+		entity: root,
+	})
+	ed.Hierarchy().Reload()
+	host.Window.Focus()
+	return nil
+}
@@ -46,14 +46,27 @@ type modelOpenHistory struct {
 	entity *engine.Entity
 }
 
+// eachEntity calls the function for the opened entity and all of the
+// entities below it, models with a node hierarchy open as many entities
+func (h *modelOpenHistory) eachEntity(call func(e *engine.Entity)) {
+	var walk func(e *engine.Entity)
+	walk = func(e *engine.Entity) {
+		call(e)
+		for i := range e.Children {
+			walk(e.Children[i])
+		}
+	}
+	walk(h.entity)
+}
+
 func (h *modelOpenHistory) Redo() {
 	h.entity.Activate()
-	h.host.AddEntity(h.entity)
+	h.eachEntity(h.host.AddEntity)
 }
 
 func (h *modelOpenHistory) Undo() {
 	h.entity.Deactivate()
-	h.host.RemoveEntity(h.entity)
+	h.eachEntity(h.host.RemoveEntity)
 }
 
 func (h *modelOpenHistory) Delete() {
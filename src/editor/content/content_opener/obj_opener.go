@@ -131,6 +131,9 @@ func load(host *engine.Host, adi asset_info.AssetDatabaseInfo, e *engine.Entity,
 		Transform:  &e.Transform,
 		CanvasId:   "default",
 	}
+	if chain, ok := project_cache.LoadMeshLODChain(adi, host.MeshCache()); ok {
+		drawing.LOD = chain
+	}
 	host.Drawings.AddDrawing(&drawing)
 	e.EditorBindings.AddDrawing(drawing)
 	e.OnActivate.Add(func() { data.Activate() })
@@ -40,6 +40,8 @@ package editor
 import (
 	"kaiju/assets/asset_importer"
 	"kaiju/assets/asset_info"
+	"kaiju/assets/vfs"
+	"kaiju/cache/project_cache"
 	"kaiju/collision"
 	"kaiju/editor/cache/editor_cache"
 	"kaiju/editor/codegen"
@@ -71,7 +73,8 @@ import (
 )
 
 const (
-	projectTemplate = "project_template.zip"
+	projectTemplate      = "project_template.zip"
+	projectTexturesLayer = "project_textures"
 )
 
 type Editor struct {
@@ -152,6 +155,7 @@ func New() *Editor {
 		&ed.assetImporters, ed.container, &ed.history)
 	registerContentOpeners(ed)
 	host.OnClose.Add(ed.SaveLayout)
+	host.OnAssetReload.Add(ed.reimportContent)
 	return ed
 }
 
@@ -201,7 +205,34 @@ func (e *Editor) pickProject(projectPath string) {
 		slog.Error("Failed to init the project folder", pathErr)
 		return
 	}
+	// Textures that are split out of model files only exist in the cache
+	e.Host().AssetDatabase().Unmount(projectTexturesLayer)
+	e.Host().AssetDatabase().Mount(projectTexturesLayer,
+		project_cache.TextureLayer(), vfs.PriorityContent)
 	project.ScanContent(&e.assetImporters)
+	if err := e.Host().EnableHotReload(); err != nil {
+		slog.Error("Failed to watch the project content for changes", slog.String("error", err.Error()))
+	}
+}
+
+// reimportContent imports the content files that were changed outside of
+// the editor, the host reloads the imported assets as they are written. The
+// assets that used a removed file are imported again without it.
+func (e *Editor) reimportContent(reload engine.AssetReload) {
+	if reload.Path == "" {
+		return
+	}
+	if reload.Removed {
+		if err := project.ImportRemoved(&e.assetImporters, reload.Path); err != nil {
+			slog.Error("Failed to import the content that used the removed file",
+				slog.String("file", reload.Path), slog.String("error", err.Error()))
+		}
+		return
+	}
+	if err := project.ImportChanged(&e.assetImporters, reload.Path); err != nil {
+		slog.Error("Failed to import the changed content file",
+			slog.String("file", reload.Path), slog.String("error", err.Error()))
+	}
 }
 
 func (e *Editor) Init() {
@@ -46,21 +46,35 @@ const (
 	FileExtensionGo          FileExtension = ".go"
 	FileExtensionMap         FileExtension = ".map"
 	FileExtensionObj         FileExtension = ".obj"
+	FileExtensionGltf        FileExtension = ".gltf"
+	FileExtensionGlb         FileExtension = ".glb"
+	FileExtensionFbx         FileExtension = ".fbx"
+	FileExtensionDae         FileExtension = ".dae"
 	FileExtensionPng         FileExtension = ".png"
 	FileExtensionMesh        FileExtension = ".msh"
 	FileExtensionStage       FileExtension = ".stg"
 	FileExtensionHTML        FileExtension = ".html"
+	FileExtensionMaterial    FileExtension = ".material"
+	FileExtensionCubeMap     FileExtension = ".cubemap"
+	FileExtensionSpriteAtlas FileExtension = ".atlas"
 	FileExtensionAssetDbInfo FileExtension = ".adi"
 )
 
 const (
-	AssetTypeH     AssetType = "h"
-	AssetTypeC     AssetType = "c"
-	AssetTypeGo    AssetType = "go"
-	AssetTypeMap   AssetType = "map"
-	AssetTypeObj   AssetType = "obj"
-	AssetTypeImage AssetType = "image"
-	AssetTypeMesh  AssetType = "mesh"
-	AssetTypeStage AssetType = "stg"
-	AssetTypeHTML  AssetType = "html"
+	AssetTypeH           AssetType = "h"
+	AssetTypeC           AssetType = "c"
+	AssetTypeGo          AssetType = "go"
+	AssetTypeMap         AssetType = "map"
+	AssetTypeObj         AssetType = "obj"
+	AssetTypeGltf        AssetType = "gltf"
+	AssetTypeImage       AssetType = "image"
+	AssetTypeMesh        AssetType = "mesh"
+	AssetTypeStage       AssetType = "stg"
+	AssetTypeHTML        AssetType = "html"
+	AssetTypeMaterial    AssetType = "material"
+	AssetTypeCubeMap     AssetType = "cubemap"
+	AssetTypeSpriteAtlas AssetType = "atlas"
+	AssetTypeSpriteSheet AssetType = "spritesheet"
+	AssetTypeAnimation   AssetType = "animation"
+	AssetTypeSkeleton    AssetType = "skeleton"
 )
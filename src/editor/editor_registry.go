@@ -47,6 +47,10 @@ func registerAssetImporters(ed *Editor) {
 	ed.assetImporters.Register(asset_importer.PNGImporter{})
 	ed.assetImporters.Register(asset_importer.StageImporter{})
 	ed.assetImporters.Register(asset_importer.HTMLImporter{})
+	ed.assetImporters.Register(asset_importer.MaterialImporter{})
+	ed.assetImporters.Register(asset_importer.CubeMapImporter{})
+	ed.assetImporters.Register(asset_importer.SpriteAtlasImporter{})
+	ed.assetImporters.Register(asset_importer.GLTFImporter{})
 }
 
 func registerContentOpeners(ed *Editor) {
@@ -54,4 +58,5 @@ func registerContentOpeners(ed *Editor) {
 	ed.contentOpener.Register(content_opener.StageOpener{})
 	ed.contentOpener.Register(content_opener.HTMLOpener{})
 	ed.contentOpener.Register(content_opener.ImageOpener{})
+	ed.contentOpener.Register(content_opener.GLTFOpener{})
 }
@@ -39,19 +39,60 @@ package project
 
 import (
 	"kaiju/assets/asset_importer"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// ScanContent imports the files in the content folder that are new or that
+// have changed since they were last imported. Once the files are imported,
+// the assets that use any of the changed assets are imported again.
 func ScanContent(importers *asset_importer.ImportRegistry) error {
-	return filepath.Walk("content", func(path string, info os.FileInfo, err error) error {
+	changed := []string{}
+	err := filepath.Walk("content", func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
 			return nil
 		}
-		importers.ImportIfNew(path)
+		if imported, err := importers.ImportIfChanged(path); err != nil {
+			slog.Error("failed to import content file",
+				slog.String("file", path), slog.String("error", err.Error()))
+		} else if imported {
+			changed = append(changed, path)
+		}
 		return nil
 	})
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	return importers.ImportDependents("content", changed)
+}
+
+// ImportChanged imports a file of the content folder again if it changed
+// since it was last imported, the assets that use it are imported again as
+// well. Files outside of the content folder are skipped.
+func ImportChanged(importers *asset_importer.ImportRegistry, path string) error {
+	rel, err := filepath.Rel("content", path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	imported, err := importers.ImportIfChanged(path)
+	if err != nil || !imported {
+		return err
+	}
+	return importers.ImportDependents("content", []string{path})
+}
+
+// ImportRemoved imports the assets that used a file of the content folder
+// that was removed, like a sprite atlas that packed the images of the folder
+// the file was in. Files outside of the content folder are skipped.
+func ImportRemoved(importers *asset_importer.ImportRegistry, path string) error {
+	rel, err := filepath.Rel("content", path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return importers.ImportDependents("content", []string{path})
 }
@@ -130,8 +130,14 @@ func (m *Manager) saveInternal() error {
 	if err = filesystem.WriteFile(m.stage, stream.Bytes()); err != nil {
 		return err
 	}
-	m.registry.ImportIfNew(m.stage)
-	return nil
+	if err = m.registry.Import(m.stage); err != nil {
+		return err
+	}
+	keys := []string{}
+	for i := range all {
+		keys = append(keys, all[i].EditorBindings.AssetKeys()...)
+	}
+	return asset_importer.RecordStageDependencies(m.stage, keys)
 }
 
 func (m *Manager) Save(statusBar *status_bar.StatusBar) error {
@@ -46,6 +46,8 @@ import (
 	"kaiju/matrix"
 	"kaiju/systems/logging"
 	"kaiju/ui"
+	"log/slog"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -73,19 +75,30 @@ type visibleMessage struct {
 	Category string
 }
 
-func newVisibleMessage(msg string, trace []string, cat string) visibleMessage {
-	mapping := logging.ToMap(msg)
-	t, _ := time.Parse(time.RFC3339, mapping["time"])
-	message := mapping["msg"]
-	delete(mapping, "time")
-	delete(mapping, "msg")
+func newVisibleMessage(rec logging.Record) visibleMessage {
+	data := maps.Clone(rec.Attrs)
+	if data == nil {
+		data = make(map[string]string)
+	}
+	if rec.Category != "" {
+		data[logging.CategoryKey] = rec.Category
+	}
 	return visibleMessage{
-		Time:     t.Format(time.StampMilli),
-		Message:  message,
-		Trace:    strings.Join(trace, "\n"),
-		Data:     mapping,
-		Category: cat,
+		Time:     rec.Time.Format(time.StampMilli),
+		Message:  rec.Message,
+		Trace:    strings.Join(rec.Trace, "\n"),
+		Data:     data,
+		Category: levelCategory(rec.Level),
+	}
+}
+
+func levelCategory(level slog.Level) string {
+	if level >= slog.LevelError {
+		return "error"
+	} else if level >= slog.LevelWarn {
+		return "warn"
 	}
+	return "info"
 }
 
 type LogWindow struct {
@@ -95,9 +108,7 @@ type LogWindow struct {
 	all        []visibleMessage
 	lastReload engine.FrameId
 	logStream  *logging.LogStream
-	infoEvtId  logging.EventId
-	warnEvtId  logging.EventId
-	errEvtId   logging.EventId
+	recordId   logging.EventId
 	group      *ui.Group
 	mutex      sync.Mutex
 }
@@ -110,14 +121,10 @@ func New(host *engine.Host, logStream *logging.LogStream, uiGroup *ui.Group) *Lo
 		host:       host,
 		group:      uiGroup,
 	}
-	l.infoEvtId = logStream.OnInfo.Add(func(msg string) {
-		l.add(msg, nil, "info")
-	})
-	l.warnEvtId = logStream.OnWarn.Add(func(msg string, trace []string) {
-		l.add(msg, trace, "warn")
-	})
-	l.errEvtId = logStream.OnError.Add(func(msg string, trace []string) {
-		l.add(msg, trace, "error")
+	l.recordId = logStream.OnRecord.Add(func(rec logging.Record) {
+		if rec.Level >= slog.LevelInfo {
+			l.add(rec)
+		}
 	})
 	host.OnClose.Add(func() {
 		if l.doc != nil {
@@ -127,10 +134,10 @@ func New(host *engine.Host, logStream *logging.LogStream, uiGroup *ui.Group) *Lo
 	return l
 }
 
-func (l *LogWindow) add(msg string, trace []string, cat string) {
+func (l *LogWindow) add(rec logging.Record) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
-	l.all = append(l.all, newVisibleMessage(msg, trace, cat))
+	l.all = append(l.all, newVisibleMessage(rec))
 	if l.isVisible() {
 		l.reloadUI()
 	}
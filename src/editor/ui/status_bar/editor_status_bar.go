@@ -46,6 +46,7 @@ import (
 	"kaiju/matrix"
 	"kaiju/systems/logging"
 	"kaiju/ui"
+	"log/slog"
 	"regexp"
 	"strconv"
 	"strings"
@@ -68,26 +69,25 @@ func New(host *engine.Host, logWindow *log_window.LogWindow) *StatusBar {
 	l, _ := s.doc.GetElementById("log")
 	s.msg = m.Children[0].UI.(*ui.Label)
 	s.log = l.Children[0].UI.(*ui.Label)
-	host.LogStream.OnInfo.Add(func(msg string) {
-		host.RunAfterFrames(1, func() { s.setLog(msg, matrix.ColorWhite()) })
-	})
-	host.LogStream.OnWarn.Add(func(msg string, _ []string) {
-		host.RunAfterFrames(1, func() { s.setLog(msg, matrix.ColorYellow()) })
-	})
-	host.LogStream.OnError.Add(func(msg string, _ []string) {
-		host.RunAfterFrames(1, func() { s.setLog(msg, matrix.ColorLightCoral()) })
+	host.LogStream.OnRecord.Add(func(rec logging.Record) {
+		var color matrix.Color
+		if rec.Level >= slog.LevelError {
+			color = matrix.ColorLightCoral()
+		} else if rec.Level >= slog.LevelWarn {
+			color = matrix.ColorYellow()
+		} else if rec.Level >= slog.LevelInfo {
+			color = matrix.ColorWhite()
+		} else {
+			return
+		}
+		host.RunAfterFrames(1, func() { s.setLog(rec.Message, color) })
 	})
 	return s
 }
 
 func (s *StatusBar) setLog(msg string, color matrix.Color) {
 	s.log.SetColor(color)
-	res := logging.ToMap(msg)
-	if m, ok := res["msg"]; ok {
-		s.log.SetText(m)
-	} else {
-		s.log.SetText(msg)
-	}
+	s.log.SetText(msg)
 }
 
 func (s *StatusBar) SetMessage(status string) {
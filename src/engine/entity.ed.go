@@ -74,8 +74,13 @@ func (e *entityEditorBindings) AddDrawing(drawing rendering.Drawing) {
 		defs = []drawingDef{}
 	}
 	drawings = append(drawings, drawing)
+	materialKey := ""
+	if drawing.Material != nil {
+		materialKey = drawing.Material.Key
+	}
 	defs = append(defs.([]drawingDef), drawingDef{
 		ShaderDefinition: drawing.Shader.Key,
+		MaterialKey:      materialKey,
 		Textures:         rendering.TextureKeys(drawing.Textures),
 		MeshKey:          drawing.Mesh.Key(),
 		UseBlending:      drawing.UseBlending,
@@ -97,6 +102,23 @@ func (e *entityEditorBindings) Drawings() []rendering.Drawing {
 	}
 }
 
+// AssetKeys will return the keys of the shader definitions, materials,
+// textures, and meshes that the drawings of this entity use
+//
+// `EDITOR ONLY`
+func (e *entityEditorBindings) AssetKeys() []string {
+	defs, ok := e.Data(editorDrawingDefinition).([]drawingDef)
+	if !ok {
+		return nil
+	}
+	keys := []string{}
+	for i := range defs {
+		keys = append(keys, defs[i].ShaderDefinition, defs[i].MaterialKey, defs[i].MeshKey)
+		keys = append(keys, defs[i].Textures...)
+	}
+	return slices.DeleteFunc(keys, func(k string) bool { return k == "" })
+}
+
 // Set will set the data associated with the key
 //
 // `EDITOR ONLY`
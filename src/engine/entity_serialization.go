@@ -67,6 +67,7 @@ type entityStorage struct {
 type drawingDef struct {
 	CanvasId         string
 	ShaderDefinition string
+	MaterialKey      string
 	MeshKey          string
 	Textures         []string
 	UseBlending      bool
@@ -114,7 +115,6 @@ func (e *Entity) Deserialize(stream io.Reader, host *Host) error {
 func setupDrawings(e *Entity, host *Host, defs []drawingDef) ([]rendering.Drawing, error) {
 	drawings := []rendering.Drawing{}
 	for _, d := range defs {
-		s := host.shaderCache.ShaderFromDefinition(d.ShaderDefinition)
 		m, ok := host.MeshCache().FindMesh(d.MeshKey)
 		if !ok {
 			adi, err := asset_info.Lookup(d.MeshKey)
@@ -127,25 +127,41 @@ func setupDrawings(e *Entity, host *Host, defs []drawingDef) ([]rendering.Drawin
 			}
 			m = host.MeshCache().Mesh(adi.ID, md.Verts, md.Indexes)
 		}
-		textures := make([]*rendering.Texture, len(d.Textures))
-		for i, t := range d.Textures {
-			tex, err := host.TextureCache().Texture(
-				t, rendering.TextureFilterLinear)
-			if err != nil {
-				return drawings, err
-			}
-			textures[i] = tex
-		}
 		drawing := rendering.Drawing{
 			Renderer:    host.Window.Renderer,
-			Shader:      s,
 			Mesh:        m,
-			Textures:    textures,
 			ShaderData:  d.ShaderData,
 			Transform:   &e.Transform,
 			CanvasId:    d.CanvasId,
 			UseBlending: d.UseBlending,
 		}
+		if adi, err := asset_info.Lookup(d.MeshKey); err == nil {
+			if chain, ok := project_cache.LoadMeshLODChain(adi, host.MeshCache()); ok {
+				drawing.LOD = chain
+			}
+		}
+		// The shader data was serialized with its edited values, so the
+		// material parameters are not applied to it again here
+		if d.MaterialKey != "" {
+			mat, err := host.MaterialCache().Material(d.MaterialKey)
+			if err != nil {
+				return drawings, err
+			}
+			drawing.Material = mat
+			drawing.Shader = mat.Shader
+			drawing.Textures = mat.Textures
+		} else {
+			drawing.Shader = host.shaderCache.ShaderFromDefinition(d.ShaderDefinition)
+			drawing.Textures = make([]*rendering.Texture, len(d.Textures))
+			for i, t := range d.Textures {
+				tex, err := host.TextureCache().Texture(
+					t, rendering.TextureFilterLinear)
+				if err != nil {
+					return drawings, err
+				}
+				drawing.Textures[i] = tex
+			}
+		}
 		host.Drawings.AddDrawing(&drawing)
 		drawings = append(drawings, drawing)
 	}
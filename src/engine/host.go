@@ -41,6 +41,7 @@ import (
 	"kaiju/assets"
 	"kaiju/audio"
 	"kaiju/cameras"
+	"kaiju/filesystem"
 	"kaiju/klib"
 	"kaiju/matrix"
 	"kaiju/rendering"
@@ -85,9 +86,11 @@ type Host struct {
 	audio          audio.Audio
 	shaderCache    rendering.ShaderCache
 	textureCache   rendering.TextureCache
+	materialCache  rendering.MaterialCache
 	meshCache      rendering.MeshCache
 	fontCache      rendering.FontCache
 	Drawings       rendering.Drawings
+	Lights         rendering.LightList
 	frame          FrameId
 	frameTime      float64
 	Closing        bool
@@ -95,9 +98,11 @@ type Host struct {
 	LateUpdater    Updater
 	assetDatabase  assets.Database
 	OnClose        events.Event
+	OnAssetReload  AssetReloadEvent
 	CloseSignal    chan struct{}
 	frameRateLimit *time.Ticker
 	inEditorEntity int
+	hotReload      *filesystem.Watcher
 }
 
 // NewHost creates a new host with the given name and log stream. The log stream
@@ -118,7 +123,9 @@ func NewHost(name string, logStream *logging.LogStream) *Host {
 		LateUpdater:    NewUpdater(),
 		assetDatabase:  assets.NewDatabase(),
 		Drawings:       rendering.NewDrawings(),
+		Lights:         rendering.NewLightList(),
 		OnClose:        events.New(),
+		OnAssetReload:  newAssetReloadEvent(),
 		CloseSignal:    make(chan struct{}, 1),
 		Camera:         cameras.NewStandardCamera(w, h, matrix.Vec3Backward()),
 		UICamera:       cameras.NewStandardCameraOrthographic(w, h, matrix.Vec3{0, 0, 250}),
@@ -148,8 +155,10 @@ func (host *Host) Initialize(width, height, x, y int) error {
 	host.UICamera.ViewportChanged(float32(width), float32(height))
 	host.shaderCache = rendering.NewShaderCache(host.Window.Renderer, &host.assetDatabase)
 	host.textureCache = rendering.NewTextureCache(host.Window.Renderer, &host.assetDatabase)
+	host.materialCache = rendering.NewMaterialCache(&host.assetDatabase, &host.shaderCache, &host.textureCache)
 	host.meshCache = rendering.NewMeshCache(host.Window.Renderer, &host.assetDatabase)
 	host.fontCache = rendering.NewFontCache(host.Window.Renderer, &host.assetDatabase)
+	host.Window.Renderer.SetLights(&host.Lights)
 	host.Window.OnResize.Add(host.resized)
 	return nil
 }
@@ -194,6 +203,11 @@ func (host *Host) TextureCache() *rendering.TextureCache {
 	return &host.textureCache
 }
 
+// MaterialCache returns the material cache for the host
+func (host *Host) MaterialCache() *rendering.MaterialCache {
+	return &host.materialCache
+}
+
 // MeshCache returns the mesh cache for the host
 func (host *Host) MeshCache() *rendering.MeshCache {
 	return &host.meshCache
@@ -282,8 +296,11 @@ func (host *Host) NewEntity() *Entity {
 // events, update the entities, and render the scene. This will also check if
 // the window has been closed or crashed and set the closing flag accordingly.
 //
-// The update order is FrameRunner -> Update -> LateUpdate -> EndUpdate:
+// The update order is Loading -> HotReload -> FrameRunner -> Update ->
+// LateUpdate -> EndUpdate:
 //
+// [-] Loading: The main thread steps of the asynchronous asset loads
+// [-] HotReload: The assets that changed on disk, see #Host.EnableHotReload
 // [-] FrameRunner: Functions added to RunAfterFrames
 // [-] Update: Functions added to Updater
 // [-] LateUpdate: Functions added to LateUpdater
@@ -295,6 +312,8 @@ func (host *Host) Update(deltaTime float64) {
 	host.frame++
 	host.frameTime += deltaTime
 	host.Window.Poll()
+	host.assetDatabase.FinishLoading()
+	host.reloadChangedAssets()
 	for i := 0; i < len(host.frameRunner); i++ {
 		if host.frameRunner[i].frame <= host.frame {
 			host.frameRunner[i].call()
@@ -321,7 +340,10 @@ func (host *Host) Update(deltaTime float64) {
 
 // Render will render the scene. This starts by preparing any drawings that are
 // pending. It also creates any pending shaders, textures, and meshes before
-// the start of the render. The frame is then readied, buffers swapped, and any
+// the start of the render. Once the frame is readied, the render cameras are
+// drawn, then the level of detail of each instance is selected, instances
+// outside of the camera view are culled and the lights for the frame are
+// selected for the main camera, then buffers are swapped, and any
 // transformations that are dirty on entities are then cleaned.
 func (host *Host) Render() {
 	host.Drawings.PreparePending()
@@ -329,8 +351,13 @@ func (host *Host) Render() {
 	host.textureCache.CreatePending()
 	host.meshCache.CreatePending()
 	if host.Drawings.HasDrawings() {
+		host.Lights.Prepare(host.Camera.Position())
 		if host.Window.Renderer.ReadyFrame(host.Camera,
 			host.UICamera, float32(host.Runtime())) {
+			host.Drawings.RenderCameras(host.Window.Renderer, &host.Lights)
+			host.Drawings.SelectLODs(host.Camera)
+			host.Drawings.Cull(host.Camera.Frustum())
+			host.Drawings.SelectLights(&host.Lights)
 			host.Drawings.Render(host.Window.Renderer)
 		}
 	}
@@ -342,6 +369,41 @@ func (host *Host) Render() {
 	host.editorEntities.resetDirty()
 }
 
+// AddRenderCamera creates a camera that draws the drawings on the given layers
+// into its own canvas of the given size. The color of the canvas can be used
+// as a texture through #rendering.RenderCamera.Texture.
+func (host *Host) AddRenderCamera(name string, camera cameras.Camera, width, height int32, layers rendering.RenderLayers) (*rendering.RenderCamera, error) {
+	canvas, err := host.Window.Renderer.CreateCameraCanvas(name, width, height)
+	if err != nil {
+		return nil, err
+	}
+	rc := rendering.NewRenderCamera(host.Window.Renderer, name, camera, canvas, layers)
+	host.Drawings.AddCamera(rc)
+	return rc, nil
+}
+
+// RenderCamera will return the render camera that was added with the given
+// name, or false if there is no such camera
+func (host *Host) RenderCamera(name string) (*rendering.RenderCamera, bool) {
+	for _, c := range host.Drawings.Cameras() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// RemoveRenderCamera stops the render camera with the given name from drawing
+// and destroys its canvas, anything still using the texture of the camera
+// should be changed before it is removed
+func (host *Host) RemoveRenderCamera(name string) {
+	if c, ok := host.RenderCamera(name); ok {
+		host.Drawings.RemoveCamera(c)
+		host.Window.Renderer.WaitForRender()
+		host.Window.Renderer.DestroyCanvas(name)
+	}
+}
+
 // Frame will return the current frame id
 func (host *Host) Frame() FrameId { return host.frame }
 
@@ -362,9 +424,11 @@ func (host *Host) RunAfterFrames(wait int, call func()) {
 func (host *Host) Teardown() {
 	host.Window.Renderer.WaitForRender()
 	host.OnClose.Execute()
+	host.DisableHotReload()
 	host.Updater.Destroy()
 	host.LateUpdater.Destroy()
 	host.Drawings.Destroy(host.Window.Renderer)
+	host.materialCache.Destroy()
 	host.textureCache.Destroy()
 	host.meshCache.Destroy()
 	host.shaderCache.Destroy()
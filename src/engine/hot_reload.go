@@ -0,0 +1,197 @@
+/******************************************************************************/
+/* hot_reload.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import (
+	"kaiju/filesystem"
+	"kaiju/systems/events"
+	"log/slog"
+	"path/filepath"
+)
+
+// AssetReload describes an asset that changed while hot reloading was
+// enabled through #Host.EnableHotReload, or that was reloaded through
+// #Host.ReloadAsset
+type AssetReload struct {
+	// Key is the key the asset is read from the asset database with
+	Key string
+	// Path is the file on disk that changed, it is empty when the asset was
+	// reloaded through #Host.ReloadAsset
+	Path string
+	// Removed is true when the file was deleted or moved away, the cached
+	// copy of the asset is kept
+	Removed bool
+	// Reloaded is true when a cache of the host held the asset and swapped
+	// it for the new content
+	Reloaded bool
+}
+
+type assetReloadEntry struct {
+	id   events.Id
+	call func(AssetReload)
+}
+
+// AssetReloadEvent calls its functions on the main thread for each asset
+// that is reloaded, after the caches of the host have swapped in the new
+// content. Games add to it to rebuild anything they made from the asset.
+type AssetReloadEvent struct {
+	nextId events.Id
+	calls  []assetReloadEntry
+}
+
+func newAssetReloadEvent() AssetReloadEvent {
+	return AssetReloadEvent{
+		nextId: 1,
+		calls:  make([]assetReloadEntry, 0),
+	}
+}
+
+func (e AssetReloadEvent) IsEmpty() bool { return len(e.calls) == 0 }
+
+func (e *AssetReloadEvent) Add(call func(reload AssetReload)) events.Id {
+	id := e.nextId
+	e.nextId++
+	e.calls = append(e.calls, assetReloadEntry{id, call})
+	return id
+}
+
+func (e *AssetReloadEvent) Remove(id events.Id) {
+	for i := range e.calls {
+		if e.calls[i].id == id {
+			last := len(e.calls) - 1
+			e.calls[i], e.calls[last] = e.calls[last], e.calls[i]
+			e.calls = e.calls[:last]
+			return
+		}
+	}
+}
+
+func (e *AssetReloadEvent) Execute(reload AssetReload) {
+	for i := range e.calls {
+		e.calls[i].call(reload)
+	}
+}
+
+// EnableHotReload starts watching the folders that the asset database reads
+// from (see #assets.Database.Folders). When a file within them changes, the
+// textures, shaders, and meshes that were read from it are swapped for the
+// new content without being recreated, then the #Host.OnAssetReload event is
+// executed. The changes are handled on the main thread during the update.
+// Calling it again watches any folders that were mounted since.
+func (host *Host) EnableHotReload() error {
+	host.DisableHotReload()
+	w := filesystem.NewWatcher(filesystem.DefaultWatchSettle)
+	watched := map[string]struct{}{}
+	for _, folder := range host.assetDatabase.Folders() {
+		abs, err := filepath.Abs(folder)
+		if err != nil || !filesystem.DirectoryExists(abs) {
+			continue
+		}
+		if _, ok := watched[abs]; ok {
+			continue
+		}
+		watched[abs] = struct{}{}
+		if err := w.Add(folder); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	host.hotReload = w
+	return nil
+}
+
+// DisableHotReload stops watching the asset folders
+func (host *Host) DisableHotReload() {
+	if host.hotReload != nil {
+		host.hotReload.Close()
+		host.hotReload = nil
+	}
+}
+
+// ReloadAsset swaps the cached textures, shaders, and meshes that were read
+// from the asset for its current content and then executes the
+// #Host.OnAssetReload event. The GPU resources are created again with the
+// other pending resources before the next frame is drawn. Shaders are found
+// by the SPIR-V files of their stages and only meshes loaded through
+// #rendering.MeshCache.MeshAsync can be reloaded from their asset.
+func (host *Host) ReloadAsset(key string) {
+	host.reloadAsset(AssetReload{Key: key})
+}
+
+func (host *Host) reloadAsset(reload AssetReload) {
+	if !reload.Removed {
+		reload.Reloaded = host.shaderCache.Reload(reload.Key)
+		reloaders := []func(string) (bool, error){
+			host.textureCache.Reload,
+			host.meshCache.Reload,
+		}
+		for _, reloader := range reloaders {
+			ok, err := reloader(reload.Key)
+			if err != nil {
+				slog.Error("failed to reload the asset",
+					slog.String("key", reload.Key), slog.String("error", err.Error()))
+			} else if ok {
+				reload.Reloaded = true
+			}
+		}
+	}
+	host.OnAssetReload.Execute(reload)
+}
+
+// reloadChangedAssets handles the changes that the hot reload watcher found
+// since the last update
+func (host *Host) reloadChangedAssets() {
+	for host.hotReload != nil {
+		select {
+		case e, ok := <-host.hotReload.Events():
+			if !ok {
+				host.hotReload = nil
+				return
+			}
+			if key, ok := host.assetDatabase.KeyForPath(e.Path); ok {
+				host.reloadAsset(AssetReload{
+					Key:     key,
+					Path:    e.Path,
+					Removed: e.Op == filesystem.WatchRemoved,
+				})
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,268 @@
+/******************************************************************************/
+/* watcher.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WatchOp is what happened to a file within a watched folder
+type WatchOp int
+
+const (
+	// WatchChanged is reported when a file is created, written to, or moved
+	// into a watched folder
+	WatchChanged WatchOp = iota
+	// WatchRemoved is reported when a file is deleted or moved out of a
+	// watched folder
+	WatchRemoved
+)
+
+const (
+	// DefaultWatchSettle is long enough for most programs to finish writing
+	// a file that they save in more than one step
+	DefaultWatchSettle = 100 * time.Millisecond
+	// DefaultWatchInterval is how often the folders are scanned when the
+	// changes can not be watched through the operating system
+	DefaultWatchInterval = 500 * time.Millisecond
+)
+
+// WatchEvent is a change to a file, the path is the path of the file joined
+// to the watched folder that it is in
+type WatchEvent struct {
+	Path string
+	Op   WatchOp
+}
+
+// watchBackend finds the changes to the files and hands them to the notify
+// function that it was created with
+type watchBackend interface {
+	add(root string) error
+	close()
+}
+
+// Watcher reports the files that change within the watched folders and all
+// of their sub folders. Programs often write a file in more than one step so
+// a file is only reported once it has gone without changes for the settle
+// time, all of the changes made within that time are reported as one event.
+type Watcher struct {
+	events  chan WatchEvent
+	raw     chan WatchEvent
+	backend watchBackend
+	settle  time.Duration
+	done    chan struct{}
+	once    sync.Once
+}
+
+type pendingWatch struct {
+	op WatchOp
+	at time.Time
+}
+
+func newWatcher(settle time.Duration) *Watcher {
+	return &Watcher{
+		events: make(chan WatchEvent, 64),
+		raw:    make(chan WatchEvent, 64),
+		settle: settle,
+		done:   make(chan struct{}),
+	}
+}
+
+// NewWatcher creates a watcher that is told about the changes by the
+// operating system where it is supported (inotify on Linux). On other
+// platforms, or when the notifications can not be started, the watched
+// folders are scanned for changes every #DefaultWatchInterval.
+func NewWatcher(settle time.Duration) *Watcher {
+	w := newWatcher(settle)
+	if b, err := newNotifyBackend(w.notify); err == nil {
+		w.backend = b
+	} else {
+		w.backend = newPollBackend(DefaultWatchInterval, w.notify)
+	}
+	go w.debounce()
+	return w
+}
+
+// NewPollingWatcher creates a watcher that scans the watched folders for
+// changes at the interval, it works on any file system, including network
+// and container mounts that do not send change notifications
+func NewPollingWatcher(interval, settle time.Duration) *Watcher {
+	w := newWatcher(settle)
+	w.backend = newPollBackend(interval, w.notify)
+	go w.debounce()
+	return w
+}
+
+// Add starts watching the folder and all of its sub folders, folders that
+// are created within it later are watched as well
+func (w *Watcher) Add(root string) error {
+	return w.backend.add(filepath.Clean(root))
+}
+
+// Events returns the channel that the changes are reported on, the channel
+// is closed when the watcher is closed
+func (w *Watcher) Events() <-chan WatchEvent { return w.events }
+
+// Close stops watching all of the folders, it is safe to call more than once
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+		w.backend.close()
+	})
+}
+
+func (w *Watcher) notify(e WatchEvent) {
+	select {
+	case w.raw <- e:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) debounce() {
+	defer close(w.events)
+	pending := map[string]pendingWatch{}
+	tick := time.NewTicker(max(w.settle/2, time.Millisecond))
+	defer tick.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case e := <-w.raw:
+			pending[e.Path] = pendingWatch{e.Op, time.Now()}
+		case now := <-tick.C:
+			for path, p := range pending {
+				if now.Sub(p.at) < w.settle {
+					continue
+				}
+				delete(pending, path)
+				select {
+				case w.events <- WatchEvent{path, p.op}:
+				case <-w.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollBackend finds the changes by comparing the modification time and size
+// of each file with the previous scan of the folders
+type pollBackend struct {
+	roots  []string
+	files  map[string]fileStamp
+	notify func(WatchEvent)
+	done   chan struct{}
+	mutex  sync.Mutex
+}
+
+func newPollBackend(interval time.Duration, notify func(WatchEvent)) *pollBackend {
+	p := &pollBackend{
+		files:  map[string]fileStamp{},
+		notify: notify,
+		done:   make(chan struct{}),
+	}
+	go p.run(interval)
+	return p
+}
+
+func (p *pollBackend) add(root string) error {
+	if _, err := os.Stat(root); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.roots = append(p.roots, root)
+	scanFiles(root, p.files)
+	return nil
+}
+
+func (p *pollBackend) close() { close(p.done) }
+
+func (p *pollBackend) run(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-tick.C:
+			p.poll()
+		}
+	}
+}
+
+func (p *pollBackend) poll() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	found := make(map[string]fileStamp, len(p.files))
+	for _, root := range p.roots {
+		scanFiles(root, found)
+	}
+	for path, stamp := range found {
+		if last, ok := p.files[path]; !ok || last != stamp {
+			p.notify(WatchEvent{path, WatchChanged})
+		}
+	}
+	for path := range p.files {
+		if _, ok := found[path]; !ok {
+			p.notify(WatchEvent{path, WatchRemoved})
+		}
+	}
+	p.files = found
+}
+
+func scanFiles(root string, into map[string]fileStamp) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			into[path] = fileStamp{info.ModTime(), info.Size()}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+/******************************************************************************/
+/* watcher.linux.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO |
+	syscall.IN_MOVED_FROM | syscall.IN_CREATE | syscall.IN_DELETE
+
+// inotifyBackend has the kernel report the changes to the watched folders,
+// each folder needs its own watch so new sub folders are watched as they
+// are created
+type inotifyBackend struct {
+	file   *os.File
+	fd     int
+	dirs   map[int32]string
+	notify func(WatchEvent)
+	mutex  sync.Mutex
+}
+
+func newNotifyBackend(notify func(WatchEvent)) (watchBackend, error) {
+	// The descriptor is non-blocking so that reading it goes through the
+	// runtime poller, which lets closing the file stop the read
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	b := &inotifyBackend{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		dirs:   map[int32]string{},
+		notify: notify,
+	}
+	go b.read()
+	return b, nil
+}
+
+func (b *inotifyBackend) add(root string) error {
+	return b.walk(root, false)
+}
+
+// walk watches the folder and its sub folders, the files that are found are
+// reported when report is true
+func (b *inotifyBackend) walk(root string, report bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			if report {
+				b.notify(WatchEvent{path, WatchChanged})
+			}
+			return nil
+		}
+		wd, err := syscall.InotifyAddWatch(b.fd, path, inotifyMask)
+		if err != nil {
+			return err
+		}
+		b.mutex.Lock()
+		b.dirs[int32(wd)] = path
+		b.mutex.Unlock()
+		return nil
+	})
+}
+
+func (b *inotifyBackend) close() { b.file.Close() }
+
+func (b *inotifyBackend) read() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			e := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(e.Len)
+			name := strings.TrimRight(string(buf[start:offset]), "\x00")
+			b.handle(e.Wd, e.Mask, name)
+		}
+	}
+}
+
+func (b *inotifyBackend) handle(wd int32, mask uint32, name string) {
+	b.mutex.Lock()
+	dir, ok := b.dirs[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(b.dirs, wd)
+	}
+	b.mutex.Unlock()
+	if !ok || name == "" {
+		return
+	}
+	path := filepath.Join(dir, name)
+	if mask&syscall.IN_ISDIR != 0 {
+		// Files can be written into a new folder before it is watched, so
+		// the files already in it are reported when it is added
+		if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+			b.walk(path, true)
+		}
+		return
+	}
+	if mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0 {
+		b.notify(WatchEvent{path, WatchChanged})
+	} else if mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0 {
+		b.notify(WatchEvent{path, WatchRemoved})
+	}
+}
@@ -0,0 +1,137 @@
+/******************************************************************************/
+/* watcher_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSettle = 20 * time.Millisecond
+
+func expectWatchEvent(t *testing.T, w *Watcher, path string, op WatchOp) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-w.Events():
+			if e.Path == path && e.Op == op {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected %s to be reported with %d", path, op)
+		}
+	}
+}
+
+func testWatcher(t *testing.T, w *Watcher) {
+	defer w.Close()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "sub"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(root); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "sub", "image.png")
+	if err := os.WriteFile(file, []byte("first"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	expectWatchEvent(t, w, file, WatchChanged)
+	folder := filepath.Join(root, "new")
+	if err := os.Mkdir(folder, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	inFolder := filepath.Join(folder, "mesh.obj")
+	if err := os.WriteFile(inFolder, []byte("v 0 0 0"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	expectWatchEvent(t, w, inFolder, WatchChanged)
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	expectWatchEvent(t, w, file, WatchRemoved)
+}
+
+func TestWatcher(t *testing.T) {
+	testWatcher(t, NewWatcher(testSettle))
+}
+
+func TestPollingWatcher(t *testing.T) {
+	testWatcher(t, NewPollingWatcher(10*time.Millisecond, testSettle))
+}
+
+func TestWatcherSettle(t *testing.T) {
+	w := NewWatcher(testSettle)
+	defer w.Close()
+	root := t.TempDir()
+	if err := w.Add(root); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "page.html")
+	for i := range 3 {
+		if err := os.WriteFile(file, []byte{byte(i)}, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectWatchEvent(t, w, file, WatchChanged)
+	select {
+	case e := <-w.Events():
+		t.Errorf("expected the writes to be reported once, got %+v", e)
+	case <-time.After(testSettle * 5):
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	w := NewWatcher(testSettle)
+	if err := w.Add(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	w.Close()
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			t.Error("expected no events after closing")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the events to be closed")
+	}
+}
@@ -42,6 +42,7 @@ import (
 	"kaiju/systems/console"
 	"kaiju/systems/logging"
 	"runtime"
+	"runtime/debug"
 	"time"
 )
 
@@ -57,6 +58,7 @@ func (c *Container) RunFunction(f func()) {
 
 func (c *Container) Run(width, height, x, y int) error {
 	runtime.LockOSThread()
+	defer c.reportCrash()
 	if err := c.Host.Initialize(width, height, x, y); err != nil {
 		return err
 	}
@@ -83,6 +85,17 @@ func (c *Container) Run(width, height, x, y int) error {
 	return nil
 }
 
+func (c *Container) reportCrash() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if c.Host.LogStream != nil {
+		c.Host.LogStream.WriteCrashReport(logging.CrashReportFile, r, debug.Stack())
+	}
+	panic(r)
+}
+
 func New(name string, logStream *logging.LogStream) *Container {
 	host := engine.NewHost(name, logStream)
 	c := &Container{
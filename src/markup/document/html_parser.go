@@ -45,6 +45,7 @@ import (
 	"kaiju/markup/elements"
 	"kaiju/matrix"
 	"kaiju/rendering"
+	"kaiju/systems/events"
 	"kaiju/ui"
 	"log/slog"
 	"slices"
@@ -95,6 +96,8 @@ type Document struct {
 	// TODO:  Should this be here?
 	firstInput *ui.Input
 	lastInput  *ui.Input
+	group      *ui.Group
+	reloadIds  []events.Id
 }
 
 func (d *Document) SetupStylizer(style rules.StyleSheet, host *engine.Host,
@@ -285,6 +288,7 @@ func (d *Document) setupBody(h *Element, host *engine.Host) *Element {
 
 func DocumentFromHTMLString(host *engine.Host, htmlStr string, withData any, funcMap map[string]func(*Element)) *Document {
 	parsed := &Document{
+		host:          host,
 		Elements:      make([]*Element, 0),
 		groups:        map[string][]*Element{},
 		ids:           map[string]*Element{},
@@ -317,6 +321,7 @@ func DocumentFromHTMLString(host *engine.Host, htmlStr string, withData any, fun
 }
 
 func (d *Document) SetGroup(group *ui.Group) {
+	d.group = group
 	for i := range d.Elements {
 		if d.Elements[i].node.Type == html.ElementNode {
 			data := d.Elements[i].Data()
@@ -343,6 +348,37 @@ func (d *Document) Destroy() {
 	for i := range d.Elements {
 		d.Elements[i].UI.Entity().Destroy()
 	}
+	for _, id := range d.reloadIds {
+		d.host.OnAssetReload.Remove(id)
+	}
+	d.reloadIds = d.reloadIds[:0]
+}
+
+// OnAssetReload calls the function for each asset that is hot reloaded until
+// the document is destroyed, see #engine.Host.EnableHotReload
+func (d *Document) OnAssetReload(call func(reload engine.AssetReload)) {
+	d.reloadIds = append(d.reloadIds, d.host.OnAssetReload.Add(call))
+}
+
+// Replace swaps the elements of the document for the elements of the fresh
+// document, the current elements are destroyed. The document keeps its
+// pointer, its group, whether it is active, and its reload functions, any
+// elements that were looked up from it before need to be looked up again.
+func (d *Document) Replace(fresh *Document) {
+	active := len(d.Elements) == 0 || d.Elements[0].UI.Entity().IsActive()
+	group := d.group
+	reloadIds := d.reloadIds
+	for i := range d.Elements {
+		d.Elements[i].UI.Entity().Destroy()
+	}
+	*d = *fresh
+	d.reloadIds = reloadIds
+	if group != nil {
+		d.SetGroup(group)
+	}
+	if !active {
+		d.Deactivate()
+	}
 }
 
 func (d *Document) Clean() {
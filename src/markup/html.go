@@ -38,11 +38,14 @@
 package markup
 
 import (
+	"kaiju/assets/vfs"
 	"kaiju/engine"
 	"kaiju/markup/css"
 	"kaiju/markup/css/rules"
 	"kaiju/markup/document"
 	"kaiju/ui"
+	"log/slog"
+	"slices"
 )
 
 func sizeTexts(doc *document.Document, host *engine.Host) {
@@ -81,7 +84,30 @@ func sizeTexts(doc *document.Document, host *engine.Host) {
 	}
 }
 
+// DocumentFromHTMLAsset creates the document from the HTML file of the asset
+// database. The document is rebuilt in place when the HTML file, or any of the
+// style sheets it links to, are hot reloaded (see #engine.Host.EnableHotReload).
 func DocumentFromHTMLAsset(host *engine.Host, htmlPath string, withData any, funcMap map[string]func(*document.Element)) (*document.Document, error) {
+	doc, err := documentFromHTMLAsset(host, htmlPath, withData, funcMap)
+	if err != nil {
+		return nil, err
+	}
+	doc.OnAssetReload(func(reload engine.AssetReload) {
+		if reload.Removed || !slices.Contains(documentAssets(doc, htmlPath), reload.Key) {
+			return
+		}
+		fresh, err := documentFromHTMLAsset(host, htmlPath, withData, funcMap)
+		if err != nil {
+			slog.Error("failed to reload the document", slog.String("html", htmlPath),
+				slog.String("error", err.Error()))
+			return
+		}
+		doc.Replace(fresh)
+	})
+	return doc, nil
+}
+
+func documentFromHTMLAsset(host *engine.Host, htmlPath string, withData any, funcMap map[string]func(*document.Element)) (*document.Document, error) {
 	m, err := host.AssetDatabase().ReadText(htmlPath)
 	if err != nil {
 		return nil, err
@@ -89,6 +115,18 @@ func DocumentFromHTMLAsset(host *engine.Host, htmlPath string, withData any, fun
 	return DocumentFromHTMLString(host, m, "", withData, funcMap), nil
 }
 
+// documentAssets returns the normalized keys of the HTML file and the linked
+// style sheets of the document
+func documentAssets(doc *document.Document, htmlPath string) []string {
+	keys := []string{vfs.NormalizeKey(htmlPath)}
+	for _, head := range doc.HeadElements {
+		if head.Data() == "link" && head.Attribute("rel") == "stylesheet" {
+			keys = append(keys, vfs.NormalizeKey(head.Attribute("href")))
+		}
+	}
+	return keys
+}
+
 func DocumentFromHTMLString(host *engine.Host, html, cssStr string, withData any, funcMap map[string]func(*document.Element)) *document.Document {
 	doc := document.DocumentFromHTMLString(host, html, withData, funcMap)
 	s := rules.NewStyleSheet()
@@ -44,7 +44,7 @@
 	MULPS  b,  X0     \
 	HADDPS X0, X0     \
 	HADDPS X0, X0     \
-	MOVUPS X0, to
+	MOVSS  X0, to
 
 #define PACK_COLUMNS(start)           \
 	INSERTPS  $14, m+start+0(FP),  X1 \  // x0y0
@@ -571,3 +571,40 @@ func (m Mat4) Invert() Mat4 {
 	res[x3y3] = 1.0
 	return res
 }
+
+// Decompose splits a transformation matrix into the position, the rotation
+// as euler angles in degrees, and the scale that #Transform would use to
+// build the same matrix. The matrix is not expected to have any shear.
+func (m Mat4) Decompose() (position, rotation, scale Vec3) {
+	position = m.Position()
+	scale = Vec3{
+		Vec3{m[x0y0], m[x1y0], m[x2y0]}.Length(),
+		Vec3{m[x0y1], m[x1y1], m[x2y1]}.Length(),
+		Vec3{m[x0y2], m[x1y2], m[x2y2]}.Length(),
+	}
+	if m.Determinant3x3() < 0 {
+		scale[Vx] = -scale[Vx]
+	}
+	rot := Mat4Identity()
+	for col := 0; col < 3; col++ {
+		if scale[col] == 0 {
+			continue
+		}
+		for row := 0; row < 3; row++ {
+			rot[col*4+row] = m[col*4+row] / scale[col]
+		}
+	}
+	// The quaternion read from the matrix is the inverse of the one the
+	// matrix was built from, see #Quaternion.ToMat4
+	q := QuaternionFromMat4(rot)
+	q.Conjugate()
+	return position, q.ToEuler(), scale
+}
+
+// Determinant3x3 returns the determinant of the rotation and scale part of
+// the matrix, it is negative when the matrix mirrors
+func (m Mat4) Determinant3x3() Float {
+	return m[x0y0]*(m[x1y1]*m[x2y2]-m[x2y1]*m[x1y2]) -
+		m[x0y1]*(m[x1y0]*m[x2y2]-m[x2y0]*m[x1y2]) +
+		m[x0y2]*(m[x1y0]*m[x2y1]-m[x2y0]*m[x1y1])
+}
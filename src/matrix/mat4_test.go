@@ -167,3 +167,30 @@ func (v Vec4) legacyMultiplyMat4(rhs Mat4) Vec4 {
 	result[Vw] = Vec4Dot(row, v)
 	return result
 }
+
+func TestMat4Decompose(t *testing.T) {
+	tr := NewTransform()
+	tr.SetPosition(Vec3{1, 2, 3})
+	tr.SetRotation(Vec3{30, 40, 50})
+	tr.SetScale(Vec3{2, 3, 4})
+	p, r, s := tr.Matrix().Decompose()
+	if !Vec3Approx(p, tr.Position()) {
+		t.Errorf("expected position %v but got %v", tr.Position(), p)
+	}
+	if !Vec3ApproxTo(r, tr.Rotation(), 0.001) {
+		t.Errorf("expected rotation %v but got %v", tr.Rotation(), r)
+	}
+	if !Vec3ApproxTo(s, tr.Scale(), 0.001) {
+		t.Errorf("expected scale %v but got %v", tr.Scale(), s)
+	}
+}
+
+func TestMat4MultiplyNested(t *testing.T) {
+	x := QuaternionFromEuler(Vec3{0, 0, 0}).ToMat4()
+	y := QuaternionFromEuler(Vec3{0, 90, 0}).ToMat4()
+	z := QuaternionFromEuler(Vec3{0, 0, 0}).ToMat4()
+	expected := legacyMat4Multiply(legacyMat4Multiply(x, y), z)
+	if c := Mat4Multiply(Mat4Multiply(x, y), z); c != expected {
+		t.Errorf("\nc = %v\nexpected = %v", c, expected)
+	}
+}
@@ -45,3 +45,14 @@ type Canvas interface {
 	ShaderPipeline(name string) FuncPipeline
 	Destroy(renderer Renderer)
 }
+
+// offscreenCanvas is implemented by canvases that are only sampled by other
+// canvases, they are drawn before the rest and are never blitted to the screen
+type offscreenCanvas interface {
+	isOffscreen()
+}
+
+func isOffscreenCanvas(c Canvas) bool {
+	_, ok := c.(offscreenCanvas)
+	return ok
+}
@@ -0,0 +1,273 @@
+/******************************************************************************/
+/* cube_map.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"errors"
+	"kaiju/assets"
+	"kaiju/matrix"
+	"math"
+	"path"
+	"strconv"
+)
+
+// CubeMapSide is the index of a face of a cube map, the faces are stored in
+// this order within the memory of a cube map #TextureData
+type CubeMapSide = int
+
+const (
+	CubeMapSidePositiveX CubeMapSide = iota
+	CubeMapSideNegativeX
+	CubeMapSidePositiveY
+	CubeMapSideNegativeY
+	CubeMapSidePositiveZ
+	CubeMapSideNegativeZ
+)
+
+// CubeMapFaceSuffixes are added to the name of a cube map to name the images
+// of each of its faces when they are generated
+var CubeMapFaceSuffixes = [CubeMapSides]string{"_px", "_nx", "_py", "_ny", "_pz", "_nz"}
+
+// CubeMapDef is the JSON asset format of a cube map. It either lists an image
+// for each of the faces, in #CubeMapSide order, or an equirectangular image
+// that is converted into the faces. The images are relative to the folder of
+// the cube map. When both are given, the faces are used, this is how the
+// importer stores the faces it generated from the equirectangular image.
+type CubeMapDef struct {
+	Faces           [CubeMapSides]string `json:",omitempty"`
+	Equirectangular string               `json:",omitempty"`
+	// Size is the width and height of the faces generated from the
+	// equirectangular image, half the height of the image is used when 0
+	Size int `json:",omitempty"`
+}
+
+func CubeMapDefFromJson(jsonStr string) (CubeMapDef, error) {
+	var def CubeMapDef
+	if err := json.Unmarshal([]byte(jsonStr), &def); err != nil {
+		return def, err
+	}
+	return def, def.Validate()
+}
+
+func (d CubeMapDef) ToJson() (string, error) {
+	data, err := json.MarshalIndent(d, "", "\t")
+	return string(data), err
+}
+
+// HasFaces returns true if an image is given for every face
+func (d *CubeMapDef) HasFaces() bool {
+	for i := range d.Faces {
+		if d.Faces[i] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate returns an error if the cube map has neither all of its faces nor
+// an equirectangular image to generate them from
+func (d *CubeMapDef) Validate() error {
+	if d.HasFaces() || d.Equirectangular != "" {
+		return nil
+	}
+	for i := range d.Faces {
+		if d.Faces[i] != "" {
+			return errors.New("the cube map is missing the face " + strconv.Itoa(i))
+		}
+	}
+	return errors.New("the cube map has no faces or equirectangular image")
+}
+
+// NewCubeMapTextureData joins the six faces into the data of a cube map
+// texture. The faces must be uncompressed RGBA, square, and of the same size.
+func NewCubeMapTextureData(faces [CubeMapSides]TextureData) (TextureData, error) {
+	size := faces[0].Width
+	faceLen := size * size * bytesInPixel
+	res := TextureData{
+		Mem:            make([]byte, 0, faceLen*CubeMapSides),
+		InternalFormat: TextureInputTypeRgba8,
+		Format:         faces[0].Format,
+		Type:           TextureMemTypeUnsignedByte,
+		Width:          size,
+		Height:         size,
+		InputType:      TextureFileFormatRaw,
+		CubeMap:        true,
+	}
+	for i := range faces {
+		f := &faces[i]
+		if f.InternalFormat != TextureInputTypeRgba8 || len(f.Mem) != faceLen {
+			return TextureData{}, errors.New("the cube map face " + strconv.Itoa(i) + " is not RGBA")
+		} else if f.Width != size || f.Height != size {
+			return TextureData{}, errors.New("the cube map faces must be square and of the same size")
+		}
+		res.Mem = append(res.Mem, f.Mem...)
+	}
+	return res, nil
+}
+
+// CubeMapDirection returns the direction that the texel coordinates (from 0
+// to 1, starting at the top left) of a face of a cube map sample from
+func CubeMapDirection(side CubeMapSide, s, t matrix.Float) matrix.Vec3 {
+	a := 2*s - 1
+	b := 2*t - 1
+	var dir matrix.Vec3
+	switch side {
+	case CubeMapSidePositiveX:
+		dir = matrix.Vec3{1, -b, -a}
+	case CubeMapSideNegativeX:
+		dir = matrix.Vec3{-1, -b, a}
+	case CubeMapSidePositiveY:
+		dir = matrix.Vec3{a, 1, b}
+	case CubeMapSideNegativeY:
+		dir = matrix.Vec3{a, -1, -b}
+	case CubeMapSidePositiveZ:
+		dir = matrix.Vec3{a, -b, 1}
+	default:
+		dir = matrix.Vec3{-a, -b, -1}
+	}
+	return dir.Normal()
+}
+
+// EquirectangularToCubeMap projects an equirectangular image onto the faces
+// of a cube map. The image is RGBA with its rows starting at the top, the
+// center of the image is in the forward (-Z) direction. The faces are
+// filtered bilinearly and are returned in #CubeMapSide order.
+func EquirectangularToCubeMap(image TextureData, size int) ([CubeMapSides]TextureData, error) {
+	var faces [CubeMapSides]TextureData
+	if image.InternalFormat != TextureInputTypeRgba8 ||
+		len(image.Mem) != image.Width*image.Height*bytesInPixel || image.Width == 0 {
+		return faces, errors.New("the equirectangular image is not RGBA")
+	}
+	if size <= 0 {
+		size = max(1, image.Height/2)
+	}
+	for side := range faces {
+		mem := make([]byte, size*size*bytesInPixel)
+		for y := 0; y < size; y++ {
+			t := (matrix.Float(y) + 0.5) / matrix.Float(size)
+			for x := 0; x < size; x++ {
+				s := (matrix.Float(x) + 0.5) / matrix.Float(size)
+				dir := CubeMapDirection(side, s, t)
+				u := 0.5 + matrix.Atan2(dir.X(), -dir.Z())/(2*math.Pi)
+				v := 0.5 - matrix.Asin(matrix.Clamp(dir.Y(), -1, 1))/math.Pi
+				sampleEquirectangular(image, u, v, mem[(y*size+x)*bytesInPixel:])
+			}
+		}
+		faces[side] = TextureData{
+			Mem:            mem,
+			InternalFormat: TextureInputTypeRgba8,
+			Format:         image.Format,
+			Type:           TextureMemTypeUnsignedByte,
+			Width:          size,
+			Height:         size,
+			InputType:      TextureFileFormatRaw,
+		}
+	}
+	return faces, nil
+}
+
+// sampleEquirectangular bilinearly samples the image into the pixel, the u
+// coordinate wraps around the image and the v coordinate is clamped
+func sampleEquirectangular(image TextureData, u, v matrix.Float, pixel []byte) {
+	fx := u*matrix.Float(image.Width) - 0.5
+	fy := matrix.Clamp(v*matrix.Float(image.Height)-0.5, 0, matrix.Float(image.Height-1))
+	x0 := int(matrix.Floor(fx))
+	y0 := int(matrix.Floor(fy))
+	tx := fx - matrix.Float(x0)
+	ty := fy - matrix.Float(y0)
+	x1 := x0 + 1
+	y1 := min(y0+1, image.Height-1)
+	x0 = ((x0 % image.Width) + image.Width) % image.Width
+	x1 = ((x1 % image.Width) + image.Width) % image.Width
+	at := func(x, y, c int) matrix.Float {
+		return matrix.Float(image.Mem[(y*image.Width+x)*bytesInPixel+c])
+	}
+	for c := 0; c < bytesInPixel; c++ {
+		top := at(x0, y0, c)*(1-tx) + at(x1, y0, c)*tx
+		bottom := at(x0, y1, c)*(1-tx) + at(x1, y1, c)*tx
+		pixel[c] = uint8(matrix.Clamp(top*(1-ty)+bottom*ty+0.5, 0, 255))
+	}
+}
+
+// ReadCubeMapData reads the cube map definition and its images from the
+// asset database into the data of a cube map texture
+func ReadCubeMapData(assetDb *assets.Database, cubeMapKey string) (TextureData, error) {
+	src, err := assetDb.ReadText(cubeMapKey)
+	if err != nil {
+		return TextureData{}, err
+	}
+	def, err := CubeMapDefFromJson(src)
+	if err != nil {
+		return TextureData{}, err
+	}
+	dir := path.Dir(cubeMapKey)
+	var faces [CubeMapSides]TextureData
+	if def.HasFaces() {
+		for i := range faces {
+			if faces[i], err = TexturePixelsFromAsset(assetDb, path.Join(dir, def.Faces[i])); err != nil {
+				return TextureData{}, err
+			}
+		}
+	} else {
+		image, err := TexturePixelsFromAsset(assetDb, path.Join(dir, def.Equirectangular))
+		if err != nil {
+			return TextureData{}, err
+		}
+		if faces, err = EquirectangularToCubeMap(image, def.Size); err != nil {
+			return TextureData{}, err
+		}
+	}
+	return NewCubeMapTextureData(faces)
+}
+
+// NewCubeMap creates a cube map texture from a #CubeMapDef asset, like
+// #NewTexture the texture is created on the renderer with DelayedCreate
+func NewCubeMap(assetDb *assets.Database, cubeMapKey string, filter TextureFilter) (*Texture, error) {
+	data, err := ReadCubeMapData(assetDb, cubeMapKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Texture{
+		Key:         cubeMapKey,
+		Filter:      filter,
+		Width:       data.Width,
+		Height:      data.Height,
+		pendingData: &data,
+	}, nil
+}
@@ -0,0 +1,166 @@
+/******************************************************************************/
+/* cube_map_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func testCubeMapFace(size int, color [4]byte) TextureData {
+	mem := make([]byte, 0, size*size*bytesInPixel)
+	for i := 0; i < size*size; i++ {
+		mem = append(mem, color[:]...)
+	}
+	return TextureData{
+		Mem:            mem,
+		InternalFormat: TextureInputTypeRgba8,
+		Format:         TextureColorFormatRgbaUnorm,
+		Type:           TextureMemTypeUnsignedByte,
+		Width:          size,
+		Height:         size,
+	}
+}
+
+func TestCubeMapDirectionFaceCenters(t *testing.T) {
+	expected := [CubeMapSides]matrix.Vec3{
+		{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1},
+	}
+	for side, want := range expected {
+		got := CubeMapDirection(side, 0.5, 0.5)
+		if !matrix.Vec3Approx(got, want) {
+			t.Errorf("side %d: expected %v but got %v", side, want, got)
+		}
+	}
+}
+
+func TestEquirectangularToCubeMapPoles(t *testing.T) {
+	const width, height = 16, 8
+	img := TextureData{
+		Mem:            make([]byte, width*height*bytesInPixel),
+		InternalFormat: TextureInputTypeRgba8,
+		Width:          width,
+		Height:         height,
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := img.Mem[(y*width+x)*bytesInPixel:]
+			if y < height/2 {
+				p[0] = 255
+			} else {
+				p[2] = 255
+			}
+			p[3] = 255
+		}
+	}
+	faces, err := EquirectangularToCubeMap(img, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range faces {
+		if faces[i].Width != 4 || faces[i].Height != 4 {
+			t.Errorf("face %d: expected a size of 4 but got %dx%d", i, faces[i].Width, faces[i].Height)
+		}
+	}
+	up := faces[CubeMapSidePositiveY].Mem
+	if up[0] != 255 || up[2] != 0 {
+		t.Errorf("expected the top face to be red but got %v", up[:4])
+	}
+	down := faces[CubeMapSideNegativeY].Mem
+	if down[0] != 0 || down[2] != 255 {
+		t.Errorf("expected the bottom face to be blue but got %v", down[:4])
+	}
+}
+
+func TestEquirectangularToCubeMapDefaultSize(t *testing.T) {
+	img := testCubeMapFace(8, [4]byte{255, 255, 255, 255})
+	img.Width = 16
+	img.Height = 4
+	faces, err := EquirectangularToCubeMap(img, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if faces[0].Width != 2 {
+		t.Errorf("expected the faces to be half the image height but got %d", faces[0].Width)
+	}
+}
+
+func TestCubeMapDefFromJson(t *testing.T) {
+	if _, err := CubeMapDefFromJson(`{"Equirectangular": "sky.png"}`); err != nil {
+		t.Errorf("expected an equirectangular cube map to be valid: %v", err)
+	}
+	faces := `{"Faces": ["px.png", "nx.png", "py.png", "ny.png", "pz.png", "nz.png"]}`
+	if def, err := CubeMapDefFromJson(faces); err != nil {
+		t.Errorf("expected a cube map with all faces to be valid: %v", err)
+	} else if def.Faces[CubeMapSideNegativeZ] != "nz.png" {
+		t.Errorf("expected the faces to be read in order")
+	}
+	if _, err := CubeMapDefFromJson(`{"Faces": ["px.png", "nx.png"]}`); err == nil {
+		t.Error("expected a cube map missing faces to be invalid")
+	}
+	if _, err := CubeMapDefFromJson(`{}`); err == nil {
+		t.Error("expected an empty cube map to be invalid")
+	}
+}
+
+func TestNewCubeMapTextureData(t *testing.T) {
+	var faces [CubeMapSides]TextureData
+	for i := range faces {
+		faces[i] = testCubeMapFace(2, [4]byte{byte(i), 0, 0, 255})
+	}
+	data, err := NewCubeMapTextureData(faces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !data.CubeMap {
+		t.Error("expected the data to be marked as a cube map")
+	}
+	faceLen := 2 * 2 * bytesInPixel
+	if len(data.Mem) != faceLen*CubeMapSides {
+		t.Fatalf("expected %d bytes but got %d", faceLen*CubeMapSides, len(data.Mem))
+	}
+	for i := range faces {
+		if data.Mem[i*faceLen] != byte(i) {
+			t.Errorf("expected face %d to be stored in order", i)
+		}
+	}
+	faces[3] = testCubeMapFace(4, [4]byte{})
+	if _, err := NewCubeMapTextureData(faces); err == nil {
+		t.Error("expected faces of different sizes to fail")
+	}
+}
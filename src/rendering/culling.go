@@ -0,0 +1,184 @@
+/******************************************************************************/
+/* culling.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "kaiju/collision"
+
+// CullingStats holds the number of instances that were found to be visible
+// or culled during a frustum culling pass
+type CullingStats struct {
+	Visible int
+	Culled  int
+}
+
+func (s CullingStats) Total() int { return s.Visible + s.Culled }
+
+func (s *CullingStats) add(other CullingStats) {
+	s.Visible += other.Visible
+	s.Culled += other.Culled
+}
+
+// InstanceBounds returns the world space bounds of the instance by
+// transforming the bounds of the mesh with the model matrix of the instance,
+// the second value is false when the mesh has no known bounds
+func InstanceBounds(mesh *Mesh, instance DrawInstance) (collision.AABB, bool) {
+	bounds, ok := mesh.Bounds()
+	if !ok {
+		return bounds, false
+	}
+	return bounds.Transform(instance.Model()), true
+}
+
+func (s *Shader) usesFrustumCulling() bool {
+	return s.definition != nil && s.definition.FrustumCulling
+}
+
+// instanceBits holds a bit for each instance of a group by the index of the
+// instance, indexes past the end are not set
+type instanceBits []uint64
+
+func (b instanceBits) has(index int) bool {
+	word := index / 64
+	return word < len(b) && b[word]&(1<<(index%64)) != 0
+}
+
+func (b *instanceBits) set(index int, value bool) {
+	word := index / 64
+	if word >= len(*b) {
+		if !value {
+			return
+		}
+		*b = append(*b, make([]uint64, word-len(*b)+1)...)
+	}
+	if value {
+		(*b)[word] |= 1 << (index % 64)
+	} else {
+		(*b)[word] &^= 1 << (index % 64)
+	}
+}
+
+// Cull marks every active instance of the group that is outside of the
+// frustum as culled so that it is skipped when the instance data is uploaded.
+// The result belongs to the group, so the same instance can be culled for
+// one camera and visible to another.
+func (d *DrawInstanceGroup) Cull(frustum collision.Frustum) CullingStats {
+	stats := CullingStats{}
+	bounds, ok := d.Mesh.Bounds()
+	for i, instance := range d.Instances {
+		if instance.IsDestroyed() || !instance.IsActive() {
+			d.culled.set(i, false)
+			continue
+		}
+		instance.UpdateModel()
+		visible := true
+		if ok {
+			box := bounds.Transform(instance.Model())
+			visible = box.InFrustum(frustum)
+		}
+		d.culled.set(i, !visible)
+		if visible {
+			stats.Visible++
+		} else {
+			stats.Culled++
+		}
+	}
+	return stats
+}
+
+// IsCulled returns if the instance at the index was outside of the frustum
+// in the last culling pass of the group
+func (d *DrawInstanceGroup) IsCulled(index int) bool { return d.culled.has(index) }
+
+// ClearCulling marks all of the instances in the group as not culled
+func (d *DrawInstanceGroup) ClearCulling() {
+	d.culled = d.culled[:0]
+}
+
+// Cull runs the frustum culling pass on all of the groups, groups of shaders
+// that do not use frustum culling are skipped
+func (s *ShaderDraw) Cull(frustum collision.Frustum) CullingStats {
+	stats := CullingStats{}
+	if !s.shader.usesFrustumCulling() {
+		return stats
+	}
+	for i := range s.instanceGroups {
+		stats.add(s.instanceGroups[i].Cull(frustum))
+	}
+	return stats
+}
+
+func (s *ShaderDraw) clearCulling() {
+	for i := range s.instanceGroups {
+		s.instanceGroups[i].ClearCulling()
+	}
+}
+
+// Cull runs the frustum culling pass on all of the drawings, this should be
+// done before the drawings are rendered so that culled instances are not
+// uploaded to the GPU
+func (d *Drawings) Cull(frustum collision.Frustum) CullingStats {
+	stats := CullingStats{}
+	if !d.cullingDisabled {
+		for i := range d.draws {
+			for j := range d.draws[i].innerDraws {
+				stats.add(d.draws[i].innerDraws[j].Cull(frustum))
+			}
+		}
+	}
+	d.cullingStats = stats
+	return stats
+}
+
+// CullingStats returns the results of the last culling pass
+func (d *Drawings) CullingStats() CullingStats { return d.cullingStats }
+
+// FrustumCulling returns if the culling pass is enabled
+func (d *Drawings) FrustumCulling() bool { return !d.cullingDisabled }
+
+// SetFrustumCulling enables or disables the culling pass, when disabled all
+// of the previously culled instances are made visible again
+func (d *Drawings) SetFrustumCulling(enabled bool) {
+	d.cullingDisabled = !enabled
+	if !enabled {
+		for i := range d.draws {
+			for j := range d.draws[i].innerDraws {
+				d.draws[i].innerDraws[j].clearCulling()
+			}
+		}
+	}
+}
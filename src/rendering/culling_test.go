@@ -0,0 +1,156 @@
+/******************************************************************************/
+/* culling_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/matrix"
+	"slices"
+	"testing"
+)
+
+func testCullingInstance(position matrix.Vec3) *ShaderDataBasic {
+	sd := &ShaderDataBasic{ShaderDataBase: NewShaderDataBase()}
+	m := matrix.Mat4Identity()
+	m.Translate(position)
+	sd.SetModel(m)
+	return sd
+}
+
+func testCullingDrawings(shader *Shader, instances ...DrawInstance) *Drawings {
+	mesh := NewMesh("cube", meshCube(matrix.ColorWhite()), nil)
+	group := NewDrawInstanceGroup(mesh, ShaderDataBasic{}.Size())
+	group.Instances = append(group.Instances, instances...)
+	draw := NewShaderDraw(shader)
+	draw.AddInstanceGroup(group)
+	d := &Drawings{draws: []RenderTargetDraw{{innerDraws: []ShaderDraw{draw}}}}
+	return d
+}
+
+func testIsCulled(d *Drawings, instance DrawInstance) bool {
+	group := &d.draws[0].innerDraws[0].instanceGroups[0]
+	return group.IsCulled(slices.Index(group.Instances, instance))
+}
+
+func TestCullingSkipsInstancesOutsideFrustum(t *testing.T) {
+	camera := cameras.NewStandardCamera(800, 600, matrix.Vec3{0, 0, 5})
+	camera.SetLookAt(matrix.Vec3Zero())
+	visible := testCullingInstance(matrix.Vec3Zero())
+	behind := testCullingInstance(matrix.Vec3{0, 0, 20})
+	side := testCullingInstance(matrix.Vec3{100, 0, 0})
+	edge := testCullingInstance(matrix.Vec3{3.5, 0, 0})
+	hidden := testCullingInstance(matrix.Vec3{0, 0, 20})
+	hidden.Deactivate()
+	shader := &Shader{definition: &ShaderDef{FrustumCulling: true}}
+	d := testCullingDrawings(shader, visible, behind, side, edge, hidden)
+	stats := d.Cull(camera.Frustum())
+	if stats.Visible != 2 || stats.Culled != 2 {
+		t.Errorf("expected 2 visible and 2 culled, got %+v", stats)
+	}
+	if testIsCulled(d, visible) || testIsCulled(d, edge) {
+		t.Errorf("expected instances in view to not be culled")
+	}
+	if !testIsCulled(d, behind) || !testIsCulled(d, side) {
+		t.Errorf("expected instances out of view to be culled")
+	}
+	if d.CullingStats() != stats {
+		t.Errorf("expected the stats of the last pass to be stored")
+	}
+	camera.SetPosition(matrix.Vec3{0, 0, 30})
+	stats = d.Cull(camera.Frustum())
+	if stats.Visible != 3 || testIsCulled(d, behind) {
+		t.Errorf("expected culling to update when the camera moves, got %+v", stats)
+	}
+	d.SetFrustumCulling(false)
+	if testIsCulled(d, side) {
+		t.Errorf("expected disabling culling to clear culled instances")
+	}
+	if stats = d.Cull(camera.Frustum()); stats.Total() != 0 {
+		t.Errorf("expected no instances to be tested when culling is disabled")
+	}
+}
+
+func TestCullingRequiresShaderOptIn(t *testing.T) {
+	camera := cameras.NewStandardCamera(800, 600, matrix.Vec3{0, 0, 5})
+	camera.SetLookAt(matrix.Vec3Zero())
+	side := testCullingInstance(matrix.Vec3{100, 0, 0})
+	d := testCullingDrawings(&Shader{definition: &ShaderDef{}}, side)
+	if stats := d.Cull(camera.Frustum()); stats.Total() != 0 || testIsCulled(d, side) {
+		t.Errorf("expected shaders without frustum culling to be skipped")
+	}
+}
+
+func TestCullingIsKeptPerDrawings(t *testing.T) {
+	near := cameras.NewStandardCamera(800, 600, matrix.Vec3{0, 0, 5})
+	near.SetLookAt(matrix.Vec3Zero())
+	far := cameras.NewStandardCamera(800, 600, matrix.Vec3{100, 0, 5})
+	far.SetLookAt(matrix.Vec3{100, 0, 0})
+	shared := testCullingInstance(matrix.Vec3Zero())
+	shader := &Shader{definition: &ShaderDef{FrustumCulling: true}}
+	a := testCullingDrawings(shader, shared)
+	b := testCullingDrawings(shader, shared)
+	a.Cull(near.Frustum())
+	b.Cull(far.Frustum())
+	if testIsCulled(a, shared) || !testIsCulled(b, shared) {
+		t.Errorf("expected each drawings to keep its own culling of the instance")
+	}
+	b.SetFrustumCulling(false)
+	a.Cull(far.Frustum())
+	if !testIsCulled(a, shared) || testIsCulled(b, shared) {
+		t.Errorf("expected disabling culling on one drawings to leave the other alone")
+	}
+}
+
+func TestInstanceBoundsFollowModel(t *testing.T) {
+	mesh := NewMesh("quad", []Vertex{
+		{Position: matrix.Vec3{-1, -1, 0}},
+		{Position: matrix.Vec3{1, 1, 0}},
+	}, nil)
+	inst := testCullingInstance(matrix.Vec3{5, 0, 0})
+	box, ok := InstanceBounds(mesh, inst)
+	if !ok {
+		t.Fatal("expected the mesh to have bounds")
+	}
+	if !matrix.Vec3ApproxTo(box.Min(), matrix.Vec3{4, -1, 0}, 0.0001) ||
+		!matrix.Vec3ApproxTo(box.Max(), matrix.Vec3{6, 1, 0}, 0.0001) {
+		t.Errorf("unexpected bounds %v - %v", box.Min(), box.Max())
+	}
+	if _, ok := InstanceBounds(NewMesh("empty", nil, nil), inst); ok {
+		t.Errorf("expected a mesh without vertices to have no bounds")
+	}
+}
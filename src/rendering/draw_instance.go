@@ -55,6 +55,7 @@ type DrawInstance interface {
 	Deactivate()
 	IsActive() bool
 	Size() int
+	Model() matrix.Mat4
 	SetModel(model matrix.Mat4)
 	UpdateModel()
 	DataPointer() unsafe.Pointer
@@ -151,6 +152,7 @@ type DrawInstanceGroup struct {
 	InstanceDriverData
 	Textures          []*Texture
 	Instances         []DrawInstance
+	culled            instanceBits
 	rawData           InstanceCopyData
 	namedInstanceData map[string]InstanceCopyData
 	instanceSize      int
@@ -254,9 +256,10 @@ func (d *DrawInstanceGroup) UpdateData(renderer Renderer) {
 		instance.UpdateModel()
 		if instance.IsDestroyed() {
 			d.Instances[i] = d.Instances[count-1]
+			d.culled.set(i, d.culled.has(count-1))
 			i--
 			count--
-		} else if instance.IsActive() {
+		} else if instance.IsActive() && !d.culled.has(i) {
 			if d.generatedSets {
 				for k := range d.namedInstanceData {
 					d.updateNamedData(instanceIndex, instance, k)
@@ -271,6 +274,9 @@ func (d *DrawInstanceGroup) UpdateData(renderer Renderer) {
 	}
 	if count < len(d.Instances) {
 		newMemLen := count * (d.instanceSize + d.rawData.padding)
+		for i := count; i < len(d.Instances); i++ {
+			d.culled.set(i, false)
+		}
 		d.Instances = d.Instances[:count]
 		d.rawData.bytes = d.rawData.bytes[:newMemLen]
 	}
@@ -281,6 +287,16 @@ func (d *DrawInstanceGroup) UpdateData(renderer Renderer) {
 	}
 }
 
+// release frees the group without destroying its instances
+func (d *DrawInstanceGroup) release(renderer Renderer) {
+	if d.destroyed {
+		return
+	}
+	d.Instances = d.Instances[:0]
+	renderer.DestroyGroup(d)
+	d.destroyed = true
+}
+
 func (d *DrawInstanceGroup) Destroy(renderer Renderer) {
 	if d.destroyed {
 		return
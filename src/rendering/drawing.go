@@ -45,14 +45,21 @@ import (
 )
 
 type Drawing struct {
-	Renderer     Renderer
-	Shader       *Shader
-	Mesh         *Mesh
-	Textures     []*Texture
-	ShaderData   DrawInstance
-	Transform    *matrix.Transform
-	CanvasId     string
-	UseBlending  bool
+	Renderer    Renderer
+	Shader      *Shader
+	Material    *Material
+	Mesh        *Mesh
+	Textures    []*Texture
+	ShaderData  DrawInstance
+	Transform   *matrix.Transform
+	CanvasId    string
+	UseBlending bool
+	// LOD optionally draws the instance with the level of the chain that
+	// suits its size on screen, Mesh is not used when this is set
+	LOD *MeshLODChain
+	// Layers are the layers that the drawing is on for the render cameras,
+	// drawings without any layers are on #RenderLayerDefault
+	Layers       RenderLayers
 	renderTarget Canvas
 }
 
@@ -60,6 +67,13 @@ func (d *Drawing) IsValid() bool {
 	return d.Shader != nil && d.renderTarget != nil
 }
 
+func (d *Drawing) layers() RenderLayers {
+	if d.Layers == RenderLayersNone {
+		return RenderLayerDefault
+	}
+	return d.Layers
+}
+
 type RenderTargetDraw struct {
 	innerDraws []ShaderDraw
 	Target     Canvas
@@ -75,9 +89,15 @@ func (t *RenderTargetDraw) findShaderDraw(shader *Shader) (*ShaderDraw, bool) {
 }
 
 type Drawings struct {
-	draws     []RenderTargetDraw
-	backDraws []Drawing
-	mutex     sync.RWMutex
+	draws           []RenderTargetDraw
+	backDraws       []Drawing
+	cullingStats    CullingStats
+	cullingDisabled bool
+	lods            []*lodInstanceSet
+	cameras         []*RenderCamera
+	sources         map[DrawInstance]Drawing
+	sourcesPruned   int
+	mutex           sync.RWMutex
 }
 
 func NewDrawings() Drawings {
@@ -123,54 +143,81 @@ func (d *Drawings) matchGroup(sd *ShaderDraw, dg *Drawing) int {
 }
 
 func (d *Drawings) PreparePending() {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	for i := range d.backDraws {
 		drawing := &d.backDraws[i]
-		rtDraw, ok := d.findRenderTargetDraw(drawing.renderTarget)
-		if !ok {
-			newDraw := RenderTargetDraw{
-				innerDraws: make([]ShaderDraw, 0),
-				Target:     drawing.renderTarget,
+		d.mirror(drawing)
+		if drawing.LOD != nil && len(drawing.LOD.Levels) > 0 {
+			levels := d.lodDrawings(drawing)
+			for j := range levels {
+				d.prepareDrawing(&levels[j])
 			}
-			d.draws = append(d.draws, newDraw)
-			rtDraw = &d.draws[len(d.draws)-1]
+		} else {
+			d.prepareDrawing(drawing)
 		}
-		draw, ok := rtDraw.findShaderDraw(drawing.Shader)
-		if !ok {
-			newDraw := NewShaderDraw(drawing.Shader)
-			rtDraw.innerDraws = append(rtDraw.innerDraws, newDraw)
-			draw = &rtDraw.innerDraws[len(rtDraw.innerDraws)-1]
+	}
+	d.backDraws = d.backDraws[:0]
+	for _, c := range d.cameras {
+		c.drawings.PreparePending()
+	}
+}
+
+func (d *Drawings) prepareDrawing(drawing *Drawing) {
+	rtDraw, ok := d.findRenderTargetDraw(drawing.renderTarget)
+	if !ok {
+		newDraw := RenderTargetDraw{
+			innerDraws: make([]ShaderDraw, 0),
+			Target:     drawing.renderTarget,
 		}
-		drawing.ShaderData.setTransform(drawing.Transform)
-		idx := d.matchGroup(draw, drawing)
-		if idx >= 0 && !draw.instanceGroups[idx].destroyed {
-			draw.instanceGroups[idx].AddInstance(drawing.ShaderData, drawing.Renderer, drawing.Shader)
+		d.draws = append(d.draws, newDraw)
+		rtDraw = &d.draws[len(d.draws)-1]
+	}
+	draw, ok := rtDraw.findShaderDraw(drawing.Shader)
+	if !ok {
+		newDraw := NewShaderDraw(drawing.Shader)
+		rtDraw.innerDraws = append(rtDraw.innerDraws, newDraw)
+		draw = &rtDraw.innerDraws[len(rtDraw.innerDraws)-1]
+	}
+	drawing.ShaderData.setTransform(drawing.Transform)
+	idx := d.matchGroup(draw, drawing)
+	if idx >= 0 && !draw.instanceGroups[idx].destroyed {
+		draw.instanceGroups[idx].AddInstance(drawing.ShaderData, drawing.Renderer, drawing.Shader)
+	} else {
+		group := NewDrawInstanceGroup(drawing.Mesh, drawing.ShaderData.Size())
+		group.AddInstance(drawing.ShaderData, drawing.Renderer, drawing.Shader)
+		group.Textures = drawing.Textures
+		group.useBlending = drawing.UseBlending
+		if idx >= 0 {
+			draw.instanceGroups[idx] = group
 		} else {
-			group := NewDrawInstanceGroup(drawing.Mesh, drawing.ShaderData.Size())
-			group.AddInstance(drawing.ShaderData, drawing.Renderer, drawing.Shader)
-			group.Textures = drawing.Textures
-			group.useBlending = drawing.UseBlending
-			if idx >= 0 {
-				draw.instanceGroups[idx] = group
-			} else {
-				draw.AddInstanceGroup(group)
-			}
+			draw.AddInstanceGroup(group)
 		}
 	}
-	d.backDraws = d.backDraws[:0]
 }
 
+// AddDrawing adds the drawing to be prepared with the pending drawings. When
+// the shader of the drawing has a #ShaderDef.ShadowCaster, a caster for the
+// drawing is added to the shadow canvas as well.
 func (d *Drawings) AddDrawing(drawing *Drawing) {
+	findRenderTarget(drawing)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.backDraws = append(d.backDraws, *drawing)
+	if drawing.Shader != nil && drawing.Shader.shadowCaster != nil {
+		caster := drawing.ShadowCaster(drawing.Shader.shadowCaster)
+		findRenderTarget(&caster)
+		d.backDraws = append(d.backDraws, caster)
+	}
+}
+
+func findRenderTarget(drawing *Drawing) {
 	if t, ok := drawing.Renderer.Canvas(drawing.CanvasId); ok {
 		drawing.renderTarget = t
 	} else {
 		slog.Error("Could not find render target, using default",
 			slog.String("id", drawing.CanvasId))
 	}
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.backDraws = append(d.backDraws, *drawing)
 }
 
 func (d *Drawings) AddDrawings(drawings []Drawing, target Canvas) {
@@ -191,6 +238,12 @@ func (d *Drawings) Render(renderer Renderer) {
 }
 
 func (d *Drawings) Destroy(renderer Renderer) {
+	for _, c := range d.cameras {
+		c.drawings.release(renderer)
+		c.parent = nil
+	}
+	d.cameras = d.cameras[:0]
+	clear(d.sources)
 	for i := range d.draws {
 		for j := range d.draws[i].innerDraws {
 			d.draws[i].innerDraws[j].Destroy(renderer)
@@ -198,3 +251,19 @@ func (d *Drawings) Destroy(renderer Renderer) {
 	}
 	d.draws = d.draws[:0]
 }
+
+// release frees the instance groups of the drawings without destroying the
+// instances, used for drawings that share their instances with other drawings
+func (d *Drawings) release(renderer Renderer) {
+	for i := range d.draws {
+		for j := range d.draws[i].innerDraws {
+			sd := &d.draws[i].innerDraws[j]
+			for k := range sd.instanceGroups {
+				sd.instanceGroups[k].release(renderer)
+			}
+		}
+	}
+	d.draws = d.draws[:0]
+	d.backDraws = d.backDraws[:0]
+	d.lods = d.lods[:0]
+}
@@ -40,6 +40,7 @@ package rendering
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"kaiju/assets"
 	"kaiju/klib"
@@ -198,6 +199,47 @@ func (cache *FontCache) requireFace(face FontFace) {
 	}
 }
 
+type fontFaceData struct {
+	texture Texture
+	bin     []byte
+}
+
+// PreloadFaceAsync reads and decodes the files of the font face on a worker
+// goroutine so that the first text drawn with the face does not have to load
+// it on the main thread. The letter meshes are created on the main thread.
+func (cache *FontCache) PreloadFaceAsync(face FontFace) *assets.Handle[FontFace] {
+	key := face.string()
+	cache.FaceMutex.RLock()
+	_, ok := cache.fontFaces[key]
+	cache.FaceMutex.RUnlock()
+	if ok {
+		return assets.Ready(key, face, nil)
+	}
+	decoded := assets.Load(cache.assetDb, key+".bin", func(data []byte) (fontFaceData, error) {
+		imgBuff, err := cache.assetDb.Read(key + ".png")
+		if err != nil {
+			return fontFaceData{}, err
+		}
+		tex, err := newTextureFromFile(cache.renderer, key+".png", key+".png", imgBuff, TextureFilterLinear)
+		if err != nil {
+			return fontFaceData{}, err
+		}
+		return fontFaceData{texture: *tex, bin: data}, nil
+	})
+	return assets.Then(cache.assetDb, decoded, func(data fontFaceData) (FontFace, error) {
+		cache.FaceMutex.Lock()
+		defer cache.FaceMutex.Unlock()
+		if _, ok := cache.fontFaces[key]; ok {
+			return face, nil
+		}
+		texture := cache.renderCaches.TextureCache().addDecoded(&data.texture)
+		if !cache.initFontFromData(face, texture, data.bin, cache.renderer) {
+			return face, errors.New("failed to read the font face " + key)
+		}
+		return face, nil
+	})
+}
+
 func (cache *FontCache) EMSize(face FontFace) float32 {
 	cache.requireFace(face)
 	return cache.fontFaces[face.string()].metrics.EMSize * DefaultFontEMSize
@@ -324,15 +366,20 @@ func (cache *FontCache) createLetterMesh(font fontBin, key rune, c fontBinChar,
 }
 
 func (cache *FontCache) initFont(face FontFace, renderer Renderer, assetDb *assets.Database) bool {
+	texture, _ := cache.renderCaches.TextureCache().Texture(face.string()+".png", TextureFilterLinear)
+	out, _ := assetDb.Read(face.string() + ".bin")
+	return cache.initFontFromData(face, texture, out, renderer)
+}
+
+func (cache *FontCache) initFontFromData(face FontFace, texture *Texture, out []byte, renderer Renderer) bool {
+	if texture == nil || len(out) == 0 {
+		return false
+	}
 	bin := fontBin{}
-	bin.texture, _ = cache.renderCaches.TextureCache().Texture(face.string()+".png", TextureFilterLinear)
+	bin.texture = texture
 	bin.texture.MipLevels = 1
 	bin.cachedLetters = make(map[rune]*cachedLetterMesh)
 	bin.cachedOrthoLetters = make(map[rune]*cachedLetterMesh)
-	out, _ := assetDb.Read(face.string() + ".bin")
-	if bin.texture == nil || out == nil || len(out) == 0 {
-		return false
-	}
 	read := bytes.NewReader(out)
 	// Create an int32 variable named count that is read from read
 	var count int32
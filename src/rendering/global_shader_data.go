@@ -37,7 +37,10 @@
 
 package rendering
 
-import "kaiju/matrix"
+import (
+	"kaiju/matrix"
+	"unsafe"
+)
 
 const (
 	MaxJoints        = 50
@@ -55,6 +58,49 @@ type GlobalShaderData struct {
 	_                matrix.Float
 	ScreenSize       matrix.Vec2
 	Time             float32
+	LightCount       int32
+	AmbientColor     matrix.Vec3
+	_                matrix.Float
+	Lights           [MaxLights]GPULight
+	// ShadowViewProjections are the light matrices of the shadow atlas tiles
+	ShadowViewProjections [MaxShadowMaps]matrix.Mat4
+	// ShadowSplits holds the view distance where each cascade ends
+	ShadowSplits matrix.Vec4
+	// ShadowParams holds the bias, normal bias, texel size of a single
+	// shadow map, and the number of cascades
+	ShadowParams matrix.Vec4
+	// EnvironmentIntensity is how much of the environment cube map is mixed
+	// into the color of shaders that sample it, 0 without an environment
+	EnvironmentIntensity matrix.Float
+}
+
+// ShaderDataLit is the instance data for the lit shader, the light indexes
+// are selected each frame by #LightList.Select
+type ShaderDataLit struct {
+	ShaderDataBase
+	Color        matrix.Color
+	Metallic     matrix.Float
+	Roughness    matrix.Float
+	LightCount   int32
+	LightIndexes [MaxLightsPerObject]int32
+}
+
+func NewShaderDataLit() *ShaderDataLit {
+	return &ShaderDataLit{
+		ShaderDataBase: NewShaderDataBase(),
+		Color:          matrix.ColorWhite(),
+		Metallic:       0,
+		Roughness:      0.5,
+	}
+}
+
+func (t ShaderDataLit) Size() int {
+	return int(unsafe.Sizeof(ShaderDataLit{}) - ShaderBaseDataStart)
+}
+
+func (t *ShaderDataLit) SetLights(indexes [MaxLightsPerObject]int32, count int32) {
+	t.LightIndexes = indexes
+	t.LightCount = count
 }
 
 type SkinnedShaderData struct {
@@ -0,0 +1,323 @@
+/******************************************************************************/
+/* light.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/collision"
+	"kaiju/matrix"
+	"slices"
+)
+
+const (
+	// MaxLights is the number of lights that are uploaded to the shaders each
+	// frame, this must match MAX_LIGHTS in inc_lighting.inl
+	MaxLights = 16
+	// MaxLightsPerObject is the number of lights a single instance is lit by,
+	// this must match the size of the lightIndices instance field
+	MaxLightsPerObject = 4
+)
+
+type LightType int32
+
+const (
+	LightTypeDirectional LightType = iota
+	LightTypePoint
+	LightTypeSpot
+)
+
+// Light is the CPU side description of a light in the scene. When the light
+// has a transform, the position and direction are read from it each frame.
+// The direction of a light points down the forward (-Z) axis of the
+// transform.
+type Light struct {
+	Type       LightType
+	Position   matrix.Vec3
+	Direction  matrix.Vec3
+	Color      matrix.Color
+	Intensity  matrix.Float
+	Range      matrix.Float
+	InnerAngle matrix.Float
+	OuterAngle matrix.Float
+	Transform  *matrix.Transform
+	Enabled    bool
+	// CastShadows requests a shadow map for the light, only directional and
+	// spot lights support shadows
+	CastShadows bool
+}
+
+// GPULight is the layout of a light inside of the global uniform buffer, it
+// follows the std140 rules so each member pair fills a vec4
+type GPULight struct {
+	Position  matrix.Vec3
+	Type      LightType
+	Direction matrix.Vec3
+	Range     matrix.Float
+	Color     matrix.Vec3
+	Intensity matrix.Float
+	CosInner  matrix.Float
+	CosOuter  matrix.Float
+	// ShadowIndex is the first shadow map of the light in the shadow atlas,
+	// -1 when the light has no shadow map
+	ShadowIndex int32
+	_           matrix.Float
+}
+
+// NewLight creates an enabled white light of the given type
+func NewLight(lightType LightType) *Light {
+	return &Light{
+		Type:       lightType,
+		Direction:  matrix.Vec3Forward(),
+		Color:      matrix.ColorWhite(),
+		Intensity:  1,
+		Range:      10,
+		InnerAngle: 25,
+		OuterAngle: 35,
+		Enabled:    true,
+	}
+}
+
+func (l *Light) update() {
+	if l.Transform == nil {
+		return
+	}
+	m := l.Transform.WorldMatrix()
+	l.Position = m.Position()
+	dir := m.TransformPoint(matrix.Vec3Forward()).Subtract(l.Position)
+	if dir.Length() > 0 {
+		l.Direction = dir.Normal()
+	}
+}
+
+func (l *Light) gpu() GPULight {
+	outer := max(l.OuterAngle, l.InnerAngle)
+	return GPULight{
+		Position:  l.Position,
+		Type:      l.Type,
+		Direction: l.Direction,
+		Range:     l.Range,
+		Color:     matrix.Vec3{l.Color.R(), l.Color.G(), l.Color.B()},
+		Intensity: l.Intensity,
+		CosInner:  matrix.Cos(matrix.Deg2Rad(l.InnerAngle)),
+		CosOuter:  matrix.Cos(matrix.Deg2Rad(outer)),
+		// Assigned by #ShadowMaps.Update
+		ShadowIndex: -1,
+	}
+}
+
+// Influence estimates how much the light contributes to anything inside of
+// the bounds, a value of 0 means that the bounds are out of reach of the
+// light. Directional lights reach everything equally.
+func (l *Light) Influence(bounds collision.AABB) matrix.Float {
+	if !l.Enabled || l.Intensity <= 0 {
+		return 0
+	}
+	if l.Type == LightTypeDirectional {
+		return l.Intensity
+	}
+	closest := matrix.Vec3Min(matrix.Vec3Max(l.Position, bounds.Min()), bounds.Max())
+	dist := closest.Subtract(l.Position).Length()
+	if dist >= l.Range {
+		return 0
+	}
+	if l.Type == LightTypeSpot && dist > 0 {
+		toCenter := bounds.Center.Subtract(l.Position)
+		centerDist := toCenter.Length()
+		if centerDist > 0 {
+			angle := matrix.Rad2Deg(matrix.Acos(matrix.Clamp(
+				matrix.Vec3Dot(toCenter.Scale(1/centerDist), l.Direction), -1, 1)))
+			slack := matrix.Rad2Deg(matrix.Atan2(bounds.Extent.Length(), centerDist))
+			if angle > max(l.OuterAngle, l.InnerAngle)+slack {
+				return 0
+			}
+		}
+	}
+	falloff := 1 - dist/l.Range
+	return l.Intensity * falloff * falloff
+}
+
+// LightList holds all of the lights in the scene and selects which of them
+// are uploaded to the shaders and which light each object
+type LightList struct {
+	Ambient matrix.Color
+	lights  []*Light
+	active  []*Light
+	gpu     [MaxLights]GPULight
+}
+
+func NewLightList() LightList {
+	return LightList{
+		Ambient: matrix.Color{0.1, 0.1, 0.1, 1},
+		lights:  make([]*Light, 0),
+		active:  make([]*Light, 0, MaxLights),
+	}
+}
+
+func (l *LightList) Add(light *Light) {
+	if !slices.Contains(l.lights, light) {
+		l.lights = append(l.lights, light)
+	}
+}
+
+func (l *LightList) Remove(light *Light) {
+	if idx := slices.Index(l.lights, light); idx >= 0 {
+		l.lights = slices.Delete(l.lights, idx, idx+1)
+	}
+}
+
+// Lights returns all of the lights that have been added to the list
+func (l *LightList) Lights() []*Light { return l.lights }
+
+// Active returns the lights that were selected by the last call to Prepare,
+// the index of a light in this slice is its index in the shader light list
+func (l *LightList) Active() []*Light { return l.active }
+
+// Prepare updates the lights from their transforms and selects up to
+// MaxLights lights to upload to the shaders. Directional lights are always
+// preferred, the remaining lights are ordered by their intensity and how
+// close the viewer is to being within their range.
+func (l *LightList) Prepare(viewPosition matrix.Vec3) {
+	l.active = l.active[:0]
+	type scored struct {
+		light *Light
+		score matrix.Float
+	}
+	candidates := make([]scored, 0, len(l.lights))
+	for _, light := range l.lights {
+		if !light.Enabled || light.Intensity <= 0 {
+			continue
+		}
+		light.update()
+		// Directional lights always win, other lights lose priority the
+		// further the viewer is outside of their range
+		score := light.Intensity + 1e9
+		if light.Type != LightTypeDirectional {
+			dist := light.Position.Subtract(viewPosition).Length()
+			score = light.Intensity / (1 + max(0, dist-light.Range))
+		}
+		candidates = append(candidates, scored{light, score})
+	}
+	slices.SortStableFunc(candidates, func(a, b scored) int {
+		if a.score > b.score {
+			return -1
+		} else if a.score < b.score {
+			return 1
+		}
+		return 0
+	})
+	for i := 0; i < len(candidates) && i < MaxLights; i++ {
+		l.active = append(l.active, candidates[i].light)
+		l.gpu[i] = candidates[i].light.gpu()
+	}
+}
+
+// Select writes the indexes of the active lights with the most influence on
+// the bounds into indexes, the number of lights written is returned
+func (l *LightList) Select(bounds collision.AABB, indexes *[MaxLightsPerObject]int32) int32 {
+	var scores [MaxLightsPerObject]matrix.Float
+	count := int32(0)
+	for i, light := range l.active {
+		s := light.Influence(bounds)
+		if s <= 0 {
+			continue
+		}
+		slot := count
+		if count == MaxLightsPerObject {
+			slot = MaxLightsPerObject - 1
+			if s <= scores[slot] {
+				continue
+			}
+		} else {
+			count++
+		}
+		for slot > 0 && scores[slot-1] < s {
+			scores[slot] = scores[slot-1]
+			indexes[slot] = indexes[slot-1]
+			slot--
+		}
+		scores[slot] = s
+		indexes[slot] = int32(i)
+	}
+	for i := count; i < MaxLightsPerObject; i++ {
+		indexes[i] = -1
+	}
+	return count
+}
+
+func (l *LightList) shaderData(data *GlobalShaderData) {
+	data.LightCount = int32(len(l.active))
+	data.AmbientColor = matrix.Vec3{l.Ambient.R(), l.Ambient.G(), l.Ambient.B()}
+	data.Lights = l.gpu
+}
+
+// LightReceiver is implemented by the instance data of lit shaders so that
+// the lights that affect the instance can be assigned to it
+type LightReceiver interface {
+	SetLights(indexes [MaxLightsPerObject]int32, count int32)
+}
+
+// SelectLights assigns the most influential lights to every visible
+// instance that is a #LightReceiver, this should be called after the lights
+// have been prepared and the drawings have been culled
+func (d *Drawings) SelectLights(lights *LightList) {
+	for i := range d.draws {
+		for j := range d.draws[i].innerDraws {
+			sd := &d.draws[i].innerDraws[j]
+			for k := range sd.instanceGroups {
+				sd.instanceGroups[k].selectLights(lights)
+			}
+		}
+	}
+}
+
+func (d *DrawInstanceGroup) selectLights(lights *LightList) {
+	bounds, ok := d.Mesh.Bounds()
+	var indexes [MaxLightsPerObject]int32
+	for i, instance := range d.Instances {
+		receiver, isReceiver := instance.(LightReceiver)
+		if !isReceiver || instance.IsDestroyed() || !instance.IsActive() || d.culled.has(i) {
+			continue
+		}
+		var count int32
+		if ok {
+			count = lights.Select(bounds.Transform(instance.Model()), &indexes)
+		} else {
+			count = lights.Select(collision.AABB{Center: instance.Model().Position()}, &indexes)
+		}
+		receiver.SetLights(indexes, count)
+	}
+}
@@ -0,0 +1,173 @@
+/******************************************************************************/
+/* light_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/collision"
+	"kaiju/matrix"
+	"testing"
+	"unsafe"
+)
+
+func testPointLight(position matrix.Vec3, intensity, lightRange matrix.Float) *Light {
+	l := NewLight(LightTypePoint)
+	l.Position = position
+	l.Intensity = intensity
+	l.Range = lightRange
+	return l
+}
+
+func TestLightShaderLayout(t *testing.T) {
+	if s := unsafe.Sizeof(GPULight{}); s != 64 {
+		t.Errorf("expected a GPU light to be 64 bytes, got %d", s)
+	}
+	if o := unsafe.Offsetof(GlobalShaderData{}.Lights); o%16 != 0 {
+		t.Errorf("expected the lights to be 16 byte aligned, got offset %d", o)
+	}
+	if o := unsafe.Offsetof(GlobalShaderData{}.AmbientColor); o%16 != 0 {
+		t.Errorf("expected the ambient color to be 16 byte aligned, got offset %d", o)
+	}
+}
+
+func TestLightListSelectsStrongestLights(t *testing.T) {
+	list := NewLightList()
+	far := testPointLight(matrix.Vec3{8, 0, 0}, 1, 10)
+	out := testPointLight(matrix.Vec3{50, 0, 0}, 1, 10)
+	near := testPointLight(matrix.Vec3{1, 0, 0}, 1, 10)
+	off := testPointLight(matrix.Vec3{0, 1, 0}, 1, 10)
+	off.Enabled = false
+	for _, l := range []*Light{far, out, near, off} {
+		list.Add(l)
+	}
+	list.Prepare(matrix.Vec3Zero())
+	var indexes [MaxLightsPerObject]int32
+	count := list.Select(collision.AABB{}, &indexes)
+	if count != 2 {
+		t.Fatalf("expected 2 lights to reach the origin, got %d", count)
+	}
+	active := list.Active()
+	if active[indexes[0]] != near || active[indexes[1]] != far {
+		t.Errorf("expected the nearest light to be selected first")
+	}
+	if indexes[2] != -1 || indexes[3] != -1 {
+		t.Errorf("expected unused slots to be -1, got %v", indexes)
+	}
+}
+
+func TestLightListSelectCapsPerObject(t *testing.T) {
+	list := NewLightList()
+	for i := 0; i < MaxLightsPerObject+2; i++ {
+		list.Add(testPointLight(matrix.Vec3{matrix.Float(i), 0, 0}, 1, 20))
+	}
+	list.Prepare(matrix.Vec3Zero())
+	var indexes [MaxLightsPerObject]int32
+	if count := list.Select(collision.AABB{}, &indexes); count != MaxLightsPerObject {
+		t.Fatalf("expected %d lights, got %d", MaxLightsPerObject, count)
+	}
+	active := list.Active()
+	for i := 1; i < MaxLightsPerObject; i++ {
+		a := active[indexes[i-1]].Position.X()
+		b := active[indexes[i]].Position.X()
+		if a > b {
+			t.Errorf("expected lights ordered by influence, got %v", indexes)
+		}
+	}
+}
+
+func TestSpotLightCone(t *testing.T) {
+	spot := NewLight(LightTypeSpot)
+	spot.Direction = matrix.Vec3{1, 0, 0}
+	spot.Range = 20
+	front := collision.AABB{Center: matrix.Vec3{5, 0, 0}, Extent: matrix.Vec3{0.5, 0.5, 0.5}}
+	side := collision.AABB{Center: matrix.Vec3{0, 5, 0}, Extent: matrix.Vec3{0.5, 0.5, 0.5}}
+	tooFar := collision.AABB{Center: matrix.Vec3{30, 0, 0}, Extent: matrix.Vec3{0.5, 0.5, 0.5}}
+	if spot.Influence(front) <= 0 {
+		t.Errorf("expected the spot light to reach bounds inside of the cone")
+	}
+	if spot.Influence(side) != 0 {
+		t.Errorf("expected the spot light to not reach bounds outside of the cone")
+	}
+	if spot.Influence(tooFar) != 0 {
+		t.Errorf("expected the spot light to not reach bounds outside of its range")
+	}
+}
+
+func TestLightListPrepareCapsLights(t *testing.T) {
+	list := NewLightList()
+	for i := 0; i < MaxLights+4; i++ {
+		list.Add(testPointLight(matrix.Vec3{matrix.Float(i), 0, 0}, 1, 1))
+	}
+	sun := NewLight(LightTypeDirectional)
+	sun.Intensity = 0.1
+	list.Add(sun)
+	list.Prepare(matrix.Vec3{100, 0, 0})
+	active := list.Active()
+	if len(active) != MaxLights {
+		t.Fatalf("expected %d active lights, got %d", MaxLights, len(active))
+	}
+	if active[0] != sun {
+		t.Errorf("expected directional lights to always be active first")
+	}
+	var data GlobalShaderData
+	list.shaderData(&data)
+	if data.LightCount != MaxLights || data.Lights[0].Type != LightTypeDirectional {
+		t.Errorf("expected the lights to be written to the global shader data")
+	}
+}
+
+func TestDrawingsSelectLights(t *testing.T) {
+	list := NewLightList()
+	list.Add(testPointLight(matrix.Vec3{1, 0, 0}, 1, 5))
+	list.Prepare(matrix.Vec3Zero())
+	lit := NewShaderDataLit()
+	lit.SetModel(matrix.Mat4Identity())
+	unlit := NewShaderDataLit()
+	m := matrix.Mat4Identity()
+	m.Translate(matrix.Vec3{50, 0, 0})
+	unlit.SetModel(m)
+	d := testCullingDrawings(&Shader{definition: &ShaderDef{}}, lit, unlit)
+	d.SelectLights(&list)
+	if lit.LightCount != 1 || lit.LightIndexes[0] != 0 {
+		t.Errorf("expected the instance near the light to be lit, got %d %v",
+			lit.LightCount, lit.LightIndexes)
+	}
+	if unlit.LightCount != 0 || unlit.LightIndexes[0] != -1 {
+		t.Errorf("expected the far instance to not be lit, got %d %v",
+			unlit.LightCount, unlit.LightIndexes)
+	}
+}
@@ -0,0 +1,284 @@
+/******************************************************************************/
+/* animation.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collada
+
+import (
+	"errors"
+	"kaiju/matrix"
+	"kaiju/rendering/loaders/load_result"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// channel is the animated values of a single transform element of a node
+type channel struct {
+	sid string
+	// index is the value of the element that is animated, -1 when all of
+	// the values of the element are animated
+	index  int
+	times  []float64
+	values []float64
+	stride int
+}
+
+// sample linearly interpolates the values of the channel at the time
+func (c *channel) sample(time float64) []float64 {
+	out := make([]float64, c.stride)
+	if len(c.times) == 0 {
+		return out
+	}
+	at := func(key int) []float64 { return c.values[key*c.stride : (key+1)*c.stride] }
+	if time <= c.times[0] {
+		copy(out, at(0))
+		return out
+	}
+	last := len(c.times) - 1
+	if time >= c.times[last] {
+		copy(out, at(last))
+		return out
+	}
+	key, _ := slices.BinarySearch(c.times, time)
+	from, to := at(key-1), at(key)
+	t := (time - c.times[key-1]) / (c.times[key] - c.times[key-1])
+	for i := range out {
+		out[i] = from[i] + (to[i]-from[i])*t
+	}
+	return out
+}
+
+// member returns the index of the value that a channel target selects, the
+// target is either a member name like .X or .ANGLE, or array indexes like
+// (3) or (0)(3) for matrices
+func member(selector string) int {
+	if strings.HasPrefix(selector, ".") {
+		switch strings.ToUpper(selector[1:]) {
+		case "X", "R", "S", "U":
+			return 0
+		case "Y", "G", "T", "V":
+			return 1
+		case "Z", "B", "P":
+			return 2
+		case "W", "A", "Q", "ANGLE":
+			return 3
+		}
+		return -1
+	}
+	index := 0
+	for _, part := range strings.Split(strings.Trim(selector, "()"), ")(") {
+		i, err := strconv.Atoi(part)
+		if err != nil {
+			return -1
+		}
+		index = index*4 + i
+	}
+	return index
+}
+
+// collectChannels flattens the nested animations into the channels of each
+// node, keyed by the index of the node
+func (s *scene) collectChannels(anim *Animation, out map[int][]channel) error {
+	for i := range anim.Channels {
+		ch := &anim.Channels[i]
+		nodeId, target, ok := strings.Cut(ch.Target, "/")
+		if !ok {
+			continue
+		}
+		node, ok := s.byId[nodeId]
+		if !ok {
+			continue
+		}
+		c := channel{sid: target, index: -1}
+		if at := strings.IndexAny(target, ".("); at >= 0 {
+			c.sid = target[:at]
+			if c.index = member(target[at:]); c.index < 0 {
+				continue
+			}
+		}
+		var sampler *Sampler
+		for j := range anim.Samplers {
+			if anim.Samplers[j].ID == id(ch.Source) {
+				sampler = &anim.Samplers[j]
+			}
+		}
+		if sampler == nil {
+			return errors.New("collada animation channel has no sampler")
+		}
+		for _, input := range sampler.Inputs {
+			var src *Source
+			for j := range anim.Sources {
+				if anim.Sources[j].ID == id(input.Source) {
+					src = &anim.Sources[j]
+				}
+			}
+			if src == nil {
+				continue
+			}
+			switch input.Semantic {
+			case "INPUT":
+				c.times = src.Floats
+			case "OUTPUT":
+				c.values = src.Floats
+			}
+		}
+		if len(c.times) == 0 || len(c.values) < len(c.times) {
+			return errors.New("collada animation sampler is missing its keys")
+		}
+		c.stride = len(c.values) / len(c.times)
+		out[node] = append(out[node], c)
+	}
+	for i := range anim.Animations {
+		if err := s.collectChannels(&anim.Animations[i], out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Collada) animation(animationId string) *Animation {
+	var find func(anims []Animation) *Animation
+	find = func(anims []Animation) *Animation {
+		for i := range anims {
+			if anims[i].ID == animationId {
+				return &anims[i]
+			} else if a := find(anims[i].Animations); a != nil {
+				return a
+			}
+		}
+		return nil
+	}
+	return find(c.Animations)
+}
+
+// readAnimations adds an animation for each of the animation clips of the
+// file, all of the animations of the file are a single animation when the
+// file has no clips
+func (s *scene) readAnimations() error {
+	type clip struct {
+		name       string
+		animations []*Animation
+	}
+	clips := []clip{}
+	for i := range s.doc.Clips {
+		c := clip{name: s.doc.Clips[i].Name}
+		if c.name == "" {
+			c.name = s.doc.Clips[i].ID
+		}
+		for _, inst := range s.doc.Clips[i].Animations {
+			if a := s.doc.animation(id(inst.URL)); a != nil {
+				c.animations = append(c.animations, a)
+			}
+		}
+		clips = append(clips, c)
+	}
+	if len(clips) == 0 && len(s.doc.Animations) > 0 {
+		c := clip{name: "default"}
+		for i := range s.doc.Animations {
+			c.animations = append(c.animations, &s.doc.Animations[i])
+		}
+		clips = append(clips, c)
+	}
+	for _, c := range clips {
+		channels := map[int][]channel{}
+		for _, a := range c.animations {
+			if err := s.collectChannels(a, channels); err != nil {
+				return err
+			}
+		}
+		anim := load_result.Animation{Name: c.name, Frames: make([]load_result.AnimKeyFrame, 0)}
+		nodes := make([]int, 0, len(channels))
+		for node := range channels {
+			nodes = append(nodes, node)
+		}
+		slices.Sort(nodes)
+		for _, node := range nodes {
+			if err := s.readNodeAnimation(&anim, node, channels[node]); err != nil {
+				return err
+			}
+		}
+		if len(anim.Frames) == 0 {
+			continue
+		}
+		anim.ToRelativeTimes()
+		s.res.Animations = append(s.res.Animations, anim)
+	}
+	return nil
+}
+
+// readNodeAnimation evaluates the transform of the node at each of the key
+// times of its channels. Collada animates the individual transform
+// elements, so the whole transform is evaluated and split into the
+// translation, rotation, and scale that the engine animates.
+func (s *scene) readNodeAnimation(anim *load_result.Animation, node int, channels []channel) error {
+	times := []float64{}
+	for i := range channels {
+		times = append(times, channels[i].times...)
+	}
+	slices.Sort(times)
+	times = slices.Compact(times)
+	for _, time := range times {
+		m, err := s.nodeMatrix(node, func(t *Transform, values Floats) {
+			for i := range channels {
+				if channels[i].sid != t.SID {
+					continue
+				}
+				v := channels[i].sample(time)
+				if channels[i].index < 0 {
+					copy(values, v)
+				} else if channels[i].index < len(values) && len(v) > 0 {
+					values[channels[i].index] = v[0]
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+		p, r, sc := m.Decompose()
+		bones := []load_result.AnimBone{
+			{PathType: load_result.AnimPathTranslation, Data: p.AsAligned16()},
+			{PathType: load_result.AnimPathRotation, Data: matrix.QuaternionFromEuler(r)},
+			{PathType: load_result.AnimPathScale, Data: sc.AsAligned16()},
+		}
+		for _, bone := range bones {
+			bone.NodeIndex = node
+			bone.Interpolation = load_result.AnimInterpolateLinear
+			anim.AddBone(float32(time), bone)
+		}
+	}
+	return nil
+}
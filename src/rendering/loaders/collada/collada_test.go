@@ -0,0 +1,260 @@
+/******************************************************************************/
+/* collada_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collada
+
+import (
+	"kaiju/matrix"
+	"kaiju/rendering/loaders/load_result"
+	"testing"
+)
+
+const testDAE = `<?xml version="1.0" encoding="utf-8"?>
+<COLLADA xmlns="http://www.collada.org/2005/11/COLLADASchema" version="1.4.1">
+	<asset><up_axis>Y_UP</up_axis></asset>
+	<library_images>
+		<image id="albedo" name="albedo"><init_from>textures/albedo.png</init_from></image>
+	</library_images>
+	<library_effects>
+		<effect id="red-effect">
+			<profile_COMMON>
+				<newparam sid="albedo-surface"><surface type="2D"><init_from>albedo</init_from></surface></newparam>
+				<newparam sid="albedo-sampler"><sampler2D><source>albedo-surface</source></sampler2D></newparam>
+				<technique sid="common">
+					<phong>
+						<diffuse><texture texture="albedo-sampler" texcoord="UVMap"/></diffuse>
+					</phong>
+				</technique>
+			</profile_COMMON>
+		</effect>
+	</library_effects>
+	<library_materials>
+		<material id="red" name="red"><instance_effect url="#red-effect"/></material>
+	</library_materials>
+	<library_geometries>
+		<geometry id="quad-mesh" name="Quad">
+			<mesh>
+				<source id="quad-positions">
+					<float_array id="quad-positions-array" count="12">0 0 0 1 0 0 1 1 0 0 1 0</float_array>
+					<technique_common><accessor source="#quad-positions-array" count="4" stride="3"/></technique_common>
+				</source>
+				<source id="quad-normals">
+					<float_array id="quad-normals-array" count="3">0 0 1</float_array>
+					<technique_common><accessor source="#quad-normals-array" count="1" stride="3"/></technique_common>
+				</source>
+				<source id="quad-uvs">
+					<float_array id="quad-uvs-array" count="8">0 0 1 0 1 1 0 1</float_array>
+					<technique_common><accessor source="#quad-uvs-array" count="4" stride="2"/></technique_common>
+				</source>
+				<vertices id="quad-vertices">
+					<input semantic="POSITION" source="#quad-positions"/>
+				</vertices>
+				<polylist material="red-material" count="1">
+					<input semantic="VERTEX" source="#quad-vertices" offset="0"/>
+					<input semantic="NORMAL" source="#quad-normals" offset="1"/>
+					<input semantic="TEXCOORD" source="#quad-uvs" offset="2" set="0"/>
+					<vcount>4</vcount>
+					<p>0 0 0 1 0 1 2 0 2 3 0 3</p>
+				</polylist>
+			</mesh>
+		</geometry>
+	</library_geometries>
+	<library_controllers>
+		<controller id="quad-skin">
+			<skin source="#quad-mesh">
+				<bind_shape_matrix>1 0 0 0 0 1 0 0 0 0 1 0 0 0 0 1</bind_shape_matrix>
+				<source id="quad-joints">
+					<Name_array id="quad-joints-array" count="1">Bone</Name_array>
+				</source>
+				<source id="quad-binds">
+					<float_array id="quad-binds-array" count="16">1 0 0 -5 0 1 0 0 0 0 1 0 0 0 0 1</float_array>
+					<technique_common><accessor source="#quad-binds-array" count="1" stride="16"/></technique_common>
+				</source>
+				<source id="quad-weights">
+					<float_array id="quad-weights-array" count="2">1 0.5</float_array>
+				</source>
+				<joints>
+					<input semantic="JOINT" source="#quad-joints"/>
+					<input semantic="INV_BIND_MATRIX" source="#quad-binds"/>
+				</joints>
+				<vertex_weights count="4">
+					<input semantic="JOINT" source="#quad-joints" offset="0"/>
+					<input semantic="WEIGHT" source="#quad-weights" offset="1"/>
+					<vcount>1 1 0 0</vcount>
+					<v>0 0 0 1</v>
+				</vertex_weights>
+			</skin>
+		</controller>
+	</library_controllers>
+	<library_animations>
+		<animation id="root-location">
+			<source id="root-location-input"><float_array id="root-location-input-array" count="2">0 1</float_array></source>
+			<source id="root-location-output"><float_array id="root-location-output-array" count="2">1 11</float_array></source>
+			<sampler id="root-location-sampler">
+				<input semantic="INPUT" source="#root-location-input"/>
+				<input semantic="OUTPUT" source="#root-location-output"/>
+			</sampler>
+			<channel source="#root-location-sampler" target="Root/location.X"/>
+		</animation>
+	</library_animations>
+	<library_visual_scenes>
+		<visual_scene id="scene" name="scene">
+			<node id="Root" name="Root" type="NODE">
+				<translate sid="location">1 2 3</translate>
+				<rotate sid="rotationY">0 1 0 90</rotate>
+				<scale sid="scale">1 1 1</scale>
+				<instance_controller url="#quad-skin">
+					<bind_material><technique_common>
+						<instance_material symbol="red-material" target="#red"/>
+					</technique_common></bind_material>
+				</instance_controller>
+				<node id="Armature_Bone" sid="Bone" name="Bone" type="JOINT">
+					<matrix sid="transform">1 0 0 5 0 1 0 0 0 0 1 0 0 0 0 1</matrix>
+				</node>
+			</node>
+		</visual_scene>
+	</library_visual_scenes>
+	<scene><instance_visual_scene url="#scene"/></scene>
+</COLLADA>`
+
+func loadTestResult(t *testing.T, dae string) load_result.Result {
+	t.Helper()
+	doc, err := LoadCollada([]byte(dae))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestNodes(t *testing.T) {
+	res := loadTestResult(t, testDAE)
+	if len(res.Nodes) != 2 || res.Nodes[0].Name != "Root" || res.Nodes[1].Parent != 0 {
+		t.Fatalf("unexpected nodes %+v", res.Nodes)
+	}
+	if p := res.Nodes[0].Transform.Position(); !matrix.Vec3Approx(p, matrix.Vec3{1, 2, 3}) {
+		t.Errorf("unexpected root position %v", p)
+	}
+	if r := res.Nodes[0].Transform.Rotation(); !matrix.Vec3ApproxTo(r, matrix.Vec3{0, 90, 0}, 0.01) {
+		t.Errorf("unexpected root rotation %v", r)
+	}
+	if p := res.Nodes[1].Transform.Position(); !matrix.Vec3Approx(p, matrix.Vec3{5, 0, 0}) {
+		t.Errorf("expected the row major matrix to be read, got position %v", p)
+	}
+}
+
+func TestUpAxis(t *testing.T) {
+	res := loadTestResult(t, `<COLLADA><asset><up_axis>Z_UP</up_axis></asset>
+		<library_visual_scenes><visual_scene id="scene">
+			<node id="Root"><translate>0 0 1</translate></node>
+		</visual_scene></library_visual_scenes></COLLADA>`)
+	if p := res.Nodes[0].Transform.Position(); !matrix.Vec3ApproxTo(p, matrix.Vec3{0, 1, 0}, 0.0001) {
+		t.Errorf("expected Z up to be converted to Y up, got %v", p)
+	}
+}
+
+func TestMeshes(t *testing.T) {
+	res := loadTestResult(t, testDAE)
+	if len(res.Meshes) != 1 {
+		t.Fatalf("expected 1 mesh but got %d", len(res.Meshes))
+	}
+	m := res.Meshes[0]
+	if m.MeshName != "Quad" || m.Node != 0 || m.Material != 0 {
+		t.Errorf("unexpected mesh %s at node %d with material %d", m.MeshName, m.Node, m.Material)
+	}
+	if len(m.Verts) != 4 || len(m.Indexes) != 6 {
+		t.Errorf("expected the quad to be split into 2 triangles, got %d verts and %d indexes", len(m.Verts), len(m.Indexes))
+	}
+	if uv := m.Verts[0].UV0; uv.Y() != 1 {
+		t.Errorf("expected the UVs to be flipped vertically, got %v", uv)
+	}
+	if n := m.Verts[2].Normal; !matrix.Vec3Approx(n, matrix.Vec3{0, 0, 1}) {
+		t.Errorf("unexpected normal %v", n)
+	}
+	if w := m.Verts[0].JointWeights; w.X() != 1 {
+		t.Errorf("expected the first vertex to be fully weighted to the bone, got %v", w)
+	}
+	if w := m.Verts[2].JointWeights; w.X() != 0 {
+		t.Errorf("expected the third vertex to have no weights, got %v", w)
+	}
+	if len(res.Joints) != 1 || res.Joints[0].Id != 1 {
+		t.Fatalf("expected the bone to be the only joint, got %+v", res.Joints)
+	}
+	if p := res.Joints[0].Skin.Position(); !matrix.Vec3Approx(p, matrix.Vec3{-5, 0, 0}) {
+		t.Errorf("unexpected inverse bind position %v", p)
+	}
+}
+
+func TestMaterials(t *testing.T) {
+	res := loadTestResult(t, testDAE)
+	if len(res.Images) != 1 || res.Images[0].URI != "textures/albedo.png" {
+		t.Fatalf("unexpected images %+v", res.Images)
+	}
+	if len(res.Materials) != 1 || res.MaterialImages[0] != 0 {
+		t.Errorf("expected the material to use the image through its sampler, got %v", res.MaterialImages)
+	}
+}
+
+func TestAnimations(t *testing.T) {
+	res := loadTestResult(t, testDAE)
+	if len(res.Animations) != 1 || len(res.Animations[0].Frames) != 2 {
+		t.Fatalf("unexpected animations %+v", res.Animations)
+	}
+	anim := res.Animations[0]
+	if anim.Frames[0].Time != 1 || anim.Frames[1].Time != 0 {
+		t.Errorf("expected relative key frame times, got %v and %v", anim.Frames[0].Time, anim.Frames[1].Time)
+	}
+	for _, b := range anim.Frames[1].Bones {
+		if b.NodeIndex != 0 {
+			t.Errorf("expected only the root to be animated, got node %d", b.NodeIndex)
+		}
+		switch b.PathType {
+		case load_result.AnimPathTranslation:
+			if p := (matrix.Vec3{b.Data[0], b.Data[1], b.Data[2]}); !matrix.Vec3Approx(p, matrix.Vec3{11, 2, 3}) {
+				t.Errorf("expected the animated X to replace the translation, got %v", p)
+			}
+		case load_result.AnimPathRotation:
+			r := matrix.Quaternion(b.Data).ToEuler()
+			if !matrix.Vec3ApproxTo(r, matrix.Vec3{0, 90, 0}, 0.01) {
+				t.Errorf("expected the rotation to be kept, got %v", r)
+			}
+		}
+	}
+}
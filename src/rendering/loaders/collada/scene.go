@@ -0,0 +1,639 @@
+/******************************************************************************/
+/* scene.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collada
+
+import (
+	"errors"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders/load_result"
+	"slices"
+	"strings"
+)
+
+type sceneNode struct {
+	node   *Node
+	parent int
+}
+
+type scene struct {
+	doc       *Collada
+	res       load_result.Result
+	nodes     []sceneNode
+	byId      map[string]int
+	bySid     map[string]int
+	materials map[string]int
+	up        matrix.Mat4
+}
+
+type influence struct {
+	joint  int32
+	weight float64
+}
+
+// Result converts the visual scene of the document into the nodes, meshes,
+// materials, skins, and animations that the engine loads. Every node of the
+// scene becomes a node of the result, every geometry or controller that is
+// instanced by a node adds a mesh at that node for each of its primitives.
+func (c *Collada) Result() (load_result.Result, error) {
+	s := scene{
+		doc:       c,
+		res:       load_result.NewResult(),
+		byId:      make(map[string]int),
+		bySid:     make(map[string]int),
+		materials: make(map[string]int),
+		up:        upAxis(c.Asset.UpAxis),
+	}
+	vs := c.visualScene()
+	if vs == nil {
+		return s.res, errors.New("the collada file has no visual scene")
+	}
+	for i := range vs.Nodes {
+		s.addNode(&vs.Nodes[i], -1)
+	}
+	s.readMaterials()
+	for i := range s.nodes {
+		if err := s.readNodeTransform(i); err != nil {
+			return s.res, err
+		}
+	}
+	for i := range s.nodes {
+		n := s.nodes[i].node
+		for j := range n.Geometries {
+			if err := s.readGeometry(i, &n.Geometries[j], nil); err != nil {
+				return s.res, err
+			}
+		}
+		for j := range n.Controllers {
+			if err := s.readController(i, &n.Controllers[j]); err != nil {
+				return s.res, err
+			}
+		}
+	}
+	if err := s.readAnimations(); err != nil {
+		return s.res, err
+	}
+	return s.res, nil
+}
+
+// upAxis returns the rotation that turns the up axis of the file into the Y
+// up axis of the engine, it is applied to the nodes at the root of the scene
+func upAxis(axis string) matrix.Mat4 {
+	switch axis {
+	case "Z_UP":
+		return matrix.QuaternionFromEuler(matrix.Vec3{-90, 0, 0}).ToMat4()
+	case "X_UP":
+		return matrix.QuaternionFromEuler(matrix.Vec3{0, 0, 90}).ToMat4()
+	}
+	return matrix.Mat4Identity()
+}
+
+func (c *Collada) visualScene() *VisualScene {
+	for i := range c.VisualScenes {
+		if c.VisualScenes[i].ID == id(c.Scene.VisualScene.URL) {
+			return &c.VisualScenes[i]
+		}
+	}
+	if len(c.VisualScenes) > 0 {
+		return &c.VisualScenes[0]
+	}
+	return nil
+}
+
+func (s *scene) addNode(n *Node, parent int) {
+	idx := len(s.nodes)
+	s.nodes = append(s.nodes, sceneNode{node: n, parent: parent})
+	if n.ID != "" {
+		s.byId[n.ID] = idx
+	}
+	if n.SID != "" {
+		s.bySid[n.SID] = idx
+	}
+	for i := range n.Nodes {
+		s.addNode(&n.Nodes[i], idx)
+	}
+}
+
+// nodeMatrix builds the local matrix of the node from its transform
+// elements. The override function can replace the values of the elements,
+// it is used to evaluate the transform at the key frames of animations.
+func (s *scene) nodeMatrix(idx int, override func(t *Transform, values Floats)) (matrix.Mat4, error) {
+	m := matrix.Mat4Identity()
+	for i := range s.nodes[idx].node.Transforms {
+		t := &s.nodes[idx].node.Transforms[i]
+		kind := t.XMLName.Local
+		if kind != "matrix" && kind != "translate" && kind != "rotate" && kind != "scale" {
+			continue
+		}
+		values, err := t.Values()
+		if err != nil {
+			return m, err
+		}
+		if override != nil {
+			override(t, values)
+		}
+		e, err := transformMatrix(kind, values)
+		if err != nil {
+			return m, err
+		}
+		// The elements are applied to the node from the last to the first
+		m = matrix.Mat4Multiply(e, m)
+	}
+	if s.nodes[idx].parent < 0 {
+		m = matrix.Mat4Multiply(m, s.up)
+	}
+	return m, nil
+}
+
+func transformMatrix(kind string, v Floats) (matrix.Mat4, error) {
+	m := matrix.Mat4Identity()
+	switch kind {
+	case "matrix":
+		if len(v) < 16 {
+			return m, errors.New("invalid collada matrix")
+		}
+		return rowMajor(v), nil
+	case "translate":
+		if len(v) < 3 {
+			return m, errors.New("invalid collada translate")
+		}
+		m.SetTranslation(vec3(v))
+	case "rotate":
+		if len(v) < 4 {
+			return m, errors.New("invalid collada rotate")
+		}
+		q := matrix.QuaternionAxisAngle(vec3(v).Normal(), matrix.Deg2Rad(matrix.Float(v[3])))
+		m = q.ToMat4()
+	case "scale":
+		if len(v) < 3 {
+			return m, errors.New("invalid collada scale")
+		}
+		m.Scale(vec3(v))
+	}
+	return m, nil
+}
+
+// rowMajor converts the row major matrices of Collada into the column major
+// matrices of the engine
+func rowMajor(v []float64) matrix.Mat4 {
+	m := matrix.Mat4{}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			m[col*4+row] = matrix.Float(v[row*4+col])
+		}
+	}
+	return m
+}
+
+func vec3(v []float64) matrix.Vec3 {
+	return matrix.Vec3{matrix.Float(v[0]), matrix.Float(v[1]), matrix.Float(v[2])}
+}
+
+func (s *scene) readNodeTransform(idx int) error {
+	m, err := s.nodeMatrix(idx, nil)
+	if err != nil {
+		return err
+	}
+	n := s.nodes[idx].node
+	name := n.Name
+	if name == "" {
+		name = n.ID
+	}
+	p, r, sc := m.Decompose()
+	t := matrix.NewTransform()
+	t.Identifier = uint8(idx)
+	t.SetPosition(p)
+	t.SetRotation(r)
+	t.SetScale(sc)
+	s.res.Nodes = append(s.res.Nodes, load_result.Node{
+		Name:      name,
+		Parent:    s.nodes[idx].parent,
+		Transform: t,
+	})
+	return nil
+}
+
+func (s *scene) readMaterials() {
+	images := map[string]int{}
+	for i := range s.doc.Images {
+		img := &s.doc.Images[i]
+		uri := strings.TrimSpace(img.InitFrom.Ref)
+		if uri == "" {
+			uri = strings.TrimSpace(img.InitFrom.Value)
+		}
+		uri = strings.TrimPrefix(uri, "file://")
+		images[img.ID] = len(s.res.Images)
+		s.res.Images = append(s.res.Images, load_result.Image{Name: img.Name, URI: uri})
+	}
+	for i := range s.doc.Materials {
+		m := &s.doc.Materials[i]
+		color := matrix.ColorWhite()
+		image := -1
+		if e := s.effect(id(m.Effect.URL)); e != nil {
+			if shading := e.shading(); shading != nil {
+				if c := shading.Diffuse.Color; c != nil && len(*c) >= 3 {
+					color = matrix.Color{matrix.Float((*c)[0]), matrix.Float((*c)[1]), matrix.Float((*c)[2]), 1}
+					if len(*c) >= 4 {
+						color[3] = matrix.Float((*c)[3])
+					}
+				}
+				if t := shading.Diffuse.Texture; t != nil {
+					if idx, ok := images[e.image(t.Texture)]; ok {
+						image = idx
+					}
+				}
+			}
+		}
+		texture := ""
+		if image >= 0 {
+			texture = s.res.Images[image].URI
+		}
+		def := load_result.LitMaterial(color, texture)
+		def.UseBlending = color.A() < 1
+		s.materials[m.ID] = len(s.res.Materials)
+		s.res.Materials = append(s.res.Materials, def)
+		s.res.MaterialImages = append(s.res.MaterialImages, image)
+	}
+}
+
+func (s *scene) effect(effectId string) *Effect {
+	for i := range s.doc.Effects {
+		if s.doc.Effects[i].ID == effectId {
+			return &s.doc.Effects[i]
+		}
+	}
+	return nil
+}
+
+func (e *Effect) shading() *Shading {
+	if e.Technique.Phong != nil {
+		return e.Technique.Phong
+	} else if e.Technique.Blinn != nil {
+		return e.Technique.Blinn
+	}
+	return e.Technique.Lambert
+}
+
+// image follows the sampler and surface parameters of the effect to the id
+// of the image, files that reference the image directly are supported too
+func (e *Effect) image(texture string) string {
+	param := func(sid string) *NewParam {
+		for i := range e.Params {
+			if e.Params[i].SID == sid {
+				return &e.Params[i]
+			}
+		}
+		return nil
+	}
+	sampler := param(texture)
+	if sampler == nil {
+		return texture
+	}
+	if sampler.Sampler.Image.URL != "" {
+		return id(sampler.Sampler.Image.URL)
+	}
+	if surface := param(sampler.Sampler.Source); surface != nil {
+		return surface.Surface.InitFrom
+	}
+	return texture
+}
+
+func (s *scene) geometry(geometryId string) *Geometry {
+	for i := range s.doc.Geometries {
+		if s.doc.Geometries[i].ID == geometryId {
+			return &s.doc.Geometries[i]
+		}
+	}
+	return nil
+}
+
+func (m *Mesh) source(sourceId string) *Source {
+	for i := range m.Sources {
+		if m.Sources[i].ID == sourceId {
+			return &m.Sources[i]
+		}
+	}
+	return nil
+}
+
+func (s *Source) stride(fallback int) int {
+	if s.Accessor.Stride > 0 {
+		return s.Accessor.Stride
+	}
+	return fallback
+}
+
+type vertexKey struct {
+	position int
+	normal   int
+	uv       int
+}
+
+type primitiveInputs struct {
+	positions, normals, uvs *Source
+	position, normal, uv    int
+	stride                  int
+}
+
+func (m *Mesh) inputs(p *Primitive) (primitiveInputs, error) {
+	in := primitiveInputs{position: -1, normal: -1, uv: -1}
+	uvSet := -1
+	for _, input := range p.Inputs {
+		in.stride = max(in.stride, input.Offset+1)
+		switch input.Semantic {
+		case "VERTEX":
+			in.position = input.Offset
+			for _, v := range m.Vertices.Inputs {
+				switch v.Semantic {
+				case "POSITION":
+					in.positions = m.source(id(v.Source))
+				case "NORMAL":
+					in.normals = m.source(id(v.Source))
+					in.normal = input.Offset
+				case "TEXCOORD":
+					in.uvs = m.source(id(v.Source))
+					in.uv = input.Offset
+				}
+			}
+		case "NORMAL":
+			in.normals = m.source(id(input.Source))
+			in.normal = input.Offset
+		case "TEXCOORD":
+			if uvSet < 0 || input.Set < uvSet {
+				uvSet = input.Set
+				in.uvs = m.source(id(input.Source))
+				in.uv = input.Offset
+			}
+		}
+	}
+	if in.positions == nil || in.position < 0 {
+		return in, errors.New("collada primitive has no positions")
+	}
+	return in, nil
+}
+
+// readGeometry adds a mesh for each primitive of the geometry, the
+// influences are the joints of the skin of each position when the geometry
+// is instanced through a controller
+func (s *scene) readGeometry(node int, inst *InstanceGeometry, influences [][]influence) error {
+	g := s.geometry(id(inst.URL))
+	if g == nil || g.Mesh == nil {
+		return nil
+	}
+	name := g.Name
+	if name == "" {
+		name = g.ID
+	}
+	prims := append(slices.Clone(g.Mesh.Triangles), g.Mesh.Polylists...)
+	for i := range prims {
+		verts, indexes, err := g.Mesh.readPrimitive(&prims[i], influences)
+		if err != nil {
+			return err
+		}
+		if len(indexes) == 0 {
+			continue
+		}
+		s.res.Add(s.res.Nodes[node].Name, name, verts, indexes, nil)
+		mesh := &s.res.Meshes[len(s.res.Meshes)-1]
+		mesh.Node = node
+		for _, m := range inst.Materials {
+			if m.Symbol == prims[i].Material {
+				if idx, ok := s.materials[id(m.Target)]; ok {
+					mesh.Material = idx
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Mesh) readPrimitive(p *Primitive, influences [][]influence) ([]rendering.Vertex, []uint32, error) {
+	in, err := m.inputs(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts := p.VCount
+	if len(counts) == 0 {
+		counts = make(Ints, len(p.P)/(in.stride*3))
+		for i := range counts {
+			counts[i] = 3
+		}
+	}
+	verts := []rendering.Vertex{}
+	indexes := []uint32{}
+	lookup := map[vertexKey]uint32{}
+	cursor := 0
+	for _, count := range counts {
+		polygon := make([]uint32, 0, count)
+		for range count {
+			if cursor+in.stride > len(p.P) {
+				return nil, nil, errors.New("collada primitive has too few indexes")
+			}
+			key := vertexKey{position: p.P[cursor+in.position], normal: -1, uv: -1}
+			if in.normals != nil {
+				key.normal = p.P[cursor+in.normal]
+			}
+			if in.uvs != nil {
+				key.uv = p.P[cursor+in.uv]
+			}
+			cursor += in.stride
+			idx, ok := lookup[key]
+			if !ok {
+				v, err := in.vertex(key, influences)
+				if err != nil {
+					return nil, nil, err
+				}
+				idx = uint32(len(verts))
+				lookup[key] = idx
+				verts = append(verts, v)
+			}
+			polygon = append(polygon, idx)
+		}
+		for i := 1; i+1 < len(polygon); i++ {
+			indexes = append(indexes, polygon[0], polygon[i], polygon[i+1])
+		}
+	}
+	return verts, indexes, nil
+}
+
+func sourceValues(s *Source, index, size int) ([]float64, bool) {
+	stride := s.stride(size)
+	if index < 0 || index*stride+size > len(s.Floats) {
+		return nil, false
+	}
+	return s.Floats[index*stride : index*stride+size], true
+}
+
+func (in *primitiveInputs) vertex(key vertexKey, influences [][]influence) (rendering.Vertex, error) {
+	v := rendering.Vertex{Color: matrix.ColorWhite()}
+	pos, ok := sourceValues(in.positions, key.position, 3)
+	if !ok {
+		return v, errors.New("collada position index is out of range")
+	}
+	v.Position = vec3(pos)
+	v.MorphTarget = v.Position
+	if in.normals != nil {
+		if n, ok := sourceValues(in.normals, key.normal, 3); ok {
+			v.Normal = vec3(n)
+		}
+	}
+	if in.uvs != nil {
+		if uv, ok := sourceValues(in.uvs, key.uv, 2); ok {
+			// Collada UVs start at the bottom left of the image
+			v.UV0 = matrix.Vec2{matrix.Float(uv[0]), 1 - matrix.Float(uv[1])}
+		}
+	}
+	if key.position < len(influences) {
+		ids := make([]int32, len(influences[key.position]))
+		weights := make([]matrix.Float, len(influences[key.position]))
+		for i, inf := range influences[key.position] {
+			ids[i] = inf.joint
+			weights[i] = matrix.Float(inf.weight)
+		}
+		v.JointIds, v.JointWeights = load_result.TopInfluences(ids, weights)
+	}
+	return v, nil
+}
+
+func (s *scene) readController(node int, inst *InstanceGeometry) error {
+	var skin *Skin
+	for i := range s.doc.Controllers {
+		if s.doc.Controllers[i].ID == id(inst.URL) {
+			skin = s.doc.Controllers[i].Skin
+		}
+	}
+	if skin == nil {
+		return nil
+	}
+	source := func(sourceId string) *Source {
+		for i := range skin.Sources {
+			if skin.Sources[i].ID == id(sourceId) {
+				return &skin.Sources[i]
+			}
+		}
+		return nil
+	}
+	var names, binds *Source
+	for _, input := range skin.Joints.Inputs {
+		switch input.Semantic {
+		case "JOINT":
+			names = source(input.Source)
+		case "INV_BIND_MATRIX":
+			binds = source(input.Source)
+		}
+	}
+	if names == nil || binds == nil {
+		return errors.New("collada skin is missing its joints")
+	}
+	jointNames := names.Names
+	if len(jointNames) == 0 {
+		jointNames = names.IDRefs
+	}
+	bindShape := matrix.Mat4Identity()
+	if len(skin.BindShapeMatrix) >= 16 {
+		bindShape = rowMajor(skin.BindShapeMatrix)
+	}
+	joints := make([]int32, len(jointNames))
+	for i, name := range jointNames {
+		if len(binds.Floats) < (i+1)*16 {
+			return errors.New("collada skin has too few bind matrices")
+		}
+		inverseBind := matrix.Mat4Multiply(bindShape, rowMajor(binds.Floats[i*16:]))
+		joints[i] = s.joint(name, inverseBind)
+	}
+	influences, err := skin.influences(source, joints)
+	if err != nil {
+		return err
+	}
+	return s.readGeometry(node, &InstanceGeometry{URL: skin.Source, Materials: inst.Materials}, influences)
+}
+
+// joint returns the index of the joint for the node with the name, the
+// joint is added with the inverse bind matrix if it was not added yet
+func (s *scene) joint(name string, inverseBind matrix.Mat4) int32 {
+	node, ok := s.bySid[name]
+	if !ok {
+		if node, ok = s.byId[name]; !ok {
+			return -1
+		}
+	}
+	for i := range s.res.Joints {
+		if s.res.Joints[i].Id == int32(node) {
+			return int32(i)
+		}
+	}
+	s.res.Joints = append(s.res.Joints, load_result.Joint{Id: int32(node), Skin: inverseBind})
+	return int32(len(s.res.Joints) - 1)
+}
+
+func (skin *Skin) influences(source func(string) *Source, joints []int32) ([][]influence, error) {
+	vw := &skin.VertexWeights
+	var weights *Source
+	jointOffset, weightOffset, stride := -1, -1, 0
+	for _, input := range vw.Inputs {
+		stride = max(stride, input.Offset+1)
+		switch input.Semantic {
+		case "JOINT":
+			jointOffset = input.Offset
+		case "WEIGHT":
+			weights = source(input.Source)
+			weightOffset = input.Offset
+		}
+	}
+	if weights == nil || jointOffset < 0 {
+		return nil, errors.New("collada skin is missing its weights")
+	}
+	influences := make([][]influence, len(vw.VCount))
+	cursor := 0
+	for i, count := range vw.VCount {
+		for range count {
+			if cursor+stride > len(vw.V) {
+				return nil, errors.New("collada skin has too few weights")
+			}
+			j := vw.V[cursor+jointOffset]
+			w := vw.V[cursor+weightOffset]
+			cursor += stride
+			// A joint of -1 is the bind shape which is not a joint
+			if j < 0 || j >= len(joints) || joints[j] < 0 || w < 0 || w >= len(weights.Floats) {
+				continue
+			}
+			influences[i] = append(influences[i], influence{joints[j], weights.Floats[w]})
+		}
+	}
+	return influences, nil
+}
@@ -0,0 +1,293 @@
+/******************************************************************************/
+/* xml.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collada
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+type Collada struct {
+	Asset        Asset         `xml:"asset"`
+	Images       []Image       `xml:"library_images>image"`
+	Effects      []Effect      `xml:"library_effects>effect"`
+	Materials    []Material    `xml:"library_materials>material"`
+	Geometries   []Geometry    `xml:"library_geometries>geometry"`
+	Controllers  []Controller  `xml:"library_controllers>controller"`
+	Animations   []Animation   `xml:"library_animations>animation"`
+	Clips        []Clip        `xml:"library_animation_clips>animation_clip"`
+	VisualScenes []VisualScene `xml:"library_visual_scenes>visual_scene"`
+	Scene        struct {
+		VisualScene InstanceURL `xml:"instance_visual_scene"`
+	} `xml:"scene"`
+}
+
+type Asset struct {
+	UpAxis string `xml:"up_axis"`
+}
+
+type InstanceURL struct {
+	URL string `xml:"url,attr"`
+}
+
+type Image struct {
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	InitFrom struct {
+		Value string `xml:",chardata"`
+		// Ref is where version 1.5 files write the path of the image
+		Ref string `xml:"ref"`
+	} `xml:"init_from"`
+}
+
+type Effect struct {
+	ID        string     `xml:"id,attr"`
+	Params    []NewParam `xml:"profile_COMMON>newparam"`
+	Technique struct {
+		Phong   *Shading `xml:"phong"`
+		Lambert *Shading `xml:"lambert"`
+		Blinn   *Shading `xml:"blinn"`
+	} `xml:"profile_COMMON>technique"`
+}
+
+type NewParam struct {
+	SID     string `xml:"sid,attr"`
+	Surface struct {
+		InitFrom string `xml:"init_from"`
+	} `xml:"surface"`
+	Sampler struct {
+		Source string      `xml:"source"`
+		Image  InstanceURL `xml:"instance_image"`
+	} `xml:"sampler2D"`
+}
+
+type Shading struct {
+	Diffuse struct {
+		Color   *Floats `xml:"color"`
+		Texture *struct {
+			Texture string `xml:"texture,attr"`
+		} `xml:"texture"`
+	} `xml:"diffuse"`
+	Transparency *Floats `xml:"transparency>float"`
+}
+
+type Material struct {
+	ID     string      `xml:"id,attr"`
+	Name   string      `xml:"name,attr"`
+	Effect InstanceURL `xml:"instance_effect"`
+}
+
+type Geometry struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+	Mesh *Mesh  `xml:"mesh"`
+}
+
+type Mesh struct {
+	Sources  []Source `xml:"source"`
+	Vertices struct {
+		ID     string  `xml:"id,attr"`
+		Inputs []Input `xml:"input"`
+	} `xml:"vertices"`
+	Triangles []Primitive `xml:"triangles"`
+	Polylists []Primitive `xml:"polylist"`
+}
+
+type Primitive struct {
+	Count    int     `xml:"count,attr"`
+	Material string  `xml:"material,attr"`
+	Inputs   []Input `xml:"input"`
+	VCount   Ints    `xml:"vcount"`
+	P        Ints    `xml:"p"`
+}
+
+type Input struct {
+	Semantic string `xml:"semantic,attr"`
+	Source   string `xml:"source,attr"`
+	Offset   int    `xml:"offset,attr"`
+	Set      int    `xml:"set,attr"`
+}
+
+type Source struct {
+	ID       string `xml:"id,attr"`
+	Floats   Floats `xml:"float_array"`
+	Names    Names  `xml:"Name_array"`
+	IDRefs   Names  `xml:"IDREF_array"`
+	Accessor struct {
+		Stride int `xml:"stride,attr"`
+	} `xml:"technique_common>accessor"`
+}
+
+type Controller struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+	Skin *Skin  `xml:"skin"`
+}
+
+type Skin struct {
+	Source          string   `xml:"source,attr"`
+	BindShapeMatrix Floats   `xml:"bind_shape_matrix"`
+	Sources         []Source `xml:"source"`
+	Joints          struct {
+		Inputs []Input `xml:"input"`
+	} `xml:"joints"`
+	VertexWeights struct {
+		Count  int     `xml:"count,attr"`
+		Inputs []Input `xml:"input"`
+		VCount Ints    `xml:"vcount"`
+		V      Ints    `xml:"v"`
+	} `xml:"vertex_weights"`
+}
+
+type Animation struct {
+	ID         string      `xml:"id,attr"`
+	Name       string      `xml:"name,attr"`
+	Sources    []Source    `xml:"source"`
+	Samplers   []Sampler   `xml:"sampler"`
+	Channels   []Channel   `xml:"channel"`
+	Animations []Animation `xml:"animation"`
+}
+
+type Sampler struct {
+	ID     string  `xml:"id,attr"`
+	Inputs []Input `xml:"input"`
+}
+
+type Channel struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type Clip struct {
+	ID         string        `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Animations []InstanceURL `xml:"instance_animation"`
+}
+
+type VisualScene struct {
+	ID    string `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Nodes []Node `xml:"node"`
+}
+
+type Node struct {
+	ID          string             `xml:"id,attr"`
+	SID         string             `xml:"sid,attr"`
+	Name        string             `xml:"name,attr"`
+	Type        string             `xml:"type,attr"`
+	Nodes       []Node             `xml:"node"`
+	Geometries  []InstanceGeometry `xml:"instance_geometry"`
+	Controllers []InstanceGeometry `xml:"instance_controller"`
+	// Transforms are the matrix, translate, rotate, and scale elements of the
+	// node in the order they are written, any other element is ignored
+	Transforms []Transform `xml:",any"`
+}
+
+type InstanceGeometry struct {
+	URL       string             `xml:"url,attr"`
+	Materials []InstanceMaterial `xml:"bind_material>technique_common>instance_material"`
+}
+
+type InstanceMaterial struct {
+	Symbol string `xml:"symbol,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type Transform struct {
+	XMLName xml.Name
+	SID     string `xml:"sid,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Floats is a list of numbers separated by white space
+type Floats []float64
+
+// Ints is a list of integers separated by white space
+type Ints []int
+
+// Names is a list of names separated by white space
+type Names []string
+
+// Values reads the numbers of the transform
+func (t *Transform) Values() (Floats, error) {
+	var f Floats
+	err := f.UnmarshalText([]byte(t.Text))
+	return f, err
+}
+
+func (f *Floats) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	*f = make(Floats, len(fields))
+	for i := range fields {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return err
+		}
+		(*f)[i] = v
+	}
+	return nil
+}
+
+func (n *Ints) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	*n = make(Ints, len(fields))
+	for i := range fields {
+		v, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return err
+		}
+		(*n)[i] = v
+	}
+	return nil
+}
+
+func (n *Names) UnmarshalText(text []byte) error {
+	*n = strings.Fields(string(text))
+	return nil
+}
+
+// LoadCollada reads the document of a Collada (.dae) file
+func LoadCollada(data []byte) (Collada, error) {
+	var c Collada
+	err := xml.Unmarshal(data, &c)
+	return c, err
+}
+
+// id removes the # from a URL that references an element in the document
+func id(url string) string { return strings.TrimPrefix(url, "#") }
@@ -0,0 +1,275 @@
+/******************************************************************************/
+/* ascii.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package fbx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type asciiTokenKind = int
+
+const (
+	asciiTokenEnd asciiTokenKind = iota
+	asciiTokenKey
+	asciiTokenString
+	asciiTokenNumber
+	asciiTokenWord
+	asciiTokenComma
+	asciiTokenOpen
+	asciiTokenClose
+	asciiTokenArray
+)
+
+type asciiToken struct {
+	kind  asciiTokenKind
+	value string
+}
+
+type asciiReader struct {
+	src  string
+	pos  int
+	peek *asciiToken
+}
+
+func parseASCII(src string) (Document, error) {
+	doc := Document{}
+	r := asciiReader{src: src}
+	children, err := r.nodes(false)
+	if err != nil {
+		return doc, err
+	}
+	doc.Root.Children = children
+	header := doc.Root.Child("FBXHeaderExtension")
+	doc.Version = int(header.Child("FBXVersion").Int(0))
+	return doc, nil
+}
+
+func (r *asciiReader) skipSpace() {
+	for r.pos < len(r.src) {
+		c := r.src[r.pos]
+		if c == ';' {
+			for r.pos < len(r.src) && r.src[r.pos] != '\n' {
+				r.pos++
+			}
+		} else if unicode.IsSpace(rune(c)) {
+			r.pos++
+		} else {
+			return
+		}
+	}
+}
+
+func (r *asciiReader) next() (asciiToken, error) {
+	if r.peek != nil {
+		t := *r.peek
+		r.peek = nil
+		return t, nil
+	}
+	r.skipSpace()
+	if r.pos >= len(r.src) {
+		return asciiToken{kind: asciiTokenEnd}, nil
+	}
+	c := r.src[r.pos]
+	switch {
+	case c == ',':
+		r.pos++
+		return asciiToken{kind: asciiTokenComma}, nil
+	case c == '{':
+		r.pos++
+		return asciiToken{kind: asciiTokenOpen}, nil
+	case c == '}':
+		r.pos++
+		return asciiToken{kind: asciiTokenClose}, nil
+	case c == '"':
+		end := strings.IndexByte(r.src[r.pos+1:], '"')
+		if end < 0 {
+			return asciiToken{}, errors.New("unterminated string in fbx file")
+		}
+		s := r.src[r.pos+1 : r.pos+1+end]
+		r.pos += end + 2
+		return asciiToken{kind: asciiTokenString, value: s}, nil
+	case c == '*':
+		r.pos++
+		start := r.pos
+		for r.pos < len(r.src) && r.src[r.pos] >= '0' && r.src[r.pos] <= '9' {
+			r.pos++
+		}
+		return asciiToken{kind: asciiTokenArray, value: r.src[start:r.pos]}, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		start := r.pos
+		for r.pos < len(r.src) && strings.IndexByte("+-.0123456789eE", r.src[r.pos]) >= 0 {
+			r.pos++
+		}
+		return asciiToken{kind: asciiTokenNumber, value: r.src[start:r.pos]}, nil
+	default:
+		start := r.pos
+		for r.pos < len(r.src) && (r.src[r.pos] == '_' || r.src[r.pos] == '|' ||
+			unicode.IsLetter(rune(r.src[r.pos])) || unicode.IsDigit(rune(r.src[r.pos]))) {
+			r.pos++
+		}
+		if start == r.pos {
+			return asciiToken{}, errors.New("unexpected character in fbx file: " + string(c))
+		}
+		word := r.src[start:r.pos]
+		if r.pos < len(r.src) && r.src[r.pos] == ':' {
+			r.pos++
+			return asciiToken{kind: asciiTokenKey, value: word}, nil
+		}
+		return asciiToken{kind: asciiTokenWord, value: word}, nil
+	}
+}
+
+func (r *asciiReader) unread(t asciiToken) { r.peek = &t }
+
+// nodes reads nodes until the end of the file, or until the closing brace
+// when the nodes are the children of another node
+func (r *asciiReader) nodes(nested bool) ([]*Node, error) {
+	nodes := []*Node{}
+	for {
+		t, err := r.next()
+		if err != nil {
+			return nodes, err
+		}
+		switch t.kind {
+		case asciiTokenEnd:
+			if nested {
+				return nodes, errors.New("missing closing brace in fbx file")
+			}
+			return nodes, nil
+		case asciiTokenClose:
+			if !nested {
+				return nodes, errors.New("unexpected closing brace in fbx file")
+			}
+			return nodes, nil
+		case asciiTokenKey:
+			n, err := r.node(t.value)
+			if err != nil {
+				return nodes, err
+			}
+			nodes = append(nodes, n)
+		default:
+			return nodes, errors.New("expected a node name in fbx file")
+		}
+	}
+}
+
+func (r *asciiReader) node(name string) (*Node, error) {
+	n := &Node{Name: name}
+	t, err := r.next()
+	if err != nil {
+		return n, err
+	}
+	// Raw data is written with an empty first value, "Content: , "..."
+	if t.kind == asciiTokenComma {
+		if t, err = r.next(); err != nil {
+			return n, err
+		}
+	}
+	if t.kind == asciiTokenArray {
+		arr, err := r.array()
+		if err != nil {
+			return n, err
+		}
+		n.Properties = append(n.Properties, arr)
+		return n, nil
+	}
+	for {
+		switch t.kind {
+		case asciiTokenString:
+			n.Properties = append(n.Properties, t.value)
+		case asciiTokenWord:
+			n.Properties = append(n.Properties, t.value)
+		case asciiTokenNumber:
+			n.Properties = append(n.Properties, asciiNumber(t.value))
+		case asciiTokenOpen:
+			n.Children, err = r.nodes(true)
+			return n, err
+		default:
+			r.unread(t)
+			return n, nil
+		}
+		if t, err = r.next(); err != nil {
+			return n, err
+		} else if t.kind == asciiTokenComma {
+			if t, err = r.next(); err != nil {
+				return n, err
+			}
+		} else if t.kind != asciiTokenOpen {
+			r.unread(t)
+			return n, nil
+		}
+	}
+}
+
+// array reads the values of an array, they are written as "*count { a: ... }"
+func (r *asciiReader) array() ([]float64, error) {
+	if t, err := r.next(); err != nil || t.kind != asciiTokenOpen {
+		return nil, errors.New("expected the values of an array in fbx file")
+	}
+	values := []float64{}
+	for {
+		t, err := r.next()
+		if err != nil {
+			return values, err
+		}
+		switch t.kind {
+		case asciiTokenClose:
+			return values, nil
+		case asciiTokenNumber:
+			f, err := strconv.ParseFloat(t.value, 64)
+			if err != nil {
+				return values, err
+			}
+			values = append(values, f)
+		case asciiTokenKey, asciiTokenComma:
+		default:
+			return values, errors.New("unexpected value in array in fbx file")
+		}
+	}
+}
+
+func asciiNumber(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,276 @@
+/******************************************************************************/
+/* binary.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package fbx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+type binaryReader struct {
+	data    []byte
+	pos     int
+	version int
+}
+
+func (r *binaryReader) take(count int) ([]byte, error) {
+	if count < 0 || r.pos+count > len(r.data) {
+		return nil, ErrInvalidFile
+	}
+	b := r.data[r.pos : r.pos+count]
+	r.pos += count
+	return b, nil
+}
+
+func (r *binaryReader) u8() (byte, error) {
+	b, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *binaryReader) u32() (uint32, error) {
+	b, err := r.take(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// offset reads the 64 bit offsets and counts of version 7.5 and later, and
+// the 32 bit ones of earlier versions
+func (r *binaryReader) offset() (uint64, error) {
+	if r.version >= 7500 {
+		b, err := r.take(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(b), nil
+	}
+	v, err := r.u32()
+	return uint64(v), err
+}
+
+func parseBinary(data []byte) (Document, error) {
+	const headerSize = 27
+	doc := Document{}
+	if len(data) < headerSize {
+		return doc, ErrInvalidFile
+	}
+	doc.Version = int(binary.LittleEndian.Uint32(data[23:27]))
+	r := binaryReader{data: data, pos: headerSize, version: doc.Version}
+	for {
+		n, err := r.node()
+		if err != nil {
+			return doc, err
+		} else if n == nil {
+			break
+		}
+		doc.Root.Children = append(doc.Root.Children, n)
+	}
+	return doc, nil
+}
+
+// node reads the next node, nil is returned for the empty record that ends
+// a list of nodes
+func (r *binaryReader) node() (*Node, error) {
+	if r.pos >= len(r.data) {
+		return nil, nil
+	}
+	start := r.pos
+	end, err := r.offset()
+	if err != nil {
+		return nil, err
+	}
+	count, err := r.offset()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.offset(); err != nil {
+		return nil, err
+	}
+	nameLen, err := r.u8()
+	if err != nil {
+		return nil, err
+	}
+	if end == 0 {
+		return nil, nil
+	} else if end <= uint64(start) || end > uint64(len(r.data)) {
+		return nil, ErrInvalidFile
+	}
+	name, err := r.take(int(nameLen))
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{Name: string(name)}
+	for range count {
+		p, err := r.property()
+		if err != nil {
+			return nil, err
+		}
+		n.Properties = append(n.Properties, p)
+	}
+	if uint64(r.pos) > end {
+		return nil, ErrInvalidFile
+	}
+	for uint64(r.pos) < end {
+		c, err := r.node()
+		if err != nil {
+			return nil, err
+		} else if c == nil {
+			break
+		}
+		n.Children = append(n.Children, c)
+	}
+	r.pos = int(end)
+	return n, nil
+}
+
+func (r *binaryReader) property() (any, error) {
+	kind, err := r.u8()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 'Y':
+		b, err := r.take(2)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.LittleEndian.Uint16(b))), nil
+	case 'C':
+		b, err := r.u8()
+		return b != 0, err
+	case 'I':
+		v, err := r.u32()
+		return int64(int32(v)), err
+	case 'L':
+		b, err := r.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.LittleEndian.Uint64(b)), nil
+	case 'F':
+		v, err := r.u32()
+		return float64(math.Float32frombits(v)), err
+	case 'D':
+		b, err := r.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case 'S', 'R':
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.take(int(size))
+		if err != nil {
+			return nil, err
+		}
+		if kind == 'S' {
+			return string(b), nil
+		}
+		return bytes.Clone(b), nil
+	case 'f', 'd', 'i', 'l', 'b':
+		return r.array(kind)
+	}
+	return nil, ErrInvalidFile
+}
+
+func (r *binaryReader) array(kind byte) (any, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	encoding, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.take(int(size))
+	if err != nil {
+		return nil, err
+	}
+	if encoding == 1 {
+		z, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer z.Close()
+		if b, err = io.ReadAll(z); err != nil {
+			return nil, err
+		}
+	}
+	elemSize := map[byte]int{'f': 4, 'd': 8, 'i': 4, 'l': 8, 'b': 1}[kind]
+	if len(b) < int(count)*elemSize {
+		return nil, ErrInvalidFile
+	}
+	switch kind {
+	case 'f', 'd':
+		out := make([]float64, count)
+		for i := range out {
+			if kind == 'f' {
+				out[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:])))
+			} else {
+				out[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+			}
+		}
+		return out, nil
+	default:
+		out := make([]int64, count)
+		for i := range out {
+			switch kind {
+			case 'i':
+				out[i] = int64(int32(binary.LittleEndian.Uint32(b[i*4:])))
+			case 'l':
+				out[i] = int64(binary.LittleEndian.Uint64(b[i*8:]))
+			case 'b':
+				out[i] = int64(b[i])
+			}
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,365 @@
+/******************************************************************************/
+/* fbx_test.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package fbx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"kaiju/matrix"
+	"kaiju/rendering/loaders/load_result"
+	"math"
+	"testing"
+)
+
+const testASCII = `; FBX 7.4.0 project file
+FBXHeaderExtension:  {
+	FBXHeaderVersion: 1003
+	FBXVersion: 7400
+}
+Objects:  {
+	Geometry: 10, "Geometry::Quad", "Mesh" {
+		Vertices: *12 {
+			a: 0,0,0,1,0,0,1,1,0,0,1,0
+		}
+		PolygonVertexIndex: *4 {
+			a: 0,1,2,-4
+		}
+		LayerElementNormal: 0 {
+			MappingInformationType: "ByVertice"
+			ReferenceInformationType: "Direct"
+			Normals: *12 {
+				a: 0,0,1,0,0,1,0,0,1,0,0,1
+			}
+		}
+		LayerElementUV: 0 {
+			MappingInformationType: "ByPolygonVertex"
+			ReferenceInformationType: "IndexToDirect"
+			UV: *8 {
+				a: 0,0,1,0,1,1,0,1
+			}
+			UVIndex: *4 {
+				a: 0,1,2,3
+			}
+		}
+	}
+	Model: 20, "Model::Quad", "Mesh" {
+		Properties70:  {
+			P: "Lcl Translation", "Lcl Translation", "", "A",1,2,3
+			P: "Lcl Rotation", "Lcl Rotation", "", "A",0,90,0
+		}
+		Shading: T
+	}
+	Model: 21, "Model::Bone", "LimbNode" {
+	}
+	Material: 30, "Material::Red", "" {
+		Properties70:  {
+			P: "DiffuseColor", "Color", "", "A",1,0,0
+		}
+	}
+	Texture: 40, "Texture::Albedo", "" {
+		RelativeFilename: "textures\albedo.png"
+	}
+	Deformer: 50, "Deformer::Skin", "Skin" {
+	}
+	Deformer: 51, "SubDeformer::Cluster", "Cluster" {
+		Indexes: *2 {
+			a: 0,1
+		}
+		Weights: *2 {
+			a: 1,0.5
+		}
+	}
+	AnimationStack: 60, "AnimStack::Wave", "" {
+	}
+	AnimationLayer: 61, "AnimLayer::Base", "" {
+	}
+	AnimationCurveNode: 62, "AnimCurveNode::T", "" {
+		Properties70:  {
+			P: "d|X", "Number", "", "A",1
+			P: "d|Y", "Number", "", "A",2
+			P: "d|Z", "Number", "", "A",3
+		}
+	}
+	AnimationCurve: 63, "AnimCurve::", "" {
+		KeyTime: *2 {
+			a: 0,46186158000
+		}
+		KeyValueFloat: *2 {
+			a: 0,10
+		}
+	}
+}
+Connections:  {
+	C: "OO",20,0
+	C: "OO",21,20
+	C: "OO",10,20
+	C: "OO",30,20
+	C: "OP",40,30, "DiffuseColor"
+	C: "OO",50,10
+	C: "OO",51,50
+	C: "OO",21,51
+	C: "OO",61,60
+	C: "OO",62,61
+	C: "OP",62,21, "Lcl Translation"
+	C: "OP",63,62, "d|X"
+}
+`
+
+func TestParseASCII(t *testing.T) {
+	doc, err := LoadFBX([]byte(testASCII))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != 7400 {
+		t.Errorf("expected version 7400 but got %d", doc.Version)
+	}
+	geom := doc.Root.Child("Objects").Child("Geometry")
+	if geom.Int(0) != 10 || geom.String(1) != "Geometry::Quad" {
+		t.Errorf("unexpected geometry properties %v", geom.Properties)
+	}
+	if v := geom.Child("Vertices").Floats(); len(v) != 12 || v[3] != 1 {
+		t.Errorf("unexpected vertices %v", v)
+	}
+	if v := geom.Child("PolygonVertexIndex").Ints(); len(v) != 4 || v[3] != -4 {
+		t.Errorf("unexpected polygon indexes %v", v)
+	}
+	model := doc.Root.Child("Objects").Child("Model")
+	if p, ok := model.Property70("Lcl Translation"); !ok || len(p) != 3 || toFloat(p[2]) != 3 {
+		t.Errorf("unexpected translation %v", p)
+	}
+	if model.Child("Shading").String(0) != "T" {
+		t.Error("expected bare words to be read as strings")
+	}
+}
+
+func TestParseOldVersion(t *testing.T) {
+	if _, err := LoadFBX([]byte("FBXHeaderExtension: {\n FBXVersion: 6100\n}\n")); err != ErrUnsupportedVersion {
+		t.Errorf("expected version 6 files to be unsupported, got %v", err)
+	}
+}
+
+func TestASCIIResult(t *testing.T) {
+	doc, err := LoadFBX([]byte(testASCII))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 2 || res.Nodes[0].Name != "Quad" || res.Nodes[1].Parent != 0 {
+		t.Fatalf("unexpected nodes %+v", res.Nodes)
+	}
+	if p := res.Nodes[0].Transform.Position(); !matrix.Vec3Approx(p, matrix.Vec3{1, 2, 3}) {
+		t.Errorf("unexpected node position %v", p)
+	}
+	if r := res.Nodes[0].Transform.Rotation(); !matrix.Vec3ApproxTo(r, matrix.Vec3{0, 90, 0}, 0.01) {
+		t.Errorf("unexpected node rotation %v", r)
+	}
+	if len(res.Meshes) != 1 {
+		t.Fatalf("expected 1 mesh but got %d", len(res.Meshes))
+	}
+	m := res.Meshes[0]
+	if len(m.Verts) != 4 || len(m.Indexes) != 6 {
+		t.Errorf("expected the quad to be split into 2 triangles, got %d verts and %d indexes", len(m.Verts), len(m.Indexes))
+	}
+	if m.Node != 0 || m.Material != 0 {
+		t.Errorf("expected the mesh to be at node 0 with material 0, got %d and %d", m.Node, m.Material)
+	}
+	if uv := m.Verts[0].UV0; uv.Y() != 1 {
+		t.Errorf("expected the UVs to be flipped vertically, got %v", uv)
+	}
+	if w := m.Verts[0].JointWeights; w.X() != 1 {
+		t.Errorf("expected the first vertex to be fully weighted to the bone, got %v", w)
+	}
+	if len(res.Joints) != 1 || res.Joints[0].Id != 1 {
+		t.Errorf("expected the bone to be the only joint, got %+v", res.Joints)
+	}
+	if len(res.Images) != 1 || res.Images[0].URI != "textures\\albedo.png" && res.Images[0].URI != "textures/albedo.png" {
+		t.Errorf("unexpected images %+v", res.Images)
+	}
+	if res.MaterialImages[0] != 0 || res.Materials[0].Parameters["color"][1] != 0 {
+		t.Errorf("unexpected material %+v", res.Materials[0])
+	}
+	if len(res.Animations) != 1 || len(res.Animations[0].Frames) != 2 {
+		t.Fatalf("unexpected animations %+v", res.Animations)
+	}
+	anim := res.Animations[0]
+	if anim.Name != "Wave" || anim.Frames[0].Time != 1 {
+		t.Errorf("unexpected animation %+v", anim)
+	}
+	last := anim.Frames[1].Bones[0]
+	if last.NodeIndex != 1 || last.PathType != load_result.AnimPathTranslation {
+		t.Errorf("unexpected bone %+v", last)
+	}
+	if last.Data[0] != 10 || last.Data[1] != 2 || last.Data[2] != 3 {
+		t.Errorf("expected the missing curves to use the defaults, got %v", last.Data)
+	}
+}
+
+type testNode struct {
+	name     string
+	props    []any
+	children []testNode
+}
+
+func writeTestProperty(w *bytes.Buffer, p any) {
+	switch v := p.(type) {
+	case int32:
+		w.WriteByte('I')
+		binary.Write(w, binary.LittleEndian, v)
+	case int64:
+		w.WriteByte('L')
+		binary.Write(w, binary.LittleEndian, v)
+	case float64:
+		w.WriteByte('D')
+		binary.Write(w, binary.LittleEndian, v)
+	case string:
+		w.WriteByte('S')
+		binary.Write(w, binary.LittleEndian, uint32(len(v)))
+		w.WriteString(v)
+	case []int32:
+		w.WriteByte('i')
+		binary.Write(w, binary.LittleEndian, uint32(len(v)))
+		binary.Write(w, binary.LittleEndian, uint32(0))
+		binary.Write(w, binary.LittleEndian, uint32(len(v)*4))
+		binary.Write(w, binary.LittleEndian, v)
+	case []float64:
+		raw := bytes.Buffer{}
+		binary.Write(&raw, binary.LittleEndian, v)
+		z := bytes.Buffer{}
+		zw := zlib.NewWriter(&z)
+		zw.Write(raw.Bytes())
+		zw.Close()
+		w.WriteByte('d')
+		binary.Write(w, binary.LittleEndian, uint32(len(v)))
+		binary.Write(w, binary.LittleEndian, uint32(1))
+		binary.Write(w, binary.LittleEndian, uint32(z.Len()))
+		w.Write(z.Bytes())
+	}
+}
+
+// writeTestNode writes the node using the 32 bit offsets of the versions
+// before 7.5
+func writeTestNode(w *bytes.Buffer, n testNode) {
+	start := w.Len()
+	w.Write(make([]byte, 12))
+	w.WriteByte(byte(len(n.name)))
+	w.WriteString(n.name)
+	propStart := w.Len()
+	for _, p := range n.props {
+		writeTestProperty(w, p)
+	}
+	propLen := w.Len() - propStart
+	for _, c := range n.children {
+		writeTestNode(w, c)
+	}
+	if len(n.children) > 0 {
+		w.Write(make([]byte, 13))
+	}
+	data := w.Bytes()
+	binary.LittleEndian.PutUint32(data[start:], uint32(w.Len()))
+	binary.LittleEndian.PutUint32(data[start+4:], uint32(len(n.props)))
+	binary.LittleEndian.PutUint32(data[start+8:], uint32(propLen))
+}
+
+func TestParseBinary(t *testing.T) {
+	w := bytes.Buffer{}
+	w.Write(binaryMagic)
+	w.Write([]byte{0x1A, 0x00})
+	binary.Write(&w, binary.LittleEndian, uint32(7400))
+	writeTestNode(&w, testNode{name: "Objects", children: []testNode{{
+		name:  "Geometry",
+		props: []any{int64(10), "Quad\x00\x01Geometry", "Mesh"},
+		children: []testNode{
+			{name: "Vertices", props: []any{[]float64{0, 0, 0, 1, 0, 0, 1, 1, 0}}},
+			{name: "PolygonVertexIndex", props: []any{[]int32{0, 1, -3}}},
+		},
+	}, {
+		name:  "Model",
+		props: []any{int64(20), "Quad\x00\x01Model", "Mesh"},
+	}}})
+	writeTestNode(&w, testNode{name: "Connections", children: []testNode{
+		{name: "C", props: []any{"OO", int64(10), int64(20)}},
+		{name: "C", props: []any{"OO", int64(20), int64(0)}},
+	}})
+	w.Write(make([]byte, 13))
+	doc, err := LoadFBX(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != 7400 {
+		t.Errorf("expected version 7400 but got %d", doc.Version)
+	}
+	geom := doc.Root.Child("Objects").Child("Geometry")
+	if v := geom.Child("Vertices").Floats(); len(v) != 9 || v[6] != 1 {
+		t.Errorf("expected the compressed vertices to be read, got %v", v)
+	}
+	res, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Meshes) != 1 || res.Meshes[0].MeshName != "Quad" || len(res.Meshes[0].Indexes) != 3 {
+		t.Errorf("unexpected meshes %+v", res.Meshes)
+	}
+	if _, err := LoadFBX(w.Bytes()[:40]); err == nil {
+		t.Error("expected a truncated file to fail")
+	}
+	backwards := bytes.Clone(w.Bytes())
+	binary.LittleEndian.PutUint32(backwards[27:], 27)
+	if _, err := LoadFBX(backwards); err == nil {
+		t.Error("expected a node that ends before it starts to fail")
+	}
+	short := bytes.Clone(w.Bytes())
+	binary.LittleEndian.PutUint32(short[27:], 40)
+	if _, err := LoadFBX(short); err == nil {
+		t.Error("expected a node that ends within its own header to fail")
+	}
+}
+
+func TestTopInfluences(t *testing.T) {
+	ids, weights := load_result.TopInfluences(
+		[]int32{1, 2, 3, 4, 5}, []matrix.Float{0.1, 0.4, 0.2, 0.2, 0.1})
+	if ids[0] == 1 && ids[1] == 1 {
+		t.Errorf("unexpected joints %v", ids)
+	}
+	if total := weights.X() + weights.Y() + weights.Z() + weights.W(); math.Abs(float64(total-1)) > 0.0001 {
+		t.Errorf("expected the weights to be normalized, got %v", weights)
+	}
+}
@@ -0,0 +1,211 @@
+/******************************************************************************/
+/* node.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package fbx
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Node is a single record of an FBX file. Binary and ASCII files are read
+// into the same tree of nodes. Integers are read as int64, floating point
+// numbers as float64, and arrays as []int64 or []float64.
+type Node struct {
+	Name       string
+	Properties []any
+	Children   []*Node
+}
+
+// Document is the tree of nodes of an FBX file, the top level nodes of the
+// file are the children of the root
+type Document struct {
+	Version int
+	Root    Node
+}
+
+var binaryMagic = []byte("Kaydara FBX Binary  \x00")
+
+var (
+	ErrUnsupportedVersion = errors.New("FBX files before version 7 are not supported")
+	ErrInvalidFile        = errors.New("invalid fbx file")
+)
+
+// LoadFBX reads the nodes of a binary or ASCII FBX file
+func LoadFBX(data []byte) (Document, error) {
+	var doc Document
+	var err error
+	if bytes.HasPrefix(data, binaryMagic) {
+		doc, err = parseBinary(data)
+	} else {
+		doc, err = parseASCII(string(data))
+	}
+	if err != nil {
+		return doc, err
+	}
+	if doc.Version < 7000 {
+		return doc, ErrUnsupportedVersion
+	}
+	return doc, nil
+}
+
+// Child returns the first child with the name, nil if there is none
+func (n *Node) Child(name string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns all of the children with the name
+func (n *Node) ChildrenNamed(name string) []*Node {
+	if n == nil {
+		return nil
+	}
+	found := []*Node{}
+	for _, c := range n.Children {
+		if c.Name == name {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+func (n *Node) prop(index int) any {
+	if n == nil || index < 0 || index >= len(n.Properties) {
+		return nil
+	}
+	return n.Properties[index]
+}
+
+func (n *Node) String(index int) string {
+	s, _ := n.prop(index).(string)
+	return s
+}
+
+func (n *Node) Int(index int) int64 {
+	return toInt(n.prop(index))
+}
+
+func (n *Node) Float(index int) float64 {
+	return toFloat(n.prop(index))
+}
+
+// Bytes returns the raw data of the property, ASCII files store raw data as
+// base64 strings which are returned as is
+func (n *Node) Bytes(index int) []byte {
+	switch v := n.prop(index).(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+// Floats returns the first property of the node as an array of floats
+func (n *Node) Floats() []float64 {
+	switch v := n.prop(0).(type) {
+	case []float64:
+		return v
+	case []int64:
+		out := make([]float64, len(v))
+		for i := range v {
+			out[i] = float64(v[i])
+		}
+		return out
+	}
+	return nil
+}
+
+// Ints returns the first property of the node as an array of integers
+func (n *Node) Ints() []int64 {
+	switch v := n.prop(0).(type) {
+	case []int64:
+		return v
+	case []float64:
+		out := make([]int64, len(v))
+		for i := range v {
+			out[i] = int64(v[i])
+		}
+		return out
+	}
+	return nil
+}
+
+// Property70 returns the values of the named property in the Properties70
+// child of the node, the name, type, label, and flags are skipped
+func (n *Node) Property70(name string) ([]any, bool) {
+	for _, p := range n.Child("Properties70").ChildrenNamed("P") {
+		if p.String(0) == name {
+			if len(p.Properties) < 4 {
+				return nil, true
+			}
+			return p.Properties[4:], true
+		}
+	}
+	return nil, false
+}
+
+func toInt(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	case bool:
+		if n {
+			return 1
+		}
+	}
+	return 0
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
@@ -0,0 +1,582 @@
+/******************************************************************************/
+/* scene.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package fbx
+
+import (
+	"encoding/base64"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders/load_result"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ktimePerSecond is the number of FBX time units in a second
+const ktimePerSecond = 46186158000
+
+type connection struct {
+	child    int64
+	parent   int64
+	property string
+}
+
+type object struct {
+	id   int64
+	name string
+	kind string
+	node *Node
+}
+
+type scene struct {
+	objects  map[int64]*object
+	ordered  []*object
+	parents  map[int64][]connection
+	children map[int64][]connection
+	models   map[int64]int
+	res      load_result.Result
+}
+
+type influence struct {
+	joint  int32
+	weight float64
+}
+
+// objectName removes the class from the name of an object, binary files
+// write them as "name\x00\x01class" and ASCII files as "class::name"
+func objectName(s string) string {
+	if name, _, ok := strings.Cut(s, "\x00\x01"); ok {
+		return name
+	}
+	if _, name, ok := strings.Cut(s, "::"); ok {
+		return name
+	}
+	return s
+}
+
+// Result converts the scene of the file into the nodes, meshes, materials,
+// skin, and animations that the engine loads. The models of the file become
+// the nodes, every model with geometry adds a mesh at its node.
+func (d *Document) Result() (load_result.Result, error) {
+	s := scene{
+		objects:  make(map[int64]*object),
+		parents:  make(map[int64][]connection),
+		children: make(map[int64][]connection),
+		models:   make(map[int64]int),
+		res:      load_result.NewResult(),
+	}
+	objects := d.Root.Child("Objects")
+	if objects == nil {
+		return s.res, ErrInvalidFile
+	}
+	for _, n := range objects.Children {
+		o := &object{id: n.Int(0), name: objectName(n.String(1)), kind: n.String(2), node: n}
+		s.objects[o.id] = o
+		s.ordered = append(s.ordered, o)
+	}
+	for _, c := range d.Root.Child("Connections").ChildrenNamed("C") {
+		conn := connection{child: c.Int(1), parent: c.Int(2), property: c.String(3)}
+		s.parents[conn.child] = append(s.parents[conn.child], conn)
+		s.children[conn.parent] = append(s.children[conn.parent], conn)
+	}
+	s.readNodes()
+	s.readMaterials()
+	s.readMeshes()
+	s.readAnimations()
+	return s.res, nil
+}
+
+func (s *scene) childrenOf(id int64, class string) []*object {
+	found := []*object{}
+	for _, c := range s.children[id] {
+		if o, ok := s.objects[c.child]; ok && o.node.Name == class {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+func (s *scene) parentsOf(id int64, class string) []*object {
+	found := []*object{}
+	for _, c := range s.parents[id] {
+		if o, ok := s.objects[c.parent]; ok && o.node.Name == class {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+func vec3Property(n *Node, name string, fallback matrix.Vec3) matrix.Vec3 {
+	v, ok := n.Property70(name)
+	if !ok || len(v) < 3 {
+		return fallback
+	}
+	return matrix.Vec3{
+		matrix.Float(toFloat(v[0])),
+		matrix.Float(toFloat(v[1])),
+		matrix.Float(toFloat(v[2])),
+	}
+}
+
+// eulerXYZ creates the rotation matrix of FBX euler angles, the rotations
+// are applied in the order of X, then Y, then Z
+func eulerXYZ(v matrix.Vec3) matrix.Mat4 {
+	x := matrix.QuaternionFromEuler(matrix.Vec3{v.X(), 0, 0}).ToMat4()
+	y := matrix.QuaternionFromEuler(matrix.Vec3{0, v.Y(), 0}).ToMat4()
+	z := matrix.QuaternionFromEuler(matrix.Vec3{0, 0, v.Z()}).ToMat4()
+	return matrix.Mat4Multiply(matrix.Mat4Multiply(x, y), z)
+}
+
+// rotation returns the local rotation of the model as euler angles of the
+// engine, the pre-rotation of the model is included
+func rotation(model *Node, lcl matrix.Vec3) matrix.Vec3 {
+	m := eulerXYZ(lcl)
+	if pre, ok := model.Property70("PreRotation"); ok && len(pre) >= 3 {
+		m = matrix.Mat4Multiply(m, eulerXYZ(vec3Property(model, "PreRotation", matrix.Vec3Zero())))
+	}
+	_, r, _ := m.Decompose()
+	return r
+}
+
+func (s *scene) readNodes() {
+	for _, o := range s.ordered {
+		if o.node.Name != "Model" {
+			continue
+		}
+		s.models[o.id] = len(s.res.Nodes)
+		t := matrix.NewTransform()
+		t.Identifier = uint8(len(s.res.Nodes))
+		t.SetPosition(vec3Property(o.node, "Lcl Translation", matrix.Vec3Zero()))
+		t.SetRotation(rotation(o.node, vec3Property(o.node, "Lcl Rotation", matrix.Vec3Zero())))
+		t.SetScale(vec3Property(o.node, "Lcl Scaling", matrix.Vec3One()))
+		s.res.Nodes = append(s.res.Nodes, load_result.Node{
+			Name:      o.name,
+			Parent:    -1,
+			Transform: t,
+		})
+	}
+	for id, idx := range s.models {
+		for _, p := range s.parentsOf(id, "Model") {
+			s.res.Nodes[idx].Parent = s.models[p.id]
+		}
+	}
+}
+
+func (s *scene) readMaterials() {
+	textures := map[int64]int{}
+	for _, o := range s.ordered {
+		if o.node.Name != "Texture" {
+			continue
+		}
+		textures[o.id] = len(s.res.Images)
+		img := load_result.Image{Name: o.name}
+		for _, v := range s.childrenOf(o.id, "Video") {
+			content := v.node.Child("Content")
+			if data := content.Bytes(0); len(data) > 0 {
+				if _, ok := content.prop(0).(string); ok {
+					if decoded, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+						data = decoded
+					}
+				}
+				img.Data = data
+			}
+		}
+		if len(img.Data) == 0 {
+			uri := o.node.Child("RelativeFilename").String(0)
+			if uri == "" {
+				uri = filepath.Base(o.node.Child("FileName").String(0))
+			}
+			img.URI = filepath.ToSlash(uri)
+		}
+		s.res.Images = append(s.res.Images, img)
+	}
+	for _, o := range s.ordered {
+		if o.node.Name != "Material" {
+			continue
+		}
+		color := vec3Property(o.node, "DiffuseColor", matrix.Vec3One())
+		alpha := matrix.Float(1)
+		if v, ok := o.node.Property70("Opacity"); ok && len(v) > 0 {
+			alpha = matrix.Float(toFloat(v[0]))
+		}
+		image := -1
+		for _, c := range s.children[o.id] {
+			if t, ok := textures[c.child]; ok && (image < 0 || c.property == "DiffuseColor") {
+				image = t
+			}
+		}
+		texture := ""
+		if image >= 0 {
+			texture = s.res.Images[image].URI
+		}
+		def := load_result.LitMaterial(matrix.Color{color.X(), color.Y(), color.Z(), alpha}, texture)
+		def.UseBlending = alpha < 1
+		s.res.Materials = append(s.res.Materials, def)
+		s.res.MaterialImages = append(s.res.MaterialImages, image)
+	}
+}
+
+func (s *scene) materialIndex(o *object) int {
+	idx := 0
+	for _, m := range s.ordered {
+		if m.node.Name != "Material" {
+			continue
+		}
+		for _, c := range s.parents[m.id] {
+			if c.parent == o.id {
+				return idx
+			}
+		}
+		idx++
+	}
+	return -1
+}
+
+func (s *scene) readMeshes() {
+	for _, o := range s.ordered {
+		if o.node.Name != "Model" {
+			continue
+		}
+		for _, g := range s.childrenOf(o.id, "Geometry") {
+			if g.kind != "Mesh" {
+				continue
+			}
+			influences := s.readSkin(g)
+			verts, indexes := readGeometry(g.node, influences)
+			if len(indexes) == 0 {
+				continue
+			}
+			s.res.Add(o.name, g.name, verts, indexes, nil)
+			mesh := &s.res.Meshes[len(s.res.Meshes)-1]
+			mesh.Node = s.models[o.id]
+			mesh.Material = s.materialIndex(o)
+		}
+	}
+}
+
+// readSkin adds the bones of the skin of the geometry as joints and returns
+// the bones that influence each of the control points of the geometry
+func (s *scene) readSkin(geometry *object) map[int][]influence {
+	influences := map[int][]influence{}
+	for _, skin := range s.childrenOf(geometry.id, "Deformer") {
+		if skin.kind != "Skin" {
+			continue
+		}
+		for _, cluster := range s.childrenOf(skin.id, "Deformer") {
+			bones := s.childrenOf(cluster.id, "Model")
+			if len(bones) == 0 {
+				continue
+			}
+			joint := s.joint(bones[0], cluster.node)
+			indexes := cluster.node.Child("Indexes").Ints()
+			weights := cluster.node.Child("Weights").Floats()
+			for i := range min(len(indexes), len(weights)) {
+				cp := int(indexes[i])
+				influences[cp] = append(influences[cp], influence{joint, weights[i]})
+			}
+		}
+	}
+	return influences
+}
+
+// joint returns the index of the joint of the bone, the joint is added with
+// the inverse bind matrix of the cluster if it was not added yet
+func (s *scene) joint(bone *object, cluster *Node) int32 {
+	node := int32(s.models[bone.id])
+	for i := range s.res.Joints {
+		if s.res.Joints[i].Id == node {
+			return int32(i)
+		}
+	}
+	link := mat4(cluster.Child("TransformLink").Floats())
+	link.Inverse()
+	bind := matrix.Mat4Multiply(mat4(cluster.Child("Transform").Floats()), link)
+	s.res.Joints = append(s.res.Joints, load_result.Joint{Id: node, Skin: bind})
+	return int32(len(s.res.Joints) - 1)
+}
+
+func mat4(v []float64) matrix.Mat4 {
+	if len(v) < 16 {
+		return matrix.Mat4Identity()
+	}
+	m := matrix.Mat4{}
+	for i := range m {
+		m[i] = matrix.Float(v[i])
+	}
+	return m
+}
+
+// layerElement is a per vertex attribute of a geometry, like the normals or
+// the UVs, and how it maps onto the polygons
+type layerElement struct {
+	mapping string
+	values  []float64
+	indexes []int64
+	stride  int
+}
+
+func readLayerElement(geometry *Node, element, values, indexes string, stride int) *layerElement {
+	n := geometry.Child(element)
+	if n == nil {
+		return nil
+	}
+	l := &layerElement{
+		mapping: n.Child("MappingInformationType").String(0),
+		values:  n.Child(values).Floats(),
+		stride:  stride,
+	}
+	if strings.HasPrefix(n.Child("ReferenceInformationType").String(0), "Index") {
+		l.indexes = n.Child(indexes).Ints()
+	}
+	return l
+}
+
+func (l *layerElement) at(polygonVertex, controlPoint, polygon int) []float64 {
+	if l == nil {
+		return nil
+	}
+	idx := polygonVertex
+	switch l.mapping {
+	case "ByVertex", "ByVertice", "ByControlPoint":
+		idx = controlPoint
+	case "ByPolygon":
+		idx = polygon
+	case "AllSame":
+		idx = 0
+	}
+	if l.indexes != nil {
+		if idx >= len(l.indexes) {
+			return nil
+		}
+		idx = int(l.indexes[idx])
+	}
+	if idx < 0 || (idx+1)*l.stride > len(l.values) {
+		return nil
+	}
+	return l.values[idx*l.stride : (idx+1)*l.stride]
+}
+
+type vertexKey struct {
+	controlPoint int
+	normal       [3]float64
+	uv           [2]float64
+}
+
+// readGeometry creates the vertices and the triangle indexes of the polygons
+// of the geometry, polygons with more than 3 sides are split into a fan
+func readGeometry(g *Node, influences map[int][]influence) ([]rendering.Vertex, []uint32) {
+	points := g.Child("Vertices").Floats()
+	polygons := g.Child("PolygonVertexIndex").Ints()
+	normals := readLayerElement(g, "LayerElementNormal", "Normals", "NormalsIndex", 3)
+	uvs := readLayerElement(g, "LayerElementUV", "UV", "UVIndex", 2)
+	verts := []rendering.Vertex{}
+	indexes := []uint32{}
+	lookup := map[vertexKey]uint32{}
+	polygon := []uint32{}
+	polygonIndex := 0
+	for pv, raw := range polygons {
+		cp := int(raw)
+		end := raw < 0
+		if end {
+			cp = int(^raw)
+		}
+		if (cp+1)*3 > len(points) {
+			return nil, nil
+		}
+		key := vertexKey{controlPoint: cp}
+		copy(key.normal[:], normals.at(pv, cp, polygonIndex))
+		copy(key.uv[:], uvs.at(pv, cp, polygonIndex))
+		idx, ok := lookup[key]
+		if !ok {
+			idx = uint32(len(verts))
+			lookup[key] = idx
+			verts = append(verts, newVertex(points[cp*3:cp*3+3], key, influences[cp]))
+		}
+		polygon = append(polygon, idx)
+		if end {
+			for i := 1; i+1 < len(polygon); i++ {
+				indexes = append(indexes, polygon[0], polygon[i], polygon[i+1])
+			}
+			polygon = polygon[:0]
+			polygonIndex++
+		}
+	}
+	return verts, indexes
+}
+
+func newVertex(point []float64, key vertexKey, influences []influence) rendering.Vertex {
+	v := rendering.Vertex{
+		Position: matrix.Vec3{matrix.Float(point[0]), matrix.Float(point[1]), matrix.Float(point[2])},
+		Normal: matrix.Vec3{
+			matrix.Float(key.normal[0]),
+			matrix.Float(key.normal[1]),
+			matrix.Float(key.normal[2]),
+		},
+		// FBX UVs start at the bottom left of the image
+		UV0:   matrix.Vec2{matrix.Float(key.uv[0]), 1 - matrix.Float(key.uv[1])},
+		Color: matrix.ColorWhite(),
+	}
+	v.MorphTarget = v.Position
+	v.JointIds, v.JointWeights = load_result.TopInfluences(jointIds(influences), jointWeights(influences))
+	return v
+}
+
+func jointIds(influences []influence) []int32 {
+	ids := make([]int32, len(influences))
+	for i := range influences {
+		ids[i] = influences[i].joint
+	}
+	return ids
+}
+
+func jointWeights(influences []influence) []matrix.Float {
+	weights := make([]matrix.Float, len(influences))
+	for i := range influences {
+		weights[i] = matrix.Float(influences[i].weight)
+	}
+	return weights
+}
+
+type curve struct {
+	times  []int64
+	values []float64
+}
+
+func (c *curve) sample(time int64, fallback float64) float64 {
+	if c == nil || len(c.times) == 0 || len(c.values) < len(c.times) {
+		return fallback
+	}
+	i, found := slices.BinarySearch(c.times, time)
+	if found {
+		return c.values[i]
+	} else if i == 0 {
+		return c.values[0]
+	} else if i >= len(c.times) {
+		return c.values[len(c.times)-1]
+	}
+	t := float64(time-c.times[i-1]) / float64(c.times[i]-c.times[i-1])
+	return c.values[i-1] + (c.values[i]-c.values[i-1])*t
+}
+
+// readAnimations adds an animation for each of the animation stacks, the
+// curves of each component are sampled at every key time of the curve node
+// so that each key frame has the whole translation, rotation, or scale
+func (s *scene) readAnimations() {
+	for _, stack := range s.ordered {
+		if stack.node.Name != "AnimationStack" {
+			continue
+		}
+		anim := load_result.Animation{Name: stack.name, Frames: make([]load_result.AnimKeyFrame, 0)}
+		for _, layer := range s.childrenOf(stack.id, "AnimationLayer") {
+			for _, cn := range s.childrenOf(layer.id, "AnimationCurveNode") {
+				s.readCurveNode(&anim, cn)
+			}
+		}
+		if len(anim.Frames) == 0 {
+			continue
+		}
+		anim.ToRelativeTimes()
+		s.res.Animations = append(s.res.Animations, anim)
+	}
+}
+
+func (s *scene) readCurveNode(anim *load_result.Animation, cn *object) {
+	var model *object
+	path := load_result.AnimPathInvalid
+	for _, c := range s.parents[cn.id] {
+		o, ok := s.objects[c.parent]
+		if !ok || o.node.Name != "Model" {
+			continue
+		}
+		model = o
+		switch c.property {
+		case "Lcl Translation":
+			path = load_result.AnimPathTranslation
+		case "Lcl Rotation":
+			path = load_result.AnimPathRotation
+		case "Lcl Scaling":
+			path = load_result.AnimPathScale
+		}
+	}
+	if model == nil || path == load_result.AnimPathInvalid {
+		return
+	}
+	curves := [3]*curve{}
+	defaults := [3]float64{}
+	times := []int64{}
+	for i, axis := range []string{"X", "Y", "Z"} {
+		if v, ok := cn.node.Property70("d|" + axis); ok && len(v) > 0 {
+			defaults[i] = toFloat(v[0])
+		}
+	}
+	for _, c := range s.children[cn.id] {
+		o, ok := s.objects[c.child]
+		if !ok || o.node.Name != "AnimationCurve" {
+			continue
+		}
+		axis := strings.Index("XYZ", strings.TrimPrefix(c.property, "d|"))
+		if axis < 0 || len(c.property) != 3 {
+			continue
+		}
+		curves[axis] = &curve{
+			times:  o.node.Child("KeyTime").Ints(),
+			values: o.node.Child("KeyValueFloat").Floats(),
+		}
+		times = append(times, curves[axis].times...)
+	}
+	slices.Sort(times)
+	times = slices.Compact(times)
+	for _, time := range times {
+		v := matrix.Vec3{
+			matrix.Float(curves[0].sample(time, defaults[0])),
+			matrix.Float(curves[1].sample(time, defaults[1])),
+			matrix.Float(curves[2].sample(time, defaults[2])),
+		}
+		bone := load_result.AnimBone{
+			NodeIndex:     s.models[model.id],
+			PathType:      path,
+			Interpolation: load_result.AnimInterpolateLinear,
+		}
+		if path == load_result.AnimPathRotation {
+			bone.Data = matrix.QuaternionFromEuler(rotation(model.node, v))
+		} else {
+			bone.Data = v.AsAligned16()
+		}
+		anim.AddBone(float32(float64(time)/ktimePerSecond), bone)
+	}
+}
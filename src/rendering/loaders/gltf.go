@@ -38,6 +38,7 @@
 package loaders
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"kaiju/assets"
@@ -47,7 +48,6 @@ import (
 	"kaiju/rendering/loaders/gltf"
 	"kaiju/rendering/loaders/load_result"
 	"path/filepath"
-	"slices"
 	"strings"
 	"unsafe"
 )
@@ -161,6 +161,15 @@ func GLTF(path string, assetDB *assets.Database) (load_result.Result, error) {
 func gltfParse(doc *fullGLTF) (load_result.Result, error) {
 	res := load_result.NewResult()
 	res.Nodes = make([]load_result.Node, len(doc.glTF.Nodes))
+	for i := range doc.glTF.Materials {
+		res.Materials = append(res.Materials, doc.glTF.MaterialDef(i))
+		res.MaterialImages = append(res.MaterialImages, doc.glTF.BaseColorImage(i))
+	}
+	images, err := gltfReadImages(doc)
+	if err != nil {
+		return res, err
+	}
+	res.Images = images
 	for i := range res.Nodes {
 		res.Nodes[i].Parent = -1
 	}
@@ -212,6 +221,10 @@ func gltfParse(doc *fullGLTF) (load_result.Result, error) {
 		} else {
 			textures := gltfReadMeshTextures(m, &doc.glTF)
 			res.Add(n.Name, m.Name, verts, indices, klib.MapValues(textures))
+			res.Meshes[len(res.Meshes)-1].Node = i
+			if mat := m.Primitives[0].Material; mat != nil && int(*mat) < len(res.Materials) {
+				res.Meshes[len(res.Meshes)-1].Material = int(*mat)
+			}
 		}
 	}
 	res.Animations = gltfReadAnimations(doc)
@@ -463,24 +476,57 @@ func gltfReadMeshTextures(mesh *gltf.Mesh, doc *gltf.GLTF) map[string]string {
 		return textures
 	}
 	mat := doc.Materials[*mesh.Primitives[0].Material]
-	if mat.PBRMetallicRoughness.BaseColorTexture != nil {
-		textures["baseColor"] = doc.Images[mat.PBRMetallicRoughness.BaseColorTexture.Index].URI
+	if uri := doc.TextureURI(mat.PBRMetallicRoughness.BaseColorTexture); uri != "" {
+		textures["baseColor"] = uri
 	}
-	if mat.PBRMetallicRoughness.MetallicRoughnessTexture != nil {
-		textures["metallicRoughness"] = doc.Images[mat.PBRMetallicRoughness.MetallicRoughnessTexture.Index].URI
+	if uri := doc.TextureURI(mat.PBRMetallicRoughness.MetallicRoughnessTexture); uri != "" {
+		textures["metallicRoughness"] = uri
 	}
-	if mat.NormalTexture != nil {
-		textures["normal"] = doc.Images[mat.NormalTexture.Index].URI
+	if uri := doc.TextureURI(mat.NormalTexture); uri != "" {
+		textures["normal"] = uri
 	}
-	if mat.OcclusionTexture != nil {
-		textures["occlusion"] = doc.Images[mat.OcclusionTexture.Index].URI
+	if uri := doc.TextureURI(mat.OcclusionTexture); uri != "" {
+		textures["occlusion"] = uri
 	}
-	if mat.EmissiveTexture != nil {
-		textures["emissive"] = doc.Images[mat.EmissiveTexture.Index].URI
+	if uri := doc.TextureURI(mat.EmissiveTexture); uri != "" {
+		textures["emissive"] = uri
 	}
 	return textures
 }
 
+// gltfReadImages reads the images of the file, the data of the images that
+// are embedded in a buffer or as a base64 data URI is read out of the file
+func gltfReadImages(doc *fullGLTF) ([]load_result.Image, error) {
+	const dataPrefix = "data:"
+	images := make([]load_result.Image, len(doc.glTF.Images))
+	for i := range doc.glTF.Images {
+		img := &doc.glTF.Images[i]
+		images[i] = load_result.Image{Name: img.Name, MimeType: img.MimeType}
+		if img.BufferView != nil {
+			if int(*img.BufferView) >= len(doc.glTF.BufferViews) {
+				return images, errors.New("invalid image buffer view index")
+			}
+			images[i].Data = gltfViewBytes(doc, &doc.glTF.BufferViews[*img.BufferView])
+		} else if strings.HasPrefix(img.URI, dataPrefix) {
+			meta, encoded, ok := strings.Cut(strings.TrimPrefix(img.URI, dataPrefix), ",")
+			if !ok || !strings.HasSuffix(meta, ";base64") {
+				return images, errors.New("image data URIs must be base64 encoded")
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return images, err
+			}
+			images[i].Data = data
+			if images[i].MimeType == "" {
+				images[i].MimeType = strings.TrimSuffix(meta, ";base64")
+			}
+		} else {
+			images[i].URI = img.URI
+		}
+	}
+	return images, nil
+}
+
 func gltfReadAnimations(doc *fullGLTF) []load_result.Animation {
 	anims := make([]load_result.Animation, len(doc.glTF.Animations))
 	for i := range doc.glTF.Animations {
@@ -501,20 +547,6 @@ func gltfReadAnimations(doc *fullGLTF) []load_result.Animation {
 			fIn := klib.ByteSliceToFloat32Slice(in)
 			fOut := klib.ByteSliceToFloat32Slice(out)
 			for k := 0; k < len(fIn); k++ {
-				var key *load_result.AnimKeyFrame = nil
-				for l := range anims[i].Frames {
-					if matrix.Approx(anims[i].Frames[l].Time, fIn[k]) {
-						key = &anims[i].Frames[l]
-						break
-					}
-				}
-				if key == nil {
-					anims[i].Frames = append(anims[i].Frames, load_result.AnimKeyFrame{
-						Bones: make([]load_result.AnimBone, 0),
-						Time:  fIn[k],
-					})
-					key = &anims[i].Frames[len(anims[i].Frames)-1]
-				}
 				bone := load_result.AnimBone{
 					PathType:      c.Target.Path(),
 					Interpolation: sampler.Interpolation(),
@@ -534,18 +566,10 @@ func gltfReadAnimations(doc *fullGLTF) []load_result.Animation {
 				case load_result.AnimPathWeights:
 					// TODO:  Implement reading weights data
 				}
-				key.Bones = append(key.Bones, bone)
+				anims[i].AddBone(fIn[k], bone)
 			}
 		}
-		slices.SortFunc(anims[i].Frames, func(a, b load_result.AnimKeyFrame) int {
-			return int((a.Time - b.Time) * 10000)
-		})
-		// Convert frame from absolute time to relative time length
-		for j := range anims[i].Frames[:len(anims[i].Frames)-1] {
-			anims[i].Frames[j].Time = anims[i].Frames[j+1].Time - anims[i].Frames[j].Time
-		}
-		// Last frame should be a goal and not have time?
-		anims[i].Frames[len(anims[i].Frames)-1].Time = 0.0
+		anims[i].ToRelativeTimes()
 	}
 	return anims
 }
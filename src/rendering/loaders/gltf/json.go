@@ -124,14 +124,15 @@ type TextureId struct {
 type PBRMetallicRoughness struct {
 	BaseColorTexture         *TextureId    `json:"baseColorTexture"`
 	MetallicRoughnessTexture *TextureId    `json:"metallicRoughnessTexture"`
-	MetallicFactor           float32       `json:"metallicFactor"`
-	RoughnessFactor          float32       `json:"roughnessFactor"`
+	MetallicFactor           *float32      `json:"metallicFactor"`
+	RoughnessFactor          *float32      `json:"roughnessFactor"`
 	BaseColorFactor          *matrix.Color `json:"baseColorFactor"`
 }
 
 type Material struct {
 	Name                 string               `json:"name"`
 	DoubleSided          bool                 `json:"doubleSided"`
+	AlphaMode            string               `json:"alphaMode"`
 	NormalTexture        *TextureId           `json:"normalTexture"`
 	OcclusionTexture     *TextureId           `json:"occlusionTexture"`
 	EmissiveTexture      *TextureId           `json:"emissiveTexture"`
@@ -175,9 +176,10 @@ type Texture struct {
 }
 
 type Image struct {
-	Name     string `json:"name"`
-	URI      string `json:"uri"`
-	MimeType string `json:"mimeType"`
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int32 `json:"bufferView"`
 }
 
 type Accessor struct {
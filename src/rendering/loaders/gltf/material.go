@@ -0,0 +1,125 @@
+/******************************************************************************/
+/* material.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gltf
+
+import (
+	"kaiju/assets"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+const (
+	AlphaModeOpaque = "OPAQUE"
+	AlphaModeMask   = "MASK"
+	AlphaModeBlend  = "BLEND"
+)
+
+// TextureURI resolves the image URI of the texture reference, an empty string
+// is returned if the reference is nil or out of range, or if the image is
+// embedded in the file
+func (g *GLTF) TextureURI(id *TextureId) string {
+	if image := g.TextureImage(id); image >= 0 {
+		return g.Images[image].URI
+	}
+	return ""
+}
+
+// TextureImage resolves the index of the image of the texture reference, -1
+// is returned if the reference is nil or out of range
+func (g *GLTF) TextureImage(id *TextureId) int {
+	if id == nil || id.Index < 0 {
+		return -1
+	}
+	image := id.Index
+	if len(g.Textures) > 0 {
+		if int(id.Index) >= len(g.Textures) {
+			return -1
+		}
+		image = g.Textures[id.Index].Source
+	}
+	if image < 0 || int(image) >= len(g.Images) {
+		return -1
+	}
+	return int(image)
+}
+
+// BaseColorImage returns the index of the image of the base color texture of
+// the material at the given index, -1 is returned if it has none
+func (g *GLTF) BaseColorImage(index int) int {
+	if index < 0 || index >= len(g.Materials) {
+		return -1
+	}
+	return g.TextureImage(g.Materials[index].PBRMetallicRoughness.BaseColorTexture)
+}
+
+// MaterialDef converts the glTF material at the given index into a material
+// definition that uses the lit shader. The base color, metallic, and
+// roughness factors become the default parameters of the material. The lit
+// shader only samples the base color, so the other texture maps are skipped.
+func (g *GLTF) MaterialDef(index int) rendering.MaterialDef {
+	def := rendering.MaterialDef{
+		Shader: assets.ShaderDefinitionLit,
+		Parameters: map[string][]matrix.Float{
+			"color":     {1, 1, 1, 1},
+			"metallic":  {1},
+			"roughness": {1},
+		},
+	}
+	baseColor := ""
+	if index >= 0 && index < len(g.Materials) {
+		mat := &g.Materials[index]
+		pbr := &mat.PBRMetallicRoughness
+		if pbr.BaseColorFactor != nil {
+			c := *pbr.BaseColorFactor
+			def.Parameters["color"] = []matrix.Float{c.R(), c.G(), c.B(), c.A()}
+		}
+		if pbr.MetallicFactor != nil {
+			def.Parameters["metallic"] = []matrix.Float{matrix.Float(*pbr.MetallicFactor)}
+		}
+		if pbr.RoughnessFactor != nil {
+			def.Parameters["roughness"] = []matrix.Float{matrix.Float(*pbr.RoughnessFactor)}
+		}
+		baseColor = g.TextureURI(pbr.BaseColorTexture)
+		def.UseBlending = mat.AlphaMode == AlphaModeBlend
+	}
+	if baseColor == "" {
+		baseColor = assets.TextureSquare
+	}
+	def.Textures = []rendering.MaterialTextureDef{{Name: "baseColor", Texture: baseColor}}
+	return def
+}
@@ -0,0 +1,110 @@
+/******************************************************************************/
+/* material_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gltf
+
+import (
+	"kaiju/assets"
+	"testing"
+)
+
+func TestMaterialDefFromGLTF(t *testing.T) {
+	doc, err := LoadGLTF(`{
+		"images": [{ "uri": "unused.png" }, { "uri": "albedo.png" }],
+		"textures": [{ "source": 1 }],
+		"materials": [{
+			"name": "Painted",
+			"alphaMode": "BLEND",
+			"pbrMetallicRoughness": {
+				"baseColorFactor": [1, 0.5, 0.25, 0.5],
+				"baseColorTexture": { "index": 0 },
+				"roughnessFactor": 0.3
+			}
+		}, {
+			"name": "Plain"
+		}]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := doc.MaterialDef(0)
+	if def.Shader != assets.ShaderDefinitionLit || !def.UseBlending {
+		t.Errorf("unexpected material %+v", def)
+	}
+	if len(def.Textures) != 1 || def.Textures[0].Texture != "albedo.png" {
+		t.Errorf("expected the base color texture to resolve through the texture source, got %+v", def.Textures)
+	}
+	if c := def.Parameters["color"]; c[1] != 0.5 || c[3] != 0.5 {
+		t.Errorf("expected the base color factor, got %v", c)
+	}
+	if def.Parameters["metallic"][0] != 1 || def.Parameters["roughness"][0] != 0.3 {
+		t.Errorf("expected missing factors to use the glTF defaults, got %v", def.Parameters)
+	}
+	if err := def.Validate(); err != nil {
+		t.Error(err)
+	}
+	plain := doc.MaterialDef(1)
+	if plain.UseBlending || plain.Textures[0].Texture != assets.TextureSquare {
+		t.Errorf("expected a material without a texture to use the default texture, got %+v", plain)
+	}
+}
+
+func TestBaseColorImage(t *testing.T) {
+	doc, err := LoadGLTF(`{
+		"images": [{ "uri": "unused.png" }, { "bufferView": 0, "mimeType": "image/png" }],
+		"textures": [{ "source": 1 }, { "source": 4 }],
+		"materials": [
+			{ "pbrMetallicRoughness": { "baseColorTexture": { "index": 0 } } },
+			{ "pbrMetallicRoughness": { "baseColorTexture": { "index": 1 } } },
+			{ "name": "Plain" }
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img := doc.BaseColorImage(0); img != 1 {
+		t.Errorf("expected the base color image to resolve through the texture source, got %d", img)
+	}
+	if doc.Images[1].BufferView == nil || *doc.Images[1].BufferView != 0 {
+		t.Error("expected the embedded image to reference its buffer view")
+	}
+	for _, i := range []int{1, 2, 3, -1} {
+		if img := doc.BaseColorImage(i); img != -1 {
+			t.Errorf("expected material %d to have no base color image, got %d", i, img)
+		}
+	}
+}
@@ -38,8 +38,10 @@
 package load_result
 
 import (
+	"kaiju/assets"
 	"kaiju/matrix"
 	"kaiju/rendering"
+	"slices"
 )
 
 type AnimationPathType = int
@@ -65,6 +67,23 @@ type Mesh struct {
 	MeshName string
 	Verts    []rendering.Vertex
 	Indexes  []uint32
+	// Material is the index into the materials of the result, -1 when the
+	// mesh has no material
+	Material int
+	// Node is the index into the nodes of the result that the mesh is drawn
+	// at, -1 when the file has no node hierarchy
+	Node int
+}
+
+// Image is an image that the model file uses for its materials
+type Image struct {
+	Name     string
+	MimeType string
+	// URI is the path of the image relative to the model file, it is empty
+	// when the image is embedded in the model file
+	URI string
+	// Data is the encoded image file when it is embedded in the model file
+	Data []byte
 }
 
 type AnimBone struct {
@@ -100,14 +119,20 @@ type Result struct {
 	Nodes      []Node
 	Meshes     []Mesh
 	Textures   []string
+	Materials  []rendering.MaterialDef
 	Animations []Animation
 	Joints     []Joint
+	Images     []Image
+	// MaterialImages is the index into the images of the base color image of
+	// each of the materials, -1 when the material has no image
+	MaterialImages []int
 }
 
 func NewResult() Result {
 	return Result{
-		Meshes:   make([]Mesh, 0),
-		Textures: make([]string, 0),
+		Meshes:    make([]Mesh, 0),
+		Textures:  make([]string, 0),
+		Materials: make([]rendering.MaterialDef, 0),
 	}
 }
 
@@ -121,9 +146,85 @@ func (r *Result) Add(name, meshName string, verts []rendering.Vertex,
 		MeshName: meshName,
 		Verts:    verts,
 		Indexes:  indexes,
+		Material: -1,
+		Node:     -1,
+	})
+}
+
+// LitMaterial creates a material that uses the lit shader with the color as
+// its base color, the default texture is used when the texture is empty
+func LitMaterial(color matrix.Color, texture string) rendering.MaterialDef {
+	if texture == "" {
+		texture = assets.TextureSquare
+	}
+	return rendering.MaterialDef{
+		Shader: assets.ShaderDefinitionLit,
+		Parameters: map[string][]matrix.Float{
+			"color":     {color.R(), color.G(), color.B(), color.A()},
+			"metallic":  {0},
+			"roughness": {1},
+		},
+		Textures: []rendering.MaterialTextureDef{{Name: "baseColor", Texture: texture}},
+	}
+}
+
+// TopInfluences picks the 4 joints with the most weight for a vertex, the
+// weights of the picked joints are normalized to add up to 1
+func TopInfluences(joints []int32, weights []matrix.Float) (matrix.Vec4i, matrix.Vec4) {
+	ids := matrix.Vec4i{}
+	top := matrix.Vec4{}
+	for i := range min(len(joints), len(weights)) {
+		lowest := 0
+		for j := 1; j < len(top); j++ {
+			if top[j] < top[lowest] {
+				lowest = j
+			}
+		}
+		if weights[i] > top[lowest] {
+			ids[lowest] = joints[i]
+			top[lowest] = weights[i]
+		}
+	}
+	if total := top.X() + top.Y() + top.Z() + top.W(); total > 0 {
+		for i := range top {
+			top[i] /= total
+		}
+	}
+	return ids, top
+}
+
+// AddBone adds the bone to the key frame at the time, the key frame is
+// created if the animation does not have one at that time yet. The time is
+// absolute until #Animation.ToRelativeTimes is called.
+func (a *Animation) AddBone(time float32, bone AnimBone) {
+	for i := range a.Frames {
+		if matrix.Approx(a.Frames[i].Time, time) {
+			a.Frames[i].Bones = append(a.Frames[i].Bones, bone)
+			return
+		}
+	}
+	a.Frames = append(a.Frames, AnimKeyFrame{
+		Bones: []AnimBone{bone},
+		Time:  time,
 	})
 }
 
+// ToRelativeTimes sorts the key frames and converts the absolute time of
+// each frame into the length of time until the next frame, the last frame
+// is the goal and has no time
+func (a *Animation) ToRelativeTimes() {
+	if len(a.Frames) == 0 {
+		return
+	}
+	slices.SortFunc(a.Frames, func(a, b AnimKeyFrame) int {
+		return int((a.Time - b.Time) * 10000)
+	})
+	for i := range a.Frames[:len(a.Frames)-1] {
+		a.Frames[i].Time = a.Frames[i+1].Time - a.Frames[i].Time
+	}
+	a.Frames[len(a.Frames)-1].Time = 0.0
+}
+
 func (mesh *Mesh) ScaledRadius(scale matrix.Vec3) matrix.Float {
 	rad := matrix.Float(0)
 	// TODO:  Take scale into consideration
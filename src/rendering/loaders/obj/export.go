@@ -0,0 +1,157 @@
+/******************************************************************************/
+/* export.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"kaiju/assets"
+	"kaiju/matrix"
+	"kaiju/rendering/loaders/load_result"
+	"strconv"
+	"strings"
+)
+
+// MaterialName is the name that #Export and #ExportMTL give to the material
+// at the index of the result
+func MaterialName(index int) string {
+	return fmt.Sprintf("material%d", index)
+}
+
+func writeFloat(w *bufio.Writer, f matrix.Float) {
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+}
+
+func isWhite(c matrix.Color) bool {
+	return c.R() == 1 && c.G() == 1 && c.B() == 1
+}
+
+// Export writes the meshes of the result as a Wavefront OBJ file. Each mesh
+// is written as an object named by the name of the mesh, and a group named
+// by the mesh name when the two are different. Vertex colors are written
+// after the positions when any vertex of the mesh is not white. The mtlLib
+// is the name of the material library that is written by #ExportMTL, it is
+// skipped when empty.
+func Export(w io.Writer, res *load_result.Result, mtlLib string) error {
+	out := bufio.NewWriter(w)
+	if mtlLib != "" {
+		fmt.Fprintf(out, "mtllib %s\n", mtlLib)
+	}
+	offset := 1
+	for i := range res.Meshes {
+		m := &res.Meshes[i]
+		fmt.Fprintf(out, "o %s\n", m.Name)
+		if m.MeshName != "" && m.MeshName != m.Name {
+			fmt.Fprintf(out, "g %s\n", m.MeshName)
+		}
+		colored := false
+		for j := range m.Verts {
+			colored = colored || !isWhite(m.Verts[j].Color)
+		}
+		for j := range m.Verts {
+			v := &m.Verts[j]
+			out.WriteString("v")
+			for k := range 3 {
+				writeFloat(out, v.Position[k])
+			}
+			if colored {
+				for k := range 3 {
+					writeFloat(out, v.Color[k])
+				}
+			}
+			out.WriteByte('\n')
+		}
+		for j := range m.Verts {
+			out.WriteString("vt")
+			for k := range 2 {
+				writeFloat(out, m.Verts[j].UV0[k])
+			}
+			out.WriteByte('\n')
+		}
+		for j := range m.Verts {
+			out.WriteString("vn")
+			for k := range 3 {
+				writeFloat(out, m.Verts[j].Normal[k])
+			}
+			out.WriteByte('\n')
+		}
+		if m.Material >= 0 {
+			fmt.Fprintf(out, "usemtl %s\n", MaterialName(m.Material))
+		}
+		for j := 0; j+2 < len(m.Indexes); j += 3 {
+			out.WriteString("f")
+			for k := range 3 {
+				idx := int(m.Indexes[j+k]) + offset
+				fmt.Fprintf(out, " %d/%d/%d", idx, idx, idx)
+			}
+			out.WriteByte('\n')
+		}
+		offset += len(m.Verts)
+	}
+	return out.Flush()
+}
+
+// ExportMTL writes the materials of the result as a Wavefront MTL file, the
+// base color and the base color texture of each material are written
+func ExportMTL(w io.Writer, res *load_result.Result) error {
+	out := bufio.NewWriter(w)
+	for i := range res.Materials {
+		def := &res.Materials[i]
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		fmt.Fprintf(out, "newmtl %s\n", MaterialName(i))
+		color := matrix.ColorWhite()
+		copy(color[:], def.Parameters["color"])
+		out.WriteString("Kd")
+		for k := range 3 {
+			writeFloat(out, color[k])
+		}
+		out.WriteString("\nd")
+		writeFloat(out, color.A())
+		out.WriteByte('\n')
+		for _, t := range def.Textures {
+			if t.Name == "baseColor" && t.Texture != assets.TextureSquare {
+				fmt.Fprintf(out, "map_Kd %s\n", strings.TrimSpace(t.Texture))
+			}
+		}
+	}
+	return out.Flush()
+}
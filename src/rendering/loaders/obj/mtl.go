@@ -0,0 +1,177 @@
+/******************************************************************************/
+/* mtl.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package obj
+
+import (
+	"bufio"
+	"kaiju/matrix"
+	"strconv"
+	"strings"
+)
+
+// Material is a material of a Wavefront MTL file, only the colors and maps
+// that the engine can use are read
+type Material struct {
+	Name     string
+	Ambient  matrix.Color
+	Diffuse  matrix.Color
+	Specular matrix.Color
+	Emissive matrix.Color
+	// Shininess is the specular exponent (Ns) of the material, 0 when the
+	// file does not set it
+	Shininess matrix.Float
+	// Opacity is the dissolve (d) of the material, a transparency (Tr) is
+	// converted into an opacity
+	Opacity     matrix.Float
+	DiffuseMap  string
+	SpecularMap string
+	NormalMap   string
+}
+
+// mapOptionArgs is the number of values that follow each of the options of
+// a texture map statement
+var mapOptionArgs = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-boost": 1, "-cc": 1, "-clamp": 1,
+	"-imfchan": 1, "-texres": 1, "-bm": 1, "-mm": 2,
+	"-o": 3, "-s": 3, "-t": 3,
+}
+
+// mapPath skips the options of a texture map statement and returns the
+// path of the image, the path may contain spaces
+func mapPath(fields []string) string {
+	i := 0
+	for i < len(fields) && strings.HasPrefix(fields[i], "-") {
+		option := fields[i]
+		i++
+		args := mapOptionArgs[option]
+		for j := 0; j < args && i < len(fields)-1; j++ {
+			// The -o, -s, and -t options can leave off their last values
+			if _, err := strconv.ParseFloat(fields[i], 64); err != nil && j > 0 && args == 3 {
+				break
+			}
+			i++
+		}
+	}
+	return strings.Join(fields[i:], " ")
+}
+
+func readColor(fields []string) matrix.Color {
+	c := matrix.ColorWhite()
+	values := readFloats(fields)
+	if len(values) == 1 {
+		return matrix.Color{values[0], values[0], values[0], 1}
+	}
+	for i := 0; i < len(values) && i < 3; i++ {
+		c[i] = values[i]
+	}
+	return c
+}
+
+func readFloats(fields []string) []matrix.Float {
+	values := make([]matrix.Float, 0, len(fields))
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			break
+		}
+		values = append(values, matrix.Float(v))
+	}
+	return values
+}
+
+// ParseMTL reads the materials of a Wavefront MTL file
+func ParseMTL(mtlData string) []Material {
+	materials := []Material{}
+	var mat *Material
+	scan := bufio.NewScanner(strings.NewReader(mtlData))
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			materials = append(materials, Material{
+				Name:     strings.Join(fields[1:], " "),
+				Ambient:  matrix.ColorBlack(),
+				Diffuse:  matrix.ColorWhite(),
+				Specular: matrix.ColorBlack(),
+				Emissive: matrix.ColorBlack(),
+				Opacity:  1,
+			})
+			mat = &materials[len(materials)-1]
+			continue
+		}
+		if mat == nil {
+			continue
+		}
+		args := fields[1:]
+		switch strings.ToLower(fields[0]) {
+		case "ka":
+			mat.Ambient = readColor(args)
+		case "kd":
+			mat.Diffuse = readColor(args)
+		case "ks":
+			mat.Specular = readColor(args)
+		case "ke":
+			mat.Emissive = readColor(args)
+		case "ns":
+			if v := readFloats(args); len(v) > 0 {
+				mat.Shininess = v[0]
+			}
+		case "d":
+			if v := readFloats(args); len(v) > 0 {
+				mat.Opacity = v[0]
+			} else if len(args) > 1 && args[0] == "-halo" {
+				if v := readFloats(args[1:]); len(v) > 0 {
+					mat.Opacity = v[0]
+				}
+			}
+		case "tr":
+			if v := readFloats(args); len(v) > 0 {
+				mat.Opacity = 1 - v[0]
+			}
+		case "map_kd":
+			mat.DiffuseMap = mapPath(args)
+		case "map_ks":
+			mat.SpecularMap = mapPath(args)
+		case "map_bump", "bump", "norm":
+			mat.NormalMap = mapPath(args)
+		}
+	}
+	return materials
+}
@@ -0,0 +1,376 @@
+/******************************************************************************/
+/* obj.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package obj
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders/load_result"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ref is the position, uv, and normal index of a corner of a face, the uv
+// and normal are -1 when the face does not have them
+type ref struct {
+	v, vt, vn int
+}
+
+type face struct {
+	refs []ref
+	// smooth is the smoothing group of the face, 0 when it is not smoothed
+	smooth int
+}
+
+// batch is the faces of a group of an object that use the same material
+type batch struct {
+	object   string
+	group    string
+	material string
+	faces    []face
+}
+
+type parser struct {
+	points    []matrix.Vec3
+	colors    []matrix.Color
+	uvs       []matrix.Vec2
+	normals   []matrix.Vec3
+	batches   []batch
+	materials []Material
+	object    string
+	group     string
+	material  string
+	smooth    int
+	current   int
+}
+
+// Load reads the meshes of a Wavefront OBJ file. Each group of each object
+// in the file is a mesh of the result, a group that uses more than one
+// material is split into a mesh for each of its materials. Faces with more
+// than 3 corners are triangulated, faces without normals use the normals of
+// their smoothing group. The readMTL function reads the text of the
+// material libraries of the file, the libraries are skipped when it is nil
+// and an error reading a library fails the load.
+func Load(objData string, readMTL func(name string) (string, error)) (load_result.Result, error) {
+	p := parser{current: -1}
+	scan := bufio.NewScanner(strings.NewReader(objData))
+	scan.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scan.Scan(); line++ {
+		if err := p.readLine(scan.Text(), readMTL); err != nil {
+			return load_result.NewResult(), fmt.Errorf("obj line %d: %w", line, err)
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return load_result.NewResult(), err
+	}
+	return p.result(), nil
+}
+
+func (p *parser) readLine(line string, readMTL func(name string) (string, error)) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "v":
+		v := readFloats(args)
+		if len(v) < 3 {
+			return errors.New("a vertex needs 3 values")
+		}
+		c := matrix.ColorWhite()
+		if len(v) >= 6 {
+			c = matrix.Color{v[3], v[4], v[5], 1}
+		}
+		p.points = append(p.points, matrix.Vec3{v[0], v[1], v[2]})
+		p.colors = append(p.colors, c)
+	case "vt":
+		v := readFloats(args)
+		if len(v) < 1 {
+			return errors.New("a uv needs at least 1 value")
+		}
+		uv := matrix.Vec2{v[0], 0}
+		if len(v) > 1 {
+			uv[1] = v[1]
+		}
+		p.uvs = append(p.uvs, uv)
+	case "vn":
+		v := readFloats(args)
+		if len(v) < 3 {
+			return errors.New("a normal needs 3 values")
+		}
+		p.normals = append(p.normals, matrix.Vec3{v[0], v[1], v[2]})
+	case "f":
+		return p.readFace(args)
+	case "o":
+		p.object = strings.Join(args, " ")
+		p.group = ""
+		p.current = -1
+	case "g":
+		p.group = strings.Join(args, " ")
+		p.current = -1
+	case "usemtl":
+		p.material = strings.Join(args, " ")
+		p.current = -1
+	case "s":
+		p.smooth = 0
+		if len(args) > 0 && args[0] != "off" {
+			p.smooth, _ = strconv.Atoi(args[0])
+		}
+	case "mtllib":
+		if readMTL == nil {
+			return nil
+		}
+		for _, name := range args {
+			mtl, err := readMTL(name)
+			if err != nil {
+				return err
+			}
+			p.materials = append(p.materials, ParseMTL(mtl)...)
+		}
+	}
+	return nil
+}
+
+// index converts a 1 based index of the file into a 0 based index, negative
+// indexes are relative to the end of the list
+func index(field string, count int) (int, error) {
+	i, err := strconv.Atoi(field)
+	if err != nil {
+		return -1, err
+	}
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+	if i < 0 || i >= count {
+		return -1, fmt.Errorf("index %s is out of range", field)
+	}
+	return i, nil
+}
+
+func (p *parser) readFace(args []string) error {
+	if len(args) < 3 {
+		return errors.New("a face needs at least 3 corners")
+	}
+	f := face{refs: make([]ref, len(args)), smooth: p.smooth}
+	for i, arg := range args {
+		parts := strings.Split(arg, "/")
+		r := ref{-1, -1, -1}
+		var err error
+		if r.v, err = index(parts[0], len(p.points)); err != nil {
+			return err
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			if r.vt, err = index(parts[1], len(p.uvs)); err != nil {
+				return err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if r.vn, err = index(parts[2], len(p.normals)); err != nil {
+				return err
+			}
+		}
+		f.refs[i] = r
+	}
+	if p.current < 0 {
+		p.current = p.batch()
+	}
+	p.batches[p.current].faces = append(p.batches[p.current].faces, f)
+	return nil
+}
+
+// batch finds the batch for the current object, group, and material, the
+// batch is added if the file has not used them together before
+func (p *parser) batch() int {
+	for i := range p.batches {
+		b := &p.batches[i]
+		if b.object == p.object && b.group == p.group && b.material == p.material {
+			return i
+		}
+	}
+	p.batches = append(p.batches, batch{
+		object:   p.object,
+		group:    p.group,
+		material: p.material,
+	})
+	return len(p.batches) - 1
+}
+
+func (p *parser) result() load_result.Result {
+	res := load_result.NewResult()
+	materials := p.readMaterials(&res)
+	for i := range p.batches {
+		b := &p.batches[i]
+		if len(b.faces) == 0 {
+			continue
+		}
+		verts, indexes := p.readBatch(b)
+		meshName := b.group
+		if meshName == "" {
+			meshName = b.object
+		}
+		res.Add(b.object, meshName, verts, indexes, nil)
+		if idx, ok := materials[b.material]; ok {
+			res.Meshes[len(res.Meshes)-1].Material = idx
+		}
+	}
+	return res
+}
+
+func (p *parser) readMaterials(res *load_result.Result) map[string]int {
+	lookup := make(map[string]int, len(p.materials))
+	images := map[string]int{}
+	for _, m := range p.materials {
+		color := m.Diffuse
+		color[3] = m.Opacity
+		def := load_result.LitMaterial(color, m.DiffuseMap)
+		def.UseBlending = m.Opacity < 1
+		if m.Shininess > 0 {
+			// Approximates the roughness that matches the highlight of the
+			// specular exponent
+			def.Parameters["roughness"][0] = matrix.Sqrt(2 / (m.Shininess + 2))
+		}
+		image := -1
+		if m.DiffuseMap != "" {
+			var ok bool
+			if image, ok = images[m.DiffuseMap]; !ok {
+				image = len(res.Images)
+				images[m.DiffuseMap] = image
+				res.Images = append(res.Images, load_result.Image{
+					Name: path.Base(strings.ReplaceAll(m.DiffuseMap, "\\", "/")),
+					URI:  m.DiffuseMap,
+				})
+				res.Textures = append(res.Textures, m.DiffuseMap)
+			}
+		}
+		lookup[m.Name] = len(res.Materials)
+		res.Materials = append(res.Materials, def)
+		res.MaterialImages = append(res.MaterialImages, image)
+	}
+	return lookup
+}
+
+type vertexKey struct {
+	ref
+	// smooth is the smoothing group of a generated normal, face is the index
+	// of the face for generated flat normals
+	smooth int
+	face   int
+}
+
+// faceNormal uses Newell's method so that the normal of a face that is not
+// planar, or has corners in a line, is still usable
+func (p *parser) faceNormal(f *face) matrix.Vec3 {
+	n := matrix.Vec3Zero()
+	for i := range f.refs {
+		a := p.points[f.refs[i].v]
+		b := p.points[f.refs[(i+1)%len(f.refs)].v]
+		n[0] += (a.Y() - b.Y()) * (a.Z() + b.Z())
+		n[1] += (a.Z() - b.Z()) * (a.X() + b.X())
+		n[2] += (a.X() - b.X()) * (a.Y() + b.Y())
+	}
+	if n.Length() > 0 {
+		n.Normalize()
+	}
+	return n
+}
+
+func (p *parser) readBatch(b *batch) ([]rendering.Vertex, []uint32) {
+	faceNormals := make([]matrix.Vec3, len(b.faces))
+	smoothNormals := map[[2]int]matrix.Vec3{}
+	for i := range b.faces {
+		f := &b.faces[i]
+		faceNormals[i] = p.faceNormal(f)
+		if f.smooth == 0 {
+			continue
+		}
+		for _, r := range f.refs {
+			key := [2]int{f.smooth, r.v}
+			smoothNormals[key] = smoothNormals[key].Add(faceNormals[i])
+		}
+	}
+	verts := []rendering.Vertex{}
+	indexes := []uint32{}
+	lookup := map[vertexKey]uint32{}
+	for i := range b.faces {
+		f := &b.faces[i]
+		corners := make([]uint32, len(f.refs))
+		for j, r := range f.refs {
+			key := vertexKey{ref: r, face: -1}
+			if r.vn < 0 {
+				if f.smooth == 0 {
+					key.face = i
+				} else {
+					key.smooth = f.smooth
+				}
+			}
+			idx, ok := lookup[key]
+			if !ok {
+				v := rendering.Vertex{
+					Position: p.points[r.v],
+					Color:    p.colors[r.v],
+				}
+				v.MorphTarget = v.Position
+				if r.vt >= 0 {
+					v.UV0 = p.uvs[r.vt]
+				}
+				if r.vn >= 0 {
+					v.Normal = p.normals[r.vn]
+				} else if f.smooth == 0 {
+					v.Normal = faceNormals[i]
+				} else if n := smoothNormals[[2]int{f.smooth, r.v}]; n.Length() > 0 {
+					v.Normal = n.Normal()
+				}
+				idx = uint32(len(verts))
+				lookup[key] = idx
+				verts = append(verts, v)
+			}
+			corners[j] = idx
+		}
+		for _, t := range p.triangulate(f, faceNormals[i]) {
+			indexes = append(indexes, corners[t[0]], corners[t[1]], corners[t[2]])
+		}
+	}
+	return verts, indexes
+}
@@ -0,0 +1,277 @@
+/******************************************************************************/
+/* obj_test.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"kaiju/matrix"
+	"strings"
+	"testing"
+)
+
+const testMTL = `# materials
+newmtl red
+Kd 1 0 0
+d 0.5
+Ns 98
+map_Kd -s 1 1 1 -clamp on textures/red.png
+
+newmtl blue
+Kd 0 0 1
+`
+
+const testOBJ = `# two objects
+mtllib test.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 1 0 1 0 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 1
+o Quad
+usemtl red
+f 1/1/1 2/2/1 3/3/1 4/4/1
+o Shape
+g Top
+usemtl blue
+s 1
+f 1 2 3
+f -4 -1 -3
+g Bottom
+usemtl red
+f 1//1 3//1 4//1
+usemtl blue
+f 2//1 3//1 4//1
+`
+
+func readTestMTL(name string) (string, error) {
+	if name != "test.mtl" {
+		return "", errors.New("unknown material library " + name)
+	}
+	return testMTL, nil
+}
+
+func TestParseMTL(t *testing.T) {
+	mats := ParseMTL(testMTL)
+	if len(mats) != 2 || mats[0].Name != "red" || mats[1].Name != "blue" {
+		t.Fatalf("unexpected materials %+v", mats)
+	}
+	if mats[0].Diffuse != (matrix.Color{1, 0, 0, 1}) || mats[0].Opacity != 0.5 {
+		t.Errorf("unexpected color %v and opacity %v", mats[0].Diffuse, mats[0].Opacity)
+	}
+	if mats[0].DiffuseMap != "textures/red.png" {
+		t.Errorf("expected the map options to be skipped, got %q", mats[0].DiffuseMap)
+	}
+	if mats[1].DiffuseMap != "" || mats[1].Opacity != 1 {
+		t.Errorf("unexpected defaults %+v", mats[1])
+	}
+}
+
+func TestLoadObjects(t *testing.T) {
+	res, err := Load(testOBJ, readTestMTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Meshes) != 4 {
+		t.Fatalf("expected a mesh for each group and material, got %d", len(res.Meshes))
+	}
+	expected := []struct {
+		name, meshName string
+		material       int
+		indexes        int
+	}{
+		{"Quad", "Quad", 0, 6},
+		{"Shape", "Top", 1, 6},
+		{"Shape", "Bottom", 0, 3},
+		{"Shape", "Bottom", 1, 3},
+	}
+	for i, e := range expected {
+		m := res.Meshes[i]
+		if m.Name != e.name || m.MeshName != e.meshName || m.Material != e.material || len(m.Indexes) != e.indexes {
+			t.Errorf("mesh %d expected %+v but got %s %s %d with %d indexes",
+				i, e, m.Name, m.MeshName, m.Material, len(m.Indexes))
+		}
+	}
+	if len(res.Images) != 1 || res.Images[0].URI != "textures/red.png" || res.MaterialImages[0] != 0 || res.MaterialImages[1] != -1 {
+		t.Errorf("unexpected images %+v and material images %v", res.Images, res.MaterialImages)
+	}
+	if c := res.Materials[0].Parameters["color"]; c[0] != 1 || c[3] != 0.5 || !res.Materials[0].UseBlending {
+		t.Errorf("expected the red material to be transparent, got %v", c)
+	}
+	if c := res.Meshes[1].Verts[3].Color; c != (matrix.Color{0, 1, 0, 1}) {
+		t.Errorf("expected the vertex color to be read, got %v", c)
+	}
+}
+
+func TestLoadSmoothing(t *testing.T) {
+	res, err := Load(testOBJ, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := res.Meshes[1]
+	// The smoothed faces share the positions 2 and 3 of the file so their
+	// corners should be shared
+	if len(top.Verts) != 4 {
+		t.Fatalf("expected the smoothed faces to share vertices, got %d", len(top.Verts))
+	}
+	if n := top.Verts[0].Normal; !matrix.Vec3Approx(n, matrix.Vec3{0, 0, 1}) {
+		t.Errorf("unexpected normal of the unshared corner %v", n)
+	}
+	shared := matrix.Vec3{-1, 0, 1}.Normal()
+	for _, i := range []int{1, 2} {
+		if n := top.Verts[i].Normal; !matrix.Vec3Approx(n, shared) {
+			t.Errorf("expected the shared corner %d to average the faces, got %v", i, n)
+		}
+	}
+	if len(res.Materials) != 0 {
+		t.Errorf("expected the material library to be skipped, got %d materials", len(res.Materials))
+	}
+}
+
+func TestFlatNormals(t *testing.T) {
+	res, err := Load("v 0 0 0\nv 1 0 0\nv 0 1 0\nv 0 0 1\nf 1 2 3\nf 1 4 2\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := res.Meshes[0]
+	if len(m.Verts) != 6 {
+		t.Fatalf("expected flat faces to not share vertices, got %d", len(m.Verts))
+	}
+	if n := m.Verts[0].Normal; !matrix.Vec3Approx(n, matrix.Vec3{0, 0, 1}) {
+		t.Errorf("unexpected normal of the first face %v", n)
+	}
+	if n := m.Verts[3].Normal; !matrix.Vec3Approx(n, matrix.Vec3{0, 1, 0}) {
+		t.Errorf("unexpected normal of the second face %v", n)
+	}
+}
+
+func TestTriangulateConcave(t *testing.T) {
+	// An L shape where a fan from the first corner would leave the shape
+	const lShape = `v 0 0 0
+v 2 0 0
+v 2 1 0
+v 1 1 0
+v 1 2 0
+v 0 2 0
+f 4 5 6 1 2 3
+`
+	res, err := Load(lShape, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := res.Meshes[0]
+	if len(m.Indexes) != 12 {
+		t.Fatalf("expected 4 triangles but got %d indexes", len(m.Indexes))
+	}
+	area := matrix.Float(0)
+	for i := 0; i < len(m.Indexes); i += 3 {
+		a := m.Verts[m.Indexes[i]].Position
+		b := m.Verts[m.Indexes[i+1]].Position
+		c := m.Verts[m.Indexes[i+2]].Position
+		cross := matrix.Vec3Cross(b.Subtract(a), c.Subtract(a))
+		if cross.Z() <= 0 {
+			t.Errorf("triangle %d is flipped or empty", i/3)
+		}
+		area += cross.Length() / 2
+	}
+	if !matrix.Approx(area, 3) {
+		t.Errorf("expected the triangles to cover an area of 3, got %v", area)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load("v 0 0 0\nf 1 2 3\n", nil); err == nil {
+		t.Error("expected an index out of range to fail")
+	}
+	if _, err := Load("mtllib missing.mtl\n", readTestMTL); err == nil {
+		t.Error("expected a missing material library to fail")
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	res, err := Load(testOBJ, readTestMTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objData, mtlData := bytes.Buffer{}, bytes.Buffer{}
+	if err := Export(&objData, &res, "out.mtl"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportMTL(&mtlData, &res); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Load(objData.String(), func(name string) (string, error) {
+		if name != "out.mtl" {
+			return "", errors.New("unexpected library " + name)
+		}
+		return mtlData.String(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Meshes) != len(res.Meshes) {
+		t.Fatalf("expected %d meshes but got %d", len(res.Meshes), len(again.Meshes))
+	}
+	for i := range res.Meshes {
+		a, b := &res.Meshes[i], &again.Meshes[i]
+		if a.Name != b.Name || a.MeshName != b.MeshName || a.Material != b.Material {
+			t.Errorf("mesh %d changed from %s %s %d to %s %s %d", i,
+				a.Name, a.MeshName, a.Material, b.Name, b.MeshName, b.Material)
+		}
+		if len(a.Verts) != len(b.Verts) || len(a.Indexes) != len(b.Indexes) {
+			t.Fatalf("mesh %d changed size", i)
+		}
+		for j := range a.Indexes {
+			va, vb := &a.Verts[a.Indexes[j]], &b.Verts[b.Indexes[j]]
+			if va.Position != vb.Position || va.UV0 != vb.UV0 || va.Normal != vb.Normal || va.Color != vb.Color {
+				t.Errorf("mesh %d corner %d changed from %+v to %+v", i, j, va, vb)
+			}
+		}
+	}
+	if !strings.Contains(mtlData.String(), "map_Kd textures/red.png") {
+		t.Errorf("expected the texture to be exported, got\n%s", mtlData.String())
+	}
+	if c := again.Materials[0].Parameters["color"]; c[0] != 1 || c[3] != 0.5 {
+		t.Errorf("expected the material color to be kept, got %v", c)
+	}
+}
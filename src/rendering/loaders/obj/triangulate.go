@@ -0,0 +1,120 @@
+/******************************************************************************/
+/* triangulate.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package obj
+
+import "kaiju/matrix"
+
+// triangulate splits the face into triangles of the indexes of its corners.
+// Faces with more than 3 corners are projected onto the plane of their
+// normal and clipped one ear at a time so that concave faces are split
+// correctly, a fan is used for whatever is left if no ear can be found.
+func (p *parser) triangulate(f *face, normal matrix.Vec3) [][3]int {
+	count := len(f.refs)
+	if count == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+	// Drop the axis the normal points along the most to project the corners
+	// onto a 2D plane that keeps their winding
+	u, v := 0, 1
+	ax, ay, az := matrix.Abs(normal.X()), matrix.Abs(normal.Y()), matrix.Abs(normal.Z())
+	sign := normal.Z()
+	if ax > ay && ax > az {
+		u, v, sign = 1, 2, normal.X()
+	} else if ay > az {
+		u, v, sign = 2, 0, normal.Y()
+	}
+	points := make([]matrix.Vec2, count)
+	for i, r := range f.refs {
+		pt := p.points[r.v]
+		points[i] = matrix.Vec2{pt[u], pt[v]}
+		if sign < 0 {
+			points[i][0] = -points[i][0]
+		}
+	}
+	remaining := make([]int, count)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	tris := make([][3]int, 0, count-2)
+	for len(remaining) > 3 {
+		clipped := false
+		for i := range remaining {
+			a := remaining[(i+len(remaining)-1)%len(remaining)]
+			b := remaining[i]
+			c := remaining[(i+1)%len(remaining)]
+			if !isEar(points, remaining, a, b, c) {
+				continue
+			}
+			tris = append(tris, [3]int{a, b, c})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			break
+		}
+	}
+	for i := 1; i+1 < len(remaining); i++ {
+		tris = append(tris, [3]int{remaining[0], remaining[i], remaining[i+1]})
+	}
+	return tris
+}
+
+func cross2(o, a, b matrix.Vec2) matrix.Float {
+	return (a.X()-o.X())*(b.Y()-o.Y()) - (a.Y()-o.Y())*(b.X()-o.X())
+}
+
+// isEar checks that the corner b is convex and that no other corner is
+// inside of the triangle a, b, c
+func isEar(points []matrix.Vec2, remaining []int, a, b, c int) bool {
+	if cross2(points[a], points[b], points[c]) <= 0 {
+		return false
+	}
+	for _, i := range remaining {
+		if i == a || i == b || i == c {
+			continue
+		}
+		pt := points[i]
+		if cross2(points[a], points[b], pt) >= 0 &&
+			cross2(points[b], points[c], pt) >= 0 &&
+			cross2(points[c], points[a], pt) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,220 @@
+/******************************************************************************/
+/* material.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"errors"
+	"kaiju/matrix"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// MaterialTextureDef binds a texture to a named slot of a material. The slots
+// are bound to the shader in the order that they are listed in the material.
+type MaterialTextureDef struct {
+	Name    string
+	Texture string
+	// Filter is either "Linear" or "Nearest", linear is used when empty
+	Filter string `json:",omitempty"`
+}
+
+// MaterialDef is the JSON asset format of a material. It references a shader
+// definition, the textures for each of the shader texture slots, and the
+// default values for the fields of the shader instance data. Parameter names
+// match the instance data fields by name, ignoring case.
+type MaterialDef struct {
+	Shader      string
+	Textures    []MaterialTextureDef
+	Parameters  map[string][]matrix.Float `json:",omitempty"`
+	UseBlending bool                      `json:",omitempty"`
+}
+
+// Material is a loaded #MaterialDef with the shader and textures resolved
+// through their caches
+type Material struct {
+	Key         string
+	Shader      *Shader
+	Textures    []*Texture
+	Slots       []string
+	Parameters  map[string][]matrix.Float
+	UseBlending bool
+}
+
+// Drawing creates a drawing of the mesh that uses the shader, textures, and
+// blending of the material. The default parameters of the material are
+// written into the shader data before the drawing is returned.
+func (m *Material) Drawing(renderer Renderer, mesh *Mesh,
+	shaderData DrawInstance, transform *matrix.Transform) Drawing {
+	m.ApplyParameters(shaderData)
+	return Drawing{
+		Renderer:    renderer,
+		Shader:      m.Shader,
+		Material:    m,
+		Mesh:        mesh,
+		Textures:    m.Textures,
+		ShaderData:  shaderData,
+		Transform:   transform,
+		UseBlending: m.UseBlending,
+	}
+}
+
+func MaterialDefFromJson(jsonStr string) (MaterialDef, error) {
+	var def MaterialDef
+	if err := json.Unmarshal([]byte(jsonStr), &def); err != nil {
+		return def, err
+	}
+	return def, def.Validate()
+}
+
+func (d MaterialDef) ToJson() (string, error) {
+	data, err := json.MarshalIndent(d, "", "\t")
+	return string(data), err
+}
+
+// Validate returns an error if the material does not reference a shader or
+// if any of its texture slots are unnamed, duplicated, or have no texture
+func (d *MaterialDef) Validate() error {
+	if d.Shader == "" {
+		return errors.New("the material does not reference a shader definition")
+	}
+	for i := range d.Textures {
+		t := &d.Textures[i]
+		if t.Name == "" {
+			return errors.New("the material has a texture slot without a name")
+		} else if t.Texture == "" {
+			return errors.New("the material texture slot " + t.Name + " has no texture")
+		}
+		if _, err := t.TextureFilter(); err != nil {
+			return err
+		}
+		for j := range i {
+			if d.Textures[j].Name == t.Name {
+				return errors.New("the material texture slot " + t.Name + " is duplicated")
+			}
+		}
+	}
+	return nil
+}
+
+// TextureFilter converts the filter name of the slot into a #TextureFilter
+func (t MaterialTextureDef) TextureFilter() (TextureFilter, error) {
+	switch strings.ToLower(t.Filter) {
+	case "", "linear":
+		return TextureFilterLinear, nil
+	case "nearest":
+		return TextureFilterNearest, nil
+	default:
+		return TextureFilterLinear, errors.New("unknown texture filter " + t.Filter)
+	}
+}
+
+// Texture returns the texture bound to the named slot of the material
+func (m *Material) Texture(slot string) (*Texture, bool) {
+	for i := range m.Slots {
+		if m.Slots[i] == slot {
+			return m.Textures[i], true
+		}
+	}
+	return nil, false
+}
+
+// ApplyParameters writes the default parameter values of the material into
+// the instance data. The data is expected to be a pointer to a struct, each
+// parameter is written to the exported field with a matching name. Float,
+// integer, and float array (vector, color, matrix) fields are supported.
+// Nothing is written when the drawing does not have its instance data yet.
+func (m *Material) ApplyParameters(data DrawInstance) {
+	applyMaterialParameters(m.Parameters, data)
+}
+
+func applyMaterialParameters(params map[string][]matrix.Float, data DrawInstance) {
+	if len(params) == 0 {
+		return
+	}
+	v := reflect.ValueOf(data)
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return
+	}
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		slog.Warn("Material parameters can only be applied to a struct pointer",
+			slog.String("type", v.Type().String()))
+		return
+	}
+	v = v.Elem()
+	for name, value := range params {
+		field := v.FieldByNameFunc(func(f string) bool {
+			return strings.EqualFold(f, name)
+		})
+		if !field.IsValid() || !field.CanSet() || len(value) == 0 {
+			slog.Warn("Material parameter does not match a shader data field",
+				slog.String("parameter", name))
+			continue
+		}
+		if !setMaterialParameter(field, value) {
+			slog.Warn("Material parameter type is not supported",
+				slog.String("parameter", name),
+				slog.String("type", field.Type().String()))
+		}
+	}
+}
+
+func setMaterialParameter(field reflect.Value, value []matrix.Float) bool {
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		field.SetFloat(float64(value[0]))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(value[0]))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(max(value[0], 0)))
+	case reflect.Bool:
+		field.SetBool(value[0] != 0)
+	case reflect.Array:
+		kind := field.Type().Elem().Kind()
+		if kind != reflect.Float32 && kind != reflect.Float64 {
+			return false
+		}
+		for i := 0; i < field.Len() && i < len(value); i++ {
+			field.Index(i).SetFloat(float64(value[i]))
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* material_cache.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/assets"
+	"sync"
+)
+
+type MaterialCache struct {
+	assetDatabase *assets.Database
+	shaderCache   *ShaderCache
+	textureCache  *TextureCache
+	materials     map[string]*Material
+	mutex         sync.Mutex
+}
+
+func NewMaterialCache(assetDatabase *assets.Database, shaderCache *ShaderCache, textureCache *TextureCache) MaterialCache {
+	return MaterialCache{
+		assetDatabase: assetDatabase,
+		shaderCache:   shaderCache,
+		textureCache:  textureCache,
+		materials:     make(map[string]*Material),
+		mutex:         sync.Mutex{},
+	}
+}
+
+// Material will return the material for the given asset key, loading the
+// material definition from the asset database if it isn't already cached
+func (m *MaterialCache) Material(materialKey string) (*Material, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if material, ok := m.materials[materialKey]; ok {
+		return material, nil
+	}
+	str, err := m.assetDatabase.ReadText(materialKey)
+	if err != nil {
+		return nil, err
+	}
+	def, err := MaterialDefFromJson(str)
+	if err != nil {
+		return nil, err
+	}
+	return m.create(materialKey, def)
+}
+
+// AddMaterial will create and cache a material from a definition that is in
+// memory, such as one converted from a model file. If the key is already in
+// use, the existing material is returned instead.
+func (m *MaterialCache) AddMaterial(materialKey string, def MaterialDef) (*Material, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if material, ok := m.materials[materialKey]; ok {
+		return material, nil
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return m.create(materialKey, def)
+}
+
+// FindMaterial will return the material for the key if it has been loaded
+func (m *MaterialCache) FindMaterial(materialKey string) (*Material, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	material, ok := m.materials[materialKey]
+	return material, ok
+}
+
+func (m *MaterialCache) create(materialKey string, def MaterialDef) (*Material, error) {
+	material := &Material{
+		Key:         materialKey,
+		Textures:    make([]*Texture, len(def.Textures)),
+		Slots:       make([]string, len(def.Textures)),
+		Parameters:  def.Parameters,
+		UseBlending: def.UseBlending,
+	}
+	for i := range def.Textures {
+		filter, _ := def.Textures[i].TextureFilter()
+		tex, err := m.textureCache.Texture(def.Textures[i].Texture, filter)
+		if err != nil {
+			return nil, err
+		}
+		material.Textures[i] = tex
+		material.Slots[i] = def.Textures[i].Name
+	}
+	material.Shader = m.shaderCache.ShaderFromDefinition(def.Shader)
+	m.materials[materialKey] = material
+	return material, nil
+}
+
+// Destroy clears the cached materials, the shaders and textures of the
+// materials are owned and destroyed by their own caches
+func (m *MaterialCache) Destroy() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.materials = make(map[string]*Material)
+}
@@ -0,0 +1,117 @@
+/******************************************************************************/
+/* material_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func TestMaterialDefFromJson(t *testing.T) {
+	def, err := MaterialDefFromJson(`{
+		"Shader": "shaders/definitions/lit.json",
+		"Textures": [
+			{ "Name": "baseColor", "Texture": "textures/square.png" },
+			{ "Name": "mask", "Texture": "textures/mask.png", "Filter": "Nearest" }
+		],
+		"Parameters": { "color": [1, 0, 0, 1], "roughness": [0.25] }
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Shader != "shaders/definitions/lit.json" || len(def.Textures) != 2 {
+		t.Errorf("unexpected material %+v", def)
+	}
+	if f, _ := def.Textures[1].TextureFilter(); f != TextureFilterNearest {
+		t.Errorf("expected the mask slot to use nearest filtering")
+	}
+	str, err := def.ToJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back, err := MaterialDefFromJson(str); err != nil || back.Parameters["roughness"][0] != 0.25 {
+		t.Errorf("expected the material to round trip, got %+v %v", back, err)
+	}
+}
+
+func TestMaterialDefValidation(t *testing.T) {
+	invalid := []string{
+		`{ "Textures": [] }`,
+		`{ "Shader": "a.json", "Textures": [{ "Texture": "a.png" }] }`,
+		`{ "Shader": "a.json", "Textures": [{ "Name": "a" }] }`,
+		`{ "Shader": "a.json", "Textures": [{ "Name": "a", "Texture": "a.png", "Filter": "Cubic" }] }`,
+		`{ "Shader": "a.json", "Textures": [
+			{ "Name": "a", "Texture": "a.png" }, { "Name": "a", "Texture": "b.png" }] }`,
+	}
+	for i := range invalid {
+		if _, err := MaterialDefFromJson(invalid[i]); err == nil {
+			t.Errorf("expected material %d to be invalid", i)
+		}
+	}
+}
+
+func TestMaterialApplyParameters(t *testing.T) {
+	mat := Material{Parameters: map[string][]matrix.Float{
+		"color":      {0.5, 0.25, 1, 1},
+		"Metallic":   {1},
+		"roughness":  {0.75},
+		"lightCount": {2},
+		"missing":    {1},
+	}}
+	data := NewShaderDataLit()
+	mat.ApplyParameters(data)
+	if !matrix.Vec4Approx(matrix.Vec4(data.Color), matrix.Vec4{0.5, 0.25, 1, 1}) {
+		t.Errorf("expected the color to be applied, got %v", data.Color)
+	}
+	if data.Metallic != 1 || data.Roughness != 0.75 || data.LightCount != 2 {
+		t.Errorf("expected the scalar parameters to be applied, got %+v", data)
+	}
+	basic := &ShaderDataBasic{ShaderDataBase: NewShaderDataBase()}
+	mat.ApplyParameters(basic)
+	if basic.Color.R() != 0.5 {
+		t.Errorf("expected the color to be applied to the basic shader data")
+	}
+}
+
+func TestMaterialApplyParametersWithoutData(t *testing.T) {
+	mat := Material{Parameters: map[string][]matrix.Float{"color": {1, 0, 0, 1}}}
+	// Neither of these should panic, the drawing has no instance data yet
+	mat.ApplyParameters(nil)
+	var lit *ShaderDataLit
+	mat.ApplyParameters(lit)
+}
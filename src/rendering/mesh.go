@@ -37,7 +37,10 @@
 
 package rendering
 
-import "kaiju/matrix"
+import (
+	"kaiju/collision"
+	"kaiju/matrix"
+)
 
 type MeshDrawMode = int
 type MeshCullMode = int
@@ -60,6 +63,8 @@ type Mesh struct {
 	key            string
 	pendingVerts   []Vertex
 	pendingIndexes []uint32
+	bounds         collision.AABB
+	hasBounds      bool
 	Details        meshDetails
 }
 
@@ -70,9 +75,34 @@ func NewMesh(key string, verts []Vertex, indexes []uint32) *Mesh {
 		pendingIndexes: indexes,
 	}
 	m.Details.Set(verts, indexes)
+	m.SetBounds(meshBounds(verts))
 	return m
 }
 
+func meshBounds(verts []Vertex) (collision.AABB, bool) {
+	if len(verts) == 0 {
+		return collision.AABB{}, false
+	}
+	min := verts[0].Position
+	max := verts[0].Position
+	for i := 1; i < len(verts); i++ {
+		min = matrix.Vec3Min(min, verts[i].Position)
+		max = matrix.Vec3Max(max, verts[i].Position)
+	}
+	return collision.AABBFromMinMax(min, max), true
+}
+
+// Bounds returns the local space bounds of the mesh, the second value is
+// false when the bounds are unknown and the mesh should not be culled
+func (m *Mesh) Bounds() (collision.AABB, bool) { return m.bounds, m.hasBounds }
+
+// SetBounds overrides the local space bounds of the mesh, this is useful for
+// meshes that are deformed in the shader (skinning, vertex animation)
+func (m *Mesh) SetBounds(bounds collision.AABB, valid bool) {
+	m.bounds = bounds
+	m.hasBounds = valid
+}
+
 func (m *Mesh) SetKey(key string) {
 	m.key = key
 }
@@ -39,6 +39,9 @@ package rendering
 
 import (
 	"kaiju/assets"
+	"kaiju/assets/vfs"
+	"kaiju/matrix"
+	"strconv"
 	"sync"
 )
 
@@ -46,16 +49,29 @@ type MeshCache struct {
 	renderer      Renderer
 	assetDatabase *assets.Database
 	meshes        map[string]*Mesh
+	lods          map[string]*MeshLODChain
 	pendingMeshes []*Mesh
+	replacements  []meshReplacement
+	decoders      map[string]func(data []byte) ([]Vertex, []uint32, error)
 	mutex         sync.Mutex
 }
 
+// meshReplacement holds the new vertices and indexes of a cached mesh until
+// the mesh is created again with the other pending meshes
+type meshReplacement struct {
+	mesh    *Mesh
+	verts   []Vertex
+	indexes []uint32
+}
+
 func NewMeshCache(renderer Renderer, assetDatabase *assets.Database) MeshCache {
 	return MeshCache{
 		renderer:      renderer,
 		assetDatabase: assetDatabase,
 		meshes:        make(map[string]*Mesh),
+		lods:          make(map[string]*MeshLODChain),
 		pendingMeshes: make([]*Mesh, 0),
+		decoders:      make(map[string]func(data []byte) ([]Vertex, []uint32, error)),
 		mutex:         sync.Mutex{},
 	}
 }
@@ -93,6 +109,107 @@ func (m *MeshCache) Mesh(key string, verts []Vertex, indexes []uint32) *Mesh {
 	}
 }
 
+type meshData struct {
+	verts   []Vertex
+	indexes []uint32
+}
+
+// MeshAsync will return a handle to the mesh for the given asset key, the
+// asset is read and turned into vertices and indexes by the decode function
+// on a worker goroutine. The mesh is put into the cache on the main thread
+// and is created with the other pending meshes. The decode function is kept
+// so that the mesh can be read again with #MeshCache.Reload.
+func (m *MeshCache) MeshAsync(key string, decode func(data []byte) ([]Vertex, []uint32, error)) *assets.Handle[*Mesh] {
+	m.mutex.Lock()
+	mesh, ok := m.meshes[key]
+	m.decoders[key] = decode
+	m.mutex.Unlock()
+	if ok {
+		return assets.Ready(key, mesh, nil)
+	}
+	decoded := assets.Load(m.assetDatabase, key, func(data []byte) (meshData, error) {
+		verts, indexes, err := decode(data)
+		return meshData{verts, indexes}, err
+	})
+	return assets.Then(m.assetDatabase, decoded, func(data meshData) (*Mesh, error) {
+		return m.Mesh(key, data.verts, data.indexes), nil
+	})
+}
+
+// LODChain returns the level of detail chain for the mesh key, the chain is
+// created with the mesh of the key as its first level if it does not exist
+func (m *MeshCache) LODChain(key string) (*MeshLODChain, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if chain, ok := m.lods[key]; ok {
+		return chain, true
+	}
+	mesh, ok := m.meshes[key]
+	if !ok {
+		return nil, false
+	}
+	chain := &MeshLODChain{Key: key, Levels: []MeshLOD{{Mesh: mesh}}}
+	m.lods[key] = chain
+	return chain, true
+}
+
+// AddLOD creates a mesh for a level of detail of the mesh key and adds it to
+// the level of detail chain of the key. The mesh for the key itself must
+// already be in the cache.
+func (m *MeshCache) AddLOD(key string, screenSize matrix.Float, verts []Vertex, indexes []uint32) (*MeshLODChain, bool) {
+	chain, ok := m.LODChain(key)
+	if !ok {
+		return nil, false
+	}
+	lodKey := key + "#lod" + strconv.Itoa(len(chain.Levels))
+	chain.AddLevel(m.Mesh(lodKey, verts, indexes), screenSize)
+	return chain, true
+}
+
+// ReplaceMesh swaps the vertices and indexes of the cached mesh for the key.
+// The mesh keeps its pointer so the drawings using it show the new mesh once
+// it is created with the other pending meshes. Returns false if the key is
+// not a cached mesh.
+func (m *MeshCache) ReplaceMesh(key string, verts []Vertex, indexes []uint32) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	mesh, ok := m.meshes[key]
+	if !ok {
+		return false
+	}
+	m.replacements = append(m.replacements, meshReplacement{mesh, verts, indexes})
+	return true
+}
+
+// Reload reads the asset of a mesh that was loaded through #MeshCache.MeshAsync
+// again and replaces the cached mesh with it, the key is compared with the
+// normalized keys of the meshes. Returns false if the key is not a cached
+// mesh that was read from an asset.
+func (m *MeshCache) Reload(key string) (bool, error) {
+	m.mutex.Lock()
+	meshKey := ""
+	var decode func(data []byte) ([]Vertex, []uint32, error)
+	for k, d := range m.decoders {
+		if _, ok := m.meshes[k]; ok && vfs.NormalizeKey(k) == key {
+			meshKey, decode = k, d
+			break
+		}
+	}
+	m.mutex.Unlock()
+	if decode == nil {
+		return false, nil
+	}
+	data, err := m.assetDatabase.Read(meshKey)
+	if err != nil {
+		return true, err
+	}
+	verts, indexes, err := decode(data)
+	if err != nil {
+		return true, err
+	}
+	return m.ReplaceMesh(meshKey, verts, indexes), nil
+}
+
 func (m *MeshCache) CreatePending() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -100,6 +217,17 @@ func (m *MeshCache) CreatePending() {
 		mesh.DelayedCreate(m.renderer)
 	}
 	m.pendingMeshes = m.pendingMeshes[:0]
+	for _, r := range m.replacements {
+		if r.mesh.IsReady() {
+			m.renderer.DestroyMesh(r.mesh)
+		}
+		r.mesh.pendingVerts = r.verts
+		r.mesh.pendingIndexes = r.indexes
+		r.mesh.Details.Set(r.verts, r.indexes)
+		r.mesh.SetBounds(meshBounds(r.verts))
+		r.mesh.DelayedCreate(m.renderer)
+	}
+	m.replacements = m.replacements[:0]
 }
 
 func (m *MeshCache) Destroy() {
@@ -107,8 +235,10 @@ func (m *MeshCache) Destroy() {
 		mesh.Destroy(m.renderer)
 	}
 	m.pendingMeshes = m.pendingMeshes[:0]
+	m.replacements = m.replacements[:0]
 	for _, mesh := range m.meshes {
 		mesh.Destroy(m.renderer)
 	}
 	m.meshes = make(map[string]*Mesh)
+	m.lods = make(map[string]*MeshLODChain)
 }
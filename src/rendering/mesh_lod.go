@@ -0,0 +1,227 @@
+/******************************************************************************/
+/* mesh_lod.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/collision"
+	"kaiju/matrix"
+	"slices"
+)
+
+// MeshLOD is a level of detail within a #MeshLODChain. The level is drawn once
+// the bounds of an instance cover less than ScreenSize of the screen height,
+// the ScreenSize of the first level in a chain is not used as it is drawn for
+// any size that the other levels do not cover.
+type MeshLOD struct {
+	Mesh       *Mesh
+	ScreenSize matrix.Float
+}
+
+// MeshLODChain holds the levels of detail of a mesh, ordered from the most
+// detailed to the least detailed level
+type MeshLODChain struct {
+	Key    string
+	Levels []MeshLOD
+	// CullScreenSize hides the instance entirely once it covers less than
+	// this fraction of the screen height, 0 never hides the instance
+	CullScreenSize matrix.Float
+}
+
+// MeshLODSettings describe a level of detail to generate from a mesh. Ratio
+// is the fraction of the source triangles to keep and ScreenSize is the
+// #MeshLOD.ScreenSize for the generated level.
+type MeshLODSettings struct {
+	Ratio      matrix.Float
+	ScreenSize matrix.Float
+	// MaxError limits how far, in mesh units, the simplified surface may move
+	// from the source, 0 places no limit on the error
+	MaxError matrix.Float
+}
+
+// MeshLODData is the vertex and index data of a generated level of detail
+type MeshLODData struct {
+	ScreenSize matrix.Float
+	Verts      []Vertex
+	Indexes    []uint32
+}
+
+// minLODReduction is how much smaller each generated level must be than the
+// level before it, levels that can not be reduced further are not generated
+const minLODReduction = 0.9
+
+func DefaultMeshLODSettings() []MeshLODSettings {
+	return []MeshLODSettings{
+		{Ratio: 0.5, ScreenSize: 0.25},
+		{Ratio: 0.25, ScreenSize: 0.1},
+		{Ratio: 0.1, ScreenSize: 0.04},
+	}
+}
+
+// GenerateMeshLODs simplifies the mesh for each of the settings, in order.
+// Each level is simplified from the previous level and generation stops at
+// the first level that could not be reduced by at least 10%.
+func GenerateMeshLODs(verts []Vertex, indexes []uint32, settings []MeshLODSettings) []MeshLODData {
+	lods := make([]MeshLODData, 0, len(settings))
+	srcVerts, srcIndexes := verts, indexes
+	for i := range settings {
+		target := int(matrix.Float(len(indexes)) * settings[i].Ratio)
+		target -= target % 3
+		v, idx := SimplifyMesh(srcVerts, srcIndexes, target, settings[i].MaxError)
+		if len(idx) == 0 || matrix.Float(len(idx)) > matrix.Float(len(srcIndexes))*minLODReduction {
+			break
+		}
+		lods = append(lods, MeshLODData{
+			ScreenSize: settings[i].ScreenSize,
+			Verts:      v,
+			Indexes:    idx,
+		})
+		srcVerts, srcIndexes = v, idx
+	}
+	return lods
+}
+
+// AddLevel adds a level of detail to the chain, the levels after the first
+// are kept ordered by their screen size from largest to smallest
+func (c *MeshLODChain) AddLevel(mesh *Mesh, screenSize matrix.Float) {
+	c.Levels = append(c.Levels, MeshLOD{Mesh: mesh, ScreenSize: screenSize})
+	if len(c.Levels) > 2 {
+		slices.SortStableFunc(c.Levels[1:], func(a, b MeshLOD) int {
+			if a.ScreenSize > b.ScreenSize {
+				return -1
+			} else if a.ScreenSize < b.ScreenSize {
+				return 1
+			}
+			return 0
+		})
+	}
+}
+
+// Select returns the index of the level to draw for an instance covering
+// screenSize of the screen height, -1 is returned when the instance is
+// smaller than #MeshLODChain.CullScreenSize
+func (c *MeshLODChain) Select(screenSize matrix.Float) int {
+	if len(c.Levels) == 0 || screenSize < c.CullScreenSize {
+		return -1
+	}
+	level := 0
+	for i := 1; i < len(c.Levels); i++ {
+		if screenSize < c.Levels[i].ScreenSize {
+			level = i
+		}
+	}
+	return level
+}
+
+// ScreenSize estimates the fraction of the screen height that the bounds
+// cover when viewed through the camera, the bounds are treated as a sphere so
+// that the size does not change as the camera rotates
+func ScreenSize(bounds collision.AABB, camera cameras.Camera) matrix.Float {
+	radius := bounds.Extent.Length()
+	projection := camera.Projection()
+	scale := matrix.Abs(projection[matrix.Mat4x1y1])
+	if projection[matrix.Mat4x3y3] == 1 {
+		// Orthographic projections do not shrink with distance
+		return radius * scale
+	}
+	dist := bounds.Center.Subtract(camera.Position()).Length()
+	if dist <= radius {
+		return 1
+	}
+	return radius * scale / dist
+}
+
+// lodInstanceSet ties the per level instances of a drawing that uses a
+// #MeshLODChain to the instance that the drawing was created with
+type lodInstanceSet struct {
+	chain    *MeshLODChain
+	instance DrawInstance
+	selected int
+}
+
+// lodInstance is the instance of a single level of a #MeshLODChain, it shares
+// the data of the source instance and is only active while its level is
+// selected
+type lodInstance struct {
+	DrawInstance
+	set   *lodInstanceSet
+	level int
+}
+
+func (l *lodInstance) IsActive() bool {
+	return l.set.selected == l.level && l.DrawInstance.IsActive()
+}
+
+// SelectLODs picks the level of detail for every instance of a drawing that
+// was added with a #MeshLODChain based on how much of the screen the instance
+// covers from the camera. This should be done before the drawings are culled.
+func (d *Drawings) SelectLODs(camera cameras.Camera) {
+	back := 0
+	for _, set := range d.lods {
+		if set.instance.IsDestroyed() {
+			continue
+		}
+		d.lods[back] = set
+		back++
+		set.instance.UpdateModel()
+		bounds, ok := set.chain.Levels[0].Mesh.Bounds()
+		if !ok {
+			set.selected = 0
+			continue
+		}
+		size := ScreenSize(bounds.Transform(set.instance.Model()), camera)
+		set.selected = set.chain.Select(size)
+	}
+	clear(d.lods[back:])
+	d.lods = d.lods[:back]
+}
+
+// lodDrawings expands a drawing with a level of detail chain into a drawing
+// for each of the levels of the chain
+func (d *Drawings) lodDrawings(drawing *Drawing) []Drawing {
+	set := &lodInstanceSet{chain: drawing.LOD, instance: drawing.ShaderData}
+	d.lods = append(d.lods, set)
+	drawings := make([]Drawing, len(drawing.LOD.Levels))
+	for i := range drawing.LOD.Levels {
+		drawings[i] = *drawing
+		drawings[i].Mesh = drawing.LOD.Levels[i].Mesh
+		drawings[i].ShaderData = &lodInstance{drawing.ShaderData, set, i}
+		drawings[i].LOD = nil
+	}
+	return drawings
+}
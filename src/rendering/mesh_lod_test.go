@@ -0,0 +1,130 @@
+/******************************************************************************/
+/* mesh_lod_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/collision"
+	"kaiju/matrix"
+	"testing"
+)
+
+func testLODChain() *MeshLODChain {
+	chain := &MeshLODChain{Key: "cube"}
+	chain.AddLevel(NewMesh("cube", meshCube(matrix.ColorWhite()), nil), 0)
+	chain.AddLevel(NewMesh("cube#lod2", meshCube(matrix.ColorWhite()), nil), 0.1)
+	chain.AddLevel(NewMesh("cube#lod1", meshCube(matrix.ColorWhite()), nil), 0.3)
+	return chain
+}
+
+func TestMeshLODChainSelect(t *testing.T) {
+	chain := testLODChain()
+	if chain.Levels[1].ScreenSize != 0.3 || chain.Levels[2].ScreenSize != 0.1 {
+		t.Fatalf("expected the levels to be ordered by screen size")
+	}
+	cases := []struct {
+		size  matrix.Float
+		level int
+	}{{0.8, 0}, {0.3, 0}, {0.2, 1}, {0.05, 2}}
+	for _, c := range cases {
+		if got := chain.Select(c.size); got != c.level {
+			t.Errorf("expected level %d at size %f, got %d", c.level, c.size, got)
+		}
+	}
+	chain.CullScreenSize = 0.01
+	if chain.Select(0.005) != -1 {
+		t.Errorf("expected instances below the cull size to be hidden")
+	}
+}
+
+func TestScreenSizeShrinksWithDistance(t *testing.T) {
+	camera := cameras.NewStandardCamera(800, 600, matrix.Vec3{0, 0, 10})
+	camera.SetLookAt(matrix.Vec3Zero())
+	box := collision.AABB{Extent: matrix.Vec3{1, 1, 1}}
+	near := ScreenSize(box, camera)
+	box.Center = matrix.Vec3{0, 0, -40}
+	far := ScreenSize(box, camera)
+	if near <= far {
+		t.Errorf("expected a closer box to cover more of the screen, %f <= %f", near, far)
+	}
+	if near <= 0 || near >= 1 {
+		t.Errorf("expected the near box to cover part of the screen, got %f", near)
+	}
+	box.Center = matrix.Vec3{0, 0, 10}
+	if ScreenSize(box, camera) != 1 {
+		t.Errorf("expected a box around the camera to cover the screen")
+	}
+}
+
+func TestDrawingsSelectLODs(t *testing.T) {
+	camera := cameras.NewStandardCamera(800, 600, matrix.Vec3{0, 0, 5})
+	camera.SetLookAt(matrix.Vec3Zero())
+	chain := testLODChain()
+	instance := testCullingInstance(matrix.Vec3Zero())
+	d := &Drawings{}
+	levels := d.lodDrawings(&Drawing{LOD: chain, ShaderData: instance})
+	if len(levels) != 3 || levels[2].Mesh != chain.Levels[2].Mesh {
+		t.Fatalf("expected a drawing for each level of the chain")
+	}
+	active := func() int {
+		for i := range levels {
+			if levels[i].ShaderData.IsActive() {
+				return i
+			}
+		}
+		return -1
+	}
+	d.SelectLODs(camera)
+	if got := active(); got != 0 {
+		t.Errorf("expected the first level up close, got %d", got)
+	}
+	camera.SetPosition(matrix.Vec3{0, 0, 100})
+	d.SelectLODs(camera)
+	if got := active(); got != 2 {
+		t.Errorf("expected the last level far away, got %d", got)
+	}
+	instance.Deactivate()
+	if active() != -1 {
+		t.Errorf("expected deactivating the instance to hide every level")
+	}
+	instance.Destroy()
+	d.SelectLODs(camera)
+	if len(d.lods) != 0 || !levels[0].ShaderData.IsDestroyed() {
+		t.Errorf("expected destroyed instances to be released")
+	}
+}
@@ -0,0 +1,393 @@
+/******************************************************************************/
+/* mesh_simplify.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"container/heap"
+	"kaiju/matrix"
+	"math"
+)
+
+// simplifyBoundaryWeight scales the planes that are added along the open
+// edges of a mesh so that the silhouette of the mesh is kept while the
+// interior is simplified
+const simplifyBoundaryWeight = 10.0
+
+// simplifyVec is a double precision position used while simplifying so that
+// the quadric error sums do not lose precision on large meshes
+type simplifyVec [3]float64
+
+func toSimplifyVec(v matrix.Vec3) simplifyVec {
+	return simplifyVec{float64(v.X()), float64(v.Y()), float64(v.Z())}
+}
+
+func (v simplifyVec) sub(o simplifyVec) simplifyVec {
+	return simplifyVec{v[0] - o[0], v[1] - o[1], v[2] - o[2]}
+}
+
+func (v simplifyVec) dot(o simplifyVec) float64 {
+	return v[0]*o[0] + v[1]*o[1] + v[2]*o[2]
+}
+
+func (v simplifyVec) cross(o simplifyVec) simplifyVec {
+	return simplifyVec{
+		v[1]*o[2] - v[2]*o[1],
+		v[2]*o[0] - v[0]*o[2],
+		v[0]*o[1] - v[1]*o[0],
+	}
+}
+
+func (v simplifyVec) normal() (simplifyVec, float64) {
+	l := math.Sqrt(v.dot(v))
+	if l == 0 {
+		return v, 0
+	}
+	return simplifyVec{v[0] / l, v[1] / l, v[2] / l}, l
+}
+
+// quadric is the symmetric 4x4 error matrix of Garland and Heckbert stored as
+// its upper triangle: a², ab, ac, ad, b², bc, bd, c², cd, d²
+type quadric [10]float64
+
+func planeQuadric(n simplifyVec, d, weight float64) quadric {
+	a, b, c := n[0], n[1], n[2]
+	return quadric{
+		a * a * weight, a * b * weight, a * c * weight, a * d * weight,
+		b * b * weight, b * c * weight, b * d * weight,
+		c * c * weight, c * d * weight,
+		d * d * weight,
+	}
+}
+
+func (q *quadric) add(o quadric) {
+	for i := range q {
+		q[i] += o[i]
+	}
+}
+
+// error returns the sum of the squared distances of the point to the planes
+// that make up the quadric
+func (q *quadric) error(p simplifyVec) float64 {
+	x, y, z := p[0], p[1], p[2]
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z + q[9]
+}
+
+type simplifyEdge struct {
+	cost     float64
+	from, to int32
+	target   simplifyVec
+	versions [2]uint32
+}
+
+type simplifyEdgeHeap []simplifyEdge
+
+func (h simplifyEdgeHeap) Len() int           { return len(h) }
+func (h simplifyEdgeHeap) Less(i, j int) bool { return h[i].cost < h[j].cost }
+func (h simplifyEdgeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *simplifyEdgeHeap) Push(x any)        { *h = append(*h, x.(simplifyEdge)) }
+func (h *simplifyEdgeHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+type meshSimplifier struct {
+	verts     []Vertex
+	tris      [][3]uint32
+	alive     []bool
+	liveCount int
+	vertPoint []int32
+	points    []simplifyVec
+	parent    []int32
+	versions  []uint32
+	quadrics  []quadric
+	pointTris [][]int32
+	edges     simplifyEdgeHeap
+}
+
+// SimplifyMesh reduces the mesh to about targetIndexCount indexes by
+// collapsing the edges with the lowest quadric error first. Vertices that
+// share a position are collapsed together so that seams in the normals or UVs
+// do not tear open, and the open edges of the mesh are weighted to keep its
+// outline. Collapses that would flip a triangle are skipped, as are any that
+// would move the surface further than maxError (a distance) when maxError is
+// greater than 0. The returned vertices only contain the vertices that are
+// still referenced by the returned indexes.
+func SimplifyMesh(verts []Vertex, indexes []uint32, targetIndexCount int, maxError matrix.Float) ([]Vertex, []uint32) {
+	if len(indexes) < 3 || targetIndexCount >= len(indexes) {
+		return verts, indexes
+	}
+	s := newMeshSimplifier(verts, indexes)
+	maxCost := math.Inf(1)
+	if maxError > 0 {
+		maxCost = float64(maxError) * float64(maxError)
+	}
+	targetTris := max(targetIndexCount/3, 1)
+	for s.liveCount > targetTris && s.edges.Len() > 0 {
+		e := heap.Pop(&s.edges).(simplifyEdge)
+		if !s.edgeIsCurrent(e) {
+			continue
+		}
+		if e.cost > maxCost {
+			break
+		}
+		if s.collapseFlips(e.from, e.to, e.target) {
+			continue
+		}
+		s.collapse(e.from, e.to, e.target)
+	}
+	return s.result()
+}
+
+func newMeshSimplifier(verts []Vertex, indexes []uint32) *meshSimplifier {
+	s := &meshSimplifier{
+		verts:     verts,
+		tris:      make([][3]uint32, 0, len(indexes)/3),
+		vertPoint: make([]int32, len(verts)),
+	}
+	lookup := make(map[matrix.Vec3]int32, len(verts))
+	for i := range verts {
+		p, ok := lookup[verts[i].Position]
+		if !ok {
+			p = int32(len(s.points))
+			lookup[verts[i].Position] = p
+			s.points = append(s.points, toSimplifyVec(verts[i].Position))
+		}
+		s.vertPoint[i] = p
+	}
+	count := len(s.points)
+	s.parent = make([]int32, count)
+	s.versions = make([]uint32, count)
+	s.quadrics = make([]quadric, count)
+	s.pointTris = make([][]int32, count)
+	for i := range s.parent {
+		s.parent[i] = int32(i)
+	}
+	edgeUse := make(map[[2]int32]int32)
+	for i := 0; i+2 < len(indexes); i += 3 {
+		tri := [3]uint32{indexes[i], indexes[i+1], indexes[i+2]}
+		p := s.triPoints(tri)
+		if p[0] == p[1] || p[1] == p[2] || p[0] == p[2] {
+			continue
+		}
+		t := int32(len(s.tris))
+		s.tris = append(s.tris, tri)
+		n, _ := s.points[p[1]].sub(s.points[p[0]]).cross(s.points[p[2]].sub(s.points[p[0]])).normal()
+		q := planeQuadric(n, -n.dot(s.points[p[0]]), 1)
+		for j := range p {
+			s.quadrics[p[j]].add(q)
+			s.pointTris[p[j]] = append(s.pointTris[p[j]], t)
+			edgeUse[edgeKey(p[j], p[(j+1)%3])]++
+		}
+	}
+	s.alive = make([]bool, len(s.tris))
+	for i := range s.alive {
+		s.alive[i] = true
+	}
+	s.liveCount = len(s.tris)
+	s.addBoundaryPlanes(edgeUse)
+	s.edges = make(simplifyEdgeHeap, 0, len(edgeUse))
+	for k := range edgeUse {
+		s.edges = append(s.edges, s.edgeCost(k[0], k[1]))
+	}
+	heap.Init(&s.edges)
+	return s
+}
+
+func edgeKey(a, b int32) [2]int32 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int32{a, b}
+}
+
+func (s *meshSimplifier) find(p int32) int32 {
+	for s.parent[p] != p {
+		s.parent[p] = s.parent[s.parent[p]]
+		p = s.parent[p]
+	}
+	return p
+}
+
+func (s *meshSimplifier) triPoints(tri [3]uint32) [3]int32 {
+	return [3]int32{
+		s.find(s.vertPoint[tri[0]]),
+		s.find(s.vertPoint[tri[1]]),
+		s.find(s.vertPoint[tri[2]]),
+	}
+}
+
+// addBoundaryPlanes adds a plane perpendicular to the triangle along each
+// edge that is only used by a single triangle
+func (s *meshSimplifier) addBoundaryPlanes(edgeUse map[[2]int32]int32) {
+	for t := range s.tris {
+		p := s.triPoints(s.tris[t])
+		a, b, c := s.points[p[0]], s.points[p[1]], s.points[p[2]]
+		faceNormal, _ := b.sub(a).cross(c.sub(a)).normal()
+		for j := range p {
+			from, to := p[j], p[(j+1)%3]
+			if edgeUse[edgeKey(from, to)] != 1 {
+				continue
+			}
+			edge := s.points[to].sub(s.points[from])
+			n, l := edge.cross(faceNormal).normal()
+			if l == 0 {
+				continue
+			}
+			q := planeQuadric(n, -n.dot(s.points[from]), simplifyBoundaryWeight)
+			s.quadrics[from].add(q)
+			s.quadrics[to].add(q)
+		}
+	}
+}
+
+func (s *meshSimplifier) edgeCost(a, b int32) simplifyEdge {
+	q := s.quadrics[a]
+	q.add(s.quadrics[b])
+	pa, pb := s.points[a], s.points[b]
+	mid := simplifyVec{(pa[0] + pb[0]) * 0.5, (pa[1] + pb[1]) * 0.5, (pa[2] + pb[2]) * 0.5}
+	e := simplifyEdge{from: a, to: b, target: pb, cost: q.error(pb)}
+	if c := q.error(pa); c < e.cost {
+		e.target, e.cost = pa, c
+	}
+	if c := q.error(mid); c < e.cost {
+		e.target, e.cost = mid, c
+	}
+	e.cost = max(e.cost, 0)
+	e.versions = [2]uint32{s.versions[a], s.versions[b]}
+	return e
+}
+
+func (s *meshSimplifier) edgeIsCurrent(e simplifyEdge) bool {
+	return s.find(e.from) == e.from && s.find(e.to) == e.to &&
+		s.versions[e.from] == e.versions[0] && s.versions[e.to] == e.versions[1]
+}
+
+// collapseFlips returns true if moving the two points to the target would
+// turn any of the remaining triangles around them upside down
+func (s *meshSimplifier) collapseFlips(a, b int32, target simplifyVec) bool {
+	for _, pt := range [2]int32{a, b} {
+		for _, t := range s.pointTris[pt] {
+			if !s.alive[t] {
+				continue
+			}
+			p := s.triPoints(s.tris[t])
+			hasA := p[0] == a || p[1] == a || p[2] == a
+			hasB := p[0] == b || p[1] == b || p[2] == b
+			if hasA && hasB {
+				continue
+			}
+			var before, after [3]simplifyVec
+			for j := range p {
+				before[j] = s.points[p[j]]
+				after[j] = before[j]
+				if p[j] == a || p[j] == b {
+					after[j] = target
+				}
+			}
+			nb := before[1].sub(before[0]).cross(before[2].sub(before[0]))
+			na := after[1].sub(after[0]).cross(after[2].sub(after[0]))
+			if nb.dot(na) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *meshSimplifier) collapse(from, to int32, target simplifyVec) {
+	s.parent[from] = to
+	s.points[to] = target
+	s.quadrics[to].add(s.quadrics[from])
+	s.versions[to]++
+	tris := make([]int32, 0, len(s.pointTris[to])+len(s.pointTris[from]))
+	neighbors := make(map[int32]struct{})
+	for _, list := range [2][]int32{s.pointTris[to], s.pointTris[from]} {
+		for _, t := range list {
+			if !s.alive[t] {
+				continue
+			}
+			p := s.triPoints(s.tris[t])
+			if p[0] == p[1] || p[1] == p[2] || p[0] == p[2] {
+				s.alive[t] = false
+				s.liveCount--
+				continue
+			}
+			tris = append(tris, t)
+			for j := range p {
+				if p[j] != to {
+					neighbors[p[j]] = struct{}{}
+				}
+			}
+		}
+	}
+	s.pointTris[to] = tris
+	s.pointTris[from] = nil
+	for n := range neighbors {
+		heap.Push(&s.edges, s.edgeCost(to, n))
+	}
+}
+
+func (s *meshSimplifier) result() ([]Vertex, []uint32) {
+	remap := make([]int32, len(s.verts))
+	for i := range remap {
+		remap[i] = -1
+	}
+	verts := make([]Vertex, 0, len(s.verts))
+	indexes := make([]uint32, 0, s.liveCount*3)
+	for t := range s.tris {
+		if !s.alive[t] {
+			continue
+		}
+		for _, v := range s.tris[t] {
+			if remap[v] < 0 {
+				remap[v] = int32(len(verts))
+				vert := s.verts[v]
+				p := s.points[s.find(s.vertPoint[v])]
+				vert.Position = matrix.Vec3{matrix.Float(p[0]),
+					matrix.Float(p[1]), matrix.Float(p[2])}
+				verts = append(verts, vert)
+			}
+			indexes = append(indexes, uint32(remap[v]))
+		}
+	}
+	return verts, indexes
+}
@@ -0,0 +1,141 @@
+/******************************************************************************/
+/* mesh_simplify_test.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+// testGridMesh builds a flat grid of quads on the XY plane, the column in the
+// middle of the grid is split into a UV seam so that its vertices are
+// duplicated the same way that model files split vertices
+func testGridMesh(size int) ([]Vertex, []uint32) {
+	verts := []Vertex{}
+	indexes := []uint32{}
+	seam := size / 2
+	index := func(x, y int, right bool) uint32 {
+		uv := matrix.Vec2{matrix.Float(x) / matrix.Float(size), matrix.Float(y) / matrix.Float(size)}
+		if x == seam && right {
+			uv[0] += 1
+		}
+		verts = append(verts, Vertex{
+			Position: matrix.Vec3{matrix.Float(x), matrix.Float(y), 0},
+			Normal:   matrix.Vec3Backward(),
+			UV0:      uv,
+		})
+		return uint32(len(verts) - 1)
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			a := index(x, y, true)
+			b := index(x+1, y, false)
+			c := index(x+1, y+1, false)
+			d := index(x, y+1, true)
+			indexes = append(indexes, a, b, c, a, c, d)
+		}
+	}
+	return verts, indexes
+}
+
+func TestSimplifyMeshFlatGrid(t *testing.T) {
+	verts, indexes := testGridMesh(16)
+	target := len(indexes) / 4
+	outVerts, outIndexes := SimplifyMesh(verts, indexes, target, 0)
+	if len(outIndexes) > target || len(outIndexes) == 0 {
+		t.Fatalf("expected at most %d indexes, got %d", target, len(outIndexes))
+	}
+	if len(outIndexes)%3 != 0 {
+		t.Fatalf("expected whole triangles, got %d indexes", len(outIndexes))
+	}
+	area := matrix.Float(0)
+	for i := 0; i < len(outIndexes); i += 3 {
+		a := outVerts[outIndexes[i]].Position
+		b := outVerts[outIndexes[i+1]].Position
+		c := outVerts[outIndexes[i+2]].Position
+		n := matrix.Vec3Cross(b.Subtract(a), c.Subtract(a))
+		if n.Z() < 0 {
+			t.Errorf("expected triangle %d to keep its winding", i/3)
+		}
+		area += n.Length() * 0.5
+	}
+	if !matrix.ApproxTo(area, 16*16, 0.01) {
+		t.Errorf("expected the grid area to be kept, got %f", area)
+	}
+	for i := range outVerts {
+		if outVerts[i].Position.Z() != 0 {
+			t.Errorf("expected vertices to stay on the plane, got %v", outVerts[i].Position)
+		}
+	}
+}
+
+func TestSimplifyMeshRespectsMaxError(t *testing.T) {
+	verts, indexes := testGridMesh(8)
+	// Fold the grid so that collapsing across the fold moves the surface
+	for i := range verts {
+		x := verts[i].Position.X()
+		verts[i].Position[2] = matrix.Abs(x-4) * 2
+	}
+	_, outIndexes := SimplifyMesh(verts, indexes, 6, 0.01)
+	if len(outIndexes) <= 6 {
+		t.Errorf("expected the max error to stop the fold from collapsing")
+	}
+	_, unlimited := SimplifyMesh(verts, indexes, 6, 0)
+	if len(unlimited) >= len(outIndexes) {
+		t.Errorf("expected more triangles to be removed without an error limit")
+	}
+}
+
+func TestGenerateMeshLODs(t *testing.T) {
+	verts, indexes := testGridMesh(16)
+	lods := GenerateMeshLODs(verts, indexes, DefaultMeshLODSettings())
+	if len(lods) != len(DefaultMeshLODSettings()) {
+		t.Fatalf("expected %d levels, got %d", len(DefaultMeshLODSettings()), len(lods))
+	}
+	last := len(indexes)
+	for i := range lods {
+		if len(lods[i].Indexes) >= last {
+			t.Errorf("expected level %d to have fewer indexes than the last", i)
+		}
+		last = len(lods[i].Indexes)
+	}
+	tiny := GenerateMeshLODs(verts[:3], []uint32{0, 1, 2}, DefaultMeshLODSettings())
+	if len(tiny) != 0 {
+		t.Errorf("expected no levels for a mesh that can't be reduced")
+	}
+}
@@ -0,0 +1,423 @@
+/******************************************************************************/
+/* post_process.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"errors"
+	"kaiju/assets"
+	"kaiju/matrix"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+const (
+	PostProcessTonemap      = "Tonemap"
+	PostProcessBloom        = "Bloom"
+	PostProcessFXAA         = "FXAA"
+	PostProcessColorGrading = "ColorGrading"
+	PostProcessVignette     = "Vignette"
+)
+
+// postProcessEffect describes a built in post process effect, the parameter
+// names are the components of #ShaderDataPostProcess.Params in order
+type postProcessEffect struct {
+	shader     string
+	parameters []string
+	defaults   matrix.Vec4
+	color      matrix.Color
+}
+
+var postProcessEffects = map[string]postProcessEffect{
+	PostProcessTonemap: {
+		shader:     assets.ShaderDefinitionPostTonemap,
+		parameters: []string{"exposure", "gamma"},
+		defaults:   matrix.Vec4{1, 1, 0, 0},
+	},
+	PostProcessBloom: {
+		shader:     assets.ShaderDefinitionPostBloom,
+		parameters: []string{"threshold", "intensity", "radius"},
+		defaults:   matrix.Vec4{0.8, 0.6, 4, 0},
+	},
+	PostProcessFXAA: {
+		shader:     assets.ShaderDefinitionPostFXAA,
+		parameters: []string{"subpixel", "edgeThreshold", "edgeThresholdMin"},
+		defaults:   matrix.Vec4{0.75, 0.166, 0.0833, 0},
+	},
+	PostProcessColorGrading: {
+		shader:     assets.ShaderDefinitionPostColorGrading,
+		parameters: []string{"strength", "lutSize"},
+		defaults:   matrix.Vec4{1, 16, 0, 0},
+	},
+	PostProcessVignette: {
+		shader:     assets.ShaderDefinitionPostVignette,
+		parameters: []string{"intensity", "smoothness", "roundness"},
+		defaults:   matrix.Vec4{0.35, 0.45, 1, 0},
+		color:      matrix.ColorBlack(),
+	},
+}
+
+// ShaderDataPostProcess is the instance data shared by every post process
+// shader. Built in effects name the components of Params, custom effects can
+// set "params" and "color" directly.
+type ShaderDataPostProcess struct {
+	ShaderDataBase
+	Params matrix.Vec4
+	Color  matrix.Color
+}
+
+func (t ShaderDataPostProcess) Size() int {
+	return int(unsafe.Sizeof(ShaderDataPostProcess{}) - ShaderBaseDataStart)
+}
+
+// PostProcessPassDef is a single pass within a #PostProcessDef. Either the
+// Effect names one of the built in effects or Shader references the shader
+// definition of a custom fullscreen pass. The textures are bound after the
+// color being processed, for example the LUT of the color grading effect.
+type PostProcessPassDef struct {
+	Name       string
+	Effect     string `json:",omitempty"`
+	Shader     string `json:",omitempty"`
+	Enabled    bool
+	Parameters map[string][]matrix.Float `json:",omitempty"`
+	Textures   []MaterialTextureDef      `json:",omitempty"`
+}
+
+// PostProcessDef is the JSON asset format of a post process stack, the passes
+// are applied in the order they are listed
+type PostProcessDef struct {
+	Passes []PostProcessPassDef
+}
+
+// PostProcessPass is a fullscreen pass of a #PostProcessStack. Changes to the
+// parameters take effect on the next frame.
+type PostProcessPass struct {
+	Name     string
+	Effect   string
+	Shader   string
+	Textures []MaterialTextureDef
+	Data     ShaderDataPostProcess
+	enabled  bool
+	stack    *PostProcessStack
+}
+
+// PostProcessStack is the ordered list of post process passes that are
+// applied to the final image of a camera
+type PostProcessStack struct {
+	passes  []*PostProcessPass
+	version uint32
+}
+
+func NewPostProcessStack() *PostProcessStack {
+	return &PostProcessStack{passes: make([]*PostProcessPass, 0)}
+}
+
+// NewPostProcessPass creates a pass for one of the built in effects using the
+// default parameters of the effect, the pass is named after the effect
+func NewPostProcessPass(effect string) (*PostProcessPass, error) {
+	e, ok := postProcessEffects[effect]
+	if !ok {
+		return nil, errors.New("unknown post process effect " + effect)
+	}
+	p := &PostProcessPass{
+		Name:    effect,
+		Effect:  effect,
+		Shader:  e.shader,
+		enabled: true,
+	}
+	p.Data.ShaderDataBase = NewShaderDataBase()
+	p.Data.Params = e.defaults
+	p.Data.Color = e.color
+	return p, nil
+}
+
+// NewPostProcessShaderPass creates a pass for a custom shader definition,
+// the shader should use the same instance data as the built in effects
+func NewPostProcessShaderPass(name, shaderDefinition string) *PostProcessPass {
+	p := &PostProcessPass{
+		Name:    name,
+		Shader:  shaderDefinition,
+		enabled: true,
+	}
+	p.Data.ShaderDataBase = NewShaderDataBase()
+	p.Data.Color = matrix.ColorWhite()
+	return p
+}
+
+func PostProcessDefFromJson(jsonStr string) (PostProcessDef, error) {
+	var def PostProcessDef
+	if err := json.Unmarshal([]byte(jsonStr), &def); err != nil {
+		return def, err
+	}
+	return def, def.Validate()
+}
+
+func (d PostProcessDef) ToJson() (string, error) {
+	data, err := json.MarshalIndent(d, "", "\t")
+	return string(data), err
+}
+
+// Validate returns an error if any of the passes are unnamed, share a name,
+// do not reference an effect or a shader, or have an unknown texture filter
+func (d *PostProcessDef) Validate() error {
+	for i := range d.Passes {
+		p := &d.Passes[i]
+		if p.Name == "" {
+			return errors.New("the post process stack has a pass without a name")
+		}
+		if p.Effect == "" && p.Shader == "" {
+			return errors.New("the post process pass " + p.Name + " has no effect or shader")
+		}
+		if p.Effect != "" {
+			if _, ok := postProcessEffects[p.Effect]; !ok {
+				return errors.New("unknown post process effect " + p.Effect)
+			}
+		}
+		for j := range p.Textures {
+			if _, err := p.Textures[j].TextureFilter(); err != nil {
+				return err
+			}
+		}
+		for j := range i {
+			if d.Passes[j].Name == p.Name {
+				return errors.New("the post process pass " + p.Name + " is duplicated")
+			}
+		}
+	}
+	return nil
+}
+
+// LoadPostProcessStack reads the post process definition asset and creates a
+// stack from it, the stack can then be given to a camera through
+// #Renderer.SetPostProcess
+func LoadPostProcessStack(assetDatabase *assets.Database, key string) (*PostProcessStack, error) {
+	str, err := assetDatabase.ReadText(key)
+	if err != nil {
+		return nil, err
+	}
+	def, err := PostProcessDefFromJson(str)
+	if err != nil {
+		return nil, err
+	}
+	return PostProcessStackFromDef(def)
+}
+
+// PostProcessStackFromDef creates a stack with the passes of the definition
+func PostProcessStackFromDef(def PostProcessDef) (*PostProcessStack, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	s := NewPostProcessStack()
+	for i := range def.Passes {
+		pd := &def.Passes[i]
+		var p *PostProcessPass
+		if pd.Effect != "" {
+			p, _ = NewPostProcessPass(pd.Effect)
+			p.Name = pd.Name
+			if pd.Shader != "" {
+				p.Shader = pd.Shader
+			}
+		} else {
+			p = NewPostProcessShaderPass(pd.Name, pd.Shader)
+		}
+		p.enabled = pd.Enabled
+		p.Textures = slices.Clone(pd.Textures)
+		for name, value := range pd.Parameters {
+			if err := p.SetParameter(name, value...); err != nil {
+				return nil, err
+			}
+		}
+		s.Add(p)
+	}
+	return s, nil
+}
+
+// Def converts the stack back into its asset format
+func (s *PostProcessStack) Def() PostProcessDef {
+	def := PostProcessDef{Passes: make([]PostProcessPassDef, len(s.passes))}
+	for i, p := range s.passes {
+		params := map[string][]matrix.Float{
+			"params": slices.Clone(p.Data.Params[:]),
+			"color":  slices.Clone(p.Data.Color[:]),
+		}
+		def.Passes[i] = PostProcessPassDef{
+			Name:       p.Name,
+			Effect:     p.Effect,
+			Enabled:    p.enabled,
+			Parameters: params,
+			Textures:   slices.Clone(p.Textures),
+		}
+		if p.Effect == "" || p.Shader != postProcessEffects[p.Effect].shader {
+			def.Passes[i].Shader = p.Shader
+		}
+	}
+	return def
+}
+
+// SetParameter writes a parameter of the pass. Built in effects accept the
+// names of their parameters, all passes accept "params" and "color" to set
+// the instance data directly. Names are not case sensitive.
+func (p *PostProcessPass) SetParameter(name string, value ...matrix.Float) error {
+	if len(value) == 0 {
+		return errors.New("no value given for the post process parameter " + name)
+	}
+	switch strings.ToLower(name) {
+	case "params":
+		copy(p.Data.Params[:], value)
+		return nil
+	case "color":
+		copy(p.Data.Color[:], value)
+		return nil
+	}
+	if e, ok := postProcessEffects[p.Effect]; ok {
+		for i := range e.parameters {
+			if strings.EqualFold(e.parameters[i], name) {
+				p.Data.Params[i] = value[0]
+				return nil
+			}
+		}
+	}
+	return errors.New("the post process pass " + p.Name + " has no parameter " + name)
+}
+
+// Parameter reads a parameter of the pass by name, see #SetParameter
+func (p *PostProcessPass) Parameter(name string) (matrix.Float, bool) {
+	if e, ok := postProcessEffects[p.Effect]; ok {
+		for i := range e.parameters {
+			if strings.EqualFold(e.parameters[i], name) {
+				return p.Data.Params[i], true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (p *PostProcessPass) IsEnabled() bool { return p.enabled }
+
+func (p *PostProcessPass) SetEnabled(enabled bool) {
+	if p.enabled != enabled {
+		p.enabled = enabled
+		if p.stack != nil {
+			p.stack.version++
+		}
+	}
+}
+
+// Passes returns all of the passes of the stack in order
+func (s *PostProcessStack) Passes() []*PostProcessPass { return s.passes }
+
+// Enabled returns the enabled passes of the stack in the order that they are
+// applied
+func (s *PostProcessStack) Enabled() []*PostProcessPass {
+	enabled := make([]*PostProcessPass, 0, len(s.passes))
+	for _, p := range s.passes {
+		if p.enabled {
+			enabled = append(enabled, p)
+		}
+	}
+	return enabled
+}
+
+// Version changes every time that the passes of the stack are added, removed,
+// reordered, enabled, or disabled
+func (s *PostProcessStack) Version() uint32 { return s.version }
+
+// Add appends the pass to the end of the stack
+func (s *PostProcessStack) Add(pass *PostProcessPass) {
+	s.Insert(len(s.passes), pass)
+}
+
+// Insert places the pass at the index of the stack, the index is clamped to
+// the bounds of the stack
+func (s *PostProcessStack) Insert(index int, pass *PostProcessPass) {
+	index = max(0, min(index, len(s.passes)))
+	pass.stack = s
+	s.passes = slices.Insert(s.passes, index, pass)
+	s.version++
+}
+
+// Find returns the first pass with the given name
+func (s *PostProcessStack) Find(name string) (*PostProcessPass, bool) {
+	idx := s.indexOf(name)
+	if idx < 0 {
+		return nil, false
+	}
+	return s.passes[idx], true
+}
+
+// Remove removes the first pass with the given name from the stack
+func (s *PostProcessStack) Remove(name string) bool {
+	idx := s.indexOf(name)
+	if idx < 0 {
+		return false
+	}
+	s.passes[idx].stack = nil
+	s.passes = slices.Delete(s.passes, idx, idx+1)
+	s.version++
+	return true
+}
+
+// Move changes the order of the named pass so that it is at the index
+func (s *PostProcessStack) Move(name string, index int) bool {
+	idx := s.indexOf(name)
+	if idx < 0 {
+		return false
+	}
+	pass := s.passes[idx]
+	s.passes = slices.Delete(s.passes, idx, idx+1)
+	index = max(0, min(index, len(s.passes)))
+	s.passes = slices.Insert(s.passes, index, pass)
+	s.version++
+	return true
+}
+
+// SetEnabled enables or disables the named pass
+func (s *PostProcessStack) SetEnabled(name string, enabled bool) bool {
+	if p, ok := s.Find(name); ok {
+		p.SetEnabled(enabled)
+		return true
+	}
+	return false
+}
+
+func (s *PostProcessStack) indexOf(name string) int {
+	return slices.IndexFunc(s.passes, func(p *PostProcessPass) bool {
+		return p.Name == name
+	})
+}
@@ -0,0 +1,180 @@
+/******************************************************************************/
+/* post_process_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"slices"
+	"testing"
+)
+
+func postProcessNames(s *PostProcessStack) []string {
+	names := make([]string, 0, len(s.Passes()))
+	for _, p := range s.Passes() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestPostProcessPassParameters(t *testing.T) {
+	p, err := NewPostProcessPass(PostProcessTonemap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := p.Parameter("exposure"); !ok || v != 1 {
+		t.Errorf("expected the default exposure of 1, got %v", v)
+	}
+	if err := p.SetParameter("Exposure", 2.5); err != nil {
+		t.Fatal(err)
+	}
+	if p.Data.Params.X() != 2.5 {
+		t.Errorf("expected the exposure to be written to the params, got %v", p.Data.Params)
+	}
+	if err := p.SetParameter("color", 1, 0, 0, 1); err != nil || p.Data.Color != matrix.ColorRed() {
+		t.Errorf("expected the color to be set directly, got %v %v", p.Data.Color, err)
+	}
+	if err := p.SetParameter("radius", 1); err == nil {
+		t.Errorf("expected an error for a parameter of another effect")
+	}
+	if _, err := NewPostProcessPass("Blur"); err == nil {
+		t.Errorf("expected an error for an unknown effect")
+	}
+}
+
+func TestPostProcessStackOrder(t *testing.T) {
+	s := NewPostProcessStack()
+	for _, e := range []string{PostProcessBloom, PostProcessTonemap, PostProcessVignette} {
+		p, _ := NewPostProcessPass(e)
+		s.Add(p)
+	}
+	fxaa, _ := NewPostProcessPass(PostProcessFXAA)
+	s.Insert(2, fxaa)
+	expect := []string{PostProcessBloom, PostProcessTonemap, PostProcessFXAA, PostProcessVignette}
+	if got := postProcessNames(s); !slices.Equal(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	version := s.Version()
+	if !s.Move(PostProcessVignette, 0) || s.Version() == version {
+		t.Errorf("expected the move to change the stack version")
+	}
+	expect = []string{PostProcessVignette, PostProcessBloom, PostProcessTonemap, PostProcessFXAA}
+	if got := postProcessNames(s); !slices.Equal(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	version = s.Version()
+	s.SetEnabled(PostProcessBloom, false)
+	if s.Version() == version || len(s.Enabled()) != 3 {
+		t.Errorf("expected disabling a pass to remove it from the enabled passes")
+	}
+	version = s.Version()
+	s.SetEnabled(PostProcessBloom, false)
+	if s.Version() != version {
+		t.Errorf("expected no change when the pass is already disabled")
+	}
+	if !s.Remove(PostProcessTonemap) || s.Remove(PostProcessTonemap) {
+		t.Errorf("expected the pass to be removed only once")
+	}
+	if _, ok := s.Find(PostProcessTonemap); ok || len(s.Passes()) != 3 {
+		t.Errorf("expected the removed pass to no longer be found")
+	}
+}
+
+func TestPostProcessDefRoundTrip(t *testing.T) {
+	def, err := PostProcessDefFromJson(`{
+		"Passes": [
+			{ "Name": "Tonemap", "Effect": "Tonemap", "Enabled": true,
+				"Parameters": { "exposure": [1.5] } },
+			{ "Name": "Grade", "Effect": "ColorGrading", "Enabled": false,
+				"Textures": [{ "Name": "lut", "Texture": "textures/lut.png" }] },
+			{ "Name": "Custom", "Shader": "shaders/definitions/custom.json", "Enabled": true }
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := PostProcessStackFromDef(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Enabled()) != 2 {
+		t.Errorf("expected 2 enabled passes, got %d", len(s.Enabled()))
+	}
+	if p, _ := s.Find("Tonemap"); p.Data.Params.X() != 1.5 {
+		t.Errorf("expected the exposure from the definition, got %v", p.Data.Params)
+	}
+	str, err := s.Def().ToJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := PostProcessDefFromJson(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := PostProcessStackFromDef(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range s.Passes() {
+		p2 := s2.Passes()[i]
+		if p.Name != p2.Name || p.Shader != p2.Shader || p.IsEnabled() != p2.IsEnabled() ||
+			p.Data.Params != p2.Data.Params || len(p.Textures) != len(p2.Textures) {
+			t.Errorf("expected pass %d to round trip, got %+v", i, p2)
+		}
+	}
+}
+
+func TestPostProcessDefValidation(t *testing.T) {
+	invalid := []string{
+		`{ "Passes": [{ "Effect": "Tonemap" }] }`,
+		`{ "Passes": [{ "Name": "a" }] }`,
+		`{ "Passes": [{ "Name": "a", "Effect": "Blur" }] }`,
+		`{ "Passes": [{ "Name": "a", "Effect": "FXAA" }, { "Name": "a", "Effect": "Bloom" }] }`,
+		`{ "Passes": [{ "Name": "a", "Effect": "ColorGrading",
+			"Textures": [{ "Name": "lut", "Texture": "lut.png", "Filter": "Cubic" }] }] }`,
+	}
+	for i := range invalid {
+		if _, err := PostProcessDefFromJson(invalid[i]); err == nil {
+			t.Errorf("expected definition %d to be invalid", i)
+		}
+	}
+	def, _ := PostProcessDefFromJson(`{ "Passes": [{ "Name": "a", "Effect": "FXAA",
+		"Parameters": { "gamma": [2] } }] }`)
+	if _, err := PostProcessStackFromDef(def); err == nil {
+		t.Errorf("expected an error for a parameter the effect does not have")
+	}
+}
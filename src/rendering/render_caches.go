@@ -40,6 +40,7 @@ package rendering
 type RenderCaches interface {
 	ShaderCache() *ShaderCache
 	TextureCache() *TextureCache
+	MaterialCache() *MaterialCache
 	MeshCache() *MeshCache
 	FontCache() *FontCache
 }
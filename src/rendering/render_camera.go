@@ -0,0 +1,198 @@
+/******************************************************************************/
+/* render_camera.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"slices"
+)
+
+// RenderCamera draws the drawings of the default canvas that are on its
+// layers from the point of view of its own camera into its own canvas. The
+// color of the canvas can be used as a texture, like for the image of a
+// minimap, a security monitor, or one of the viewports of a split screen.
+type RenderCamera struct {
+	Name     string
+	Camera   cameras.Camera
+	Canvas   Canvas
+	renderer Renderer
+	layers   RenderLayers
+	drawings Drawings
+	parent   *Drawings
+	active   bool
+}
+
+// NewRenderCamera creates a render camera that draws into the canvas, the
+// camera draws nothing until it is added to the #Drawings to mirror
+func NewRenderCamera(renderer Renderer, name string, camera cameras.Camera, canvas Canvas, layers RenderLayers) *RenderCamera {
+	return &RenderCamera{
+		Name:     name,
+		Camera:   camera,
+		Canvas:   canvas,
+		renderer: renderer,
+		layers:   layers,
+		drawings: NewDrawings(),
+		active:   true,
+	}
+}
+
+// Texture returns the color that the camera draws into
+func (c *RenderCamera) Texture() *Texture { return c.Canvas.Color() }
+
+func (c *RenderCamera) Layers() RenderLayers { return c.layers }
+
+// SetLayers changes the layers that the camera draws, the drawings of the
+// camera are rebuilt from the drawings that it mirrors
+func (c *RenderCamera) SetLayers(layers RenderLayers) {
+	if c.layers == layers {
+		return
+	}
+	c.layers = layers
+	if c.parent != nil {
+		c.drawings.release(c.renderer)
+		c.parent.mutex.Lock()
+		defer c.parent.mutex.Unlock()
+		c.parent.mirrorAll(c)
+	}
+}
+
+func (c *RenderCamera) IsActive() bool { return c.active }
+
+// SetActive pauses or resumes the drawing of the camera, the canvas keeps
+// the last image that was drawn while the camera is paused
+func (c *RenderCamera) SetActive(active bool) { c.active = active }
+
+// Drawings gives access to the drawings of the camera, for example to disable
+// frustum culling or to read the culling stats
+func (c *RenderCamera) Drawings() *Drawings { return &c.drawings }
+
+func (c *RenderCamera) sees(drawing *Drawing) bool {
+	return c.layers.Has(drawing.layers())
+}
+
+// AddCamera starts mirroring the drawings of the default canvas that are on
+// the layers of the camera into the drawings of the camera
+func (d *Drawings) AddCamera(camera *RenderCamera) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	camera.parent = d
+	camera.drawings.cullingDisabled = d.cullingDisabled
+	d.cameras = append(d.cameras, camera)
+	d.mirrorAll(camera)
+}
+
+// RemoveCamera stops mirroring the drawings into the camera and releases the
+// drawings of the camera, the canvas of the camera is not destroyed
+func (d *Drawings) RemoveCamera(camera *RenderCamera) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	idx := slices.Index(d.cameras, camera)
+	if idx < 0 {
+		return false
+	}
+	d.cameras = slices.Delete(d.cameras, idx, idx+1)
+	camera.drawings.release(camera.renderer)
+	camera.parent = nil
+	return true
+}
+
+// Cameras returns the render cameras that are mirroring the drawings
+func (d *Drawings) Cameras() []*RenderCamera { return d.cameras }
+
+// RenderCameras draws each of the active render cameras. The level of detail,
+// culling, and lights are selected for each camera before it is drawn, so
+// this should be done after the renderer is ready for the frame and before
+// the same is done for the main camera.
+func (d *Drawings) RenderCameras(renderer Renderer, lights *LightList) {
+	for _, c := range d.cameras {
+		if !c.active || !c.drawings.HasDrawings() {
+			continue
+		}
+		c.drawings.SelectLODs(c.Camera)
+		c.drawings.Cull(c.Camera.Frustum())
+		c.drawings.SelectLights(lights)
+		renderer.DrawCamera(c.Camera, c.drawings.draws)
+	}
+}
+
+// mirror records the drawing so that cameras added later can mirror it and
+// passes it on to the cameras that see it, only drawings of the default
+// canvas are mirrored. The write lock of the drawings must be held.
+func (d *Drawings) mirror(drawing *Drawing) {
+	if drawing.Renderer == nil || drawing.ShaderData == nil ||
+		drawing.renderTarget != drawing.Renderer.DefaultCanvas() {
+		return
+	}
+	if d.sources == nil {
+		d.sources = make(map[DrawInstance]Drawing)
+	}
+	d.sources[drawing.ShaderData] = *drawing
+	if len(d.sources) > max(d.sourcesPruned*2, minSourcesPrune) {
+		d.pruneSources()
+	}
+	for _, c := range d.cameras {
+		if c.sees(drawing) {
+			c.drawings.AddDrawings([]Drawing{*drawing}, c.Canvas)
+		}
+	}
+}
+
+// mirrorAll passes every recorded drawing that the camera sees on to it
+func (d *Drawings) mirrorAll(camera *RenderCamera) {
+	d.pruneSources()
+	drawings := make([]Drawing, 0, len(d.sources))
+	for _, s := range d.sources {
+		if camera.sees(&s) {
+			drawings = append(drawings, s)
+		}
+	}
+	camera.drawings.AddDrawings(drawings, camera.Canvas)
+}
+
+// minSourcesPrune is how many drawings are recorded before destroyed
+// drawings are first removed from the record
+const minSourcesPrune = 256
+
+func (d *Drawings) pruneSources() {
+	for k := range d.sources {
+		if k.IsDestroyed() {
+			delete(d.sources, k)
+		}
+	}
+	d.sourcesPruned = len(d.sources)
+}
@@ -0,0 +1,169 @@
+/******************************************************************************/
+/* render_camera_test.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/matrix"
+	"testing"
+)
+
+// testCameraRenderer is the part of a renderer that drawings and render
+// cameras use on the CPU, any other call will panic
+type testCameraRenderer struct {
+	Renderer
+	canvas OITCanvas
+}
+
+func (r *testCameraRenderer) DefaultCanvas() Canvas           { return &r.canvas }
+func (r *testCameraRenderer) DestroyGroup(*DrawInstanceGroup) {}
+
+func testCameraInstanceCount(d *Drawings, target Canvas) int {
+	count := 0
+	for i := range d.draws {
+		if d.draws[i].Target != target {
+			continue
+		}
+		for j := range d.draws[i].innerDraws {
+			for k := range d.draws[i].innerDraws[j].instanceGroups {
+				count += len(d.draws[i].innerDraws[j].instanceGroups[k].Instances)
+			}
+		}
+	}
+	return count
+}
+
+func TestRenderLayers(t *testing.T) {
+	mask := RenderLayerDefault.With(UserRenderLayer(1))
+	if !mask.Has(RenderLayerDefault) || mask.Has(RenderLayerUI) || mask.Has(UserRenderLayer(0)) {
+		t.Errorf("unexpected layers in the mask %b", mask)
+	}
+	if mask.Without(RenderLayerDefault) != UserRenderLayer(1) {
+		t.Errorf("expected only the user layer to remain")
+	}
+	if UserRenderLayer(0) != RenderLayerUser || UserRenderLayer(100) != UserRenderLayer(MaxUserRenderLayers-1) {
+		t.Errorf("expected the user layers to start at RenderLayerUser and be clamped")
+	}
+	if UserRenderLayer(MaxUserRenderLayers-1) == RenderLayersNone {
+		t.Errorf("expected the last user layer to fit in the mask")
+	}
+	if (&Drawing{}).layers() != RenderLayerDefault {
+		t.Errorf("expected drawings without layers to be on the default layer")
+	}
+}
+
+func TestRenderCameraMirrorsDrawings(t *testing.T) {
+	renderer := &testCameraRenderer{}
+	shader := &Shader{}
+	mesh := NewMesh("cube", meshCube(matrix.ColorWhite()), nil)
+	drawing := func(layers RenderLayers) (Drawing, *ShaderDataBasic) {
+		sd := testCullingInstance(matrix.Vec3Zero())
+		return Drawing{
+			Renderer:   renderer,
+			Shader:     shader,
+			Mesh:       mesh,
+			ShaderData: sd,
+			Layers:     layers,
+		}, sd
+	}
+	d := NewDrawings()
+	world, worldData := drawing(RenderLayersNone)
+	ui, _ := drawing(RenderLayerUI)
+	offscreen, _ := drawing(RenderLayersNone)
+	d.AddDrawings([]Drawing{world, ui}, renderer.DefaultCanvas())
+	d.AddDrawings([]Drawing{offscreen}, &CombineCanvas{})
+	d.PreparePending()
+
+	canvas := &OITCanvas{}
+	camera := NewRenderCamera(renderer, "minimap",
+		cameras.NewStandardCamera(256, 256, matrix.Vec3Backward()), canvas, RenderLayerDefault)
+	d.AddCamera(camera)
+	d.PreparePending()
+	if got := testCameraInstanceCount(camera.Drawings(), canvas); got != 1 {
+		t.Errorf("expected the camera to only see the world drawing, got %d", got)
+	}
+	late, _ := drawing(RenderLayerDefault)
+	d.AddDrawings([]Drawing{late}, renderer.DefaultCanvas())
+	d.PreparePending()
+	if got := testCameraInstanceCount(camera.Drawings(), canvas); got != 2 {
+		t.Errorf("expected drawings added later to be mirrored, got %d", got)
+	}
+	camera.SetLayers(RenderLayersAll)
+	d.PreparePending()
+	if got := testCameraInstanceCount(camera.Drawings(), canvas); got != 3 {
+		t.Errorf("expected the camera to see the ui drawing after changing layers, got %d", got)
+	}
+	if got := testCameraInstanceCount(&d, renderer.DefaultCanvas()); got != 3 {
+		t.Errorf("expected changing layers to leave the main drawings alone, got %d", got)
+	}
+	if !d.RemoveCamera(camera) || len(d.Cameras()) != 0 {
+		t.Fatalf("expected the camera to be removed")
+	}
+	if worldData.IsDestroyed() || testCameraInstanceCount(camera.Drawings(), canvas) != 0 {
+		t.Errorf("expected removing the camera to release its drawings but not the instances")
+	}
+}
+
+func TestRenderCameraSkipsDestroyedDrawings(t *testing.T) {
+	renderer := &testCameraRenderer{}
+	mesh := NewMesh("cube", meshCube(matrix.ColorWhite()), nil)
+	d := NewDrawings()
+	instances := make([]*ShaderDataBasic, 3)
+	for i := range instances {
+		instances[i] = testCullingInstance(matrix.Vec3Zero())
+		d.AddDrawings([]Drawing{{
+			Renderer:   renderer,
+			Shader:     &Shader{},
+			Mesh:       mesh,
+			ShaderData: instances[i],
+		}}, renderer.DefaultCanvas())
+	}
+	d.PreparePending()
+	instances[1].Destroy()
+	canvas := &OITCanvas{}
+	camera := NewRenderCamera(renderer, "monitor",
+		cameras.NewStandardCamera(256, 256, matrix.Vec3Backward()), canvas, RenderLayersAll)
+	d.AddCamera(camera)
+	d.PreparePending()
+	if got := testCameraInstanceCount(camera.Drawings(), canvas); got != 2 {
+		t.Errorf("expected destroyed drawings to not be mirrored, got %d", got)
+	}
+	if len(d.sources) != 2 {
+		t.Errorf("expected the destroyed drawing to be pruned, got %d", len(d.sources))
+	}
+}
@@ -57,6 +57,7 @@ type ShaderDriverData struct {
 	DescriptorSetLayoutStructure
 	CullMode              vk.CullModeFlagBits
 	DrawMode              MeshDrawMode
+	DepthCompare          vk.CompareOp
 	Stride                uint32
 	AttributeDescriptions []vk.VertexInputAttributeDescription
 	pipelineConstructor   FuncPipeline
@@ -85,11 +86,20 @@ func (d *ShaderDriverData) setup(def ShaderDef, locationStart uint32, pipeFunc F
 	default:
 		d.DrawMode = MeshDrawModeTriangles
 	}
+	switch strings.ToLower(def.DepthCompare) {
+	case "lessorequal":
+		d.DepthCompare = vk.CompareOpLessOrEqual
+	case "always":
+		d.DepthCompare = vk.CompareOpAlways
+	default:
+		d.DepthCompare = vk.CompareOpLess
+	}
 }
 
 func NewShaderDriverData() ShaderDriverData {
 	return ShaderDriverData{
-		CullMode: vk.CullModeFrontBit,
+		CullMode:     vk.CullModeFrontBit,
+		DepthCompare: vk.CompareOpLess,
 	}
 }
 
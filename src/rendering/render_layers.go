@@ -0,0 +1,73 @@
+/******************************************************************************/
+/* render_layers.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+// RenderLayers is a mask of the layers that a drawing is on, a #RenderCamera
+// only draws the drawings that are on at least one of the layers of its mask
+type RenderLayers uint32
+
+const (
+	RenderLayerDefault RenderLayers = 1 << iota
+	RenderLayerUI
+	// RenderLayerUser is the first of the layers that are free for games to
+	// use, further layers are created with #UserRenderLayer
+	RenderLayerUser
+)
+
+const (
+	RenderLayersNone RenderLayers = 0
+	RenderLayersAll  RenderLayers = ^RenderLayers(0)
+	// MaxUserRenderLayers is the number of layers from #RenderLayerUser on
+	MaxUserRenderLayers = 30
+)
+
+// UserRenderLayer returns the user layer at the index, the index is clamped to
+// the layers that are available to games
+func UserRenderLayer(index int) RenderLayers {
+	index = max(0, min(index, MaxUserRenderLayers-1))
+	return RenderLayerUser << index
+}
+
+// Has returns true if any of the layers are in the mask
+func (l RenderLayers) Has(layers RenderLayers) bool { return l&layers != 0 }
+
+// With returns the mask with the layers added
+func (l RenderLayers) With(layers RenderLayers) RenderLayers { return l | layers }
+
+// Without returns the mask with the layers removed
+func (l RenderLayers) Without(layers RenderLayers) RenderLayers { return l &^ layers }
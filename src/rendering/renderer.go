@@ -46,9 +46,16 @@ import (
 type Renderer interface {
 	Initialize(caches RenderCaches, width, height int32) error
 	ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, runtime float32) bool
+	SetLights(lights *LightList)
+	SetEnvironment(texture *Texture, intensity matrix.Float)
+	Environment() (*Texture, matrix.Float)
+	SetPostProcess(camera cameras.Camera, stack *PostProcessStack)
+	PostProcess(camera cameras.Camera) *PostProcessStack
 	CreateShader(shader *Shader, assetDatabase *assets.Database)
+	ReloadShader(shader *Shader, assetDatabase *assets.Database) error
 	CreateMesh(mesh *Mesh, verts []Vertex, indices []uint32)
 	CreateTexture(texture *Texture, textureData *TextureData)
+	TextureFormatSupported(format TextureInputType) bool
 	TextureReadPixel(texture *Texture, x, y int) matrix.Color
 	TextureWritePixels(texture *Texture, x, y, width, height int, pixels []byte)
 	Draw(drawings []RenderTargetDraw)
@@ -62,6 +69,9 @@ type Renderer interface {
 	DestroyMesh(mesh *Mesh)
 	Destroy()
 	RegisterCanvas(name string, canvas Canvas)
+	CreateCameraCanvas(name string, width, height int32) (Canvas, error)
+	DestroyCanvas(name string)
+	DrawCamera(camera cameras.Camera, drawings []RenderTargetDraw)
 	Canvas(name string) (Canvas, bool)
 	DefaultCanvas() Canvas
 	WaitForRender()
@@ -45,6 +45,7 @@ import (
 	"kaiju/matrix"
 	"log/slog"
 	"math"
+	"sync"
 	"unsafe"
 
 	vk "kaiju/rendering/vulkan"
@@ -101,20 +102,54 @@ type Vulkan struct {
 	defaultCanvas              OITCanvas
 	outlineCanvas              OutlineCanvas
 	combineCanvas              CombineCanvas
+	shadowCanvas               ShadowCanvas
+	postProcessCanvas          PostProcessCanvas
 	combinedDrawings           Drawings
 	preRuns                    []func()
 	canvases                   map[string]Canvas
 	dbg                        debugVulkan
 	hasSwapChain               bool
+	lights                     *LightList
+	postProcess                map[cameras.Camera]*PostProcessStack
+	frameCamera                cameras.Camera
+	frameShaderData            GlobalShaderData
+	cameraCanvas               *CameraCanvas
+	environment                *Texture
+	defaultEnvironment         *Texture
+	environmentIntensity       matrix.Float
 }
 
-func init() {
-	klib.Must(vk.SetDefaultGetInstanceProcAddr())
-	klib.Must(vk.Init())
-}
+// loadVulkan loads the Vulkan library the first time a renderer is created
+// rather than on package init so that the CPU side of the package (drawings,
+// culling, meshes) can be used and tested on machines without Vulkan
+var loadVulkan = sync.OnceValue(func() error {
+	if err := vk.SetDefaultGetInstanceProcAddr(); err != nil {
+		return err
+	}
+	return vk.Init()
+})
 
 func (vr *Vulkan) DefaultCanvas() Canvas { return &vr.defaultCanvas }
 
+func (vr *Vulkan) SetLights(lights *LightList) { vr.lights = lights }
+
+// ShadowCanvas returns the canvas that renders the shadow map atlas
+func (vr *Vulkan) ShadowCanvas() *ShadowCanvas { return &vr.shadowCanvas }
+
+// SetPostProcess sets the post process stack that is applied to the frames
+// rendered with the camera, a nil stack removes post processing
+func (vr *Vulkan) SetPostProcess(camera cameras.Camera, stack *PostProcessStack) {
+	if stack == nil {
+		delete(vr.postProcess, camera)
+	} else {
+		vr.postProcess[camera] = stack
+	}
+}
+
+func (vr *Vulkan) PostProcess(camera cameras.Camera) *PostProcessStack {
+	return vr.postProcess[camera]
+}
+
 func (vr *Vulkan) Canvas(name string) (Canvas, bool) {
 	c, ok := vr.canvases[name]
 	if !ok {
@@ -140,6 +175,15 @@ func (vr *Vulkan) WaitForRender() {
 	vk.WaitForFences(vr.device, uint32(len(fences)), &fences[0], vk.True, math.MaxUint64)
 }
 
+// frameUniformBuffer is the global uniform buffer for the camera that is
+// currently being drawn
+func (vr *Vulkan) frameUniformBuffer() vk.Buffer {
+	if vr.cameraCanvas != nil {
+		return vr.cameraCanvas.uniformBuffers[vr.currentFrame]
+	}
+	return vr.globalUniformBuffers[vr.currentFrame]
+}
+
 func (vr *Vulkan) createGlobalUniformBuffers() {
 	bufferSize := vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil)))
 	for i := uint64(0); i < maxFramesInFlight; i++ {
@@ -212,6 +256,14 @@ func (vr *Vulkan) updateGlobalUniformBuffer(camera cameras.Camera, uiCamera came
 			matrix.Float(vr.swapChainExtent.Height),
 		},
 	}
+	if vr.lights != nil {
+		vr.lights.shaderData(&ubo)
+	}
+	vr.shadowCanvas.update(camera, vr.lights, &ubo)
+	if vr.environment != nil {
+		ubo.EnvironmentIntensity = vr.environmentIntensity
+	}
+	vr.frameShaderData = ubo
 	var data unsafe.Pointer
 	r := vk.MapMemory(vr.device, vr.globalUniformBuffersMemory[vr.currentFrame],
 		0, vk.DeviceSize(unsafe.Sizeof(ubo)), 0, &data)
@@ -233,6 +285,9 @@ func (vr *Vulkan) createColorResources() bool {
 }
 
 func NewVKRenderer(window RenderingContainer, applicationName string) (*Vulkan, error) {
+	if err := loadVulkan(); err != nil {
+		return nil, err
+	}
 	vr := &Vulkan{
 		window:           window,
 		instance:         vk.NullInstance,
@@ -242,6 +297,7 @@ func NewVKRenderer(window RenderingContainer, applicationName string) (*Vulkan,
 		dbg:              debugVulkanNew(),
 		combinedDrawings: NewDrawings(),
 		canvases:         make(map[string]Canvas),
+		postProcess:      make(map[cameras.Camera]*PostProcessStack),
 	}
 
 	appInfo := vk.ApplicationInfo{}
@@ -305,6 +361,13 @@ func NewVKRenderer(window RenderingContainer, applicationName string) (*Vulkan,
 	if err := vr.combineCanvas.Create(vr); err != nil {
 		return nil, err
 	}
+	vr.shadowCanvas.Maps = NewShadowMaps()
+	if err := vr.shadowCanvas.Create(vr); err != nil {
+		return nil, err
+	}
+	if err := vr.postProcessCanvas.Create(vr); err != nil {
+		return nil, err
+	}
 	vr.bufferTrash = newBufferDestroyer(vr.device, &vr.dbg)
 	return vr, nil
 }
@@ -319,10 +382,13 @@ func (vr *Vulkan) Initialize(caches RenderCaches, width, height int32) error {
 	}
 	vr.caches = caches
 	caches.TextureCache().CreatePending()
+	vr.createDefaultEnvironment()
 	vr.defaultCanvas.Initialize(vr, float32(width), float32(height))
 	vr.RegisterCanvas("default", &vr.defaultCanvas)
 	vr.RegisterCanvas("outline", &vr.outlineCanvas)
 	vr.RegisterCanvas("combine", &vr.combineCanvas)
+	vr.RegisterCanvas("shadow", &vr.shadowCanvas)
+	vr.RegisterCanvas("postprocess", &vr.postProcessCanvas)
 	return nil
 }
 
@@ -513,6 +579,7 @@ func (vr *Vulkan) ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, run
 	vk.ResetCommandBuffer(vr.commandBuffers[vr.currentFrame*MaxCommandBuffers], 0)
 	vr.bufferTrash.Cycle()
 	vr.updateGlobalUniformBuffer(camera, uiCamera, runtime)
+	vr.frameCamera = camera
 	for _, r := range vr.preRuns {
 		r()
 	}
@@ -580,12 +647,20 @@ func (vr *Vulkan) SwapFrame(width, height int32) bool {
 
 func (vr *Vulkan) Destroy() {
 	vr.combinedDrawings.Destroy(vr)
+	vr.postProcessCanvas.destroyPasses(vr)
 	vr.bufferTrash.Purge()
 	if vr.device != vk.NullDevice {
 		for _, c := range vr.canvases {
 			c.Destroy(vr)
+			if cc, ok := c.(*CameraCanvas); ok {
+				cc.release(vr)
+			}
 		}
 		vr.defaultTexture = nil
+		if vr.defaultEnvironment != nil {
+			vr.DestroyTexture(vr.defaultEnvironment)
+			vr.defaultEnvironment = nil
+		}
 		for i := 0; i < maxFramesInFlight; i++ {
 			vk.DestroySemaphore(vr.device, vr.imageSemaphores[i], nil)
 			vr.dbg.remove(vk.TypeToUintPtr(vr.imageSemaphores[i]))
@@ -39,6 +39,7 @@ package rendering
 
 import (
 	"kaiju/assets"
+	"kaiju/assets/vfs"
 )
 
 type Shader struct {
@@ -53,6 +54,9 @@ type Shader struct {
 	DriverData ShaderDriverData
 	subShaders map[string]*Shader
 	definition *ShaderDef
+	// shadowCaster draws the depth of the drawings of this shader into the
+	// shadow canvas, see #ShaderDef.ShadowCaster
+	shadowCaster *Shader
 }
 
 func (s *Shader) AddSubShader(key string, shader *Shader) {
@@ -90,6 +94,23 @@ func (s *Shader) DelayedCreate(renderer Renderer, assetDatabase *assets.Database
 	}
 }
 
+// usesFile returns true if the shader, or any of its sub shaders, reads the
+// file for one of its stages, the key is compared with the normalized paths
+// of the stages
+func (s *Shader) usesFile(key string) bool {
+	for _, p := range []string{s.VertPath, s.FragPath, s.GeomPath, s.CtrlPath, s.EvalPath} {
+		if p != "" && vfs.NormalizeKey(p) == key {
+			return true
+		}
+	}
+	for _, ss := range s.subShaders {
+		if ss.usesFile(key) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Shader) IsComposite() bool {
 	return s.VertPath == assets.ShaderOitCompositeVert
 }
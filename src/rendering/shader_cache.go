@@ -40,6 +40,7 @@ package rendering
 import (
 	"kaiju/assets"
 	"log/slog"
+	"slices"
 	"sync"
 )
 
@@ -48,6 +49,7 @@ type ShaderCache struct {
 	assetDatabase     *assets.Database
 	shaders           map[string]*Shader
 	pendingShaders    []*Shader
+	reloads           []*Shader
 	shaderDefinitions map[string]ShaderDef
 	mutex             sync.Mutex
 }
@@ -107,10 +109,30 @@ func (s *ShaderCache) ShaderFromDefinition(definitionKey string) *Shader {
 	if isNew {
 		shader.definition = &def
 		shader.DriverData.setup(def, baseVertexAttributeCount, c.ShaderPipeline(def.Pipeline))
+		if def.ShadowCaster != "" && def.ShadowCaster != definitionKey {
+			shader.shadowCaster = s.ShaderFromDefinition(def.ShadowCaster)
+		}
 	}
 	return shader
 }
 
+// Reload finds the cached shaders that use the SPIR-V file of the key for
+// any of their stages, the stages of the shaders are read again and their
+// pipelines are rebuilt with the pending shaders. Returns false if no cached
+// shader uses the file.
+func (s *ShaderCache) Reload(spvKey string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	found := false
+	for _, shader := range s.shaders {
+		if shader.usesFile(spvKey) && !slices.Contains(s.reloads, shader) {
+			s.reloads = append(s.reloads, shader)
+			found = true
+		}
+	}
+	return found
+}
+
 func (s *ShaderCache) CreatePending() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -118,6 +140,13 @@ func (s *ShaderCache) CreatePending() {
 		shader.DelayedCreate(s.renderer, s.assetDatabase)
 	}
 	s.pendingShaders = s.pendingShaders[:0]
+	for _, shader := range s.reloads {
+		if err := s.renderer.ReloadShader(shader, s.assetDatabase); err != nil {
+			slog.Error("failed to reload the shader",
+				slog.String("shader", shader.Key), slog.String("error", err.Error()))
+		}
+	}
+	s.reloads = s.reloads[:0]
 }
 
 func (s *ShaderCache) Destroy() {
@@ -125,6 +154,7 @@ func (s *ShaderCache) Destroy() {
 		shader.Destroy(s.renderer)
 	}
 	s.pendingShaders = s.pendingShaders[:0]
+	s.reloads = s.reloads[:0]
 	for _, shader := range s.shaders {
 		shader.Destroy(s.renderer)
 	}
@@ -107,6 +107,12 @@ type ShaderDefLayout struct {
 	Count   int
 	Binding int
 	Buffer  *LayoutBufferDescription
+	// Canvas names a canvas whose color output is bound to this layout, such
+	// as the "shadow" canvas depth atlas
+	Canvas string `json:",omitempty"`
+	// Environment binds the environment cube map of the renderer to this
+	// layout, see #Renderer.SetEnvironment
+	Environment bool `json:",omitempty"`
 }
 
 func (l ShaderDefLayout) DescriptorType() vk.DescriptorType {
@@ -195,6 +201,17 @@ type ShaderDef struct {
 	Canvas     string
 	RenderPass string
 	Pipeline   string
+	// FrustumCulling skips instances outside of the camera view, only enable
+	// it for shaders that draw meshes in world space
+	FrustumCulling bool
+	// DepthCompare is the depth test of the shader, either "Less" (default),
+	// "LessOrEqual", or "Always". A skybox that is drawn on the far plane
+	// uses "LessOrEqual" so that it shows where nothing else was drawn.
+	DepthCompare string `json:",omitempty"`
+	// ShadowCaster is the key of the shader definition that draws the depth
+	// of the drawings of this shader into the "shadow" canvas, lit shaders
+	// use #assets.ShaderDefinitionShadow so their drawings cast shadows
+	ShadowCaster string `json:",omitempty"`
 }
 
 type defType struct {
@@ -211,6 +228,7 @@ var defTypes = map[string]defType{
 	"mat4":   {uint32(vec4Size), vk.FormatR32g32b32a32Sfloat, 4},
 	"int32":  {uint32(int32Size), vk.FormatR32Sint, 1},
 	"uint32": {uint32(uint32Size), vk.FormatR32Uint, 1},
+	"ivec4":  {uint32(int32Size) * 4, vk.FormatR32g32b32a32Sint, 1},
 }
 
 func (sd *ShaderDef) AddField(name, glslType string) {
@@ -0,0 +1,340 @@
+/******************************************************************************/
+/* shadow.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/matrix"
+	"unsafe"
+)
+
+const (
+	// MaxShadowCascades is the most cascades a directional light can use
+	MaxShadowCascades = 4
+	// MaxShadowMaps is the number of tiles in the shadow atlas, this must
+	// match MAX_SHADOW_MAPS in inc_globals.inl
+	MaxShadowMaps = 8
+	// ShadowAtlasColumns and ShadowAtlasRows describe how the shadow maps
+	// are laid out in the atlas, these must match inc_globals.inl
+	ShadowAtlasColumns = 4
+	ShadowAtlasRows    = 2
+)
+
+// ShadowSettings controls the quality and reach of the shadow maps
+type ShadowSettings struct {
+	Enabled bool
+	// Resolution is the width and height, in pixels, of a single shadow map
+	// within the atlas. Changing it will recreate the shadow canvas.
+	Resolution int32
+	// Bias is subtracted from the depth of a surface before it is compared
+	// against the shadow map to prevent shadow acne
+	Bias matrix.Float
+	// NormalBias offsets the surface along its normal, in world units, before
+	// the shadow map is sampled
+	NormalBias matrix.Float
+	// CascadeCount is how many cascades are used for directional lights, up
+	// to #MaxShadowCascades
+	CascadeCount int
+	// Splits optionally sets where each cascade ends as a fraction (0-1) of
+	// MaxDistance, when empty the splits are computed with SplitLambda
+	Splits []matrix.Float
+	// SplitLambda blends the computed splits between uniform (0) and
+	// logarithmic (1) distribution
+	SplitLambda matrix.Float
+	// MaxDistance is how far from the camera directional shadows are drawn
+	MaxDistance matrix.Float
+}
+
+func DefaultShadowSettings() ShadowSettings {
+	return ShadowSettings{
+		Enabled:      true,
+		Resolution:   1024,
+		Bias:         0.002,
+		NormalBias:   0.02,
+		CascadeCount: 3,
+		SplitLambda:  0.75,
+		MaxDistance:  100,
+	}
+}
+
+// ShadowMaps fits the shadow maps for the lights that cast shadows each frame
+type ShadowMaps struct {
+	Settings        ShadowSettings
+	viewProjections [MaxShadowMaps]matrix.Mat4
+	splits          [MaxShadowCascades]matrix.Float
+	count           int
+	cascades        int
+}
+
+func NewShadowMaps() ShadowMaps {
+	return ShadowMaps{Settings: DefaultShadowSettings()}
+}
+
+// Count returns the number of shadow maps that were fit by the last update
+func (s *ShadowMaps) Count() int { return s.count }
+
+// Cascades returns the number of directional cascades fit by the last update
+func (s *ShadowMaps) Cascades() int { return s.cascades }
+
+// ViewProjection returns the light view projection for the shadow map
+func (s *ShadowMaps) ViewProjection(index int) matrix.Mat4 {
+	return s.viewProjections[index]
+}
+
+// Split returns the view distance where the cascade ends
+func (s *ShadowMaps) Split(cascade int) matrix.Float { return s.splits[cascade] }
+
+// Update fits the shadow maps to the camera for the active lights of the
+// list. The first directional light that casts shadows takes the first
+// cascades of the atlas and the spot lights that cast shadows take the
+// remaining maps. The shadow index of each of the lights is written into the
+// shader data of the list.
+func (s *ShadowMaps) Update(camera cameras.Camera, lights *LightList) {
+	s.count = 0
+	s.cascades = 0
+	if lights == nil {
+		return
+	}
+	active := lights.Active()
+	for i := range active {
+		lights.gpu[i].ShadowIndex = -1
+	}
+	if !s.Settings.Enabled {
+		return
+	}
+	near := matrix.Float(camera.NearPlane())
+	far := min(matrix.Float(camera.FarPlane()), s.Settings.MaxDistance)
+	cascadeCount := min(max(s.Settings.CascadeCount, 0), MaxShadowCascades)
+	for i, l := range active {
+		if !l.CastShadows || l.Type != LightTypeDirectional || cascadeCount == 0 || far <= near {
+			continue
+		}
+		splits := s.cascadeSplits(near, far, cascadeCount)
+		start := near
+		for c := range splits {
+			corners := FrustumCorners(camera.View(), camera.Projection(),
+				matrix.Float(camera.NearPlane()), matrix.Float(camera.FarPlane()), start, splits[c])
+			s.viewProjections[c] = FitCascade(corners, l.Direction,
+				s.Settings.Resolution, s.Settings.MaxDistance)
+			s.splits[c] = splits[c]
+			start = splits[c]
+		}
+		lights.gpu[i].ShadowIndex = 0
+		s.cascades = len(splits)
+		s.count = s.cascades
+		break
+	}
+	for i, l := range active {
+		if s.count == MaxShadowMaps {
+			break
+		}
+		if !l.CastShadows || l.Type != LightTypeSpot {
+			continue
+		}
+		s.viewProjections[s.count] = SpotShadowMatrix(l)
+		lights.gpu[i].ShadowIndex = int32(s.count)
+		s.count++
+	}
+}
+
+func (s *ShadowMaps) cascadeSplits(near, far matrix.Float, count int) []matrix.Float {
+	if len(s.Settings.Splits) == 0 {
+		return CascadeSplits(near, far, count, s.Settings.SplitLambda)
+	}
+	splits := make([]matrix.Float, 0, count)
+	for i := 0; i < count && i < len(s.Settings.Splits); i++ {
+		d := near + (far-near)*matrix.Clamp(s.Settings.Splits[i], 0, 1)
+		if len(splits) > 0 {
+			d = max(d, splits[len(splits)-1])
+		}
+		splits = append(splits, d)
+	}
+	return splits
+}
+
+func (s *ShadowMaps) shaderData(data *GlobalShaderData) {
+	copy(data.ShadowViewProjections[:], s.viewProjections[:s.count])
+	for i := range MaxShadowCascades {
+		data.ShadowSplits[i] = s.splits[i]
+	}
+	texelSize := matrix.Float(0)
+	if s.Settings.Resolution > 0 {
+		texelSize = 1 / matrix.Float(s.Settings.Resolution)
+	}
+	data.ShadowParams = matrix.Vec4{s.Settings.Bias, s.Settings.NormalBias,
+		texelSize, matrix.Float(s.cascades)}
+}
+
+// shadowCasterInstance draws the depth of an instance into the shadow canvas,
+// it shares the data of the source instance and follows its lifetime
+type shadowCasterInstance struct {
+	DrawInstance
+}
+
+// Size is only the model matrix of the source instance, which is the start of
+// its data, the rest of the data is not needed to render the depth
+func (s *shadowCasterInstance) Size() int {
+	return int(unsafe.Sizeof(matrix.Mat4{}))
+}
+
+// ShadowCaster creates a drawing of the same mesh and instance on the shadow
+// canvas using the given shadow shader (#assets.ShaderDefinitionShadow). This
+// is done by #Drawings.AddDrawing for shaders that name a
+// #ShaderDef.ShadowCaster, the caster is removed along with the instance.
+func (d *Drawing) ShadowCaster(shader *Shader) Drawing {
+	return Drawing{
+		Renderer:   d.Renderer,
+		Shader:     shader,
+		Mesh:       d.Mesh,
+		LOD:        d.LOD,
+		ShaderData: &shadowCasterInstance{d.ShaderData},
+		Transform:  d.Transform,
+		CanvasId:   "shadow",
+	}
+}
+
+// ShadowAtlasViewport returns the pixel offset of the shadow map within the
+// atlas, each map is resolution pixels wide and tall
+func ShadowAtlasViewport(index int, resolution int32) (x, y int32) {
+	return int32(index%ShadowAtlasColumns) * resolution,
+		int32(index/ShadowAtlasColumns) * resolution
+}
+
+// CascadeSplits returns the view distance where each of the cascades end.
+// Lambda blends between a uniform (0) and a logarithmic (1) distribution of
+// the splits, the last split is always the far distance.
+func CascadeSplits(near, far matrix.Float, count int, lambda matrix.Float) []matrix.Float {
+	splits := make([]matrix.Float, count)
+	near = max(near, 0.0001)
+	lambda = matrix.Clamp(lambda, 0, 1)
+	for i := range splits {
+		p := matrix.Float(i+1) / matrix.Float(count)
+		log := near * matrix.Pow(far/near, p)
+		uniform := near + (far-near)*p
+		splits[i] = lambda*log + (1-lambda)*uniform
+	}
+	if count > 0 {
+		splits[count-1] = far
+	}
+	return splits
+}
+
+// FrustumCorners returns the world space corners of the slice of the camera
+// frustum between the two view distances. The first four corners are on the
+// near side of the slice and the last four are on the far side.
+func FrustumCorners(view, projection matrix.Mat4, near, far, sliceNear, sliceFar matrix.Float) [8]matrix.Vec3 {
+	nearNDC := projectPoint(projection, matrix.Vec3{0, 0, -near}).Z()
+	farNDC := projectPoint(projection, matrix.Vec3{0, 0, -far}).Z()
+	inv := matrix.Mat4Multiply(view, projection)
+	inv.Inverse()
+	ndc := [4][2]matrix.Float{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	tNear := (sliceNear - near) / (far - near)
+	tFar := (sliceFar - near) / (far - near)
+	var corners [8]matrix.Vec3
+	for i := range ndc {
+		a := projectPoint(inv, matrix.Vec3{ndc[i][0], ndc[i][1], nearNDC})
+		b := projectPoint(inv, matrix.Vec3{ndc[i][0], ndc[i][1], farNDC})
+		ray := b.Subtract(a)
+		corners[i] = a.Add(ray.Scale(tNear))
+		corners[i+4] = a.Add(ray.Scale(tFar))
+	}
+	return corners
+}
+
+// FitCascade returns the view projection of a directional light that covers
+// the frustum slice corners. The slice is bounded by a sphere so that the
+// size of the projection does not change as the camera rotates, and the
+// projection is snapped to the texels of the shadow map so that the shadow
+// edges do not shimmer as the camera moves. Casters up to casterDistance
+// behind the slice, towards the light, are included.
+func FitCascade(corners [8]matrix.Vec3, lightDirection matrix.Vec3, resolution int32, casterDistance matrix.Float) matrix.Mat4 {
+	center := matrix.Vec3Zero()
+	for i := range corners {
+		center.AddAssign(corners[i])
+	}
+	center.ShrinkAssign(matrix.Float(len(corners)))
+	radius := matrix.Float(0)
+	for i := range corners {
+		radius = max(radius, corners[i].Subtract(center).Length())
+	}
+	radius = matrix.Ceil(radius*16) / 16
+	dir := lightDirection.Normal()
+	up := matrix.Vec3Up()
+	if matrix.Abs(matrix.Vec3Dot(dir, up)) > 0.99 {
+		up = matrix.Vec3Forward()
+	}
+	depth := radius + max(casterDistance, 0)
+	eye := center.Subtract(dir.Scale(depth))
+	view := matrix.Mat4LookAt(eye, center, up)
+	var projection matrix.Mat4
+	projection.Orthographic(-radius, radius, -radius, radius, 0, depth+radius)
+	vp := matrix.Mat4Multiply(view, projection)
+	if resolution > 0 {
+		half := matrix.Float(resolution) * 0.5
+		origin := projectPoint(vp, matrix.Vec3Zero())
+		x := origin.X() * half
+		y := origin.Y() * half
+		projection[matrix.Mat4x0y3] += (matrix.Round(x) - x) / half
+		projection[matrix.Mat4x1y3] += (matrix.Round(y) - y) / half
+		vp = matrix.Mat4Multiply(view, projection)
+	}
+	return vp
+}
+
+// SpotShadowMatrix returns the view projection of a spot light that covers
+// its cone out to the range of the light
+func SpotShadowMatrix(light *Light) matrix.Mat4 {
+	dir := light.Direction.Normal()
+	up := matrix.Vec3Up()
+	if matrix.Abs(matrix.Vec3Dot(dir, up)) > 0.99 {
+		up = matrix.Vec3Forward()
+	}
+	view := matrix.Mat4LookAt(light.Position, light.Position.Add(dir), up)
+	fov := matrix.Clamp(2*max(light.OuterAngle, light.InnerAngle), 1, 170)
+	near := max(light.Range*0.01, 0.05)
+	var projection matrix.Mat4
+	projection.Perspective(matrix.Deg2Rad(fov), 1, near, max(light.Range, near+0.01))
+	return matrix.Mat4Multiply(view, projection)
+}
+
+// projectPoint transforms the point by the matrix and applies the
+// perspective divide
+func projectPoint(m matrix.Mat4, point matrix.Vec3) matrix.Vec3 {
+	p := matrix.Mat4MultiplyVec4(m, matrix.Vec4{point.X(), point.Y(), point.Z(), 1})
+	return matrix.Vec3{p.X(), p.Y(), p.Z()}.Shrink(p.W())
+}
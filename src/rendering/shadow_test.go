@@ -0,0 +1,253 @@
+/******************************************************************************/
+/* shadow_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/cameras"
+	"kaiju/matrix"
+	"testing"
+	"unsafe"
+)
+
+func TestShadowShaderLayout(t *testing.T) {
+	if o := unsafe.Offsetof(GlobalShaderData{}.ShadowViewProjections); o%16 != 0 {
+		t.Errorf("expected the shadow matrices to be 16 byte aligned, got offset %d", o)
+	}
+	if o := unsafe.Offsetof(GlobalShaderData{}.ShadowSplits); o%16 != 0 {
+		t.Errorf("expected the shadow splits to be 16 byte aligned, got offset %d", o)
+	}
+	if o := unsafe.Offsetof(GlobalShaderData{}.ShadowParams); o%16 != 0 {
+		t.Errorf("expected the shadow params to be 16 byte aligned, got offset %d", o)
+	}
+	if MaxShadowMaps != ShadowAtlasColumns*ShadowAtlasRows {
+		t.Errorf("expected the shadow atlas to hold exactly %d maps", MaxShadowMaps)
+	}
+}
+
+func TestCascadeSplits(t *testing.T) {
+	splits := CascadeSplits(0.1, 100, 4, 0.75)
+	if len(splits) != 4 {
+		t.Fatalf("expected 4 splits, got %d", len(splits))
+	}
+	for i := 1; i < len(splits); i++ {
+		if splits[i] <= splits[i-1] {
+			t.Errorf("expected the splits to increase, got %v", splits)
+		}
+	}
+	if splits[3] != 100 {
+		t.Errorf("expected the last split to be the far plane, got %f", splits[3])
+	}
+	uniform := CascadeSplits(0, 90, 3, 0)
+	for i, want := range []matrix.Float{30, 60, 90} {
+		if !matrix.ApproxTo(uniform[i], want, 0.001) {
+			t.Errorf("expected uniform split %d to be %f, got %f", i, want, uniform[i])
+		}
+	}
+}
+
+func TestFrustumCornersSlice(t *testing.T) {
+	view := matrix.Mat4LookAt(matrix.Vec3{0, 0, 5}, matrix.Vec3Zero(), matrix.Vec3Up())
+	var projection matrix.Mat4
+	projection.Perspective(matrix.Deg2Rad(60), 1, 0.1, 100)
+	corners := FrustumCorners(view, projection, 0.1, 100, 2, 10)
+	for i := 0; i < 4; i++ {
+		if z := corners[i].Z(); matrix.Abs(z-3) > 0.01 {
+			t.Errorf("expected near corner %d at z 3, got %f", i, z)
+		}
+		if z := corners[i+4].Z(); matrix.Abs(z+5) > 0.01 {
+			t.Errorf("expected far corner %d at z -5, got %f", i, z)
+		}
+	}
+	// A 60 degree field of view reaches out tan(30) units per unit of depth
+	halfWidth := 10 * matrix.Tan(matrix.Deg2Rad(30))
+	if x := matrix.Abs(corners[5].X()); matrix.Abs(x-halfWidth) > 0.01 {
+		t.Errorf("expected the far corner half width to be %f, got %f", halfWidth, x)
+	}
+}
+
+func TestFitCascadeContainsSlice(t *testing.T) {
+	view := matrix.Mat4LookAt(matrix.Vec3{3, 2, 5}, matrix.Vec3Zero(), matrix.Vec3Up())
+	var projection matrix.Mat4
+	projection.Perspective(matrix.Deg2Rad(60), 16.0/9.0, 0.1, 100)
+	corners := FrustumCorners(view, projection, 0.1, 100, 0.1, 20)
+	lightDir := matrix.Vec3{-1, -2, -0.5}.Normal()
+	vp := FitCascade(corners, lightDir, 1024, 50)
+	for i := range corners {
+		p := projectPoint(vp, corners[i])
+		if matrix.Abs(p.X()) > 1.001 || matrix.Abs(p.Y()) > 1.001 {
+			t.Errorf("expected corner %d inside of the shadow map, got %v", i, p)
+		}
+		if p.Z() < 0 || p.Z() > 1 {
+			t.Errorf("expected corner %d inside of the shadow depth range, got %f", i, p.Z())
+		}
+	}
+	// Snapping keeps the world origin on a texel boundary
+	origin := projectPoint(vp, matrix.Vec3Zero())
+	x := origin.X() * 512
+	if matrix.Abs(x-matrix.Round(x)) > 0.01 {
+		t.Errorf("expected the origin to be snapped to a texel, got %f", x)
+	}
+	// Casters between the light and the slice must not be clipped
+	caster := corners[0].Subtract(lightDir.Scale(30))
+	if z := projectPoint(vp, caster).Z(); z < 0 || z > 1 {
+		t.Errorf("expected a caster towards the light to be in range, got %f", z)
+	}
+}
+
+func TestSpotShadowMatrix(t *testing.T) {
+	spot := NewLight(LightTypeSpot)
+	spot.Position = matrix.Vec3{0, 5, 0}
+	spot.Direction = matrix.Vec3{0, -1, 0}
+	spot.Range = 20
+	vp := SpotShadowMatrix(spot)
+	below := projectPoint(vp, matrix.Vec3{0, 0, 0})
+	if matrix.Abs(below.X()) > 0.001 || matrix.Abs(below.Y()) > 0.001 {
+		t.Errorf("expected a point down the cone to be centered, got %v", below)
+	}
+	if below.Z() < -1 || below.Z() > 1 {
+		t.Errorf("expected a point within range to be within depth, got %f", below.Z())
+	}
+	beyond := projectPoint(vp, matrix.Vec3{0, -30, 0})
+	if beyond.Z() <= 1 {
+		t.Errorf("expected a point out of range to be beyond the far plane, got %f", beyond.Z())
+	}
+}
+
+func TestShadowMapsAssignLights(t *testing.T) {
+	list := NewLightList()
+	sun := NewLight(LightTypeDirectional)
+	sun.Direction = matrix.Vec3{-1, -1, 0}.Normal()
+	sun.CastShadows = true
+	spot := NewLight(LightTypeSpot)
+	spot.Position = matrix.Vec3{0, 5, 0}
+	spot.Direction = matrix.Vec3{0, -1, 0}
+	spot.CastShadows = true
+	point := testPointLight(matrix.Vec3{1, 0, 0}, 1, 10)
+	point.CastShadows = true
+	list.Add(sun)
+	list.Add(spot)
+	list.Add(point)
+	list.Prepare(matrix.Vec3Zero())
+	camera := cameras.NewStandardCamera(1280, 720, matrix.Vec3{0, 2, 10})
+	maps := NewShadowMaps()
+	maps.Update(camera, &list)
+	if maps.Cascades() != 3 {
+		t.Fatalf("expected 3 cascades, got %d", maps.Cascades())
+	}
+	if maps.Count() != 4 {
+		t.Fatalf("expected 4 shadow maps, got %d", maps.Count())
+	}
+	for i, l := range list.Active() {
+		want := int32(-1)
+		switch l {
+		case sun:
+			want = 0
+		case spot:
+			want = 3
+		}
+		if got := list.gpu[i].ShadowIndex; got != want {
+			t.Errorf("expected light %d to have shadow index %d, got %d", i, want, got)
+		}
+	}
+	if maps.Split(2) != maps.Settings.MaxDistance {
+		t.Errorf("expected the last cascade to end at the max distance, got %f", maps.Split(2))
+	}
+	var data GlobalShaderData
+	maps.shaderData(&data)
+	if data.ShadowParams.W() != 3 {
+		t.Errorf("expected the cascade count in the shader data, got %f", data.ShadowParams.W())
+	}
+	maps.Settings.Enabled = false
+	maps.Update(camera, &list)
+	if maps.Count() != 0 || list.gpu[0].ShadowIndex != -1 {
+		t.Errorf("expected no shadow maps when shadows are disabled")
+	}
+}
+
+func TestShadowAtlasViewport(t *testing.T) {
+	if x, y := ShadowAtlasViewport(5, 512); x != 512 || y != 512 {
+		t.Errorf("expected the 6th map at 512,512, got %d,%d", x, y)
+	}
+}
+
+// testShadowRenderer finds the default and shadow canvases for drawings
+type testShadowRenderer struct {
+	testCameraRenderer
+	shadow OITCanvas
+}
+
+func (r *testShadowRenderer) Canvas(name string) (Canvas, bool) {
+	if name == "shadow" {
+		return &r.shadow, true
+	}
+	return &r.canvas, name == "default"
+}
+
+func TestShadowCastersFollowDrawings(t *testing.T) {
+	renderer := &testShadowRenderer{}
+	shadow := &Shader{}
+	lit := &Shader{shadowCaster: shadow}
+	mesh := NewMesh("cube", meshCube(matrix.ColorWhite()), nil)
+	litData := testCullingInstance(matrix.Vec3Zero())
+	unlitData := testCullingInstance(matrix.Vec3Zero())
+	d := NewDrawings()
+	d.AddDrawing(&Drawing{Renderer: renderer, Shader: lit, Mesh: mesh,
+		ShaderData: litData, CanvasId: "default"})
+	d.AddDrawing(&Drawing{Renderer: renderer, Shader: &Shader{}, Mesh: mesh,
+		ShaderData: unlitData, CanvasId: "default"})
+	d.PreparePending()
+	if got := testCameraInstanceCount(&d, &renderer.shadow); got != 1 {
+		t.Fatalf("expected only the lit drawing to cast a shadow, got %d casters", got)
+	}
+	group := &d.draws[1].innerDraws[0].instanceGroups[0]
+	caster := group.Instances[0]
+	if group.instanceSize != int(unsafe.Sizeof(matrix.Mat4{})) {
+		t.Errorf("expected the caster to only upload the model, got %d bytes", group.instanceSize)
+	}
+	if caster.Model() != litData.Model() {
+		t.Errorf("expected the caster to share the model of the drawing")
+	}
+	litData.Deactivate()
+	if caster.IsActive() {
+		t.Errorf("expected the caster to follow the drawing being deactivated")
+	}
+	litData.Destroy()
+	if !caster.IsDestroyed() {
+		t.Errorf("expected the caster to be destroyed with the drawing")
+	}
+}
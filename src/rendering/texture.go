@@ -89,6 +89,7 @@ const (
 	TextureInputTypeRgba8
 	TextureInputTypeRgb8
 	TextureInputTypeLuminance
+	TextureInputTypeCompressedRgbaBc7
 )
 
 const (
@@ -113,6 +114,9 @@ const (
 	TextureFileFormatAstc TextureFileFormat = iota
 	TextureFileFormatPng
 	TextureFileFormatRaw
+	// TextureFileFormatProcessed is a texture that was processed at import
+	// time, see #ProcessedTexture
+	TextureFileFormatProcessed
 )
 
 const (
@@ -128,6 +132,13 @@ type TextureData struct {
 	Width          int
 	Height         int
 	InputType      TextureFileFormat
+	// CubeMap data holds the six faces of a cube map one after another, see
+	// #NewCubeMapTextureData
+	CubeMap bool
+	// Mips is the number of mip levels that are stored one after another in
+	// the memory, largest first. The renderer generates the mip levels when
+	// this is 0.
+	Mips int
 }
 
 type Texture struct {
@@ -271,22 +282,68 @@ func (t *Texture) create(imgBuff []byte) {
 	t.Height = data.Height
 }
 
+// createProcessed reads a #ProcessedTexture and picks the variant with the
+// best format that the renderer supports
+func (t *Texture) createProcessed(renderer Renderer, imgBuff []byte) error {
+	processed, err := ReadProcessedTexture(imgBuff)
+	if err != nil {
+		return err
+	}
+	data, err := processed.Select(renderer.TextureFormatSupported)
+	if err != nil {
+		return err
+	}
+	t.pendingData = &data
+	t.MipLevels = data.Mips
+	t.Width = data.Width
+	t.Height = data.Height
+	return nil
+}
+
 func NewTexture(renderer Renderer, assetDb *assets.Database, textureKey string, filter TextureFilter) (*Texture, error) {
-	tex := &Texture{Key: textureKey, Filter: filter}
-	if assetDb.Exists(textureKey) {
-		if imgBuff, err := assetDb.Read(textureKey); err != nil {
+	fileKey := textureFileKey(assetDb, textureKey)
+	if assetDb.Exists(fileKey) {
+		if imgBuff, err := assetDb.Read(fileKey); err != nil {
 			return nil, err
-		} else if len(imgBuff) == 0 {
-			return nil, errors.New("no data in texture")
 		} else {
-			tex.create(imgBuff)
-			return tex, nil
+			return newTextureFromFile(renderer, textureKey, fileKey, imgBuff, filter)
 		}
 	} else {
 		return nil, errors.New("texture does not exist")
 	}
 }
 
+// textureFileKey returns the key of the file that the texture is read from,
+// the processed texture that was made when the image was imported is read
+// instead of the image when it exists (see #ProcessedTextureKey)
+func textureFileKey(assetDb *assets.Database, textureKey string) string {
+	if strings.HasSuffix(textureKey, ProcessedTextureExtension) {
+		return textureKey
+	}
+	if processed := ProcessedTextureKey(textureKey); assetDb.Exists(processed) {
+		return processed
+	}
+	return textureKey
+}
+
+// newTextureFromFile decodes the data of the texture file that was read with
+// the file key, it does not touch the renderer beyond checking the supported
+// formats so it is safe to call from any goroutine
+func newTextureFromFile(renderer Renderer, textureKey, fileKey string, imgBuff []byte, filter TextureFilter) (*Texture, error) {
+	tex := &Texture{Key: textureKey, Filter: filter}
+	if len(imgBuff) == 0 {
+		return nil, errors.New("no data in texture")
+	} else if strings.HasSuffix(fileKey, ProcessedTextureExtension) {
+		if err := tex.createProcessed(renderer, imgBuff); err != nil {
+			return nil, err
+		}
+		return tex, nil
+	} else {
+		tex.create(imgBuff)
+		return tex, nil
+	}
+}
+
 func (t *Texture) DelayedCreate(renderer Renderer) {
 	renderer.CreateTexture(t, t.pendingData)
 	t.pendingData = nil
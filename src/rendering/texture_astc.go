@@ -0,0 +1,94 @@
+/******************************************************************************/
+/* texture_astc.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "kaiju/matrix"
+
+const (
+	// astcBlockMode4x4Weights2Bit is a 4x4 grid of weights with 2 bits each,
+	// which leaves room for 8 bit endpoints without any trit or quint packing
+	astcBlockMode4x4Weights2Bit = 0x42
+	// astcColorEndpointModeRgba is the LDR RGBA direct color endpoint mode
+	astcColorEndpointModeRgba = 12
+)
+
+// astcWeights2 are the unquantized (out of 64) values of the 2 bit weights
+var astcWeights2 = [4]int{0, 21, 43, 64}
+
+// EncodeASTC4x4 encodes RGBA memory into 4x4 ASTC blocks. Every block uses a
+// single partition with 8 bit RGBA endpoints and 4 levels between them, a
+// simple encoding that keeps the encoder small and fast.
+func EncodeASTC4x4(rgba []byte, width, height int) []byte {
+	return encodeBlocks(rgba, width, height, encodeASTCBlock)
+}
+
+func encodeASTCBlock(block *[16][4]matrix.Float) [compressedBlockBytes]byte {
+	e0, e1 := blockEndpoints(block)
+	var c0, c1 [4]int
+	for c := 0; c < 4; c++ {
+		c0[c] = int(matrix.Round(e0[c]))
+		c1[c] = int(matrix.Round(e1[c]))
+	}
+	// The decoder swaps the endpoints and applies blue contraction when the
+	// second endpoint is darker than the first
+	if c0[0]+c0[1]+c0[2] > c1[0]+c1[1]+c1[2] {
+		c0, c1 = c1, c0
+	}
+	palette := make([][4]int, len(astcWeights2))
+	for i, w := range astcWeights2 {
+		for c := 0; c < 4; c++ {
+			v := ((c0[c]*257)*(64-w) + (c1[c]*257)*w + 32) >> 6
+			palette[i][c] = v >> 8
+		}
+	}
+	w := blockWriter{}
+	w.write(astcBlockMode4x4Weights2Bit, 11)
+	w.write(0, 2) // Partition count - 1
+	w.write(astcColorEndpointModeRgba, 4)
+	for c := 0; c < 4; c++ {
+		w.write(c0[c], 8)
+		w.write(c1[c], 8)
+	}
+	// The weights are stored in reverse bit order from the top of the block
+	for i := range block {
+		weight := nearestPaletteIndex(block[i], palette)
+		w.setBit(127-i*2, weight&1)
+		w.setBit(127-(i*2+1), (weight>>1)&1)
+	}
+	return w.data
+}
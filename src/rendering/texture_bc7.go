@@ -0,0 +1,112 @@
+/******************************************************************************/
+/* texture_bc7.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "kaiju/matrix"
+
+// bc7Weights4 are the interpolation weights (out of 64) of the 4 bit indices
+var bc7Weights4 = [16]int{0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64}
+
+// EncodeBC7 encodes RGBA memory into BC7 blocks. Every block is encoded with
+// mode 6, a single pair of RGBA endpoints with 16 levels between them, which
+// is fast to encode and does well on most color textures.
+func EncodeBC7(rgba []byte, width, height int) []byte {
+	return encodeBlocks(rgba, width, height, encodeBC7Block)
+}
+
+func encodeBC7Block(block *[16][4]matrix.Float) [compressedBlockBytes]byte {
+	e0, e1 := blockEndpoints(block)
+	q0, p0 := quantizeBC7Endpoint(e0)
+	q1, p1 := quantizeBC7Endpoint(e1)
+	var c0, c1 [4]int
+	for c := 0; c < 4; c++ {
+		c0[c] = q0[c]<<1 | p0
+		c1[c] = q1[c]<<1 | p1
+	}
+	palette := make([][4]int, len(bc7Weights4))
+	for i, w := range bc7Weights4 {
+		for c := 0; c < 4; c++ {
+			palette[i][c] = ((64-w)*c0[c] + w*c1[c] + 32) >> 6
+		}
+	}
+	var indices [16]int
+	for i := range block {
+		indices[i] = nearestPaletteIndex(block[i], palette)
+	}
+	// The highest bit of the first index is implied to be 0
+	if indices[0] >= 8 {
+		q0, q1 = q1, q0
+		p0, p1 = p1, p0
+		for i := range indices {
+			indices[i] = 15 - indices[i]
+		}
+	}
+	w := blockWriter{}
+	w.write(1<<6, 7)
+	for c := 0; c < 4; c++ {
+		w.write(q0[c], 7)
+		w.write(q1[c], 7)
+	}
+	w.write(p0, 1)
+	w.write(p1, 1)
+	w.write(indices[0], 3)
+	for i := 1; i < len(indices); i++ {
+		w.write(indices[i], 4)
+	}
+	return w.data
+}
+
+// quantizeBC7Endpoint quantizes the color to the 7 bits per channel and the
+// shared lowest bit of mode 6, picking the lowest bit that is most accurate
+func quantizeBC7Endpoint(color [4]matrix.Float) ([4]int, int) {
+	var best [4]int
+	bestBit, bestErr := 0, matrix.Float(-1)
+	for p := 0; p < 2; p++ {
+		var q [4]int
+		err := matrix.Float(0)
+		for c := 0; c < 4; c++ {
+			q[c] = int(matrix.Clamp(matrix.Round((color[c]-matrix.Float(p))/2), 0, 127))
+			d := matrix.Float(q[c]<<1|p) - color[c]
+			err += d * d
+		}
+		if bestErr < 0 || err < bestErr {
+			best, bestBit, bestErr = q, p, err
+		}
+	}
+	return best, bestBit
+}
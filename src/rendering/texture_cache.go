@@ -39,6 +39,7 @@ package rendering
 
 import (
 	"kaiju/assets"
+	"kaiju/assets/vfs"
 	"sync"
 )
 
@@ -47,9 +48,18 @@ type TextureCache struct {
 	assetDatabase   *assets.Database
 	textures        [TextureFilterMax]map[string]*Texture
 	pendingTextures []*Texture
+	loading         [TextureFilterMax]map[string]*assets.Handle[*Texture]
+	reloads         []textureReload
 	mutex           sync.Mutex
 }
 
+// textureReload is a texture in the cache that is swapped for the freshly
+// decoded texture with the other pending textures
+type textureReload struct {
+	texture *Texture
+	fresh   *Texture
+}
+
 func NewTextureCache(renderer Renderer, assetDatabase *assets.Database) TextureCache {
 	tc := TextureCache{
 		renderer:        renderer,
@@ -59,6 +69,7 @@ func NewTextureCache(renderer Renderer, assetDatabase *assets.Database) TextureC
 	}
 	for i := range tc.textures {
 		tc.textures[i] = make(map[string]*Texture)
+		tc.loading[i] = make(map[string]*assets.Handle[*Texture])
 	}
 	return tc
 }
@@ -79,6 +90,103 @@ func (t *TextureCache) Texture(textureKey string, filter TextureFilter) (*Textur
 	}
 }
 
+// TextureAsync will return a handle to the texture for the given key, the
+// texture file is read and decoded on a worker goroutine. The texture is put
+// into the cache on the main thread and is created with the other pending
+// textures. The file is only read once when the texture is requested with
+// more than one filter at the same time.
+func (t *TextureCache) TextureAsync(textureKey string, filter TextureFilter) *assets.Handle[*Texture] {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if texture, ok := t.textures[filter][textureKey]; ok {
+		return assets.Ready(textureKey, texture, nil)
+	} else if h, ok := t.loading[filter][textureKey]; ok && !h.IsDone() {
+		return h
+	}
+	fileKey := textureFileKey(t.assetDatabase, textureKey)
+	decoded := assets.Load(t.assetDatabase, fileKey, func(data []byte) (Texture, error) {
+		tex, err := newTextureFromFile(t.renderer, textureKey, fileKey, data, filter)
+		if err != nil {
+			return Texture{}, err
+		}
+		return *tex, nil
+	})
+	h := assets.Then(t.assetDatabase, decoded, func(decodedTex Texture) (*Texture, error) {
+		decodedTex.Filter = filter
+		return t.addDecoded(&decodedTex), nil
+	})
+	t.loading[filter][textureKey] = h
+	return h
+}
+
+// addDecoded puts a texture that was decoded off of the main thread into the
+// cache, if the texture was loaded in the meantime the cached one is returned
+func (t *TextureCache) addDecoded(texture *Texture) *Texture {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.loading[texture.Filter], texture.Key)
+	if found, ok := t.textures[texture.Filter][texture.Key]; ok {
+		return found
+	}
+	t.pendingTextures = append(t.pendingTextures, texture)
+	t.textures[texture.Filter][texture.Key] = texture
+	return texture
+}
+
+// CubeMap will return the cube map texture for the given #CubeMapDef asset
+// key, the cube map is created on the renderer with the pending textures
+func (t *TextureCache) CubeMap(cubeMapKey string, filter TextureFilter) (*Texture, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if texture, ok := t.textures[filter][cubeMapKey]; ok {
+		return texture, nil
+	}
+	texture, err := NewCubeMap(t.assetDatabase, cubeMapKey, filter)
+	if err != nil {
+		return nil, err
+	}
+	t.pendingTextures = append(t.pendingTextures, texture)
+	t.textures[filter][cubeMapKey] = texture
+	return texture, nil
+}
+
+// Reload reads the texture file for the key again and decodes it for each of
+// the filters the texture is cached with, the key is compared with the
+// normalized keys of the cached textures, the processed texture of a cached
+// image reloads the image as well. The cached textures keep their
+// pointers, their contents are swapped for the new image when the pending
+// textures are created so anything drawing with them shows the new image.
+// Returns false if the key is not a cached texture. Cube maps are not
+// reloaded.
+func (t *TextureCache) Reload(textureKey string) (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var data []byte
+	var fileKey string
+	found := false
+	for filter := range t.textures {
+		for key, texture := range t.textures[filter] {
+			if k := vfs.NormalizeKey(key); k != textureKey && ProcessedTextureKey(k) != textureKey {
+				continue
+			}
+			found = true
+			if data == nil {
+				var err error
+				fileKey = textureFileKey(t.assetDatabase, key)
+				if data, err = t.assetDatabase.Read(fileKey); err != nil {
+					return found, err
+				}
+			}
+			fresh, err := newTextureFromFile(t.renderer, key, fileKey, data, filter)
+			if err != nil {
+				return found, err
+			}
+			t.reloads = append(t.reloads, textureReload{texture, fresh})
+		}
+	}
+	return found, nil
+}
+
 func (t *TextureCache) CreatePending() {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -86,6 +194,14 @@ func (t *TextureCache) CreatePending() {
 		texture.DelayedCreate(t.renderer)
 	}
 	t.pendingTextures = t.pendingTextures[:0]
+	for _, r := range t.reloads {
+		if r.texture.RenderId.IsValid() {
+			t.renderer.DestroyTexture(r.texture)
+		}
+		*r.texture = *r.fresh
+		r.texture.DelayedCreate(t.renderer)
+	}
+	t.reloads = t.reloads[:0]
 }
 
 func (t *TextureCache) Destroy() {
@@ -93,6 +209,7 @@ func (t *TextureCache) Destroy() {
 		texture.Destroy(t.renderer)
 	}
 	t.pendingTextures = t.pendingTextures[:0]
+	t.reloads = t.reloads[:0]
 	for i := range t.textures {
 		for _, texture := range t.textures[i] {
 			texture.Destroy(t.renderer)
@@ -0,0 +1,356 @@
+/******************************************************************************/
+/* texture_compression.go                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"kaiju/matrix"
+)
+
+// TextureCompression is the block compression that textures are encoded to
+// when they are processed at import time
+type TextureCompression = string
+
+const (
+	TextureCompressionNone TextureCompression = "none"
+	TextureCompressionASTC TextureCompression = "astc"
+	TextureCompressionBC7  TextureCompression = "bc7"
+	// TextureCompressionAll encodes to every supported compression so that
+	// the runtime can pick whichever the device supports
+	TextureCompressionAll TextureCompression = "all"
+)
+
+// ProcessedTextureExtension is the extension of the file that a
+// #ProcessedTexture is stored in
+const ProcessedTextureExtension = ".ktex"
+
+// ProcessedTextureKey returns the key that the processed texture of an image
+// is read with, textures prefer it over the image when it exists
+func ProcessedTextureKey(textureKey string) string {
+	return textureKey + ProcessedTextureExtension
+}
+
+// compressedBlockBytes is the size of a block for both ASTC and BC7
+const compressedBlockBytes = 16
+
+var astcBlockSizes = [...][2]int{
+	{4, 4}, {5, 4}, {5, 5}, {6, 5}, {6, 6}, {8, 5}, {8, 6},
+	{8, 8}, {10, 5}, {10, 6}, {10, 8}, {10, 10}, {12, 10}, {12, 12},
+}
+
+// TextureVariant is a texture encoded to a single format, with all of its
+// mip levels, largest first
+type TextureVariant struct {
+	InternalFormat TextureInputType
+	Mips           [][]byte
+}
+
+// ProcessedTexture is a texture that was prepared at import time, it holds
+// the texture encoded to one or more formats. The variants are ordered by
+// preference, the uncompressed RGBA variant is always last so that there is
+// a format that every device can use.
+type ProcessedTexture struct {
+	Width    int
+	Height   int
+	Variants []TextureVariant
+}
+
+// textureLevelSize returns the size in bytes of a mip level of the format
+func textureLevelSize(format TextureInputType, width, height int) int {
+	bw, bh := 1, 1
+	size := bytesInPixel
+	if format == TextureInputTypeCompressedRgbaBc7 {
+		bw, bh, size = 4, 4, compressedBlockBytes
+	} else if int(format) < len(astcBlockSizes) {
+		bw, bh, size = astcBlockSizes[format][0], astcBlockSizes[format][1], compressedBlockBytes
+	}
+	return ((width + bw - 1) / bw) * ((height + bh - 1) / bh) * size
+}
+
+// GenerateTextureMips creates the mip chain of an uncompressed RGBA texture
+// down to 1x1 by averaging each 2x2 group of pixels. The first level is the
+// memory of the texture itself.
+func GenerateTextureMips(data TextureData) ([][]byte, error) {
+	if data.InternalFormat != TextureInputTypeRgba8 ||
+		len(data.Mem) != data.Width*data.Height*bytesInPixel || data.Width == 0 {
+		return nil, errors.New("mips can only be generated for RGBA textures")
+	}
+	mips := [][]byte{data.Mem}
+	w, h := data.Width, data.Height
+	for w > 1 || h > 1 {
+		src := mips[len(mips)-1]
+		nw, nh := max(1, w/2), max(1, h/2)
+		dst := make([]byte, nw*nh*bytesInPixel)
+		for y := 0; y < nh; y++ {
+			y0, y1 := min(y*2, h-1), min(y*2+1, h-1)
+			for x := 0; x < nw; x++ {
+				x0, x1 := min(x*2, w-1), min(x*2+1, w-1)
+				for c := 0; c < bytesInPixel; c++ {
+					sum := int(src[(y0*w+x0)*bytesInPixel+c]) +
+						int(src[(y0*w+x1)*bytesInPixel+c]) +
+						int(src[(y1*w+x0)*bytesInPixel+c]) +
+						int(src[(y1*w+x1)*bytesInPixel+c])
+					dst[(y*nw+x)*bytesInPixel+c] = byte((sum + 2) / 4)
+				}
+			}
+		}
+		mips = append(mips, dst)
+		w, h = nw, nh
+	}
+	return mips, nil
+}
+
+// ProcessTexture generates the mip levels of an uncompressed RGBA texture and
+// encodes them with the compression
+func ProcessTexture(data TextureData, compression TextureCompression) (ProcessedTexture, error) {
+	res := ProcessedTexture{Width: data.Width, Height: data.Height}
+	mips, err := GenerateTextureMips(data)
+	if err != nil {
+		return res, err
+	}
+	encode := func(format TextureInputType, encoder func([]byte, int, int) []byte) {
+		v := TextureVariant{InternalFormat: format, Mips: make([][]byte, len(mips))}
+		w, h := data.Width, data.Height
+		for i := range mips {
+			v.Mips[i] = encoder(mips[i], w, h)
+			w, h = max(1, w/2), max(1, h/2)
+		}
+		res.Variants = append(res.Variants, v)
+	}
+	switch compression {
+	case TextureCompressionNone, "":
+	case TextureCompressionBC7:
+		encode(TextureInputTypeCompressedRgbaBc7, EncodeBC7)
+	case TextureCompressionASTC:
+		encode(TextureInputTypeCompressedRgbaAstc4x4, EncodeASTC4x4)
+	case TextureCompressionAll:
+		encode(TextureInputTypeCompressedRgbaBc7, EncodeBC7)
+		encode(TextureInputTypeCompressedRgbaAstc4x4, EncodeASTC4x4)
+	default:
+		return res, errors.New("unknown texture compression: " + compression)
+	}
+	res.Variants = append(res.Variants, TextureVariant{
+		InternalFormat: TextureInputTypeRgba8,
+		Mips:           mips,
+	})
+	return res, nil
+}
+
+func ReadProcessedTexture(mem []byte) (ProcessedTexture, error) {
+	var tex ProcessedTexture
+	err := gob.NewDecoder(bytes.NewReader(mem)).Decode(&tex)
+	return tex, err
+}
+
+func (p *ProcessedTexture) Serialize() ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := gob.NewEncoder(&buf).Encode(p)
+	return buf.Bytes(), err
+}
+
+// Select returns the texture data of the first variant that the supported
+// function accepts, the uncompressed variant is used if none are accepted
+func (p *ProcessedTexture) Select(supported func(format TextureInputType) bool) (TextureData, error) {
+	if len(p.Variants) == 0 {
+		return TextureData{}, errors.New("the processed texture has no variants")
+	}
+	v := &p.Variants[len(p.Variants)-1]
+	for i := range p.Variants {
+		if supported(p.Variants[i].InternalFormat) {
+			v = &p.Variants[i]
+			break
+		}
+	}
+	size := 0
+	for i := range v.Mips {
+		size += len(v.Mips[i])
+	}
+	mem := make([]byte, 0, size)
+	for i := range v.Mips {
+		mem = append(mem, v.Mips[i]...)
+	}
+	return TextureData{
+		Mem:            mem,
+		InternalFormat: v.InternalFormat,
+		Format:         TextureColorFormatRgbaUnorm,
+		Type:           TextureMemTypeUnsignedByte,
+		Width:          p.Width,
+		Height:         p.Height,
+		InputType:      TextureFileFormatProcessed,
+		Mips:           len(v.Mips),
+	}, nil
+}
+
+// readTextureBlock reads a 4x4 block of pixels from the RGBA memory, pixels
+// past the edges of the texture repeat the edge pixels
+func readTextureBlock(rgba []byte, width, height, bx, by int) [16][4]matrix.Float {
+	var block [16][4]matrix.Float
+	for y := 0; y < 4; y++ {
+		py := min(by*4+y, height-1)
+		for x := 0; x < 4; x++ {
+			px := min(bx*4+x, width-1)
+			idx := (py*width + px) * bytesInPixel
+			for c := 0; c < 4; c++ {
+				block[y*4+x][c] = matrix.Float(rgba[idx+c])
+			}
+		}
+	}
+	return block
+}
+
+// blockEndpoints finds the two colors at the ends of the principal axis of
+// the colors of the block, the colors of the block lie close to the line
+// between them
+func blockEndpoints(block *[16][4]matrix.Float) (e0, e1 [4]matrix.Float) {
+	var mean [4]matrix.Float
+	for i := range block {
+		for c := 0; c < 4; c++ {
+			mean[c] += block[i][c] / 16
+		}
+	}
+	var cov [4][4]matrix.Float
+	for i := range block {
+		for a := 0; a < 4; a++ {
+			for b := 0; b < 4; b++ {
+				cov[a][b] += (block[i][a] - mean[a]) * (block[i][b] - mean[b])
+			}
+		}
+	}
+	// Power iteration, starting on the channel that varies the most, the
+	// diagonal of the bounds can't be used as it is orthogonal to the axis
+	// when channels change in opposite directions
+	widest := 0
+	for c := 1; c < 4; c++ {
+		if cov[c][c] > cov[widest][widest] {
+			widest = c
+		}
+	}
+	axis := cov[widest]
+	if !normalizeAxis(&axis) {
+		return mean, mean
+	}
+	for iter := 0; iter < 8; iter++ {
+		var next [4]matrix.Float
+		for a := 0; a < 4; a++ {
+			for b := 0; b < 4; b++ {
+				next[a] += cov[a][b] * axis[b]
+			}
+		}
+		if !normalizeAxis(&next) {
+			break
+		}
+		axis = next
+	}
+	tMin, tMax := matrix.Float(0), matrix.Float(0)
+	for i := range block {
+		t := matrix.Float(0)
+		for c := 0; c < 4; c++ {
+			t += (block[i][c] - mean[c]) * axis[c]
+		}
+		tMin = min(tMin, t)
+		tMax = max(tMax, t)
+	}
+	for c := 0; c < 4; c++ {
+		e0[c] = matrix.Clamp(mean[c]+axis[c]*tMin, 0, 255)
+		e1[c] = matrix.Clamp(mean[c]+axis[c]*tMax, 0, 255)
+	}
+	return e0, e1
+}
+
+func normalizeAxis(axis *[4]matrix.Float) bool {
+	length := matrix.Sqrt(axis[0]*axis[0] + axis[1]*axis[1] + axis[2]*axis[2] + axis[3]*axis[3])
+	if length < 1e-6 {
+		return false
+	}
+	for c := range axis {
+		axis[c] /= length
+	}
+	return true
+}
+
+// nearestPaletteIndex returns the index of the palette color that is closest
+// to the color
+func nearestPaletteIndex(color [4]matrix.Float, palette [][4]int) int {
+	best, bestErr := 0, matrix.Float(-1)
+	for i := range palette {
+		err := matrix.Float(0)
+		for c := 0; c < 4; c++ {
+			d := color[c] - matrix.Float(palette[i][c])
+			err += d * d
+		}
+		if bestErr < 0 || err < bestErr {
+			best, bestErr = i, err
+		}
+	}
+	return best
+}
+
+// blockWriter writes bits into a compressed block starting at the lowest bit
+type blockWriter struct {
+	data [compressedBlockBytes]byte
+	pos  int
+}
+
+func (w *blockWriter) write(value, bits int) {
+	for i := 0; i < bits; i++ {
+		w.setBit(w.pos, (value>>i)&1)
+		w.pos++
+	}
+}
+
+func (w *blockWriter) setBit(pos, bit int) {
+	if bit != 0 {
+		w.data[pos>>3] |= 1 << (pos & 7)
+	}
+}
+
+// encodeBlocks encodes the RGBA memory 4x4 block at a time
+func encodeBlocks(rgba []byte, width, height int, encoder func(*[16][4]matrix.Float) [compressedBlockBytes]byte) []byte {
+	bw, bh := (width+3)/4, (height+3)/4
+	out := make([]byte, 0, bw*bh*compressedBlockBytes)
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			block := readTextureBlock(rgba, width, height, bx, by)
+			encoded := encoder(&block)
+			out = append(out, encoded[:]...)
+		}
+	}
+	return out
+}
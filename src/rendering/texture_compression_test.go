@@ -0,0 +1,282 @@
+/******************************************************************************/
+/* texture_compression_test.go                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/assets"
+	"testing"
+)
+
+func testTextureGradient(width, height int) TextureData {
+	mem := make([]byte, 0, width*height*bytesInPixel)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mem = append(mem, byte(x*255/max(1, width-1)), byte(y*255/max(1, height-1)), 128, 255)
+		}
+	}
+	return TextureData{
+		Mem:            mem,
+		InternalFormat: TextureInputTypeRgba8,
+		Format:         TextureColorFormatRgbaUnorm,
+		Width:          width,
+		Height:         height,
+	}
+}
+
+// testTextureRamp is a block whose colors lie on a line, which the single
+// pair of endpoints of the encoders can represent
+func testTextureRamp() TextureData {
+	data := testCubeMapFace(4, [4]byte{})
+	for i := 0; i < 16; i++ {
+		x := i % 4
+		copy(data.Mem[i*bytesInPixel:], []byte{byte(x * 85), byte(255 - x*85), 60, 255})
+	}
+	return data
+}
+
+func testBlockBits(block []byte, start, count int) int {
+	v := 0
+	for i := 0; i < count; i++ {
+		pos := start + i
+		v |= int((block[pos>>3]>>(pos&7))&1) << i
+	}
+	return v
+}
+
+// testDecodeBC7Mode6 decodes a mode 6 BC7 block into 16 RGBA pixels
+func testDecodeBC7Mode6(t *testing.T, block []byte) [16][4]int {
+	t.Helper()
+	if testBlockBits(block, 0, 7) != 1<<6 {
+		t.Fatalf("expected a mode 6 block but got the mode bits %b", testBlockBits(block, 0, 7))
+	}
+	var c0, c1 [4]int
+	pos := 7
+	for c := 0; c < 4; c++ {
+		c0[c] = testBlockBits(block, pos, 7)
+		c1[c] = testBlockBits(block, pos+7, 7)
+		pos += 14
+	}
+	p0, p1 := testBlockBits(block, pos, 1), testBlockBits(block, pos+1, 1)
+	pos += 2
+	var out [16][4]int
+	for i := range out {
+		bits := 4
+		if i == 0 {
+			bits = 3
+		}
+		w := bc7Weights4[testBlockBits(block, pos, bits)]
+		pos += bits
+		for c := 0; c < 4; c++ {
+			e0, e1 := c0[c]<<1|p0, c1[c]<<1|p1
+			out[i][c] = ((64-w)*e0 + w*e1 + 32) >> 6
+		}
+	}
+	return out
+}
+
+// testDecodeASTC decodes a block in the single mode that the encoder writes
+func testDecodeASTC(t *testing.T, block []byte) [16][4]int {
+	t.Helper()
+	if m := testBlockBits(block, 0, 11); m != astcBlockMode4x4Weights2Bit {
+		t.Fatalf("unexpected block mode %x", m)
+	}
+	if testBlockBits(block, 11, 2) != 0 || testBlockBits(block, 13, 4) != astcColorEndpointModeRgba {
+		t.Fatal("unexpected partition count or endpoint mode")
+	}
+	var v [8]int
+	for i := range v {
+		v[i] = testBlockBits(block, 17+i*8, 8)
+	}
+	if v[1]+v[3]+v[5] < v[0]+v[2]+v[4] {
+		t.Fatal("the endpoints would be blue contracted by the decoder")
+	}
+	var out [16][4]int
+	for i := range out {
+		w := testBlockBits(block, 127-i*2, 1) | testBlockBits(block, 127-(i*2+1), 1)<<1
+		for c := 0; c < 4; c++ {
+			e0, e1 := v[c*2]*257, v[c*2+1]*257
+			out[i][c] = ((e0*(64-astcWeights2[w]) + e1*astcWeights2[w] + 32) >> 6) >> 8
+		}
+	}
+	return out
+}
+
+func testBlockError(src TextureData, decoded [16][4]int) int {
+	worst := 0
+	for i := range decoded {
+		for c := 0; c < 4; c++ {
+			d := decoded[i][c] - int(src.Mem[((i/4)*src.Width+i%4)*bytesInPixel+c])
+			worst = max(worst, max(d, -d))
+		}
+	}
+	return worst
+}
+
+func TestGenerateTextureMips(t *testing.T) {
+	data := testTextureGradient(8, 2)
+	mips, err := GenerateTextureMips(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{8 * 2, 4 * 1, 2 * 1, 1 * 1}
+	if len(mips) != len(expected) {
+		t.Fatalf("expected %d mips but got %d", len(expected), len(mips))
+	}
+	for i := range expected {
+		if len(mips[i]) != expected[i]*bytesInPixel {
+			t.Errorf("mip %d: expected %d pixels but got %d", i, expected[i], len(mips[i])/bytesInPixel)
+		}
+	}
+	last := mips[len(mips)-1]
+	if last[2] != 128 || last[3] != 255 {
+		t.Errorf("expected the constant channels to stay the same but got %v", last)
+	}
+	if _, err := GenerateTextureMips(TextureData{InternalFormat: TextureInputTypeCompressedRgbaAstc4x4}); err == nil {
+		t.Error("expected compressed textures to fail")
+	}
+}
+
+func TestEncodeBC7SolidColor(t *testing.T) {
+	color := [4]byte{200, 17, 90, 255}
+	data := testCubeMapFace(4, color)
+	out := EncodeBC7(data.Mem, 4, 4)
+	if len(out) != compressedBlockBytes {
+		t.Fatalf("expected a single block but got %d bytes", len(out))
+	}
+	decoded := testDecodeBC7Mode6(t, out)
+	// The lowest bit is shared by all channels of an endpoint
+	if e := testBlockError(data, decoded); e > 1 {
+		t.Errorf("expected a solid color to be off by at most 1 but it was off by %d", e)
+	}
+}
+
+func TestEncodeBC7Ramp(t *testing.T) {
+	data := testTextureRamp()
+	decoded := testDecodeBC7Mode6(t, EncodeBC7(data.Mem, 4, 4))
+	if e := testBlockError(data, decoded); e > 4 {
+		t.Errorf("expected the ramp to be close but it was off by %d", e)
+	}
+}
+
+func TestEncodeASTCSolidColor(t *testing.T) {
+	color := [4]byte{3, 250, 41, 128}
+	data := testCubeMapFace(4, color)
+	decoded := testDecodeASTC(t, EncodeASTC4x4(data.Mem, 4, 4))
+	if e := testBlockError(data, decoded); e != 0 {
+		t.Errorf("expected a solid color to be exact but it was off by %d", e)
+	}
+}
+
+func TestEncodeASTCRamp(t *testing.T) {
+	data := testTextureRamp()
+	decoded := testDecodeASTC(t, EncodeASTC4x4(data.Mem, 4, 4))
+	if e := testBlockError(data, decoded); e > 4 {
+		t.Errorf("expected the ramp to be close but it was off by %d", e)
+	}
+}
+
+func TestEncodeBlocksPadsEdges(t *testing.T) {
+	data := testTextureGradient(5, 3)
+	if got := len(EncodeBC7(data.Mem, 5, 3)); got != 2*compressedBlockBytes {
+		t.Errorf("expected 2 blocks but got %d bytes", got)
+	}
+	if got := textureLevelSize(TextureInputTypeCompressedRgbaAstc4x4, 5, 3); got != 2*compressedBlockBytes {
+		t.Errorf("expected the level size to be 2 blocks but got %d bytes", got)
+	}
+	if got := textureLevelSize(TextureInputTypeRgba8, 5, 3); got != 5*3*bytesInPixel {
+		t.Errorf("expected the level size to be 15 pixels but got %d bytes", got)
+	}
+}
+
+func TestProcessedTextureSelect(t *testing.T) {
+	tex, err := ProcessTexture(testTextureGradient(16, 8), TextureCompressionAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem, err := tex.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tex, err = ReadProcessedTexture(mem); err != nil {
+		t.Fatal(err)
+	}
+	if len(tex.Variants) != 3 {
+		t.Fatalf("expected 3 variants but got %d", len(tex.Variants))
+	}
+	onlyASTC := func(f TextureInputType) bool { return f == TextureInputTypeCompressedRgbaAstc4x4 }
+	data, err := tex.Select(onlyASTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.InternalFormat != TextureInputTypeCompressedRgbaAstc4x4 || data.Mips != 5 {
+		t.Errorf("expected the ASTC variant with 5 mips but got %d with %d", data.InternalFormat, data.Mips)
+	}
+	size := 0
+	w, h := 16, 8
+	for i := 0; i < data.Mips; i++ {
+		size += textureLevelSize(data.InternalFormat, w, h)
+		w, h = max(1, w/2), max(1, h/2)
+	}
+	if len(data.Mem) != size {
+		t.Errorf("expected %d bytes of mips but got %d", size, len(data.Mem))
+	}
+	data, _ = tex.Select(func(TextureInputType) bool { return false })
+	if data.InternalFormat != TextureInputTypeRgba8 {
+		t.Errorf("expected the uncompressed fallback but got %d", data.InternalFormat)
+	}
+	if _, err := ProcessTexture(testTextureGradient(4, 4), "etc2"); err == nil {
+		t.Error("expected an unknown compression to fail")
+	}
+}
+
+func TestTextureFileKeyPrefersProcessed(t *testing.T) {
+	db := assets.NewMemoryDatabase(map[string][]byte{
+		"textures/a.png":      {1},
+		"textures/a.png.ktex": {2},
+		"textures/b.png":      {3},
+	})
+	if k := textureFileKey(&db, "textures/a.png"); k != "textures/a.png.ktex" {
+		t.Errorf("expected the processed texture of the image, got %s", k)
+	}
+	if k := textureFileKey(&db, "textures/b.png"); k != "textures/b.png" {
+		t.Errorf("expected the image without a processed texture, got %s", k)
+	}
+	if k := textureFileKey(&db, "abc.ktex"); k != "abc.ktex" {
+		t.Errorf("expected a processed key to be read as is, got %s", k)
+	}
+}
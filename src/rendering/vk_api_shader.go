@@ -38,6 +38,7 @@
 package rendering
 
 import (
+	"errors"
 	"kaiju/assets"
 	"log/slog"
 	"strings"
@@ -49,114 +50,18 @@ import (
 type FuncPipeline func(renderer Renderer, shader *Shader, shaderStages []vk.PipelineShaderStageCreateInfo) bool
 
 func (vr *Vulkan) CreateShader(shader *Shader, assetDB *assets.Database) {
-	var vert, frag, geom, tesc, tese vk.ShaderModule
-	var vMem, fMem, gMem, cMem, eMem []byte
-	vertStage := vk.PipelineShaderStageCreateInfo{}
-	vMem, err := assetDB.Read(shader.VertPath)
+	stages, err := vr.loadShaderStages(shader, assetDB)
 	if err != nil {
-		panic("Failed to load vertex shader")
+		panic(err.Error())
 	}
-	vert, ok := vr.createSpvModule(vMem)
-	if !ok {
-		panic("Failed to create vertex shader module")
-	}
-	vertStage.SType = vk.StructureTypePipelineShaderStageCreateInfo
-	vertStage.Stage = vk.ShaderStageVertexBit
-	vertStage.Module = vert
-	vertStage.PName = (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0]))
-	shader.RenderId.vertModule = vert
-
-	fragStage := vk.PipelineShaderStageCreateInfo{}
-	fMem, err = assetDB.Read(shader.FragPath)
-	if err != nil {
-		panic("Failed to load fragment shader")
-	}
-	frag, ok = vr.createSpvModule(fMem)
-	if !ok {
-		panic("Failed to create fragment shader module")
-	}
-	fragStage.SType = vk.StructureTypePipelineShaderStageCreateInfo
-	fragStage.Stage = vk.ShaderStageFragmentBit
-	fragStage.Module = frag
-	fragStage.PName = (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0]))
-	shader.RenderId.fragModule = frag
-
-	geomStage := vk.PipelineShaderStageCreateInfo{}
-	if len(shader.GeomPath) > 0 {
-		gMem, err = assetDB.Read(shader.GeomPath)
-		if err != nil {
-			panic("Failed to load geometry shader")
-		}
-		geom, ok = vr.createSpvModule(gMem)
-		if !ok {
-			panic("Failed to create geometry shader module")
-		}
-		geomStage.SType = vk.StructureTypePipelineShaderStageCreateInfo
-		geomStage.Stage = vk.ShaderStageGeometryBit
-		geomStage.Module = geom
-		geomStage.PName = (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0]))
-	}
-
-	tescStage := vk.PipelineShaderStageCreateInfo{}
-	if len(shader.CtrlPath) > 0 {
-		cMem, err = assetDB.Read(shader.CtrlPath)
-		if err != nil {
-			panic("Failed to load tessellation control shader")
-		}
-		tesc, ok = vr.createSpvModule(cMem)
-		if !ok {
-			panic("Failed to create tessellation control shader module")
-		}
-		tescStage.SType = vk.StructureTypePipelineShaderStageCreateInfo
-		tescStage.Stage = vk.ShaderStageTessellationControlBit
-		tescStage.Module = tesc
-		tescStage.PName = (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0]))
-		shader.RenderId.tescModule = tesc
-	}
-
-	teseStage := vk.PipelineShaderStageCreateInfo{}
-	if len(shader.EvalPath) > 0 {
-		eMem, err = assetDB.Read(shader.EvalPath)
-		if err != nil {
-			panic("Failed to load tessellation evaluation shader")
-		}
-		tese, ok = vr.createSpvModule(eMem)
-		if !ok {
-			panic("Failed to create tessellation evaluation shader module")
-		}
-		teseStage.SType = vk.StructureTypePipelineShaderStageCreateInfo
-		teseStage.Stage = vk.ShaderStageTessellationEvaluationBit
-		teseStage.Module = tese
-		teseStage.PName = (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0]))
-		shader.RenderId.teseModule = tese
-	}
-
 	id := &shader.RenderId
-
+	setShaderModules(id, stages)
 	id.descriptorSetLayout, err = vr.createDescriptorSetLayout(vr.device,
 		shader.DriverData.DescriptorSetLayoutStructure)
 	if err != nil {
 		// TODO:  Handle this error properly
 		slog.Error(err.Error())
 	}
-
-	stages := make([]vk.PipelineShaderStageCreateInfo, 0)
-	if vertStage.SType != 0 {
-		stages = append(stages, vertStage)
-	}
-	if fragStage.SType != 0 {
-		stages = append(stages, fragStage)
-	}
-	if geomStage.SType != 0 {
-		stages = append(stages, geomStage)
-	}
-	if tescStage.SType != 0 {
-		stages = append(stages, tescStage)
-	}
-	if teseStage.SType != 0 {
-		stages = append(stages, teseStage)
-	}
-
 	shader.DriverData.pipelineConstructor(vr, shader, stages)
 	// TODO:  Setup subshader in the shader definition?
 	subShaderCheck := strings.TrimSuffix(shader.FragPath, ".spv") + oitSuffix
@@ -169,6 +74,92 @@ func (vr *Vulkan) CreateShader(shader *Shader, assetDB *assets.Database) {
 	}
 }
 
+// ReloadShader reads the stages of the shader again and rebuilds its
+// pipeline, along with the pipelines of its sub shaders. The descriptor set
+// layout is kept so the descriptor sets that were allocated for the shader
+// stay valid. The shader is left as it was if any of its stages fail to load.
+func (vr *Vulkan) ReloadShader(shader *Shader, assetDB *assets.Database) error {
+	stages, err := vr.loadShaderStages(shader, assetDB)
+	if err != nil {
+		return err
+	}
+	vk.DeviceWaitIdle(vr.device)
+	vr.destroyShaderPipeline(&shader.RenderId)
+	setShaderModules(&shader.RenderId, stages)
+	if !shader.DriverData.pipelineConstructor(vr, shader, stages) {
+		return errors.New("failed to create the pipeline for the shader " + shader.Key)
+	}
+	for _, ss := range shader.subShaders {
+		if err := vr.ReloadShader(ss, assetDB); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadShaderStages reads the SPIR-V of each of the stages of the shader and
+// creates their modules, the modules that were created are destroyed if any
+// of the stages fail to load
+func (vr *Vulkan) loadShaderStages(shader *Shader, assetDB *assets.Database) ([]vk.PipelineShaderStageCreateInfo, error) {
+	files := []struct {
+		path  string
+		stage vk.ShaderStageFlagBits
+		name  string
+	}{
+		{shader.VertPath, vk.ShaderStageVertexBit, "vertex"},
+		{shader.FragPath, vk.ShaderStageFragmentBit, "fragment"},
+		{shader.GeomPath, vk.ShaderStageGeometryBit, "geometry"},
+		{shader.CtrlPath, vk.ShaderStageTessellationControlBit, "tessellation control"},
+		{shader.EvalPath, vk.ShaderStageTessellationEvaluationBit, "tessellation evaluation"},
+	}
+	stages := make([]vk.PipelineShaderStageCreateInfo, 0, len(files))
+	fail := func(err error) ([]vk.PipelineShaderStageCreateInfo, error) {
+		for i := range stages {
+			vk.DestroyShaderModule(vr.device, stages[i].Module, nil)
+			vr.dbg.remove(vk.TypeToUintPtr(stages[i].Module))
+		}
+		return nil, err
+	}
+	for i, f := range files {
+		// The vertex and fragment stages are required
+		if i > 1 && len(f.path) == 0 {
+			continue
+		}
+		mem, err := assetDB.Read(f.path)
+		if err != nil || len(mem) == 0 {
+			return fail(errors.New("Failed to load " + f.name + " shader"))
+		}
+		module, ok := vr.createSpvModule(mem)
+		if !ok {
+			return fail(errors.New("Failed to create " + f.name + " shader module"))
+		}
+		stages = append(stages, vk.PipelineShaderStageCreateInfo{
+			SType:  vk.StructureTypePipelineShaderStageCreateInfo,
+			Stage:  f.stage,
+			Module: module,
+			PName:  (*vk.Char)(unsafe.Pointer(&([]byte("main\x00"))[0])),
+		})
+	}
+	return stages, nil
+}
+
+func setShaderModules(id *ShaderId, stages []vk.PipelineShaderStageCreateInfo) {
+	for i := range stages {
+		switch stages[i].Stage {
+		case vk.ShaderStageVertexBit:
+			id.vertModule = stages[i].Module
+		case vk.ShaderStageFragmentBit:
+			id.fragModule = stages[i].Module
+		case vk.ShaderStageGeometryBit:
+			id.geomModule = stages[i].Module
+		case vk.ShaderStageTessellationControlBit:
+			id.tescModule = stages[i].Module
+		case vk.ShaderStageTessellationEvaluationBit:
+			id.teseModule = stages[i].Module
+		}
+	}
+}
+
 func (vr *Vulkan) createSpvModule(mem []byte) (vk.ShaderModule, bool) {
 	info := vk.ShaderModuleCreateInfo{}
 	info.SType = vk.StructureTypeShaderModuleCreateInfo
@@ -186,29 +177,38 @@ func (vr *Vulkan) createSpvModule(mem []byte) (vk.ShaderModule, bool) {
 
 func (vr *Vulkan) DestroyShader(shader *Shader) {
 	vk.DeviceWaitIdle(vr.device)
-	vk.DestroyPipeline(vr.device, shader.RenderId.graphicsPipeline, nil)
-	vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.graphicsPipeline))
-	vk.DestroyPipelineLayout(vr.device, shader.RenderId.pipelineLayout, nil)
-	vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.pipelineLayout))
-	vk.DestroyShaderModule(vr.device, shader.RenderId.vertModule, nil)
-	vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.vertModule))
-	vk.DestroyShaderModule(vr.device, shader.RenderId.fragModule, nil)
-	vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.fragModule))
-	if shader.RenderId.geomModule != vk.ShaderModule(vk.NullHandle) {
-		vk.DestroyShaderModule(vr.device, shader.RenderId.geomModule, nil)
-		vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.geomModule))
-	}
-	if shader.RenderId.tescModule != vk.ShaderModule(vk.NullHandle) {
-		vk.DestroyShaderModule(vr.device, shader.RenderId.tescModule, nil)
-		vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.tescModule))
-	}
-	if shader.RenderId.teseModule != vk.ShaderModule(vk.NullHandle) {
-		vk.DestroyShaderModule(vr.device, shader.RenderId.teseModule, nil)
-		vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.teseModule))
-	}
+	vr.destroyShaderPipeline(&shader.RenderId)
 	vk.DestroyDescriptorSetLayout(vr.device, shader.RenderId.descriptorSetLayout, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(shader.RenderId.descriptorSetLayout))
 	for _, ss := range shader.subShaders {
 		vr.DestroyShader(ss)
 	}
 }
+
+// destroyShaderPipeline destroys the pipeline, the pipeline layout, and the
+// modules of the shader, the caller must make sure the device is idle
+func (vr *Vulkan) destroyShaderPipeline(id *ShaderId) {
+	vk.DestroyPipeline(vr.device, id.graphicsPipeline, nil)
+	vr.dbg.remove(vk.TypeToUintPtr(id.graphicsPipeline))
+	vk.DestroyPipelineLayout(vr.device, id.pipelineLayout, nil)
+	vr.dbg.remove(vk.TypeToUintPtr(id.pipelineLayout))
+	vk.DestroyShaderModule(vr.device, id.vertModule, nil)
+	vr.dbg.remove(vk.TypeToUintPtr(id.vertModule))
+	vk.DestroyShaderModule(vr.device, id.fragModule, nil)
+	vr.dbg.remove(vk.TypeToUintPtr(id.fragModule))
+	if id.geomModule != vk.ShaderModule(vk.NullHandle) {
+		vk.DestroyShaderModule(vr.device, id.geomModule, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(id.geomModule))
+	}
+	if id.tescModule != vk.ShaderModule(vk.NullHandle) {
+		vk.DestroyShaderModule(vr.device, id.tescModule, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(id.tescModule))
+	}
+	if id.teseModule != vk.ShaderModule(vk.NullHandle) {
+		vk.DestroyShaderModule(vr.device, id.teseModule, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(id.teseModule))
+	}
+	id.geomModule = vk.ShaderModule(vk.NullHandle)
+	id.tescModule = vk.ShaderModule(vk.NullHandle)
+	id.teseModule = vk.ShaderModule(vk.NullHandle)
+}
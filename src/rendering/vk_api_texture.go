@@ -60,6 +60,9 @@ func (vr *Vulkan) CreateImage(width, height, mipLevels uint32, numSamples vk.Sam
 	imageInfo.Usage = usage
 	imageInfo.Samples = numSamples
 	imageInfo.SharingMode = vk.SharingModeExclusive
+	if layerCount == CubeMapSides {
+		imageInfo.Flags = vk.ImageCreateFlags(vk.ImageCreateCubeCompatibleBit)
+	}
 	var image vk.Image
 	if vk.CreateImage(vr.device, &imageInfo, nil, &image) != vk.Success {
 		slog.Error("Failed to create image")
@@ -93,13 +96,14 @@ func (vr *Vulkan) CreateImage(width, height, mipLevels uint32, numSamples vk.Sam
 	textureId.Format = format
 	textureId.Width = int(width)
 	textureId.Height = int(height)
-	textureId.LayerCount = 1
+	textureId.LayerCount = layerCount
 	textureId.MipLevels = mipLevels
 	textureId.Samples = numSamples
 	return true
 }
 
-func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
+// textureFormat returns the Vulkan format of the texture data
+func textureFormat(data *TextureData) vk.Format {
 	format := vk.FormatR8g8b8a8Srgb
 	switch data.InternalFormat {
 	case TextureInputTypeRgba8:
@@ -118,6 +122,10 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 		//format = VK_FORMAT_ASTC_4x4_SFLOAT_BLOCK
 		format = vk.FormatAstc4x4SrgbBlock
 		//format = VK_FORMAT_ASTC_4x4_UNORM_BLOCK;
+		// Processed textures keep the color space of the PNG they came from
+		if data.InputType == TextureFileFormatProcessed {
+			format = vk.FormatAstc4x4UnormBlock
+		}
 	case TextureInputTypeCompressedRgbaAstc5x4:
 		//format = VK_FORMAT_ASTC_5x4_SFLOAT_BLOCK
 		format = vk.FormatAstc5x4SrgbBlock
@@ -170,6 +178,8 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 		//format = VK_FORMAT_ASTC_12x1SFLOAT_BLOCK;
 		format = vk.FormatAstc12x12SrgbBlock
 		//format = VK_FORMAT_ASTC_12x12_UNORM_BLOCK;
+	case TextureInputTypeCompressedRgbaBc7:
+		format = vk.FormatBc7UnormBlock
 	case TextureInputTypeLuminance:
 		panic("Luminance textures are not supported")
 	}
@@ -190,6 +200,27 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	//		fmt = VK_FORMAT_R8G8B8A8_SRGB;
 	//		break;
 	//}
+	return format
+}
+
+// TextureFormatSupported returns true if the device can sample textures of
+// the format, used to pick between the variants of a #ProcessedTexture
+func (vr *Vulkan) TextureFormatSupported(format TextureInputType) bool {
+	if format == TextureInputTypeLuminance {
+		return false
+	}
+	data := TextureData{
+		InternalFormat: format,
+		Format:         TextureColorFormatRgbaUnorm,
+		InputType:      TextureFileFormatProcessed,
+	}
+	var fp vk.FormatProperties
+	vk.GetPhysicalDeviceFormatProperties(vr.physicalDevice, textureFormat(&data), &fp)
+	return (uint32(fp.OptimalTilingFeatures) & uint32(vk.FormatFeatureSampledImageBit)) != 0
+}
+
+func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
+	format := textureFormat(data)
 
 	filter := vk.FilterLinear
 	switch texture.Filter {
@@ -203,7 +234,9 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	use := vk.ImageUsageTransferSrcBit | vk.ImageUsageTransferDstBit | vk.ImageUsageSampledBit
 	props := vk.MemoryPropertyDeviceLocalBit
 	mip := texture.MipLevels
-	if mip <= 0 {
+	if data.Mips > 0 {
+		mip = data.Mips
+	} else if mip <= 0 {
 		w, h := float32(data.Width), float32(data.Height)
 		mip = int(matrix.Floor(matrix.Log2(matrix.Max(w, h)))) + 1
 	}
@@ -222,6 +255,9 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	vk.UnmapMemory(vr.device, stagingBufferMemory)
 	// TODO:  Provide the desired sample as part of texture data?
 	layerCount := 1
+	if data.CubeMap {
+		layerCount = CubeMapSides
+	}
 	vr.CreateImage(uint32(data.Width), uint32(data.Height), uint32(mip),
 		vk.SampleCount1Bit, format, tile, vk.ImageUsageFlags(use), vk.MemoryPropertyFlags(props), &texture.RenderId, layerCount)
 	texture.RenderId.MipLevels = uint32(mip)
@@ -232,14 +268,24 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	vr.transitionImageLayout(&texture.RenderId,
 		vk.ImageLayoutTransferDstOptimal, vk.ImageAspectFlags(vk.ImageAspectColorBit),
 		texture.RenderId.Access, vk.NullCommandBuffer)
-	vr.copyBufferToImage(stagingBuffer, texture.RenderId.Image,
-		uint32(data.Width), uint32(data.Height))
+	if data.Mips > 0 {
+		vr.copyBufferToImageMips(stagingBuffer, texture.RenderId.Image, data, uint32(layerCount))
+	} else {
+		vr.copyBufferToImage(stagingBuffer, texture.RenderId.Image,
+			uint32(data.Width), uint32(data.Height), uint32(layerCount))
+	}
 	vk.DestroyBuffer(vr.device, stagingBuffer, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(stagingBuffer))
 	vk.FreeMemory(vr.device, stagingBufferMemory, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(stagingBufferMemory))
-	vr.generateMipmaps(texture.RenderId.Image, format,
-		uint32(data.Width), uint32(data.Height), uint32(mip), filter)
+	if data.Mips > 0 {
+		vr.transitionImageLayout(&texture.RenderId, vk.ImageLayoutShaderReadOnlyOptimal,
+			vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessShaderReadBit), vk.NullCommandBuffer)
+	} else {
+		vr.generateMipmaps(texture.RenderId.Image, format,
+			uint32(data.Width), uint32(data.Height), uint32(mip), uint32(layerCount), filter)
+	}
 	vr.createImageView(&texture.RenderId,
 		vk.ImageAspectFlags(vk.ImageAspectColorBit))
 	vr.createTextureSampler(&texture.RenderId.Sampler, uint32(mip), filter)
@@ -0,0 +1,201 @@
+/******************************************************************************/
+/* vk_camera_canvas.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/cameras"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"log/slog"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// CameraCanvas is the canvas that a #RenderCamera draws into. It draws like
+// the default canvas, using the same shaders, but with its own size and the
+// view of its own camera. Once drawn the color is left ready to be sampled so
+// that it can be used as the texture of an image or a material.
+type CameraCanvas struct {
+	OITCanvas
+	uniformBuffers       [maxFramesInFlight]vk.Buffer
+	uniformBuffersMemory [maxFramesInFlight]vk.DeviceMemory
+}
+
+func (r *CameraCanvas) isOffscreen() {}
+
+func (r *CameraCanvas) Draw(renderer Renderer, drawings []ShaderDraw) {
+	vr := renderer.(*Vulkan)
+	vr.cameraCanvas = r
+	defer func() { vr.cameraCanvas = nil }()
+	for i := range drawings {
+		vr.writeDrawingDescriptors(drawings[i].shader, drawings[i].instanceGroups)
+	}
+	cmd := vr.commandBuffers[vr.currentFrame*MaxCommandBuffers+vr.commandBuffersCount]
+	vr.commandBuffersCount++
+	beginInfo := vk.CommandBufferBeginInfo{SType: vk.StructureTypeCommandBufferBeginInfo}
+	if vk.BeginCommandBuffer(cmd, &beginInfo) != vk.Success {
+		slog.Error("Failed to begin recording command buffer")
+		return
+	}
+	extent := r.extent(vr)
+	vr.transitionImageLayout(&r.color, vk.ImageLayoutColorAttachmentOptimal,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit),
+		vk.AccessFlags(vk.AccessColorAttachmentReadBit|vk.AccessColorAttachmentWriteBit), cmd)
+	beginRenderPass(r.opaquePass, extent, cmd, r.opaqueClear())
+	r.renderOpaque(vr, cmd, drawings)
+	vk.CmdEndRenderPass(cmd)
+	beginRenderPass(r.transparentPass, extent, cmd, r.transparentClear())
+	r.renderTransparent(vr, cmd, drawings)
+	vk.CmdEndRenderPass(cmd)
+	vr.transitionImageLayout(&r.color, vk.ImageLayoutShaderReadOnlyOptimal,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit), vk.AccessFlags(vk.AccessShaderReadBit), cmd)
+	r.colorTexture.RenderId = r.color
+	vk.EndCommandBuffer(cmd)
+}
+
+func (r *CameraCanvas) Create(renderer Renderer) error {
+	vr := renderer.(*Vulkan)
+	if err := r.OITCanvas.Create(vr); err != nil {
+		return err
+	}
+	// The color may be sampled before the camera has drawn anything
+	vr.transitionImageLayout(&r.color, vk.ImageLayoutShaderReadOnlyOptimal,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit),
+		vk.AccessFlags(vk.AccessShaderReadBit), vk.NullCommandBuffer)
+	r.colorTexture.RenderId = r.color
+	r.colorTexture.Width = int(r.size.Width)
+	r.colorTexture.Height = int(r.size.Height)
+	return nil
+}
+
+// updateUniformBuffer writes the global shader data of the frame into the
+// uniform buffer of the canvas, replacing the view with the camera's
+func (r *CameraCanvas) updateUniformBuffer(vr *Vulkan, camera cameras.Camera) {
+	ubo := vr.frameShaderData
+	ubo.View = camera.View()
+	ubo.Projection = camera.Projection()
+	ubo.CameraPosition = camera.Position()
+	ubo.ScreenSize = matrix.Vec2{
+		matrix.Float(r.size.Width),
+		matrix.Float(r.size.Height),
+	}
+	var data unsafe.Pointer
+	res := vk.MapMemory(vr.device, r.uniformBuffersMemory[vr.currentFrame],
+		0, vk.DeviceSize(unsafe.Sizeof(ubo)), 0, &data)
+	if res != vk.Success {
+		slog.Error("Failed to map uniform buffer memory", slog.Int("code", int(res)))
+		return
+	}
+	vk.Memcopy(data, klib.StructToByteArray(ubo))
+	vk.UnmapMemory(vr.device, r.uniformBuffersMemory[vr.currentFrame])
+}
+
+// release frees the resources of the canvas that live beyond the swap chain,
+// it is called once the canvas is no longer registered
+func (r *CameraCanvas) release(vr *Vulkan) {
+	vk.DeviceWaitIdle(vr.device)
+	for i := 0; i < maxFramesInFlight; i++ {
+		vk.DestroyBuffer(vr.device, r.uniformBuffers[i], nil)
+		vr.dbg.remove(vk.TypeToUintPtr(r.uniformBuffers[i]))
+		vk.FreeMemory(vr.device, r.uniformBuffersMemory[i], nil)
+		vr.dbg.remove(vk.TypeToUintPtr(r.uniformBuffersMemory[i]))
+	}
+	if r.descriptorPool != vk.DescriptorPool(vk.NullHandle) {
+		vk.FreeDescriptorSets(vr.device, r.descriptorPool,
+			uint32(len(r.descriptorSets)), &r.descriptorSets[0])
+	}
+}
+
+// CreateCameraCanvas creates a #CameraCanvas of the given size and registers
+// it under the name
+func (vr *Vulkan) CreateCameraCanvas(name string, width, height int32) (Canvas, error) {
+	if _, ok := vr.canvases[name]; ok {
+		return nil, errors.New("a canvas named " + name + " is already registered")
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("the camera canvas " + name + " must have a size")
+	}
+	c := &CameraCanvas{}
+	c.size = vk.Extent2D{Width: uint32(width), Height: uint32(height)}
+	bufferSize := vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil)))
+	for i := 0; i < maxFramesInFlight; i++ {
+		vr.CreateBuffer(bufferSize, vk.BufferUsageFlags(vk.BufferUsageUniformBufferBit),
+			vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit|vk.MemoryPropertyHostCoherentBit),
+			&c.uniformBuffers[i], &c.uniformBuffersMemory[i])
+	}
+	if err := c.Create(vr); err != nil {
+		c.Destroy(vr)
+		c.release(vr)
+		return nil, err
+	}
+	c.Initialize(vr, float32(width), float32(height))
+	vr.RegisterCanvas(name, c)
+	return c, nil
+}
+
+// DrawCamera draws the drawings that target camera canvases from the point
+// of view of the camera
+func (vr *Vulkan) DrawCamera(camera cameras.Camera, drawings []RenderTargetDraw) {
+	if !vr.hasSwapChain {
+		return
+	}
+	for i := range drawings {
+		c, ok := drawings[i].Target.(*CameraCanvas)
+		if !ok {
+			continue
+		}
+		c.updateUniformBuffer(vr, camera)
+		c.Draw(vr, drawings[i].innerDraws)
+	}
+}
+
+// DestroyCanvas destroys the canvas that was registered under the name and
+// removes it from the renderer
+func (vr *Vulkan) DestroyCanvas(name string) {
+	c, ok := vr.canvases[name]
+	if !ok {
+		return
+	}
+	delete(vr.canvases, name)
+	c.Destroy(vr)
+	if cc, ok := c.(*CameraCanvas); ok {
+		cc.release(vr)
+	}
+}
@@ -91,7 +91,7 @@ func (vr *Vulkan) writeDrawingDescriptors(key *Shader, groups []DrawInstanceGrou
 			continue
 		}
 		set := group.InstanceDriverData.descriptorSets[vr.currentFrame]
-		globalInfo := bufferInfo(vr.globalUniformBuffers[vr.currentFrame],
+		globalInfo := bufferInfo(vr.frameUniformBuffer(),
 			vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil))))
 		namedInfos := map[string]vk.DescriptorBufferInfo{}
 		for k := range group.namedBuffers {
@@ -123,6 +123,7 @@ func (vr *Vulkan) writeDrawingDescriptors(key *Shader, groups []DrawInstanceGrou
 				count++
 			}
 			vk.UpdateDescriptorSets(vr.device, uint32(count), &descriptorWrites[0], 0, nil)
+			vr.writeCanvasDescriptors(key, set)
 		} else {
 			descriptorWrites := []vk.WriteDescriptorSet{
 				prepareSetWriteBuffer(set, []vk.DescriptorBufferInfo{globalInfo},
@@ -130,10 +131,45 @@ func (vr *Vulkan) writeDrawingDescriptors(key *Shader, groups []DrawInstanceGrou
 			}
 			count := uint32(len(descriptorWrites))
 			vk.UpdateDescriptorSets(vr.device, count, &descriptorWrites[0], 0, nil)
+			vr.writeCanvasDescriptors(key, set)
 		}
 	}
 }
 
+// writeCanvasDescriptors binds the color output of the canvases named by the
+// layouts of the shader definition, like the shadow atlas for lit shaders,
+// and the environment cube map to the environment layouts
+func (vr *Vulkan) writeCanvasDescriptors(shader *Shader, set vk.DescriptorSet) {
+	if shader.definition == nil {
+		return
+	}
+	for i := range shader.definition.Layouts {
+		layout := &shader.definition.Layouts[i]
+		if layout.Environment {
+			t := vr.environmentTexture()
+			infos := [...]vk.DescriptorImageInfo{
+				imageInfo(t.RenderId.View, t.RenderId.Sampler),
+			}
+			write := prepareSetWriteImage(set, infos[:], uint32(layout.Binding), false)
+			vk.UpdateDescriptorSets(vr.device, 1, &write, 0, nil)
+			continue
+		}
+		if layout.Canvas == "" {
+			continue
+		}
+		canvas, ok := vr.canvases[layout.Canvas]
+		if !ok || canvas.Color() == nil || !canvas.Color().RenderId.IsValid() {
+			continue
+		}
+		t := canvas.Color()
+		infos := [...]vk.DescriptorImageInfo{
+			imageInfo(t.RenderId.View, t.RenderId.Sampler),
+		}
+		write := prepareSetWriteImage(set, infos[:], uint32(layout.Binding), false)
+		vk.UpdateDescriptorSets(vr.device, 1, &write, 0, nil)
+	}
+}
+
 func beginRender(pass RenderPass, extent vk.Extent2D,
 	commandBuffer vk.CommandBuffer, clearColors [2]vk.ClearValue) {
 
@@ -145,6 +181,14 @@ func beginRender(pass RenderPass, extent vk.Extent2D,
 		slog.Error("Failed to begin recording command buffer")
 		return
 	}
+	beginRenderPass(pass, extent, commandBuffer, clearColors)
+}
+
+// beginRenderPass begins the render pass within a command buffer that is
+// already recording, for recording multiple passes into a single buffer
+func beginRenderPass(pass RenderPass, extent vk.Extent2D,
+	commandBuffer vk.CommandBuffer, clearColors [2]vk.ClearValue) {
+
 	renderPassInfo := vk.RenderPassBeginInfo{}
 	renderPassInfo.SType = vk.StructureTypeRenderPassBeginInfo
 	renderPassInfo.RenderPass = pass.Handle
@@ -259,7 +303,14 @@ func (vr *Vulkan) Draw(drawings []RenderTargetDraw) {
 		return
 	}
 	for i := range drawings {
-		drawings[i].Target.Draw(vr, drawings[i].innerDraws)
+		if isOffscreenCanvas(drawings[i].Target) {
+			drawings[i].Target.Draw(vr, drawings[i].innerDraws)
+		}
+	}
+	for i := range drawings {
+		if !isOffscreenCanvas(drawings[i].Target) {
+			drawings[i].Target.Draw(vr, drawings[i].innerDraws)
+		}
 	}
 }
 
@@ -343,8 +394,13 @@ func (vr *Vulkan) BlitTargets(targets ...RenderTargetDraw) {
 	if !vr.hasSwapChain {
 		return
 	}
+	targets = onscreenTargets(targets)
+	if len(targets) == 0 {
+		return
+	}
 	vr.prepCombinedTargets(targets...)
 	combined := vr.combineTargets(targets...)
+	combined = vr.postProcessCanvas.apply(vr, vr.postProcess[vr.frameCamera], combined)
 	frame := vr.currentFrame
 	cmdBuffIdx := frame * MaxCommandBuffers
 	idxSF := vr.imageIndex[frame]
@@ -387,6 +443,21 @@ func (vr *Vulkan) BlitTargets(targets ...RenderTargetDraw) {
 	vr.cleanupCombined(targets...)
 }
 
+func onscreenTargets(targets []RenderTargetDraw) []RenderTargetDraw {
+	for i := range targets {
+		if isOffscreenCanvas(targets[i].Target) {
+			onscreen := make([]RenderTargetDraw, 0, len(targets)-1)
+			for j := range targets {
+				if !isOffscreenCanvas(targets[j].Target) {
+					onscreen = append(onscreen, targets[j])
+				}
+			}
+			return onscreen
+		}
+	}
+	return targets
+}
+
 func (vr *Vulkan) resizeUniformBuffer(shader *Shader, group *DrawInstanceGroup) {
 	currentCount := len(group.Instances)
 	lastCount := group.InstanceDriverData.lastInstanceCount
@@ -0,0 +1,88 @@
+/******************************************************************************/
+/* vk_environment.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+)
+
+// SetEnvironment sets the cube map that shaders with an environment layout
+// sample their reflections from, the intensity is how much of the reflection
+// is mixed into the color. A nil texture removes the environment.
+func (vr *Vulkan) SetEnvironment(texture *Texture, intensity matrix.Float) {
+	vr.environment = texture
+	vr.environmentIntensity = intensity
+}
+
+// Environment returns the cube map and intensity set with SetEnvironment
+func (vr *Vulkan) Environment() (*Texture, matrix.Float) {
+	return vr.environment, vr.environmentIntensity
+}
+
+// createDefaultEnvironment creates the black cube map that is bound to the
+// environment layouts of shaders while there is no environment, or while
+// the environment cube map is still waiting to be created
+func (vr *Vulkan) createDefaultEnvironment() {
+	var faces [CubeMapSides]TextureData
+	for i := range faces {
+		faces[i] = TextureData{
+			Mem:            []byte{0, 0, 0, 255},
+			InternalFormat: TextureInputTypeRgba8,
+			Format:         TextureColorFormatRgbaUnorm,
+			Type:           TextureMemTypeUnsignedByte,
+			Width:          1,
+			Height:         1,
+		}
+	}
+	data, _ := NewCubeMapTextureData(faces)
+	vr.defaultEnvironment = &Texture{
+		Key:       "environment",
+		Filter:    TextureFilterLinear,
+		MipLevels: 1,
+		Width:     1,
+		Height:    1,
+	}
+	vr.CreateTexture(vr.defaultEnvironment, &data)
+}
+
+func (vr *Vulkan) environmentTexture() *Texture {
+	if vr.environment != nil && vr.environment.RenderId.IsValid() {
+		return vr.environment
+	}
+	return vr.defaultEnvironment
+}
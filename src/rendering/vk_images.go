@@ -51,7 +51,7 @@ var accessMaskPipelineStageFlagsDefault = uint32(vk.PipelineStageVertexShaderBit
 	vk.PipelineStageFragmentShaderBit |
 	vk.PipelineStageComputeShaderBit)
 
-func (vr *Vulkan) generateMipmaps(image vk.Image, imageFormat vk.Format, texWidth, texHeight, mipLevels uint32, filter vk.Filter) bool {
+func (vr *Vulkan) generateMipmaps(image vk.Image, imageFormat vk.Format, texWidth, texHeight, mipLevels, layerCount uint32, filter vk.Filter) bool {
 	var fp vk.FormatProperties
 	vk.GetPhysicalDeviceFormatProperties(vr.physicalDevice, imageFormat, &fp)
 	if (uint32(fp.OptimalTilingFeatures) & uint32(vk.FormatFeatureSampledImageFilterLinearBit)) == 0 {
@@ -66,7 +66,7 @@ func (vr *Vulkan) generateMipmaps(image vk.Image, imageFormat vk.Format, texWidt
 	barrier.DstQueueFamilyIndex = vk.QueueFamilyIgnored
 	barrier.SubresourceRange.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
 	barrier.SubresourceRange.BaseArrayLayer = 0
-	barrier.SubresourceRange.LayerCount = 1
+	barrier.SubresourceRange.LayerCount = layerCount
 	barrier.SubresourceRange.LevelCount = 1
 	mipWidth := texWidth
 	mipHeight := texHeight
@@ -84,7 +84,7 @@ func (vr *Vulkan) generateMipmaps(image vk.Image, imageFormat vk.Format, texWidt
 		blit.SrcSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
 		blit.SrcSubresource.MipLevel = i - 1
 		blit.SrcSubresource.BaseArrayLayer = 0
-		blit.SrcSubresource.LayerCount = 1
+		blit.SrcSubresource.LayerCount = layerCount
 		blit.DstOffsets[0] = vk.Offset3D{X: 0, Y: 0, Z: 0}
 		blit.DstOffsets[1] = vk.Offset3D{X: 1, Y: 1, Z: 1}
 		if mipWidth > 1 {
@@ -96,7 +96,7 @@ func (vr *Vulkan) generateMipmaps(image vk.Image, imageFormat vk.Format, texWidt
 		blit.DstSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
 		blit.DstSubresource.MipLevel = i
 		blit.DstSubresource.BaseArrayLayer = 0
-		blit.DstSubresource.LayerCount = 1
+		blit.DstSubresource.LayerCount = layerCount
 		vk.CmdBlitImage(commandBuffer, image, vk.ImageLayoutTransferSrcOptimal,
 			image, vk.ImageLayoutTransferDstOptimal, 1, &blit, filter)
 		barrier.OldLayout = vk.ImageLayoutTransferSrcOptimal
@@ -128,6 +128,10 @@ func (vr *Vulkan) createImageView(id *TextureId, aspectFlags vk.ImageAspectFlags
 	viewInfo.SType = vk.StructureTypeImageViewCreateInfo
 	viewInfo.Image = id.Image
 	viewInfo.ViewType = vk.ImageViewType2d
+	// Images are only created with a layer for each side for cube maps
+	if id.LayerCount == CubeMapSides {
+		viewInfo.ViewType = vk.ImageViewTypeCube
+	}
 	viewInfo.Format = id.Format
 	viewInfo.SubresourceRange.AspectMask = aspectFlags
 	viewInfo.SubresourceRange.BaseMipLevel = 0
@@ -299,7 +303,7 @@ func (vr *Vulkan) transitionImageLayout(vt *TextureId, newLayout vk.ImageLayout,
 	return true
 }
 
-func (vr *Vulkan) copyBufferToImage(buffer vk.Buffer, image vk.Image, width, height uint32) {
+func (vr *Vulkan) copyBufferToImage(buffer vk.Buffer, image vk.Image, width, height, layerCount uint32) {
 	commandBuffer := vr.beginSingleTimeCommands()
 	region := vk.BufferImageCopy{}
 	region.BufferOffset = 0
@@ -308,13 +312,34 @@ func (vr *Vulkan) copyBufferToImage(buffer vk.Buffer, image vk.Image, width, hei
 	region.ImageSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
 	region.ImageSubresource.MipLevel = 0
 	region.ImageSubresource.BaseArrayLayer = 0
-	region.ImageSubresource.LayerCount = 1
+	region.ImageSubresource.LayerCount = layerCount
 	region.ImageOffset = vk.Offset3D{X: 0, Y: 0, Z: 0}
 	region.ImageExtent = vk.Extent3D{Width: width, Height: height, Depth: 1}
 	vk.CmdCopyBufferToImage(commandBuffer, buffer, image, vk.ImageLayoutTransferDstOptimal, 1, &region)
 	vr.endSingleTimeCommands(commandBuffer)
 }
 
+// copyBufferToImageMips copies each of the mip levels that are stored one
+// after another in the buffer into the matching mip level of the image
+func (vr *Vulkan) copyBufferToImageMips(buffer vk.Buffer, image vk.Image, data *TextureData, layerCount uint32) {
+	regions := make([]vk.BufferImageCopy, data.Mips)
+	offset := 0
+	w, h := data.Width, data.Height
+	for i := range regions {
+		regions[i].BufferOffset = vk.DeviceSize(offset)
+		regions[i].ImageSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
+		regions[i].ImageSubresource.MipLevel = uint32(i)
+		regions[i].ImageSubresource.LayerCount = layerCount
+		regions[i].ImageExtent = vk.Extent3D{Width: uint32(w), Height: uint32(h), Depth: 1}
+		offset += textureLevelSize(data.InternalFormat, w, h) * int(layerCount)
+		w, h = max(1, w/2), max(1, h/2)
+	}
+	commandBuffer := vr.beginSingleTimeCommands()
+	vk.CmdCopyBufferToImage(commandBuffer, buffer, image,
+		vk.ImageLayoutTransferDstOptimal, uint32(len(regions)), &regions[0])
+	vr.endSingleTimeCommands(commandBuffer)
+}
+
 func (vr *Vulkan) writeBufferToImageRegion(image vk.Image, buffer []byte, x, y, width, height int) {
 	var stagingBuffer vk.Buffer
 	var stagingBufferMemory vk.DeviceMemory
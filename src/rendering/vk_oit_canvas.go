@@ -62,6 +62,7 @@ type OITCanvas struct {
 	ClearColor      matrix.Color
 	colorTexture    Texture
 	depthTexture    Texture
+	size            vk.Extent2D
 }
 
 func (r *OITCanvas) Pass(name string) *RenderPass {
@@ -87,30 +88,56 @@ func (r *OITCanvas) Draw(renderer Renderer, drawings []ShaderDraw) {
 
 	cmd1 := vr.commandBuffers[cmdBuffIdx+vr.commandBuffersCount]
 	vr.commandBuffersCount++
+	beginRender(r.opaquePass, r.extent(vr), cmd1, r.opaqueClear())
+	r.renderOpaque(vr, cmd1, drawings)
+	endRender(cmd1)
+
+	cmd2 := vr.commandBuffers[cmdBuffIdx+vr.commandBuffersCount]
+	vr.commandBuffersCount++
+	beginRender(r.transparentPass, r.extent(vr), cmd2, r.transparentClear())
+	r.renderTransparent(vr, cmd2, drawings)
+	endRender(cmd2)
+}
+
+// extent is the size of the images of the canvas, canvases without a size of
+// their own match the size of the swap chain
+func (r *OITCanvas) extent(vr *Vulkan) vk.Extent2D {
+	if r.size.Width == 0 || r.size.Height == 0 {
+		return vr.swapChainExtent
+	}
+	return r.size
+}
+
+func (r *OITCanvas) opaqueClear() [2]vk.ClearValue {
 	var opaqueClear [2]vk.ClearValue
 	cc := r.ClearColor
 	opaqueClear[0].SetColor(cc[:])
 	opaqueClear[1].SetDepthStencil(1.0, 0.0)
-	beginRender(r.opaquePass, vr.swapChainExtent, cmd1, opaqueClear)
-	for i := range drawings {
-		vr.renderEach(cmd1, drawings[i].shader, drawings[i].instanceGroups)
-	}
-	endRender(cmd1)
+	return opaqueClear
+}
 
-	cmd2 := vr.commandBuffers[cmdBuffIdx+vr.commandBuffersCount]
-	vr.commandBuffersCount++
+func (r *OITCanvas) transparentClear() [2]vk.ClearValue {
 	var transparentClear [2]vk.ClearValue
 	transparentClear[0].SetColor([]float32{0.0, 0.0, 0.0, 0.0})
 	transparentClear[1].SetColor([]float32{1.0, 0.0, 0.0, 0.0})
-	beginRender(r.transparentPass, vr.swapChainExtent, cmd2, transparentClear)
+	return transparentClear
+}
+
+func (r *OITCanvas) renderOpaque(vr *Vulkan, cmd vk.CommandBuffer, drawings []ShaderDraw) {
 	for i := range drawings {
-		vr.renderEachAlpha(cmd2,
+		vr.renderEach(cmd, drawings[i].shader, drawings[i].instanceGroups)
+	}
+}
+
+func (r *OITCanvas) renderTransparent(vr *Vulkan, cmd vk.CommandBuffer, drawings []ShaderDraw) {
+	for i := range drawings {
+		vr.renderEachAlpha(cmd,
 			drawings[i].shader.SubShader("transparent"),
 			drawings[i].TransparentGroups())
 	}
 	offsets := vk.DeviceSize(0)
-	vk.CmdNextSubpass(cmd2, vk.SubpassContentsInline)
-	vk.CmdBindPipeline(cmd2, vk.PipelineBindPointGraphics, r.compositeShader.RenderId.graphicsPipeline)
+	vk.CmdNextSubpass(cmd, vk.SubpassContentsInline)
+	vk.CmdBindPipeline(cmd, vk.PipelineBindPointGraphics, r.compositeShader.RenderId.graphicsPipeline)
 	imageInfos := [...]vk.DescriptorImageInfo{
 		imageInfo(r.weightedColor.View, r.weightedColor.Sampler),
 		imageInfo(r.weightedReveal.View, r.weightedReveal.Sampler),
@@ -123,16 +150,15 @@ func (r *OITCanvas) Draw(renderer Renderer, drawings []ShaderDraw) {
 	vk.UpdateDescriptorSets(vr.device, uint32(len(descriptorWrites)), &descriptorWrites[0], 0, nil)
 	ds := [...]vk.DescriptorSet{r.descriptorSets[vr.currentFrame]}
 	dsOffsets := [...]uint32{0}
-	vk.CmdBindDescriptorSets(cmd2, vk.PipelineBindPointGraphics,
+	vk.CmdBindDescriptorSets(cmd, vk.PipelineBindPointGraphics,
 		r.compositeShader.RenderId.pipelineLayout,
 		0, 1, &ds[0], 0, &dsOffsets[0])
 	mid := &r.compositeQuad.MeshId
 	vb := [...]vk.Buffer{mid.vertexBuffer}
 	vbOffsets := [...]vk.DeviceSize{offsets}
-	vk.CmdBindVertexBuffers(cmd2, 0, 1, &vb[0], &vbOffsets[0])
-	vk.CmdBindIndexBuffer(cmd2, mid.indexBuffer, 0, vk.IndexTypeUint32)
-	vk.CmdDrawIndexed(cmd2, mid.indexCount, 1, 0, 0, 0)
-	endRender(cmd2)
+	vk.CmdBindVertexBuffers(cmd, 0, 1, &vb[0], &vbOffsets[0])
+	vk.CmdBindIndexBuffer(cmd, mid.indexBuffer, 0, vk.IndexTypeUint32)
+	vk.CmdDrawIndexed(cmd, mid.indexCount, 1, 0, 0, 0)
 }
 
 func (r *OITCanvas) Initialize(renderer Renderer, width, height float32) {
@@ -182,8 +208,8 @@ func (r *OITCanvas) createRenderPasses(vr *Vulkan) bool {
 }
 
 func (r *OITCanvas) createSolidImages(vr *Vulkan) bool {
-	w := uint32(vr.swapChainExtent.Width)
-	h := uint32(vr.swapChainExtent.Height)
+	extent := r.extent(vr)
+	w, h := extent.Width, extent.Height
 	samples := vk.SampleCount1Bit
 	//VkSampleCountFlagBits samples = vr.msaaSamples;
 	// Create the solid color image
@@ -215,8 +241,8 @@ func (r *OITCanvas) createSolidImages(vr *Vulkan) bool {
 }
 
 func (r *OITCanvas) createTransparentImages(vr *Vulkan) bool {
-	w := uint32(vr.swapChainExtent.Width)
-	h := uint32(vr.swapChainExtent.Height)
+	extent := r.extent(vr)
+	w, h := extent.Width, extent.Height
 	samples := vk.SampleCount1Bit
 	//VkSampleCountFlagBits samples = vr.msaaSamples;
 	// Create the transparent weighted color image
@@ -603,7 +629,7 @@ func defaultOITPipeline(renderer Renderer, shader *Shader, shaderStages []vk.Pip
 	depthStencil := vk.PipelineDepthStencilStateCreateInfo{
 		SType:                 vk.StructureTypePipelineDepthStencilStateCreateInfo,
 		DepthTestEnable:       vk.True,
-		DepthCompareOp:        vk.CompareOpLess,
+		DepthCompareOp:        shader.DriverData.DepthCompare,
 		DepthBoundsTestEnable: vk.False,
 		StencilTestEnable:     vk.False,
 		//minDepthBounds: 0.0F,
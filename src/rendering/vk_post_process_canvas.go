@@ -0,0 +1,324 @@
+/******************************************************************************/
+/* vk_post_process_canvas.go                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"log/slog"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// postProcessTarget is one of the two color images that the passes of a
+// #PostProcessStack alternate between, each pass samples the image that the
+// pass before it drew into
+type postProcessTarget struct {
+	pass    RenderPass
+	color   TextureId
+	texture Texture
+}
+
+// PostProcessCanvas applies a #PostProcessStack to the combined image of the
+// frame before it is blitted to the screen. Each enabled pass of the stack is
+// drawn as a fullscreen quad into the target that the next pass will sample.
+type PostProcessCanvas struct {
+	targets [2]postProcessTarget
+	passes  []*Drawings
+	stack   *PostProcessStack
+	input   *Texture
+	version uint32
+	output  int
+}
+
+func (r *PostProcessCanvas) Pass(name string) *RenderPass { return &r.targets[0].pass }
+
+// Color returns the image that the last pass of the stack drew into
+func (r *PostProcessCanvas) Color() *Texture { return &r.targets[r.output].texture }
+
+func (r *PostProcessCanvas) ShaderPipeline(name string) FuncPipeline {
+	return defaultCombinePipeline
+}
+
+func (r *PostProcessCanvas) isOffscreen() {}
+
+// Draw draws each of the shader draws as a pass, in order, alternating
+// between the targets of the canvas
+func (r *PostProcessCanvas) Draw(renderer Renderer, drawings []ShaderDraw) {
+	if len(drawings) == 0 {
+		return
+	}
+	vr := renderer.(*Vulkan)
+	for i := range drawings {
+		vr.writeDrawingDescriptors(drawings[i].shader, drawings[i].instanceGroups)
+	}
+	cmd := vr.commandBuffers[vr.currentFrame*MaxCommandBuffers+vr.commandBuffersCount]
+	vr.commandBuffersCount++
+	beginInfo := vk.CommandBufferBeginInfo{SType: vk.StructureTypeCommandBufferBeginInfo}
+	if vk.BeginCommandBuffer(cmd, &beginInfo) != vk.Success {
+		slog.Error("Failed to begin recording command buffer")
+		return
+	}
+	var input TextureId
+	if r.input != nil {
+		input = r.input.RenderId
+		vr.transitionImageLayout(&input, vk.ImageLayoutShaderReadOnlyOptimal,
+			vk.ImageAspectFlags(vk.ImageAspectColorBit), vk.AccessFlags(vk.AccessShaderReadBit), cmd)
+	}
+	for i := range drawings {
+		r.output = i % len(r.targets)
+		r.recordPass(vr, cmd, &drawings[i])
+	}
+	if r.input != nil {
+		vr.transitionImageLayout(&input, vk.ImageLayoutColorAttachmentOptimal,
+			vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessColorAttachmentReadBit|vk.AccessColorAttachmentWriteBit), cmd)
+	}
+	vk.EndCommandBuffer(cmd)
+}
+
+func (r *PostProcessCanvas) recordPass(vr *Vulkan, cmd vk.CommandBuffer, draw *ShaderDraw) {
+	target := &r.targets[r.output]
+	extent := vk.Extent2D{
+		Width:  uint32(target.color.Width),
+		Height: uint32(target.color.Height),
+	}
+	var clear [1]vk.ClearValue
+	clear[0].SetColor([]float32{0, 0, 0, 1})
+	renderPassInfo := vk.RenderPassBeginInfo{
+		SType:           vk.StructureTypeRenderPassBeginInfo,
+		RenderPass:      target.pass.Handle,
+		Framebuffer:     target.pass.Buffer,
+		ClearValueCount: uint32(len(clear)),
+		PClearValues:    &clear[0],
+	}
+	renderPassInfo.RenderArea.Extent = extent
+	vk.CmdBeginRenderPass(cmd, &renderPassInfo, vk.SubpassContentsInline)
+	viewport := vk.Viewport{
+		Width:    float32(extent.Width),
+		Height:   float32(extent.Height),
+		MinDepth: 0.0,
+		MaxDepth: 1.0,
+	}
+	vk.CmdSetViewport(cmd, 0, 1, &viewport)
+	scissor := vk.Rect2D{Extent: extent}
+	vk.CmdSetScissor(cmd, 0, 1, &scissor)
+	vr.renderEach(cmd, draw.shader, draw.instanceGroups)
+	vk.CmdEndRenderPass(cmd)
+}
+
+// apply draws the enabled passes of the stack using the color of the canvas
+// as the input of the first pass. The canvas holding the final image is
+// returned, which is the input canvas when there is nothing to apply.
+func (r *PostProcessCanvas) apply(vr *Vulkan, stack *PostProcessStack, canvas Canvas) Canvas {
+	if stack == nil {
+		r.destroyPasses(vr)
+		return canvas
+	}
+	if stack != r.stack || stack.Version() != r.version || canvas.Color() != r.input {
+		r.preparePasses(vr, stack, canvas.Color())
+	}
+	if len(r.passes) == 0 {
+		return canvas
+	}
+	draws := make([]ShaderDraw, 0, len(r.passes))
+	for i := range r.passes {
+		if len(r.passes[i].draws) > 0 && len(r.passes[i].draws[0].innerDraws) > 0 {
+			draws = append(draws, r.passes[i].draws[0].innerDraws[0])
+		}
+	}
+	r.Draw(vr, draws)
+	return r
+}
+
+// preparePasses creates a fullscreen drawing for each of the enabled passes
+// of the stack, the first pass samples the input and every pass after samples
+// the target of the pass before it
+func (r *PostProcessCanvas) preparePasses(vr *Vulkan, stack *PostProcessStack, input *Texture) {
+	r.destroyPasses(vr)
+	r.stack = stack
+	r.version = stack.Version()
+	r.input = input
+	shaderCache := vr.caches.ShaderCache()
+	textureCache := vr.caches.TextureCache()
+	mesh := NewMeshQuad(vr.caches.MeshCache())
+	vr.caches.MeshCache().CreatePending()
+	enabled := stack.Enabled()
+	source := input
+	for i, pass := range enabled {
+		textures := []*Texture{source}
+		for j := range pass.Textures {
+			filter, err := pass.Textures[j].TextureFilter()
+			if err != nil {
+				slog.Error(err.Error(), slog.String("pass", pass.Name))
+			}
+			tex, err := textureCache.Texture(pass.Textures[j].Texture, filter)
+			if err != nil {
+				slog.Error(err.Error(), slog.String("pass", pass.Name))
+				tex = vr.defaultTexture
+			}
+			textures = append(textures, tex)
+		}
+		pass.Data.CancelDestroy()
+		d := new(Drawings)
+		*d = NewDrawings()
+		d.AddDrawing(&Drawing{
+			Renderer:   vr,
+			Shader:     shaderCache.ShaderFromDefinition(pass.Shader),
+			Mesh:       mesh,
+			Textures:   textures,
+			ShaderData: &pass.Data,
+			CanvasId:   "postprocess",
+		})
+		r.passes = append(r.passes, d)
+		source = &r.targets[i%len(r.targets)].texture
+	}
+	shaderCache.CreatePending()
+	textureCache.CreatePending()
+	for i := range r.passes {
+		r.passes[i].PreparePending()
+	}
+}
+
+func (r *PostProcessCanvas) destroyPasses(vr *Vulkan) {
+	for i := range r.passes {
+		r.passes[i].Destroy(vr)
+	}
+	r.passes = r.passes[:0]
+	r.stack = nil
+	r.input = nil
+	r.output = 0
+}
+
+func (r *PostProcessCanvas) Create(renderer Renderer) error {
+	vr := renderer.(*Vulkan)
+	for i := range r.targets {
+		if !r.createImage(vr, &r.targets[i]) {
+			return errors.New("failed to create the post process images")
+		}
+		if !r.createRenderPass(vr, &r.targets[i]) {
+			return errors.New("failed to create the post process render pass")
+		}
+		r.targets[i].texture.RenderId = r.targets[i].color
+	}
+	return nil
+}
+
+func (r *PostProcessCanvas) Destroy(renderer Renderer) {
+	vr := renderer.(*Vulkan)
+	vk.DeviceWaitIdle(vr.device)
+	for i := range r.targets {
+		r.targets[i].pass.Destroy()
+		vr.textureIdFree(&r.targets[i].color)
+		r.targets[i].color = TextureId{}
+		r.targets[i].texture.RenderId = TextureId{}
+	}
+}
+
+func (r *PostProcessCanvas) createImage(vr *Vulkan, target *postProcessTarget) bool {
+	w := uint32(vr.swapChainExtent.Width)
+	h := uint32(vr.swapChainExtent.Height)
+	imagesCreated := vr.CreateImage(w, h, 1, vk.SampleCount1Bit,
+		vk.FormatB8g8r8a8Unorm, vk.ImageTilingOptimal,
+		vk.ImageUsageFlags(vk.ImageUsageColorAttachmentBit|vk.ImageUsageTransferSrcBit|vk.ImageUsageSampledBit),
+		vk.MemoryPropertyFlags(vk.MemoryPropertyDeviceLocalBit), &target.color, 1)
+	imagesCreated = imagesCreated && vr.createImageView(&target.color,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit))
+	vr.createTextureSampler(&target.color.Sampler, 1, vk.FilterLinear)
+	if imagesCreated {
+		vr.transitionImageLayout(&target.color,
+			vk.ImageLayoutShaderReadOnlyOptimal, vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessShaderReadBit), vk.NullCommandBuffer)
+	}
+	return imagesCreated
+}
+
+func (r *PostProcessCanvas) createRenderPass(vr *Vulkan, target *postProcessTarget) bool {
+	attachment := vk.AttachmentDescription{
+		Format:         target.color.Format,
+		Samples:        vk.SampleCount1Bit,
+		LoadOp:         vk.AttachmentLoadOpClear,
+		StoreOp:        vk.AttachmentStoreOpStore,
+		StencilLoadOp:  vk.AttachmentLoadOpDontCare,
+		StencilStoreOp: vk.AttachmentStoreOpDontCare,
+		InitialLayout:  vk.ImageLayoutUndefined,
+		FinalLayout:    vk.ImageLayoutShaderReadOnlyOptimal,
+	}
+	colorAttachmentRef := vk.AttachmentReference{
+		Attachment: 0,
+		Layout:     vk.ImageLayoutColorAttachmentOptimal,
+	}
+	subpass := vk.SubpassDescription{
+		PipelineBindPoint:    vk.PipelineBindPointGraphics,
+		ColorAttachmentCount: 1,
+		PColorAttachments:    &colorAttachmentRef,
+	}
+	dependencies := []vk.SubpassDependency{
+		{
+			SrcSubpass:      vk.SubpassExternal,
+			DstSubpass:      0,
+			SrcStageMask:    vk.PipelineStageFlags(vk.PipelineStageFragmentShaderBit),
+			DstStageMask:    vk.PipelineStageFlags(vk.PipelineStageColorAttachmentOutputBit),
+			SrcAccessMask:   vk.AccessFlags(vk.AccessShaderReadBit),
+			DstAccessMask:   vk.AccessFlags(vk.AccessColorAttachmentWriteBit),
+			DependencyFlags: vk.DependencyFlags(vk.DependencyByRegionBit),
+		},
+		{
+			SrcSubpass:      0,
+			DstSubpass:      vk.SubpassExternal,
+			SrcStageMask:    vk.PipelineStageFlags(vk.PipelineStageColorAttachmentOutputBit),
+			DstStageMask:    vk.PipelineStageFlags(vk.PipelineStageFragmentShaderBit | vk.PipelineStageTransferBit),
+			SrcAccessMask:   vk.AccessFlags(vk.AccessColorAttachmentWriteBit),
+			DstAccessMask:   vk.AccessFlags(vk.AccessShaderReadBit | vk.AccessTransferReadBit),
+			DependencyFlags: vk.DependencyFlags(vk.DependencyByRegionBit),
+		},
+	}
+	pass, err := NewRenderPass(vr.device, &vr.dbg, []vk.AttachmentDescription{attachment},
+		[]vk.SubpassDescription{subpass}, dependencies)
+	if err != nil {
+		slog.Error("Failed to create the post process render pass")
+		return false
+	}
+	target.pass = pass
+	err = target.pass.CreateFrameBuffer(vr,
+		[]vk.ImageView{target.color.View}, target.color.Width, target.color.Height)
+	if err != nil {
+		slog.Error("Failed to create the post process frame buffer")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,352 @@
+/******************************************************************************/
+/* vk_shadow_canvas.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/cameras"
+	"log/slog"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// ShadowCanvas renders the depth of the shadow casters from the point of
+// view of the lights into a single atlas. Each cascade of a directional light
+// and each spot light is a tile of the atlas, the tile is selected in the
+// shadow vertex shader through a push constant. Shaders sample the atlas by
+// naming this canvas in a layout of their definition.
+type ShadowCanvas struct {
+	Maps         ShadowMaps
+	pass         RenderPass
+	depth        TextureId
+	depthTexture Texture
+	resolution   int32
+}
+
+func (r *ShadowCanvas) Pass(name string) *RenderPass { return &r.pass }
+
+// Color returns the depth atlas so that it can be sampled by other shaders
+func (r *ShadowCanvas) Color() *Texture { return &r.depthTexture }
+
+func (r *ShadowCanvas) ShaderPipeline(name string) FuncPipeline {
+	return defaultShadowPipeline
+}
+
+// Settings returns the current shadow settings
+func (r *ShadowCanvas) Settings() ShadowSettings { return r.Maps.Settings }
+
+// SetSettings changes the shadow settings, the atlas is recreated if the
+// resolution of the shadow maps has changed
+func (r *ShadowCanvas) SetSettings(renderer Renderer, settings ShadowSettings) error {
+	r.Maps.Settings = settings
+	if settings.Resolution == r.resolution {
+		return nil
+	}
+	r.Destroy(renderer)
+	return r.Create(renderer)
+}
+
+func (r *ShadowCanvas) isOffscreen() {}
+
+func (r *ShadowCanvas) update(camera cameras.Camera, lights *LightList, data *GlobalShaderData) {
+	r.Maps.Update(camera, lights)
+	r.Maps.shaderData(data)
+}
+
+func (r *ShadowCanvas) atlasExtent() vk.Extent2D {
+	return vk.Extent2D{
+		Width:  uint32(r.resolution * ShadowAtlasColumns),
+		Height: uint32(r.resolution * ShadowAtlasRows),
+	}
+}
+
+func (r *ShadowCanvas) Draw(renderer Renderer, drawings []ShaderDraw) {
+	vr := renderer.(*Vulkan)
+	frame := vr.currentFrame
+	cmdBuffIdx := frame * MaxCommandBuffers
+	for i := range drawings {
+		vr.writeDrawingDescriptors(drawings[i].shader, drawings[i].instanceGroups)
+	}
+	cmd := vr.commandBuffers[cmdBuffIdx+vr.commandBuffersCount]
+	vr.commandBuffersCount++
+	var clear [2]vk.ClearValue
+	clear[0].SetDepthStencil(1.0, 0.0)
+	beginRender(r.pass, r.atlasExtent(), cmd, clear)
+	for i := 0; i < r.Maps.Count(); i++ {
+		x, y := ShadowAtlasViewport(i, r.resolution)
+		viewport := vk.Viewport{
+			X:        float32(x),
+			Y:        float32(y),
+			Width:    float32(r.resolution),
+			Height:   float32(r.resolution),
+			MinDepth: 0.0,
+			MaxDepth: 1.0,
+		}
+		vk.CmdSetViewport(cmd, 0, 1, &viewport)
+		scissor := vk.Rect2D{
+			Offset: vk.Offset2D{X: x, Y: y},
+			Extent: vk.Extent2D{Width: uint32(r.resolution), Height: uint32(r.resolution)},
+		}
+		vk.CmdSetScissor(cmd, 0, 1, &scissor)
+		index := int32(i)
+		for j := range drawings {
+			shader := drawings[j].shader
+			if shader == nil {
+				continue
+			}
+			vk.CmdPushConstants(cmd, shader.RenderId.pipelineLayout,
+				vk.ShaderStageFlags(vk.ShaderStageVertexBit), 0,
+				uint32(unsafe.Sizeof(index)), unsafe.Pointer(&index))
+			vr.renderEach(cmd, shader, drawings[j].instanceGroups)
+		}
+	}
+	endRender(cmd)
+}
+
+func (r *ShadowCanvas) Create(renderer Renderer) error {
+	vr := renderer.(*Vulkan)
+	r.resolution = max(r.Maps.Settings.Resolution, 1)
+	r.Maps.Settings.Resolution = r.resolution
+	if !r.createImage(vr) {
+		return errors.New("failed to create the shadow atlas image")
+	}
+	if !r.createRenderPass(vr) {
+		return errors.New("failed to create the shadow render pass")
+	}
+	r.depthTexture.RenderId = r.depth
+	return nil
+}
+
+func (r *ShadowCanvas) Destroy(renderer Renderer) {
+	vr := renderer.(*Vulkan)
+	vk.DeviceWaitIdle(vr.device)
+	r.pass.Destroy()
+	vr.textureIdFree(&r.depth)
+	r.depth = TextureId{}
+	r.depthTexture.RenderId = TextureId{}
+	r.resolution = 0
+}
+
+func (r *ShadowCanvas) createImage(vr *Vulkan) bool {
+	extent := r.atlasExtent()
+	imagesCreated := vr.CreateImage(extent.Width, extent.Height, 1,
+		vk.SampleCount1Bit, vk.FormatD32Sfloat, vk.ImageTilingOptimal,
+		vk.ImageUsageFlags(vk.ImageUsageDepthStencilAttachmentBit|vk.ImageUsageSampledBit),
+		vk.MemoryPropertyFlags(vk.MemoryPropertyDeviceLocalBit), &r.depth, 1)
+	imagesCreated = imagesCreated && vr.createImageView(&r.depth,
+		vk.ImageAspectFlags(vk.ImageAspectDepthBit))
+	vr.createTextureSampler(&r.depth.Sampler, 1, vk.FilterNearest)
+	if imagesCreated {
+		// The atlas is sampled before anything has been drawn into it when
+		// there are no shadow casters
+		vr.transitionImageLayout(&r.depth,
+			vk.ImageLayoutShaderReadOnlyOptimal, vk.ImageAspectFlags(vk.ImageAspectDepthBit),
+			vk.AccessFlags(vk.AccessShaderReadBit), vk.NullCommandBuffer)
+	}
+	return imagesCreated
+}
+
+func (r *ShadowCanvas) createRenderPass(vr *Vulkan) bool {
+	attachment := vk.AttachmentDescription{
+		Format:         r.depth.Format,
+		Samples:        vk.SampleCount1Bit,
+		LoadOp:         vk.AttachmentLoadOpClear,
+		StoreOp:        vk.AttachmentStoreOpStore,
+		StencilLoadOp:  vk.AttachmentLoadOpDontCare,
+		StencilStoreOp: vk.AttachmentStoreOpDontCare,
+		InitialLayout:  vk.ImageLayoutUndefined,
+		FinalLayout:    vk.ImageLayoutShaderReadOnlyOptimal,
+	}
+	depthAttachmentRef := vk.AttachmentReference{
+		Attachment: 0,
+		Layout:     vk.ImageLayoutDepthStencilAttachmentOptimal,
+	}
+	subpass := vk.SubpassDescription{
+		PipelineBindPoint:       vk.PipelineBindPointGraphics,
+		PDepthStencilAttachment: &depthAttachmentRef,
+	}
+	dependencies := []vk.SubpassDependency{
+		{
+			SrcSubpass:      vk.SubpassExternal,
+			DstSubpass:      0,
+			SrcStageMask:    vk.PipelineStageFlags(vk.PipelineStageFragmentShaderBit),
+			DstStageMask:    vk.PipelineStageFlags(vk.PipelineStageEarlyFragmentTestsBit),
+			SrcAccessMask:   vk.AccessFlags(vk.AccessShaderReadBit),
+			DstAccessMask:   vk.AccessFlags(vk.AccessDepthStencilAttachmentWriteBit),
+			DependencyFlags: vk.DependencyFlags(vk.DependencyByRegionBit),
+		},
+		{
+			SrcSubpass:      0,
+			DstSubpass:      vk.SubpassExternal,
+			SrcStageMask:    vk.PipelineStageFlags(vk.PipelineStageLateFragmentTestsBit),
+			DstStageMask:    vk.PipelineStageFlags(vk.PipelineStageFragmentShaderBit),
+			SrcAccessMask:   vk.AccessFlags(vk.AccessDepthStencilAttachmentWriteBit),
+			DstAccessMask:   vk.AccessFlags(vk.AccessShaderReadBit),
+			DependencyFlags: vk.DependencyFlags(vk.DependencyByRegionBit),
+		},
+	}
+	pass, err := NewRenderPass(vr.device, &vr.dbg, []vk.AttachmentDescription{attachment},
+		[]vk.SubpassDescription{subpass}, dependencies)
+	if err != nil {
+		slog.Error("Failed to create the shadow render pass")
+		return false
+	}
+	r.pass = pass
+	extent := r.atlasExtent()
+	err = r.pass.CreateFrameBuffer(vr,
+		[]vk.ImageView{r.depth.View}, int(extent.Width), int(extent.Height))
+	if err != nil {
+		slog.Error("Failed to create the shadow frame buffer")
+		return false
+	}
+	return true
+}
+
+func defaultShadowPipeline(renderer Renderer, shader *Shader, shaderStages []vk.PipelineShaderStageCreateInfo) bool {
+	vr := renderer.(*Vulkan)
+	viewportState := vk.PipelineViewportStateCreateInfo{
+		SType:         vk.StructureTypePipelineViewportStateCreateInfo,
+		ViewportCount: 1,
+		ScissorCount:  1,
+	}
+	dynamicStates := [...]vk.DynamicState{
+		vk.DynamicStateViewport,
+		vk.DynamicStateScissor,
+	}
+	dynamicState := vk.PipelineDynamicStateCreateInfo{
+		SType:             vk.StructureTypePipelineDynamicStateCreateInfo,
+		DynamicStateCount: uint32(len(dynamicStates)),
+		PDynamicStates:    &dynamicStates[0],
+	}
+	bDesc := vertexGetBindingDescription(shader)
+	bDescCount := uint32(len(bDesc))
+	for i := uint32(1); i < bDescCount; i++ {
+		bDesc[i].Stride = uint32(vr.padUniformBufferSize(vk.DeviceSize(bDesc[i].Stride)))
+	}
+	aDesc := vertexGetAttributeDescription(shader)
+	vertexInputInfo := vk.PipelineVertexInputStateCreateInfo{
+		SType:                           vk.StructureTypePipelineVertexInputStateCreateInfo,
+		VertexBindingDescriptionCount:   bDescCount,
+		VertexAttributeDescriptionCount: uint32(len(aDesc)),
+		PVertexBindingDescriptions:      &bDesc[0],
+		PVertexAttributeDescriptions:    &aDesc[0],
+	}
+	inputAssembly := vk.PipelineInputAssemblyStateCreateInfo{
+		SType:                  vk.StructureTypePipelineInputAssemblyStateCreateInfo,
+		PrimitiveRestartEnable: vk.False,
+		Topology:               vk.PrimitiveTopologyTriangleList,
+	}
+	rasterizer := vk.PipelineRasterizationStateCreateInfo{
+		SType:                   vk.StructureTypePipelineRasterizationStateCreateInfo,
+		DepthClampEnable:        vk.False,
+		RasterizerDiscardEnable: vk.False,
+		PolygonMode:             vk.PolygonModeFill,
+		LineWidth:               1.0,
+		CullMode:                vk.CullModeFlags(vk.CullModeNone),
+		FrontFace:               vk.FrontFaceClockwise,
+	}
+	multisampling := vk.PipelineMultisampleStateCreateInfo{
+		SType:                vk.StructureTypePipelineMultisampleStateCreateInfo,
+		SampleShadingEnable:  vk.False,
+		RasterizationSamples: vk.SampleCount1Bit,
+		MinSampleShading:     1.0,
+	}
+	colorBlending := vk.PipelineColorBlendStateCreateInfo{
+		SType:           vk.StructureTypePipelineColorBlendStateCreateInfo,
+		LogicOpEnable:   vk.False,
+		LogicOp:         vk.LogicOpCopy,
+		AttachmentCount: 0,
+	}
+	depthStencil := vk.PipelineDepthStencilStateCreateInfo{
+		SType:                 vk.StructureTypePipelineDepthStencilStateCreateInfo,
+		DepthTestEnable:       vk.True,
+		DepthWriteEnable:      vk.True,
+		DepthCompareOp:        vk.CompareOpLess,
+		DepthBoundsTestEnable: vk.False,
+		StencilTestEnable:     vk.False,
+	}
+	pushConstant := vk.PushConstantRange{
+		StageFlags: vk.ShaderStageFlags(vk.ShaderStageVertexBit),
+		Offset:     0,
+		Size:       uint32(unsafe.Sizeof(int32(0))),
+	}
+	layoutInfo := vk.PipelineLayoutCreateInfo{
+		SType:                  vk.StructureTypePipelineLayoutCreateInfo,
+		SetLayoutCount:         1,
+		PSetLayouts:            &shader.RenderId.descriptorSetLayout,
+		PushConstantRangeCount: 1,
+		PPushConstantRanges:    &pushConstant,
+	}
+	var layout vk.PipelineLayout
+	if vk.CreatePipelineLayout(vr.device, &layoutInfo, nil, &layout) != vk.Success {
+		slog.Error("Failed to create pipeline layout")
+		return false
+	} else {
+		vr.dbg.add(vk.TypeToUintPtr(layout))
+	}
+	shader.RenderId.pipelineLayout = layout
+	pipelineInfo := vk.GraphicsPipelineCreateInfo{
+		SType:               vk.StructureTypeGraphicsPipelineCreateInfo,
+		StageCount:          uint32(len(shaderStages)),
+		PStages:             &shaderStages[0],
+		PVertexInputState:   &vertexInputInfo,
+		PInputAssemblyState: &inputAssembly,
+		PViewportState:      &viewportState,
+		PRasterizationState: &rasterizer,
+		PMultisampleState:   &multisampling,
+		PDepthStencilState:  &depthStencil,
+		PColorBlendState:    &colorBlending,
+		PDynamicState:       &dynamicState,
+		Layout:              layout,
+		RenderPass:          shader.RenderPass.Handle,
+		Subpass:             0,
+		BasePipelineHandle:  vk.Pipeline(vk.NullHandle),
+		BasePipelineIndex:   -1,
+	}
+	success := true
+	pipelines := [1]vk.Pipeline{}
+	if vk.CreateGraphicsPipelines(vr.device, vk.PipelineCache(vk.NullHandle), 1, &pipelineInfo, nil, &pipelines[0]) != vk.Success {
+		success = false
+		slog.Error("Failed to create graphics pipeline")
+	} else {
+		vr.dbg.add(vk.TypeToUintPtr(pipelines[0]))
+	}
+	shader.RenderId.graphicsPipeline = pipelines[0]
+	return success
+}
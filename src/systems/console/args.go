@@ -0,0 +1,315 @@
+/******************************************************************************/
+/* args.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ArgType is the type that an argument string will be converted into before
+// being passed to a typed console command
+type ArgType int
+
+const (
+	ArgTypeString ArgType = iota
+	ArgTypeInt
+	ArgTypeFloat
+	ArgTypeBool
+)
+
+// Arg describes a single argument for a typed console command. Arguments are
+// positional unless they are marked as named, named arguments are supplied as
+// --name=value or --name value (bool named arguments can be supplied as just
+// --name). If Choices is not empty, the value must be one of the choices, the
+// choices are also used for tab completion.
+type Arg struct {
+	Name        string
+	Description string
+	Type        ArgType
+	Default     string
+	Choices     []string
+	Optional    bool
+	Named       bool
+	Complete    func(prefix string) []string
+}
+
+// Args are the parsed and validated arguments that are passed to a typed
+// console command. Any argument that was not supplied and has no default will
+// return the zero value for its type.
+type Args struct {
+	values map[string]any
+}
+
+func (t ArgType) String() string {
+	switch t {
+	case ArgTypeInt:
+		return "int"
+	case ArgTypeFloat:
+		return "float"
+	case ArgTypeBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// Has returns true if the argument was supplied or has a default value
+func (a Args) Has(name string) bool { _, ok := a.values[name]; return ok }
+
+// String returns the value of the argument as a string
+func (a Args) String(name string) string { s, _ := a.values[name].(string); return s }
+
+// Int returns the value of the argument as an int
+func (a Args) Int(name string) int { i, _ := a.values[name].(int); return i }
+
+// Float returns the value of the argument as a float64
+func (a Args) Float(name string) float64 { f, _ := a.values[name].(float64); return f }
+
+// Bool returns the value of the argument as a bool
+func (a Args) Bool(name string) bool { b, _ := a.values[name].(bool); return b }
+
+func (a *Arg) convert(value string) (any, error) {
+	if len(a.Choices) > 0 && !slices.Contains(a.Choices, value) {
+		return nil, fmt.Errorf("%w: %s must be one of [%s]", ErrInvalidArgument,
+			a.Name, strings.Join(a.Choices, ", "))
+	}
+	switch a.Type {
+	case ArgTypeInt:
+		if i, err := strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%w: %s expects an int but got %q",
+				ErrInvalidArgument, a.Name, value)
+		} else {
+			return i, nil
+		}
+	case ArgTypeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("%w: %s expects a float but got %q",
+				ErrInvalidArgument, a.Name, value)
+		} else {
+			return f, nil
+		}
+	case ArgTypeBool:
+		if b, err := strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%w: %s expects a bool but got %q",
+				ErrInvalidArgument, a.Name, value)
+		} else {
+			return b, nil
+		}
+	default:
+		return value, nil
+	}
+}
+
+func (a *Arg) usage() string {
+	var s string
+	if a.Named {
+		s = "--" + a.Name
+		if a.Type != ArgTypeBool {
+			s += "=<" + a.Type.String() + ">"
+		}
+	} else {
+		s = "<" + a.Name + ":" + a.Type.String() + ">"
+	}
+	if a.Optional || a.Named || a.Default != "" {
+		s = "[" + s + "]"
+	}
+	return s
+}
+
+func parseArgs(defs []Arg, tokens []string) (Args, error) {
+	args := Args{values: make(map[string]any, len(defs))}
+	positional := make([]*Arg, 0, len(defs))
+	for i := range defs {
+		if !defs[i].Named {
+			positional = append(positional, &defs[i])
+		}
+	}
+	findNamed := func(name string) *Arg {
+		for i := range defs {
+			if defs[i].Named && strings.EqualFold(defs[i].Name, name) {
+				return &defs[i]
+			}
+		}
+		return nil
+	}
+	nextPositional := 0
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if strings.HasPrefix(tok, "--") && len(tok) > 2 {
+			name, value, hasValue := strings.Cut(tok[2:], "=")
+			def := findNamed(name)
+			if def == nil {
+				return args, fmt.Errorf("%w: unknown argument --%s",
+					ErrInvalidArgument, name)
+			}
+			if !hasValue {
+				if def.Type == ArgTypeBool {
+					value = "true"
+				} else if i+1 < len(tokens) {
+					i++
+					value = tokens[i]
+				} else {
+					return args, fmt.Errorf("%w: --%s expects a value",
+						ErrInvalidArgument, name)
+				}
+			}
+			v, err := def.convert(value)
+			if err != nil {
+				return args, err
+			}
+			args.values[def.Name] = v
+			continue
+		}
+		if nextPositional >= len(positional) {
+			return args, fmt.Errorf("%w: unexpected argument %q",
+				ErrInvalidArgument, tok)
+		}
+		def := positional[nextPositional]
+		nextPositional++
+		v, err := def.convert(tok)
+		if err != nil {
+			return args, err
+		}
+		args.values[def.Name] = v
+	}
+	for i := range defs {
+		def := &defs[i]
+		if _, ok := args.values[def.Name]; ok {
+			continue
+		}
+		if def.Default != "" {
+			v, err := def.convert(def.Default)
+			if err != nil {
+				return args, err
+			}
+			args.values[def.Name] = v
+		} else if !def.Optional && !def.Named {
+			return args, fmt.Errorf("%w: missing required argument %s",
+				ErrInvalidArgument, def.Name)
+		}
+	}
+	return args, nil
+}
+
+// quotedEscape reports if a backslash inside of quotes escapes the rune that
+// follows it. Only quotes, semicolons and backslashes are escaped so that paths
+// like C:\dir\file.txt keep their backslashes.
+func quotedEscape(r rune) bool {
+	return r == '"' || r == '\'' || r == ';' || r == '\\'
+}
+
+// tokenize splits a command line into its tokens. Tokens are separated by
+// whitespace, quotes (single or double) can be used to keep whitespace inside
+// of a single token. Inside of quotes a backslash will escape a following
+// quote, semicolon or backslash, every other backslash is kept as written.
+func tokenize(line string) ([]string, error) {
+	tokens := make([]string, 0)
+	sb := strings.Builder{}
+	var quote rune
+	inToken := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == '\\' && i+1 < len(runes) && quotedEscape(runes[i+1]) {
+				i++
+				sb.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, sb.String())
+				sb.Reset()
+				inToken = false
+			}
+		default:
+			sb.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return tokens, ErrUnterminatedQuote
+	}
+	if inToken {
+		tokens = append(tokens, sb.String())
+	}
+	return tokens, nil
+}
+
+// splitChain splits a line into multiple commands on each semicolon that is
+// not quoted
+func splitChain(line string) []string {
+	cmds := make([]string, 0, 1)
+	var quote rune
+	start := 0
+	// The quotes, escapes and semicolons are all single bytes so the line
+	// can be walked by byte
+	for i := 0; i < len(line); i++ {
+		switch r := rune(line[i]); {
+		case quote != 0:
+			if r == '\\' && i+1 < len(line) && quotedEscape(rune(line[i+1])) {
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';':
+			cmds = append(cmds, line[start:i])
+			start = i + 1
+		}
+	}
+	cmds = append(cmds, line[start:])
+	out := cmds[:0]
+	for _, c := range cmds {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
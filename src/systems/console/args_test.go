@@ -0,0 +1,119 @@
+/******************************************************************************/
+/* args_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize(`spawn "big box" 'a b' "say \"hi\"" C:\dir\a.txt "C:\dir\b.txt"  --x=1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"spawn", "big box", "a b", `say "hi"`,
+		`C:\dir\a.txt`, `C:\dir\b.txt`, "--x=1"}
+	if !slices.Equal(tokens, expected) {
+		t.Errorf("expected %v but got %v", expected, tokens)
+	}
+	if _, err := tokenize(`say "hello`); !errors.Is(err, ErrUnterminatedQuote) {
+		t.Error("expected an unterminated quote error")
+	}
+}
+
+func TestSplitChain(t *testing.T) {
+	cmds := splitChain(`clear; say "a;\"b;" ;; exec C:\dir\boot.txt; help`)
+	expected := []string{"clear", `say "a;\"b;"`, `exec C:\dir\boot.txt`, "help"}
+	if !slices.Equal(cmds, expected) {
+		t.Errorf("expected %v but got %v", expected, cmds)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	defs := []Arg{
+		{Name: "name"},
+		{Name: "count", Type: ArgTypeInt, Default: "1"},
+		{Name: "scale", Type: ArgTypeFloat, Named: true},
+		{Name: "visible", Type: ArgTypeBool, Named: true},
+		{Name: "mode", Named: true, Choices: []string{"fast", "slow"}},
+	}
+	args, err := parseArgs(defs, []string{"box", "--scale", "2.5", "--visible", "--mode=slow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.String("name") != "box" || args.Int("count") != 1 ||
+		args.Float("scale") != 2.5 || !args.Bool("visible") ||
+		args.String("mode") != "slow" {
+		t.Errorf("unexpected arguments parsed %v", args.values)
+	}
+	failures := [][]string{
+		{},
+		{"box", "two"},
+		{"box", "1", "extra"},
+		{"box", "--mode=medium"},
+		{"box", "--unknown"},
+		{"box", "--scale"},
+	}
+	for _, f := range failures {
+		if _, err := parseArgs(defs, f); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("expected %v to fail to parse", f)
+		}
+	}
+}
+
+func TestCompleteArg(t *testing.T) {
+	defs := []Arg{
+		{Name: "mode", Choices: []string{"fast", "faster", "slow"}},
+		{Name: "enabled", Type: ArgTypeBool},
+		{Name: "verbose", Type: ArgTypeBool, Named: true},
+	}
+	if got := completeArg(defs, []string{}, "fa"); !slices.Equal(got, []string{"fast", "faster"}) {
+		t.Errorf("unexpected completion %v", got)
+	}
+	if got := completeArg(defs, []string{"slow"}, ""); !slices.Equal(got, []string{"false", "true"}) {
+		t.Errorf("unexpected completion %v", got)
+	}
+	if got := completeArg(defs, []string{"slow"}, "--v"); !slices.Equal(got, []string{"--verbose"}) {
+		t.Errorf("unexpected completion %v", got)
+	}
+	if got := commonPrefix([]string{"fast", "faster"}); got != "fast" {
+		t.Errorf("unexpected common prefix %s", got)
+	}
+}
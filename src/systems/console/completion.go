@@ -0,0 +1,170 @@
+/******************************************************************************/
+/* completion.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"slices"
+	"strings"
+)
+
+// Complete returns the possible completions for the last word of the command
+// line. The first word of a command will complete to command names, the rest
+// of the words will complete using the argument definitions of typed commands.
+func (c *Console) Complete(line string) []string {
+	chain := splitChain(line)
+	if len(chain) == 0 || strings.HasSuffix(strings.TrimRight(line, " \t"), ";") {
+		return c.completeCommandName("")
+	}
+	segment := chain[len(chain)-1]
+	tokens, _ := tokenize(segment)
+	prefix := ""
+	if !strings.HasSuffix(line, " ") && len(tokens) > 0 {
+		prefix = tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return c.completeCommandName(prefix)
+	}
+	cmd, ok := c.commands[strings.ToLower(tokens[0])]
+	if !ok || cmd.typedFn == nil {
+		return []string{}
+	}
+	return completeArg(cmd.args, tokens[1:], prefix)
+}
+
+func (c *Console) completeCommandName(prefix string) []string {
+	return filterPrefix(c.CommandNames(), prefix)
+}
+
+func completeArg(defs []Arg, tokens []string, prefix string) []string {
+	if strings.HasPrefix(prefix, "--") {
+		names := make([]string, 0)
+		for i := range defs {
+			if defs[i].Named {
+				names = append(names, "--"+defs[i].Name)
+			}
+		}
+		return filterPrefix(names, prefix)
+	}
+	var target *Arg
+	if len(tokens) > 0 && strings.HasPrefix(tokens[len(tokens)-1], "--") {
+		name := tokens[len(tokens)-1][2:]
+		for i := range defs {
+			if defs[i].Named && defs[i].Type != ArgTypeBool &&
+				!strings.Contains(name, "=") && strings.EqualFold(defs[i].Name, name) {
+				target = &defs[i]
+			}
+		}
+	}
+	if target == nil {
+		positional := 0
+		for i := 0; i < len(tokens); i++ {
+			if !strings.HasPrefix(tokens[i], "--") {
+				positional++
+			}
+		}
+		for i := range defs {
+			if defs[i].Named {
+				continue
+			}
+			if positional == 0 {
+				target = &defs[i]
+				break
+			}
+			positional--
+		}
+	}
+	if target == nil {
+		return []string{}
+	}
+	return filterPrefix(target.completions(prefix), prefix)
+}
+
+func (a *Arg) completions(prefix string) []string {
+	if a.Complete != nil {
+		return a.Complete(prefix)
+	} else if len(a.Choices) > 0 {
+		return a.Choices
+	} else if a.Type == ArgTypeBool {
+		return []string{"false", "true"}
+	}
+	return []string{}
+}
+
+func filterPrefix(options []string, prefix string) []string {
+	out := make([]string, 0, len(options))
+	lower := strings.ToLower(prefix)
+	for _, o := range options {
+		if strings.HasPrefix(strings.ToLower(o), lower) {
+			out = append(out, o)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func commonPrefix(options []string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	prefix := options[0]
+	for _, o := range options[1:] {
+		for !strings.HasPrefix(o, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func (c *Console) autocomplete() {
+	text := c.input.Text()
+	options := c.Complete(text)
+	if len(options) == 0 {
+		return
+	}
+	start := strings.LastIndexAny(text, " \t;") + 1
+	completed := commonPrefix(options)
+	if len(options) == 1 {
+		completed += " "
+	} else if strings.EqualFold(completed, text[start:]) {
+		c.Write(strings.Join(options, "  "))
+	}
+	if len(completed) >= len(text[start:]) {
+		c.input.SetText(text[:start] + completed)
+	}
+}
@@ -38,17 +38,25 @@
 package console
 
 import (
+	"errors"
+	"fmt"
 	"kaiju/engine"
 	"kaiju/hid"
 	"kaiju/markup"
 	"kaiju/markup/document"
 	"kaiju/matrix"
 	"kaiju/ui"
+	"slices"
 	"strings"
 )
 
 type ConsoleFunc func(*engine.Host, string) string
 
+// TypedConsoleFunc is the function signature for commands that are added
+// through #Console.AddTypedCommand. The arguments have already been parsed
+// and validated against the command's argument definitions.
+type TypedConsoleFunc func(*engine.Host, Args) (string, error)
+
 var consoles = map[*engine.Host]*Console{}
 
 type history struct {
@@ -94,18 +102,47 @@ type ConsoleData interface{}
 type consoleCommand struct {
 	description string
 	fn          ConsoleFunc
+	args        []Arg
+	typedFn     TypedConsoleFunc
+}
+
+func (cmd *consoleCommand) exec(host *engine.Host, arg string) (string, error) {
+	if cmd.typedFn == nil {
+		return cmd.fn(host, arg), nil
+	}
+	tokens, err := tokenize(arg)
+	if err != nil {
+		return "", err
+	}
+	args, err := parseArgs(cmd.args, tokens)
+	if err != nil {
+		return "", err
+	}
+	return cmd.typedFn(host, args)
+}
+
+func (cmd *consoleCommand) usage(key string) string {
+	sb := strings.Builder{}
+	sb.WriteString(key)
+	for i := range cmd.args {
+		sb.WriteRune(' ')
+		sb.WriteString(cmd.args[i].usage())
+	}
+	return sb.String()
 }
 
 type Console struct {
-	doc        *document.Document
-	host       *engine.Host
-	commands   map[string]consoleCommand
-	history    history
-	historyIdx int
-	updateId   int
-	isActive   bool
-	input      *ui.Input
-	data       map[string]ConsoleData
+	doc         *document.Document
+	host        *engine.Host
+	commands    map[string]consoleCommand
+	history     history
+	historyIdx  int
+	updateId    int
+	isActive    bool
+	input       *ui.Input
+	data        map[string]ConsoleData
+	remote      *RemoteServer
+	scriptDepth int
 }
 
 func For(host *engine.Host) *Console {
@@ -139,12 +176,30 @@ func initialize(host *engine.Host) *Console {
 	console.input = input
 	input.Clean()
 	console.hide()
-	console.AddCommand("help", "Display list of commands and their descriptions", console.help)
+	console.AddTypedCommand("help", "Display list of commands and their descriptions", []Arg{
+		{
+			Name:        "command",
+			Description: "The command to show the usage of",
+			Optional:    true,
+			Complete:    console.completeCommandName,
+		},
+	}, console.help)
 	console.AddCommand("clear", "Clears the console text", console.clear)
+	console.AddTypedCommand("exec", "Runs each line of a script file as a console command", []Arg{
+		{Name: "file", Description: "The path to the script file"},
+	}, console.exec)
+	console.addCVarCommands(CVarsFor(host))
+	console.addRemoteCommands()
+	if host.LogStream != nil {
+		console.addLogCommands(host.LogStream)
+	}
 	return console
 }
 
-func UnlinkHost(host *engine.Host) { delete(consoles, host) }
+func UnlinkHost(host *engine.Host) {
+	delete(consoles, host)
+	delete(cvars, host)
+}
 
 func (c *Console) SetUIGroup(group *ui.Group)           { c.doc.SetGroup(group) }
 func (c *Console) Host() *engine.Host                   { return c.host }
@@ -182,33 +237,102 @@ func (c *Console) IsActive() bool {
 }
 
 func (c *Console) AddCommand(key, description string, fn ConsoleFunc) {
-	c.commands[strings.ToLower(key)] = consoleCommand{description, fn}
+	c.commands[strings.ToLower(key)] = consoleCommand{description: description, fn: fn}
+}
+
+// AddTypedCommand adds a command whose argument string is parsed into the
+// supplied argument definitions before the function is called. If the
+// arguments fail to parse, the function is not called and the error along
+// with the usage of the command is written to the console.
+func (c *Console) AddTypedCommand(key, description string, args []Arg, fn TypedConsoleFunc) {
+	c.commands[strings.ToLower(key)] = consoleCommand{
+		description: description,
+		args:        args,
+		typedFn:     fn,
+	}
 }
 
 func (c *Console) ExecCommand(key, arg string) (string, error) {
 	if cmd, ok := c.commands[strings.ToLower(key)]; ok {
-		return cmd.fn(c.host, arg), nil
+		return cmd.exec(c.host, arg)
 	} else {
 		return "", ErrCommandNotFound
 	}
 }
 
+// Run will execute the command line as if it were typed into the console.
+// Multiple commands can be chained together by separating them with a
+// semicolon. The output of each command is joined by a new line and execution
+// stops at the first command that fails.
+func (c *Console) Run(line string) (string, error) {
+	out := make([]string, 0, 1)
+	for _, cmdStr := range splitChain(line) {
+		key, value, _ := strings.Cut(cmdStr, " ")
+		res, err := c.ExecCommand(key, strings.TrimSpace(value))
+		if res = strings.TrimSpace(res); res != "" {
+			out = append(out, res)
+		}
+		if err != nil {
+			if errors.Is(err, ErrCommandNotFound) {
+				err = fmt.Errorf("unknown command %q, type help for a list of commands", key)
+			} else if errors.Is(err, ErrInvalidArgument) || errors.Is(err, ErrUnterminatedQuote) {
+				cmd := c.commands[strings.ToLower(key)]
+				err = fmt.Errorf("%w\nusage: %s", err, cmd.usage(strings.ToLower(key)))
+			}
+			return strings.Join(out, "\n"), err
+		}
+	}
+	return strings.Join(out, "\n"), nil
+}
+
 func (c *Console) Write(message string) {
+	lblParent, _ := c.doc.GetElementById("consoleContent")
 	lbl := c.outputLabel()
 	lbl.SetText(lbl.Text() + "\n" + message)
+	lblParent.UIPanel.SetScrollY(matrix.FloatMax)
 }
 
-func (c *Console) help(*engine.Host, string) string {
+func (c *Console) help(_ *engine.Host, args Args) (string, error) {
 	sb := strings.Builder{}
+	if args.Has("command") {
+		key := strings.ToLower(args.String("command"))
+		cmd, ok := c.commands[key]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrCommandNotFound, key)
+		}
+		sb.WriteString(cmd.description)
+		sb.WriteString("\nusage: ")
+		sb.WriteString(cmd.usage(key))
+		for i := range cmd.args {
+			a := &cmd.args[i]
+			sb.WriteString("\n  ")
+			sb.WriteString(a.Name)
+			sb.WriteString(":\t")
+			sb.WriteString(a.Description)
+			if a.Default != "" {
+				sb.WriteString(" (default: " + a.Default + ")")
+			}
+		}
+		return sb.String(), nil
+	}
 	sb.WriteString("Available Commands:\n")
-	for name, cmd := range c.commands {
+	for _, name := range c.CommandNames() {
 		sb.WriteString(name)
 		sb.WriteString(":\t")
-		sb.WriteString(cmd.description)
+		sb.WriteString(c.commands[name].description)
 		sb.WriteRune('\n')
 	}
+	return sb.String(), nil
+}
 
-	return sb.String()
+// CommandNames returns the sorted names of all the commands in the console
+func (c *Console) CommandNames() []string {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
 }
 
 func (c *Console) clear(*engine.Host, string) string {
@@ -228,26 +352,15 @@ func (c *Console) submit(input *ui.Input) {
 	}
 	input.SetText("")
 	c.history.add(cmdStr)
-	head := strings.Index(cmdStr, " ")
-	var key, value string
-	if head != -1 {
-		key = cmdStr[:head]
-		value = strings.TrimSpace(cmdStr[head+1:])
-	} else {
-		key = cmdStr
+	res, err := c.Run(cmdStr)
+	if err != nil {
+		res = strings.TrimSpace(res + "\nError: " + err.Error())
 	}
-	var res string
-	if cmd, ok := c.commands[key]; ok {
-		res = strings.TrimSpace(cmd.fn(c.host, value))
-	}
-	lblParent, _ := c.doc.GetElementById("consoleContent")
-	lbl := c.outputLabel()
 	if res != "" {
-		lbl.SetText(lbl.Text() + "\n" + cmdStr + "\n" + res)
+		c.Write(cmdStr + "\n" + res)
 	} else {
-		lbl.SetText(lbl.Text() + "\n" + cmdStr)
+		c.Write(cmdStr)
 	}
-	lblParent.UIPanel.SetScrollY(matrix.FloatMax)
 }
 
 func (c *Console) update(deltaTime float64) {
@@ -258,5 +371,7 @@ func (c *Console) update(deltaTime float64) {
 		c.input.SetText(c.history.back())
 	} else if kb.KeyDown(hid.KeyboardKeyDown) {
 		c.input.SetText(c.history.forward())
+	} else if kb.KeyDown(hid.KeyboardKeyTab) && c.isActive {
+		c.autocomplete()
 	}
 }
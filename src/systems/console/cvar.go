@@ -0,0 +1,253 @@
+/******************************************************************************/
+/* cvar.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CVarType is the type of value that a console variable holds
+type CVarType int
+
+const (
+	CVarTypeBool CVarType = iota
+	CVarTypeInt
+	CVarTypeFloat
+	CVarTypeString
+	CVarTypeEnum
+)
+
+// CVarFlags are used to restrict how and when a console variable can change
+type CVarFlags uint8
+
+const (
+	// CVarFlagCheat variables can only be changed while cheats are enabled
+	CVarFlagCheat CVarFlags = 1 << iota
+	// CVarFlagDevOnly variables can only be changed in debug/editor builds
+	CVarFlagDevOnly
+	// CVarFlagNoSave variables are never written to the config file
+	CVarFlagNoSave
+)
+
+// CVar is a typed console variable that can be changed at runtime through
+// the console or through code. Values are validated against the range (for
+// int and float variables) or the options (for enum variables) before they
+// are applied, any change callbacks are called after the value changes.
+type CVar struct {
+	name         string
+	description  string
+	cvarType     CVarType
+	flags        CVarFlags
+	defaultValue any
+	value        any
+	min          float64
+	max          float64
+	hasRange     bool
+	options      []string
+	onChange     []func(*CVar)
+}
+
+func (t CVarType) String() string {
+	switch t {
+	case CVarTypeBool:
+		return "bool"
+	case CVarTypeInt:
+		return "int"
+	case CVarTypeFloat:
+		return "float"
+	case CVarTypeEnum:
+		return "enum"
+	default:
+		return "string"
+	}
+}
+
+func (v *CVar) Name() string                { return v.name }
+func (v *CVar) Description() string         { return v.description }
+func (v *CVar) Type() CVarType              { return v.cvarType }
+func (v *CVar) Flags() CVarFlags            { return v.flags }
+func (v *CVar) HasFlag(flag CVarFlags) bool { return v.flags&flag != 0 }
+func (v *CVar) Options() []string           { return v.options }
+func (v *CVar) IsDefault() bool             { return v.value == v.defaultValue }
+
+// Range returns the inclusive range of the variable, the last return value
+// will be false if the variable has no range
+func (v *CVar) Range() (float64, float64, bool) { return v.min, v.max, v.hasRange }
+
+// Bool returns the value of the variable as a bool
+func (v *CVar) Bool() bool { b, _ := v.value.(bool); return b }
+
+// Int returns the value of the variable as an int
+func (v *CVar) Int() int { i, _ := v.value.(int); return i }
+
+// Float returns the value of the variable as a float64, int variables are
+// converted to float64
+func (v *CVar) Float() float64 {
+	switch val := v.value.(type) {
+	case float64:
+		return val
+	case int:
+		return float64(val)
+	}
+	return 0
+}
+
+// String returns the value of the variable formatted as a string, this is the
+// same format that is accepted by #CVar.Set
+func (v *CVar) String() string { return formatCVarValue(v.value) }
+
+// Default returns the default value formatted as a string
+func (v *CVar) Default() string { return formatCVarValue(v.defaultValue) }
+
+// OnChange adds a function to be called any time the value of the variable
+// changes
+func (v *CVar) OnChange(fn func(*CVar)) { v.onChange = append(v.onChange, fn) }
+
+// Set parses the string value into the type of the variable and applies it.
+// This does not check the cheat or developer flags, those are only enforced
+// on changes that come through the console (#CVars.Set).
+func (v *CVar) Set(value string) error {
+	parsed, err := v.parse(value)
+	if err != nil {
+		return err
+	}
+	v.apply(parsed)
+	return nil
+}
+
+func (v *CVar) SetBool(value bool) error { return v.Set(strconv.FormatBool(value)) }
+func (v *CVar) SetInt(value int) error   { return v.Set(strconv.Itoa(value)) }
+func (v *CVar) SetFloat(value float64) error {
+	return v.Set(strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// Reset will set the variable back to its default value
+func (v *CVar) Reset() { v.apply(v.defaultValue) }
+
+func (v *CVar) apply(value any) {
+	if v.value == value {
+		return
+	}
+	v.value = value
+	for _, fn := range v.onChange {
+		fn(v)
+	}
+}
+
+func (v *CVar) parse(value string) (any, error) {
+	value = strings.TrimSpace(value)
+	switch v.cvarType {
+	case CVarTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects a bool but got %q",
+				ErrCVarInvalidValue, v.name, value)
+		}
+		return b, nil
+	case CVarTypeInt:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects an int but got %q",
+				ErrCVarInvalidValue, v.name, value)
+		}
+		return i, v.checkRange(float64(i))
+	case CVarTypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects a float but got %q",
+				ErrCVarInvalidValue, v.name, value)
+		}
+		return f, v.checkRange(f)
+	case CVarTypeEnum:
+		idx := slices.IndexFunc(v.options, func(o string) bool {
+			return strings.EqualFold(o, value)
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %s must be one of [%s]",
+				ErrCVarInvalidValue, v.name, strings.Join(v.options, ", "))
+		}
+		return v.options[idx], nil
+	default:
+		return value, nil
+	}
+}
+
+// validDefault checks the default value against the range or options of the
+// variable, an invalid default is replaced by the closest valid value
+func (v *CVar) validDefault() (any, error) {
+	parsed, err := v.parse(formatCVarValue(v.defaultValue))
+	if err == nil {
+		return parsed, nil
+	}
+	switch def := v.defaultValue.(type) {
+	case int:
+		return int(max(v.min, min(v.max, float64(def)))), err
+	case float64:
+		return max(v.min, min(v.max, def)), err
+	case string:
+		if v.cvarType == CVarTypeEnum && len(v.options) > 0 {
+			return v.options[0], err
+		}
+	}
+	return v.defaultValue, err
+}
+
+func (v *CVar) checkRange(value float64) error {
+	if v.hasRange && (value < v.min || value > v.max) {
+		return fmt.Errorf("%w: %s must be between %g and %g",
+			ErrCVarOutOfRange, v.name, v.min, v.max)
+	}
+	return nil
+}
+
+func formatCVarValue(value any) string {
+	switch val := value.(type) {
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case string:
+		return val
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+/******************************************************************************/
+/* cvar_commands.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"fmt"
+	"kaiju/engine"
+	"strings"
+)
+
+var cvars = map[*engine.Host]*CVars{}
+
+// CVarsFor returns the console variable registry for the host, the registry
+// is created the first time it is requested
+func CVarsFor(host *engine.Host) *CVars {
+	c, ok := cvars[host]
+	if !ok {
+		c = NewCVars()
+		cvars[host] = c
+	}
+	return c
+}
+
+func (c *Console) addCVarCommands(vars *CVars) {
+	nameArg := Arg{
+		Name:        "name",
+		Description: "The name of the console variable",
+		Complete:    func(string) []string { return vars.Names() },
+	}
+	fileArg := Arg{
+		Name:        "file",
+		Description: "The path to the config file",
+		Default:     CVarConfigFile,
+	}
+	c.AddTypedCommand("cvar.get", "Shows the value of a console variable",
+		[]Arg{nameArg}, func(_ *engine.Host, args Args) (string, error) {
+			v, ok := vars.Find(args.String("name"))
+			if !ok {
+				return "", fmt.Errorf("%w: %s", ErrCVarNotFound, args.String("name"))
+			}
+			return describeCVar(v), nil
+		})
+	c.AddTypedCommand("cvar.set", "Changes the value of a console variable",
+		[]Arg{nameArg, {Name: "value", Description: "The new value"}},
+		func(_ *engine.Host, args Args) (string, error) {
+			if err := vars.Set(args.String("name"), args.String("value")); err != nil {
+				return "", err
+			}
+			v, _ := vars.Find(args.String("name"))
+			return v.Name() + " = " + v.String(), nil
+		})
+	c.AddTypedCommand("cvar.reset", "Resets a console variable to its default value",
+		[]Arg{nameArg}, func(_ *engine.Host, args Args) (string, error) {
+			if err := vars.Reset(args.String("name")); err != nil {
+				return "", err
+			}
+			v, _ := vars.Find(args.String("name"))
+			return v.Name() + " = " + v.String(), nil
+		})
+	c.AddTypedCommand("cvar.list", "Lists the console variables", []Arg{
+		{Name: "filter", Description: "Only list variables containing this text", Optional: true},
+	}, func(_ *engine.Host, args Args) (string, error) {
+		filter := strings.ToLower(args.String("filter"))
+		sb := strings.Builder{}
+		for _, v := range vars.List() {
+			if filter == "" || strings.Contains(strings.ToLower(v.Name()), filter) {
+				sb.WriteString(describeCVar(v))
+				sb.WriteRune('\n')
+			}
+		}
+		return sb.String(), nil
+	})
+	c.AddTypedCommand("cvar.save", "Saves the changed console variables to a file",
+		[]Arg{fileArg}, func(_ *engine.Host, args Args) (string, error) {
+			if err := vars.Save(args.String("file")); err != nil {
+				return "", err
+			}
+			return "Console variables saved to " + args.String("file"), nil
+		})
+	c.AddTypedCommand("cvar.load", "Loads console variables from a file",
+		[]Arg{fileArg}, func(_ *engine.Host, args Args) (string, error) {
+			if err := vars.Load(args.String("file")); err != nil {
+				return "", err
+			}
+			return "Console variables loaded from " + args.String("file"), nil
+		})
+}
+
+func describeCVar(v *CVar) string {
+	sb := strings.Builder{}
+	sb.WriteString(v.Name())
+	sb.WriteString(" = ")
+	sb.WriteString(v.String())
+	sb.WriteString(" (" + v.Type().String())
+	if min, max, ok := v.Range(); ok {
+		sb.WriteString(fmt.Sprintf(" %g..%g", min, max))
+	}
+	if len(v.Options()) > 0 {
+		sb.WriteString(" [" + strings.Join(v.Options(), ", ") + "]")
+	}
+	sb.WriteString(", default: " + v.Default())
+	if v.HasFlag(CVarFlagCheat) {
+		sb.WriteString(", cheat")
+	}
+	if v.HasFlag(CVarFlagDevOnly) {
+		sb.WriteString(", dev")
+	}
+	sb.WriteString(")")
+	if v.Description() != "" {
+		sb.WriteString(" ")
+		sb.WriteString(v.Description())
+	}
+	return sb.String()
+}
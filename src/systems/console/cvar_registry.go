@@ -0,0 +1,279 @@
+/******************************************************************************/
+/* cvar_registry.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kaiju/filesystem"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// CVarConfigFile is the default file that console variables are saved to and
+// loaded from
+const CVarConfigFile = "cvars.json"
+
+// cheatsCVar is the name of the variable that enables changing cheat variables
+const cheatsCVar = "cheats"
+
+// CVars is a registry of console variables. Values that are loaded for
+// variables that have not yet been registered are held until the variable is
+// registered, so the config file can be loaded before the game registers its
+// variables.
+type CVars struct {
+	vars    map[string]*CVar
+	pending map[string]string
+	cheats  *CVar
+}
+
+// NewCVars creates a new registry that contains the "cheats" variable
+func NewCVars() *CVars {
+	c := &CVars{
+		vars:    make(map[string]*CVar),
+		pending: make(map[string]string),
+	}
+	c.cheats = c.RegisterBool(cheatsCVar, "Allows changing cheat variables",
+		false, CVarFlagDevOnly|CVarFlagNoSave)
+	return c
+}
+
+// CheatsEnabled returns true if variables flagged as cheats can be changed
+func (c *CVars) CheatsEnabled() bool { return c.cheats.Bool() }
+
+// RegisterBool registers a new bool variable
+func (c *CVars) RegisterBool(name, description string, value bool, flags CVarFlags) *CVar {
+	return c.register(&CVar{
+		name:         name,
+		description:  description,
+		cvarType:     CVarTypeBool,
+		flags:        flags,
+		defaultValue: value,
+	})
+}
+
+// RegisterInt registers a new int variable, if min and max are equal then
+// the variable will have no range restriction
+func (c *CVars) RegisterInt(name, description string, value, min, max int, flags CVarFlags) *CVar {
+	return c.register(&CVar{
+		name:         name,
+		description:  description,
+		cvarType:     CVarTypeInt,
+		flags:        flags,
+		defaultValue: value,
+		min:          float64(min),
+		max:          float64(max),
+		hasRange:     min != max,
+	})
+}
+
+// RegisterFloat registers a new float variable, if min and max are equal
+// then the variable will have no range restriction
+func (c *CVars) RegisterFloat(name, description string, value, min, max float64, flags CVarFlags) *CVar {
+	return c.register(&CVar{
+		name:         name,
+		description:  description,
+		cvarType:     CVarTypeFloat,
+		flags:        flags,
+		defaultValue: value,
+		min:          min,
+		max:          max,
+		hasRange:     min != max,
+	})
+}
+
+// RegisterString registers a new string variable
+func (c *CVars) RegisterString(name, description, value string, flags CVarFlags) *CVar {
+	return c.register(&CVar{
+		name:         name,
+		description:  description,
+		cvarType:     CVarTypeString,
+		flags:        flags,
+		defaultValue: value,
+	})
+}
+
+// RegisterEnum registers a new variable whose value must be one of the options
+func (c *CVars) RegisterEnum(name, description, value string, options []string, flags CVarFlags) *CVar {
+	return c.register(&CVar{
+		name:         name,
+		description:  description,
+		cvarType:     CVarTypeEnum,
+		flags:        flags,
+		defaultValue: value,
+		options:      slices.Clone(options),
+	})
+}
+
+func (c *CVars) register(cvar *CVar) *CVar {
+	key := strings.ToLower(cvar.name)
+	if found, ok := c.vars[key]; ok {
+		slog.Error("A console variable with this name is already registered",
+			slog.String("name", cvar.name))
+		return found
+	}
+	value, err := cvar.validDefault()
+	if err != nil {
+		slog.Error("The default value of the console variable is invalid",
+			slog.String("name", cvar.name), slog.String("error", err.Error()))
+	}
+	cvar.defaultValue = value
+	cvar.value = value
+	cvar.onChange = make([]func(*CVar), 0)
+	c.vars[key] = cvar
+	if value, ok := c.pending[key]; ok {
+		delete(c.pending, key)
+		if err := c.Set(cvar.name, value); err != nil {
+			slog.Warn("Failed to apply the loaded console variable value",
+				slog.String("name", cvar.name), slog.String("error", err.Error()))
+		}
+	}
+	return cvar
+}
+
+// Find returns the variable with the given name
+func (c *CVars) Find(name string) (*CVar, bool) {
+	v, ok := c.vars[strings.ToLower(name)]
+	return v, ok
+}
+
+// Names returns the sorted names of all of the registered variables
+func (c *CVars) Names() []string {
+	names := make([]string, 0, len(c.vars))
+	for _, v := range c.vars {
+		names = append(names, v.name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// List returns all the registered variables sorted by name
+func (c *CVars) List() []*CVar {
+	list := make([]*CVar, 0, len(c.vars))
+	for _, name := range c.Names() {
+		list = append(list, c.vars[strings.ToLower(name)])
+	}
+	return list
+}
+
+// Set will change the value of the variable if it is allowed to be changed.
+// Variables flagged as cheats can only be changed when cheats are enabled and
+// developer only variables can only be changed in debug and editor builds.
+func (c *CVars) Set(name, value string) error {
+	v, ok := c.Find(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCVarNotFound, name)
+	}
+	if err := c.checkAccess(v); err != nil {
+		return err
+	}
+	return v.Set(value)
+}
+
+// Reset will change the value of the variable back to its default if it is
+// allowed to be changed
+func (c *CVars) Reset(name string) error {
+	v, ok := c.Find(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCVarNotFound, name)
+	}
+	if err := c.checkAccess(v); err != nil {
+		return err
+	}
+	v.Reset()
+	return nil
+}
+
+func (c *CVars) checkAccess(v *CVar) error {
+	if v.HasFlag(CVarFlagDevOnly) && !isDevelopmentBuild {
+		return fmt.Errorf("%w: %s", ErrCVarDevOnly, v.name)
+	}
+	if v.HasFlag(CVarFlagCheat) && !c.CheatsEnabled() {
+		return fmt.Errorf("%w: %s", ErrCVarCheat, v.name)
+	}
+	return nil
+}
+
+// Save writes all of the variables that are not set to their default value
+// to the file at the given path. Variables flagged with #CVarFlagNoSave or
+// #CVarFlagCheat are not saved.
+func (c *CVars) Save(path string) error {
+	values := make(map[string]string)
+	for _, v := range c.vars {
+		if v.IsDefault() || v.HasFlag(CVarFlagNoSave|CVarFlagCheat) {
+			continue
+		}
+		values[v.name] = v.String()
+	}
+	for k, v := range c.pending {
+		values[k] = v
+	}
+	str, err := json.MarshalIndent(values, "", "\t")
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteTextFile(path, string(str))
+}
+
+// Load reads the values from the file at the given path and applies them.
+// Values for variables that are not yet registered are applied once they are
+// registered. Values that fail to apply are skipped and the errors are joined
+// together in the returned error.
+func (c *CVars) Load(path string) error {
+	str, err := filesystem.ReadTextFile(path)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal([]byte(str), &values); err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for name, value := range values {
+		if _, ok := c.Find(name); ok {
+			if err := c.Set(name, value); err != nil {
+				errs = append(errs, err)
+			}
+		} else {
+			c.pending[strings.ToLower(name)] = value
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,124 @@
+/******************************************************************************/
+/* cvar_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCVarSetAndValidate(t *testing.T) {
+	vars := NewCVars()
+	fov := vars.RegisterFloat("fov", "Field of view", 60, 30, 120, 0)
+	quality := vars.RegisterEnum("quality", "Render quality", "high",
+		[]string{"low", "medium", "high"}, 0)
+	changes := 0
+	fov.OnChange(func(*CVar) { changes++ })
+	if err := vars.Set("fov", "90"); err != nil || fov.Float() != 90 {
+		t.Errorf("expected fov to be 90, got %v (%v)", fov.Float(), err)
+	}
+	if err := vars.Set("fov", "200"); !errors.Is(err, ErrCVarOutOfRange) {
+		t.Error("expected an out of range error")
+	}
+	if err := vars.Set("quality", "ultra"); !errors.Is(err, ErrCVarInvalidValue) {
+		t.Error("expected an invalid value error")
+	}
+	if err := vars.Set("quality", "LOW"); err != nil || quality.String() != "low" {
+		t.Errorf("expected quality to be low, got %s (%v)", quality.String(), err)
+	}
+	if err := vars.Set("missing", "1"); !errors.Is(err, ErrCVarNotFound) {
+		t.Error("expected a not found error")
+	}
+	fov.Reset()
+	if !fov.IsDefault() || changes != 2 {
+		t.Errorf("expected fov to be reset with 2 changes, had %d", changes)
+	}
+}
+
+func TestCVarInvalidDefault(t *testing.T) {
+	vars := NewCVars()
+	volume := vars.RegisterInt("volume", "Master volume", 150, 0, 100, 0)
+	quality := vars.RegisterEnum("quality", "Render quality", "ultra",
+		[]string{"low", "high"}, 0)
+	mode := vars.RegisterEnum("mode", "Window mode", "Windowed",
+		[]string{"windowed", "fullscreen"}, 0)
+	if volume.Int() != 100 || !volume.IsDefault() {
+		t.Errorf("expected the default volume to be clamped to 100, got %d", volume.Int())
+	}
+	if quality.String() != "low" {
+		t.Errorf("expected the default quality to be the first option, got %s", quality.String())
+	}
+	if mode.String() != "windowed" {
+		t.Errorf("expected the default mode to match the option, got %s", mode.String())
+	}
+	if err := volume.SetBool(true); !errors.Is(err, ErrCVarInvalidValue) {
+		t.Error("expected setting a bool on an int variable to fail")
+	}
+}
+
+func TestCVarCheat(t *testing.T) {
+	vars := NewCVars()
+	god := vars.RegisterBool("god", "Invincibility", false, CVarFlagCheat)
+	if err := vars.Set("god", "true"); !errors.Is(err, ErrCVarCheat) {
+		t.Error("expected a cheat error while cheats are disabled")
+	}
+	vars.cheats.SetBool(true)
+	if err := vars.Set("god", "true"); err != nil || !god.Bool() {
+		t.Errorf("expected god to be enabled (%v)", err)
+	}
+}
+
+func TestCVarSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CVarConfigFile)
+	vars := NewCVars()
+	vars.RegisterInt("volume", "Master volume", 100, 0, 100, 0).SetInt(25)
+	vars.RegisterString("name", "Player name", "player", 0)
+	vars.RegisterBool("god", "Invincibility", false, CVarFlagCheat)
+	if err := vars.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewCVars()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	volume := loaded.RegisterInt("volume", "Master volume", 100, 0, 100, 0)
+	if volume.Int() != 25 {
+		t.Errorf("expected the pending volume to be applied, got %d", volume.Int())
+	}
+}
@@ -40,5 +40,14 @@ package console
 import "errors"
 
 var (
-	ErrCommandNotFound = errors.New("the command with the given key does not exist")
+	ErrCommandNotFound   = errors.New("the command with the given key does not exist")
+	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrUnterminatedQuote = errors.New("the command has an unterminated quote")
+	ErrCVarNotFound      = errors.New("the console variable does not exist")
+	ErrCVarInvalidValue  = errors.New("invalid console variable value")
+	ErrCVarOutOfRange    = errors.New("the console variable value is out of range")
+	ErrCVarCheat         = errors.New("cheats must be enabled to change the console variable")
+	ErrCVarDevOnly       = errors.New("the console variable can only be changed in development builds")
+	ErrRemoteRunning     = errors.New("the remote console is already running")
+	ErrScriptDepth       = errors.New("scripts are nested too deeply")
 )
@@ -0,0 +1,163 @@
+/******************************************************************************/
+/* log_commands.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"fmt"
+	"kaiju/engine"
+	"kaiju/systems/logging"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (c *Console) addLogCommands(stream *logging.LogStream) {
+	var fileSink *logging.JSONFileSink
+	levels := []string{"debug", "info", "warn", "error", "default"}
+	c.AddTypedCommand("log.level", "Shows or changes the minimum log level, optionally for a single category", []Arg{
+		{
+			Name:        "level",
+			Description: "The minimum level, default clears the category override",
+			Choices:     levels,
+			Optional:    true,
+		},
+		{
+			Name:        "category",
+			Description: "The category to change the level of",
+			Named:       true,
+		},
+	}, func(_ *engine.Host, args Args) (string, error) {
+		category := args.String("category")
+		if !args.Has("level") {
+			return describeLogFilter(stream.Filter), nil
+		}
+		name := args.String("level")
+		if name == "default" {
+			if category == "" {
+				return "", fmt.Errorf("%w: default requires a category", ErrInvalidArgument)
+			}
+			stream.Filter.ClearCategoryLevel(category)
+			return "Category " + category + " uses the default level", nil
+		}
+		level, err := logging.ParseLevel(name)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidArgument, name)
+		}
+		if category == "" {
+			stream.Filter.SetLevel(level)
+			return "Log level = " + level.String(), nil
+		}
+		stream.Filter.SetCategoryLevel(category, level)
+		return "Log level for " + category + " = " + level.String(), nil
+	})
+	c.AddTypedCommand("log.recent", "Shows the most recent log messages", []Arg{
+		{Name: "count", Description: "The number of messages to show", Type: ArgTypeInt, Default: "20"},
+	}, func(_ *engine.Host, args Args) (string, error) {
+		count := args.Int("count")
+		if count < 0 {
+			return "", fmt.Errorf("%w: count can not be negative", ErrInvalidArgument)
+		}
+		records := stream.Recent()
+		records = records[max(0, len(records)-count):]
+		sb := strings.Builder{}
+		for i := range records {
+			sb.WriteString(records[i].Time.Format(time.TimeOnly))
+			sb.WriteString(" [" + records[i].Level.String() + "] ")
+			if records[i].Category != "" {
+				sb.WriteString(records[i].Category + ": ")
+			}
+			sb.WriteString(records[i].Message)
+			sb.WriteRune('\n')
+		}
+		return sb.String(), nil
+	})
+	c.AddTypedCommand("log.file", "Writes the log to a rotating JSON lines file", []Arg{
+		{Name: "file", Description: "The path to the log file", Default: "kaiju.log.jsonl"},
+		{
+			Name:        "maxSize",
+			Description: "The size in bytes the file can reach before it is rotated",
+			Type:        ArgTypeInt,
+			Default:     fmt.Sprint(logging.DefaultLogFileMaxSize),
+			Named:       true,
+		},
+		{
+			Name:        "maxFiles",
+			Description: "The number of rotated files to keep",
+			Type:        ArgTypeInt,
+			Default:     fmt.Sprint(logging.DefaultLogFileMaxFiles),
+			Named:       true,
+		},
+	}, func(_ *engine.Host, args Args) (string, error) {
+		sink, err := logging.NewJSONFileSink(args.String("file"),
+			int64(args.Int("maxSize")), args.Int("maxFiles"))
+		if err != nil {
+			return "", err
+		}
+		if fileSink != nil {
+			stream.RemoveSink(fileSink)
+			fileSink.Close()
+		}
+		fileSink = sink
+		stream.AddSink(fileSink)
+		return "Logging to " + sink.Path(), nil
+	})
+	c.AddCommand("log.file.stop", "Stops writing the log to a file", func(*engine.Host, string) string {
+		if fileSink == nil {
+			return "The log is not being written to a file"
+		}
+		stream.RemoveSink(fileSink)
+		fileSink.Close()
+		fileSink = nil
+		return "Stopped logging to file"
+	})
+}
+
+func describeLogFilter(filter *logging.LogFilter) string {
+	sb := strings.Builder{}
+	sb.WriteString("Log level = " + filter.Level().String())
+	categories := filter.CategoryLevels()
+	names := make([]string, 0, len(categories))
+	for k := range categories {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	for _, k := range names {
+		sb.WriteString("\n" + k + " = " + categories[k].String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+//go:build debug || editor
+
+/******************************************************************************/
+/* remote.dbg.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"kaiju/engine"
+	"kaiju/systems/console/remote_protocol"
+)
+
+// addRemoteCommands adds the commands that start and stop the remote console,
+// they are only available in development builds
+func (c *Console) addRemoteCommands() {
+	c.AddTypedCommand("remote.start", "Starts the remote console server", []Arg{
+		{
+			Name:        "address",
+			Description: "The address to listen on, prefix with unix: for a Unix socket",
+			Default:     remote_protocol.DefaultAddress,
+		},
+		{
+			Name:        "token",
+			Description: "The token clients must authenticate with",
+			Named:       true,
+		},
+	}, func(_ *engine.Host, args Args) (string, error) {
+		s, err := c.StartRemote(args.String("address"), args.String("token"))
+		if err != nil {
+			return "", err
+		}
+		return "Remote console listening on " + s.Address(), nil
+	})
+	c.AddCommand("remote.stop", "Stops the remote console server", func(*engine.Host, string) string {
+		if c.remote == nil {
+			return "Remote console is not running"
+		}
+		if err := c.remote.Stop(); err != nil {
+			return err.Error()
+		}
+		return "Remote console stopped"
+	})
+	c.host.OnClose.Add(func() {
+		if c.remote != nil {
+			c.remote.Stop()
+		}
+	})
+}
@@ -0,0 +1,222 @@
+/******************************************************************************/
+/* remote.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"kaiju/systems/console/remote_protocol"
+	"log/slog"
+	"net"
+	"sync"
+)
+
+type remoteRequest struct {
+	command string
+	reply   chan remote_protocol.Response
+}
+
+// RemoteServer exposes the commands of a console over a local TCP or Unix
+// socket. Commands received from clients are queued and run on the host
+// update so that they are executed on the same thread as commands typed
+// into the console.
+type RemoteServer struct {
+	console  *Console
+	listener net.Listener
+	token    string
+	requests chan remoteRequest
+	conns    map[net.Conn]struct{}
+	done     chan struct{}
+	mutex    sync.Mutex
+	updateId int
+	closed   bool
+}
+
+// StartRemote will start listening for remote console connections on the
+// given address (see #remote_protocol.ParseAddress). If the token is not
+// empty, clients are required to authenticate with the token before any
+// commands are run. Only one remote server can be running per console.
+func (c *Console) StartRemote(address, token string) (*RemoteServer, error) {
+	if c.remote != nil {
+		return nil, ErrRemoteRunning
+	}
+	network, addr := remote_protocol.ParseAddress(address)
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &RemoteServer{
+		console:  c,
+		listener: listener,
+		token:    token,
+		requests: make(chan remoteRequest, 32),
+		conns:    make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
+	}
+	s.updateId = c.host.Updater.AddUpdate(s.update)
+	c.remote = s
+	go s.accept()
+	return s, nil
+}
+
+// Remote returns the running remote server or nil if it isn't running
+func (c *Console) Remote() *RemoteServer { return c.remote }
+
+// Address returns the address that the server is listening on
+func (s *RemoteServer) Address() string {
+	return s.listener.Addr().Network() + ":" + s.listener.Addr().String()
+}
+
+// Stop will close the listener and all of the open client connections
+func (s *RemoteServer) Stop() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.console.host.Updater.RemoveUpdate(s.updateId)
+	s.console.remote = nil
+	return s.listener.Close()
+}
+
+func (s *RemoteServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("Remote console failed to accept a connection",
+					slog.String("error", err.Error()))
+			}
+			return
+		}
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+		go s.serve(conn)
+	}
+}
+
+func (s *RemoteServer) serve(conn net.Conn) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	if s.token != "" && !s.authenticate(conn, reader) {
+		return
+	}
+	reply := make(chan remote_protocol.Response, 1)
+	for {
+		cmd, err := remote_protocol.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		select {
+		case s.requests <- remoteRequest{command: cmd, reply: reply}:
+		case <-s.done:
+			return
+		}
+		select {
+		case res := <-reply:
+			if err := remote_protocol.WriteResponse(conn, res); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *RemoteServer) authenticate(conn net.Conn, reader *bufio.Reader) bool {
+	req, err := remote_protocol.ReadRequest(reader)
+	if err != nil {
+		return false
+	}
+	token, ok := remote_protocol.ParseAuth(req)
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		remote_protocol.WriteResponse(conn, remote_protocol.Response{
+			Status: remote_protocol.StatusError,
+			Output: remote_protocol.ErrUnauthorized.Error(),
+		})
+		slog.Warn("Remote console connection failed to authenticate",
+			slog.String("address", conn.RemoteAddr().String()))
+		return false
+	}
+	return remote_protocol.WriteResponse(conn, remote_protocol.Response{
+		Status: remote_protocol.StatusOK,
+	}) == nil
+}
+
+func (s *RemoteServer) update(float64) {
+	for {
+		select {
+		case req := <-s.requests:
+			res, err := s.console.Run(req.command)
+			if err != nil {
+				if res != "" {
+					res += "\n"
+				}
+				req.reply <- remote_protocol.Response{
+					Status: remote_protocol.StatusError,
+					Output: res + err.Error(),
+				}
+			} else {
+				req.reply <- remote_protocol.Response{
+					Status: remote_protocol.StatusOK,
+					Output: res,
+				}
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/******************************************************************************/
+/* remote_protocol.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package remote_protocol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultAddress is the address the remote console listens on when no other
+// address is supplied
+const DefaultAddress = "tcp:127.0.0.1:15940"
+
+// TokenEnvironmentVariable can be set to supply the auth token to the client
+const TokenEnvironmentVariable = "KAIJU_CONSOLE_TOKEN"
+
+const (
+	StatusOK    = "ok"
+	StatusError = "err"
+	authCommand = "auth"
+)
+
+var (
+	ErrInvalidHeader  = errors.New("invalid remote console response header")
+	ErrInvalidRequest = errors.New("remote console requests can not contain new lines")
+	ErrUnauthorized   = errors.New("the remote console token is invalid")
+)
+
+// Response is the result of running a command on the remote console
+type Response struct {
+	Status string
+	Output string
+}
+
+// IsError returns true if the command that was run returned an error
+func (r Response) IsError() bool { return r.Status != StatusOK }
+
+// ParseAddress splits an address into the network and address to be used with
+// the net package. Addresses prefixed with "unix:" will use a Unix socket,
+// addresses prefixed with "tcp:" or without a prefix will use TCP.
+func ParseAddress(address string) (network string, addr string) {
+	if address == "" {
+		address = DefaultAddress
+	}
+	if strings.HasPrefix(address, "unix:") {
+		return "unix", strings.TrimPrefix(address, "unix:")
+	}
+	return "tcp", strings.TrimPrefix(address, "tcp:")
+}
+
+// WriteRequest writes a single command line to the connection. Requests are
+// new line terminated, so the command itself can not contain new lines.
+func WriteRequest(w io.Writer, command string) error {
+	if strings.ContainsAny(command, "\r\n") {
+		return ErrInvalidRequest
+	}
+	_, err := io.WriteString(w, command+"\n")
+	return err
+}
+
+// WriteAuth writes the auth request that must be the first request sent to a
+// server that was started with a token
+func WriteAuth(w io.Writer, token string) error {
+	return WriteRequest(w, authCommand+" "+token)
+}
+
+// ReadRequest reads a single command line from the connection
+func ReadRequest(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ParseAuth returns the token from an auth request, the second return value
+// is false if the request is not an auth request
+func ParseAuth(request string) (string, bool) {
+	cmd, token, _ := strings.Cut(request, " ")
+	return token, cmd == authCommand
+}
+
+// WriteResponse writes the header line "<status> <length>" followed by the
+// output of the command
+func WriteResponse(w io.Writer, res Response) error {
+	if _, err := fmt.Fprintf(w, "%s %d\n", res.Status, len(res.Output)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, res.Output)
+	return err
+}
+
+// ReadResponse reads a single response written by #WriteResponse
+func ReadResponse(r *bufio.Reader) (Response, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return Response{}, err
+	}
+	status, lenStr, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || (status != StatusOK && status != StatusError) {
+		return Response{}, ErrInvalidHeader
+	}
+	size, err := strconv.Atoi(lenStr)
+	if err != nil || size < 0 {
+		return Response{}, ErrInvalidHeader
+	}
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return Response{}, err
+	}
+	return Response{Status: status, Output: string(out)}, nil
+}
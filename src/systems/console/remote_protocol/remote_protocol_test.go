@@ -0,0 +1,87 @@
+/******************************************************************************/
+/* remote_protocol_test.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package remote_protocol
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRequestResponse(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := WriteRequest(&buf, "cvar.set fov 90; help"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteRequest(&buf, "help\nclear"); !errors.Is(err, ErrInvalidRequest) {
+		t.Error("expected requests with new lines to fail")
+	}
+	req, err := ReadRequest(bufio.NewReader(&buf))
+	if err != nil || req != "cvar.set fov 90; help" {
+		t.Errorf("unexpected request %q (%v)", req, err)
+	}
+	buf.Reset()
+	expected := []Response{
+		{Status: StatusOK, Output: "line 1\nline 2"},
+		{Status: StatusError, Output: "unknown command"},
+		{Status: StatusOK},
+	}
+	for _, r := range expected {
+		WriteResponse(&buf, r)
+	}
+	reader := bufio.NewReader(&buf)
+	for _, r := range expected {
+		res, err := ReadResponse(reader)
+		if err != nil || res != r {
+			t.Errorf("expected %v but got %v (%v)", r, res, err)
+		}
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	if n, a := ParseAddress("unix:/tmp/kaiju.sock"); n != "unix" || a != "/tmp/kaiju.sock" {
+		t.Errorf("unexpected unix address %s %s", n, a)
+	}
+	if n, a := ParseAddress("127.0.0.1:1234"); n != "tcp" || a != "127.0.0.1:1234" {
+		t.Errorf("unexpected tcp address %s %s", n, a)
+	}
+	if token, ok := ParseAuth("auth secret"); !ok || token != "secret" {
+		t.Error("failed to parse the auth request")
+	}
+}
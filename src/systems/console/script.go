@@ -0,0 +1,87 @@
+/******************************************************************************/
+/* script.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package console
+
+import (
+	"fmt"
+	"kaiju/engine"
+	"kaiju/filesystem"
+	"strings"
+)
+
+// maxScriptDepth is how many scripts can be running inside of each other
+const maxScriptDepth = 8
+
+// RunScript will read the file at the given path and run each of its lines as
+// a console command. Empty lines and lines that start with # are skipped. The
+// script stops at the first command that fails and the error will include the
+// line number of the command. Scripts may exec other scripts up to
+// #maxScriptDepth deep, past that #ErrScriptDepth is returned so that a script
+// that runs itself does not recurse forever.
+func (c *Console) RunScript(path string) error {
+	if c.scriptDepth >= maxScriptDepth {
+		return fmt.Errorf("%w: %s", ErrScriptDepth, path)
+	}
+	c.scriptDepth++
+	defer func() { c.scriptDepth-- }()
+	src, err := filesystem.ReadTextFile(path)
+	if err != nil {
+		return err
+	}
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		res, err := c.Run(line)
+		if res != "" {
+			c.Write(res)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+	}
+	return nil
+}
+
+func (c *Console) exec(_ *engine.Host, args Args) (string, error) {
+	if err := c.RunScript(args.String("file")); err != nil {
+		return "", err
+	}
+	return "Finished running " + args.String("file"), nil
+}
@@ -0,0 +1,108 @@
+/******************************************************************************/
+/* draw_lines.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package debug
+
+import (
+	"kaiju/assets"
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+// DrawLines will draw a line segment between each pair of points supplied, so
+// the number of points must be even. The lines will stay visible until the
+// returned shader data is destroyed by the caller. The key names the mesh of
+// the lines, drawing again with the same key replaces the lines of that mesh
+// rather than adding another mesh to the cache, so a drawing that is redrawn
+// often should keep using its key.
+func DrawLines(host *engine.Host, key string, points []matrix.Vec3, color matrix.Color) *rendering.ShaderDataBasic {
+	cache := host.MeshCache()
+	key = "debug_lines_" + key
+	grid, ok := cache.FindMesh(key)
+	if ok {
+		verts, indexes := lineVertices(points)
+		cache.ReplaceMesh(key, verts, indexes)
+	} else {
+		grid = rendering.NewMeshGrid(cache, key, points, matrix.ColorWhite())
+	}
+	shader := host.ShaderCache().ShaderFromDefinition(assets.ShaderDefinitionGrid)
+	sd := &rendering.ShaderDataBasic{
+		ShaderDataBase: rendering.NewShaderDataBase(),
+		Color:          color,
+	}
+	host.Drawings.AddDrawing(&rendering.Drawing{
+		Renderer:   host.Window.Renderer,
+		Shader:     shader,
+		Mesh:       grid,
+		ShaderData: sd,
+		CanvasId:   "default",
+	})
+	return sd
+}
+
+// DrawPath will draw a connected line through each of the points supplied.
+// The path will stay visible until the returned shader data is destroyed, the
+// key is used the same way as in #DrawLines.
+func DrawPath(host *engine.Host, key string, points []matrix.Vec3, color matrix.Color) *rendering.ShaderDataBasic {
+	if len(points) < 2 {
+		return nil
+	}
+	segments := make([]matrix.Vec3, 0, (len(points)-1)*2)
+	for i := 1; i < len(points); i++ {
+		segments = append(segments, points[i-1], points[i])
+	}
+	return DrawLines(host, key, segments, color)
+}
+
+// lineVertices builds the same vertices and indexes as #rendering.NewMeshGrid
+// so that the mesh of a line drawing can be replaced
+func lineVertices(points []matrix.Vec3) ([]rendering.Vertex, []uint32) {
+	if len(points)%2 != 0 {
+		panic("points length must be even")
+	}
+	verts := make([]rendering.Vertex, len(points))
+	indexes := make([]uint32, len(points))
+	for i := range points {
+		verts[i].Position = points[i]
+		verts[i].Normal = matrix.Vec3{0.0, 0.0, 1.0}
+		verts[i].UV0 = matrix.Vec2{0.0, 1.0}
+		verts[i].Color = matrix.ColorWhite()
+		indexes[i] = uint32(i)
+	}
+	return verts, indexes
+}
@@ -0,0 +1,74 @@
+/******************************************************************************/
+/* directional_light.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package lighting
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+// DirectionalLight is the entity data for a light that lights the whole
+// scene from a single direction, like the sun. The light shines down the
+// forward (-Z) axis of the entity.
+type DirectionalLight struct {
+	Color       matrix.Color
+	Intensity   matrix.Float
+	CastShadows bool
+	light       *rendering.Light
+}
+
+func NewDirectionalLight() *DirectionalLight {
+	return &DirectionalLight{
+		Color:       matrix.ColorWhite(),
+		Intensity:   1,
+		CastShadows: true,
+	}
+}
+
+// Light returns the light that was created when the entity data was
+// initialized, changes to the light take effect on the next frame
+func (d *DirectionalLight) Light() *rendering.Light { return d.light }
+
+func (d *DirectionalLight) Init(entity *engine.Entity, host *engine.Host) {
+	d.light = rendering.NewLight(rendering.LightTypeDirectional)
+	d.light.Color = d.Color
+	d.light.Intensity = d.Intensity
+	d.light.CastShadows = d.CastShadows
+	attach(d.light, entity, host)
+}
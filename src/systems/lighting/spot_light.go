@@ -0,0 +1,84 @@
+/******************************************************************************/
+/* spot_light.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package lighting
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+// SpotLight is the entity data for a cone shaped light that shines down the
+// forward (-Z) axis of the entity. The light is at full strength inside of
+// the inner angle and fades out towards the outer angle, both angles are in
+// degrees measured from the center of the cone.
+type SpotLight struct {
+	Color       matrix.Color
+	Intensity   matrix.Float
+	Range       matrix.Float
+	InnerAngle  matrix.Float
+	OuterAngle  matrix.Float
+	CastShadows bool
+	light       *rendering.Light
+}
+
+func NewSpotLight() *SpotLight {
+	return &SpotLight{
+		Color:       matrix.ColorWhite(),
+		Intensity:   1,
+		Range:       10,
+		InnerAngle:  25,
+		OuterAngle:  35,
+		CastShadows: true,
+	}
+}
+
+// Light returns the light that was created when the entity data was
+// initialized, changes to the light take effect on the next frame
+func (s *SpotLight) Light() *rendering.Light { return s.light }
+
+func (s *SpotLight) Init(entity *engine.Entity, host *engine.Host) {
+	s.light = rendering.NewLight(rendering.LightTypeSpot)
+	s.light.Color = s.Color
+	s.light.Intensity = s.Intensity
+	s.light.Range = s.Range
+	s.light.InnerAngle = s.InnerAngle
+	s.light.OuterAngle = s.OuterAngle
+	s.light.CastShadows = s.CastShadows
+	attach(s.light, entity, host)
+}
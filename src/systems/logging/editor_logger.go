@@ -58,3 +58,7 @@ func newLogHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
 		Handler: slog.NewTextHandler(w, opts),
 	}
 }
+
+func defaultLogLevel() slog.Level {
+	return slog.LevelDebug
+}
@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* filter.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package logging
+
+import (
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// LogFilter decides which records are let through to the stream. There is a
+// default minimum level and each category can override it, the filter can be
+// changed at any time while the program is running
+type LogFilter struct {
+	level      slog.Level
+	categories map[string]slog.Level
+	mutex      sync.RWMutex
+}
+
+func newLogFilter(level slog.Level) *LogFilter {
+	return &LogFilter{
+		level:      level,
+		categories: make(map[string]slog.Level),
+	}
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog
+// level, offsets like "warn+2" are also accepted
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(strings.TrimSpace(name)))
+	return level, err
+}
+
+// Level returns the minimum level for records without a category override
+func (f *LogFilter) Level() slog.Level {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.level
+}
+
+// SetLevel changes the minimum level for records without a category override
+func (f *LogFilter) SetLevel(level slog.Level) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.level = level
+}
+
+// CategoryLevel returns the minimum level of the category and if the
+// category has an override, otherwise the default level is returned
+func (f *LogFilter) CategoryLevel(category string) (slog.Level, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if l, ok := f.categories[category]; ok {
+		return l, true
+	}
+	return f.level, false
+}
+
+// SetCategoryLevel overrides the minimum level for a single category
+func (f *LogFilter) SetCategoryLevel(category string, level slog.Level) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.categories[category] = level
+}
+
+// ClearCategoryLevel removes the override for the category so that it uses
+// the default level again
+func (f *LogFilter) ClearCategoryLevel(category string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.categories, category)
+}
+
+// CategoryLevels returns a copy of all of the category overrides
+func (f *LogFilter) CategoryLevels() map[string]slog.Level {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return maps.Clone(f.categories)
+}
+
+// Enabled reports if a record of the given level could pass the filter for
+// any category, it is used to skip building records early
+func (f *LogFilter) Enabled(level slog.Level) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if level >= f.level {
+		return true
+	}
+	for _, l := range f.categories {
+		if level >= l {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports if a record of the given category and level passes
+func (f *LogFilter) Allows(category string, level slog.Level) bool {
+	min, _ := f.CategoryLevel(category)
+	return level >= min
+}
@@ -38,42 +38,89 @@
 package logging
 
 import (
+	"errors"
+	"fmt"
 	"kaiju/klib"
 	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
+// DefaultRecentRecords is the number of records kept in memory by the log
+// stream for crash reports
+const DefaultRecentRecords = 256
+
 type LogStream struct {
-	OnInfo  Event
-	OnWarn  TracedEvent
-	OnError TracedEvent
+	OnInfo   Event
+	OnWarn   TracedEvent
+	OnError  TracedEvent
+	OnRecord RecordEvent
+	Filter   *LogFilter
+	recent   *RingBuffer
+	sinks    []Sink
+	mutex    sync.Mutex
 }
 
 func (l *LogStream) writeLine(line string) {
 	if line == "" {
 		return
 	}
-	if !strings.HasPrefix(line, "time=") {
+	rec, ok := RecordFromLine(line)
+	if !ok {
 		println(line)
 		return
 	}
-	levelOffset := 21
-	if strings.HasPrefix(line, "time=") {
-		levelOffset = 41
+	// Skips the frames up to the caller of #LogStream.Write
+	if l.accept(&rec, 5) {
+		l.emit(line, rec)
+	}
+}
+
+// accept checks the record against the filter and gives warnings and errors
+// the stack trace that starts skip frames up from runtime.Callers. Records
+// from slog and lines written to the stream both go through here so that
+// they are filtered and traced the same way.
+func (l *LogStream) accept(rec *Record, skip int) bool {
+	if !l.Filter.Allows(rec.Category, rec.Level) {
+		return false
+	}
+	if rec.Level >= slog.LevelWarn {
+		rec.Trace = klib.TraceStrings(rec.Message, skip)
 	}
-	level := line[levelOffset:]
-	if strings.HasPrefix(level, "INFO") {
+	return true
+}
+
+func (l *LogStream) emit(line string, rec Record) {
+	l.dispatch(rec)
+	if rec.Level >= slog.LevelError {
+		l.OnError.Execute(line, rec.Trace)
+	} else if rec.Level >= slog.LevelWarn {
+		l.OnWarn.Execute(line, rec.Trace)
+	} else if rec.Level >= slog.LevelInfo {
 		l.OnInfo.Execute(line)
-	} else if strings.HasPrefix(level, "WARN") {
-		l.OnWarn.Execute(line, klib.TraceStrings(line, 7))
-	} else if strings.HasPrefix(level, "ERROR") {
-		l.OnError.Execute(line, klib.TraceStrings(line, 7))
 	}
-	os.Stdout.WriteString(line)
+	os.Stdout.WriteString(line + "\n")
 }
 
+func (l *LogStream) dispatch(rec Record) {
+	l.mutex.Lock()
+	sinks := l.sinks
+	l.mutex.Unlock()
+	l.recent.WriteRecord(rec)
+	for _, s := range sinks {
+		if err := s.WriteRecord(rec); err != nil {
+			// Logging the failure would end up back in this sink
+			fmt.Fprintln(os.Stderr, "Failed to write the log record:", err)
+		}
+	}
+	l.OnRecord.Execute(rec)
+}
+
+// Write is used for text lines that were not logged through slog, such as
+// the output of another process, they are parsed into records
 func (l *LogStream) Write(p []byte) (n int, err error) {
 	lines := strings.Split(string(p), "\n")
 	for i := range lines {
@@ -82,13 +129,62 @@ func (l *LogStream) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// AddSink starts sending all of the records that pass the filter to the sink
+func (l *LogStream) AddSink(sink Sink) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.sinks = append(slices.Clone(l.sinks), sink)
+}
+
+// RemoveSink stops sending records to the sink, the sink is not closed
+func (l *LogStream) RemoveSink(sink Sink) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if idx := slices.Index(l.sinks, sink); idx >= 0 {
+		l.sinks = slices.Delete(slices.Clone(l.sinks), idx, idx+1)
+	}
+}
+
+// Recent returns the most recent records from oldest to newest
+func (l *LogStream) Recent() []Record { return l.recent.Records() }
+
+// RecentBuffer returns the ring buffer holding the most recent records
+func (l *LogStream) RecentBuffer() *RingBuffer { return l.recent }
+
+// Close removes and closes all of the sinks of the stream
+func (l *LogStream) Close() error {
+	l.mutex.Lock()
+	sinks := l.sinks
+	l.sinks = nil
+	l.mutex.Unlock()
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func Initialize(opts *slog.HandlerOptions) *LogStream {
+	level := defaultLogLevel()
+	if opts != nil && opts.Level != nil {
+		level = opts.Level.Level()
+	}
 	stream := &LogStream{
-		OnInfo:  newEvent(),
-		OnWarn:  newTracedEvent(),
-		OnError: newTracedEvent(),
+		OnInfo:   newEvent(),
+		OnWarn:   newTracedEvent(),
+		OnError:  newTracedEvent(),
+		OnRecord: newRecordEvent(),
+		Filter:   newLogFilter(level),
+		recent:   NewRingBuffer(DefaultRecentRecords),
 	}
-	logger := slog.New(newLogHandler(stream, opts))
+	out := &lineCapture{}
+	logger := slog.New(&recordHandler{
+		inner:  newLogHandler(out, opts),
+		stream: stream,
+		out:    out,
+	})
 	slog.SetDefault(logger)
 	return stream
 }
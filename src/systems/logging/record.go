@@ -0,0 +1,122 @@
+/******************************************************************************/
+/* record.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package logging
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CategoryKey is the attribute key used to assign a log message to a
+// category, categories are used by the filters of the log stream
+const CategoryKey = "category"
+
+// Record is a typed log entry, it is produced directly from the slog record
+// so that consumers do not need to parse the formatted text line
+type Record struct {
+	Time     time.Time         `json:"time"`
+	Level    slog.Level        `json:"level"`
+	Category string            `json:"category,omitempty"`
+	Message  string            `json:"msg"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Trace    []string          `json:"trace,omitempty"`
+}
+
+// Category creates the attribute that assigns a log message to a category
+func Category(name string) slog.Attr { return slog.String(CategoryKey, name) }
+
+// ForCategory returns a logger that assigns all of its messages to the
+// given category
+func ForCategory(name string) *slog.Logger {
+	return slog.Default().With(Category(name))
+}
+
+// RecordFromLine builds a record from a line formatted by the slog text
+// handler, this is used for lines that are written directly to the stream
+// (such as the output of a running game) rather than logged through slog
+func RecordFromLine(line string) (Record, bool) {
+	if !strings.HasPrefix(line, "time=") {
+		return Record{}, false
+	}
+	mapping := ToMap(line)
+	rec := Record{Message: mapping["msg"], Category: mapping[CategoryKey]}
+	rec.Time, _ = time.Parse(time.RFC3339, mapping["time"])
+	if err := rec.Level.UnmarshalText([]byte(mapping["level"])); err != nil {
+		rec.Level = slog.LevelInfo
+	}
+	delete(mapping, "time")
+	delete(mapping, "level")
+	delete(mapping, "msg")
+	delete(mapping, CategoryKey)
+	if len(mapping) > 0 {
+		rec.Attrs = mapping
+	}
+	return rec, true
+}
+
+// Attr returns the value of the attribute with the given key
+func (r *Record) Attr(key string) (string, bool) {
+	v, ok := r.Attrs[key]
+	return v, ok
+}
+
+func (r *Record) addAttr(prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for i := range group {
+			r.addAttr(prefix, group[i])
+		}
+		return
+	}
+	if prefix == "" && a.Key == CategoryKey {
+		r.Category = a.Value.String()
+		return
+	}
+	if r.Attrs == nil {
+		r.Attrs = make(map[string]string)
+	}
+	r.Attrs[prefix+a.Key] = a.Value.String()
+}
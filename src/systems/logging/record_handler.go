@@ -0,0 +1,127 @@
+/******************************************************************************/
+/* record_handler.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// lineCapture collects the line written by the text handler for a single
+// record, it is shared between all handlers derived from the same logger
+type lineCapture struct {
+	buf   bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (c *lineCapture) Write(p []byte) (int, error) { return c.buf.Write(p) }
+
+// recordHandler builds a typed record for every slog record, filters it and
+// dispatches it to the stream. The wrapped handler is still used to produce
+// the text line for the string based events and the standard output
+type recordHandler struct {
+	inner  slog.Handler
+	stream *LogStream
+	out    *lineCapture
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.stream.Filter.Enabled(level)
+}
+
+func (h *recordHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := Record{Time: r.Time, Level: r.Level, Message: r.Message}
+	for i := range h.attrs {
+		rec.addAttr("", h.attrs[i])
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for _, a := range h.qualify(attrs) {
+		rec.addAttr("", a)
+	}
+	// Skips the frames of slog to start the trace at the caller of the logger
+	if !h.stream.accept(&rec, 6) {
+		return nil
+	}
+	h.out.mutex.Lock()
+	err := h.inner.Handle(ctx, r)
+	line := h.out.buf.String()
+	h.out.buf.Reset()
+	h.out.mutex.Unlock()
+	h.stream.emit(strings.TrimSuffix(line, "\n"), rec)
+	return err
+}
+
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	c := *h
+	c.inner = h.inner.WithAttrs(attrs)
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), h.qualify(attrs)...)
+	return &c
+}
+
+func (h *recordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.inner = h.inner.WithGroup(name)
+	c.groups = append(append([]string{}, h.groups...), name)
+	return &c
+}
+
+// qualify nests the attributes inside of the groups of the handler
+func (h *recordHandler) qualify(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return attrs
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
@@ -0,0 +1,178 @@
+/******************************************************************************/
+/* record_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordHandlerBuildsTypedRecords(t *testing.T) {
+	stream := Initialize(&slog.HandlerOptions{Level: slog.LevelDebug})
+	var got []Record
+	stream.OnRecord.Add(func(r Record) { got = append(got, r) })
+	ForCategory("physics").WithGroup("body").Warn("Sleeping",
+		slog.Int("id", 3), slog.Group("pos", slog.Float64("x", 1.5)))
+	if len(got) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(got))
+	}
+	r := got[0]
+	if r.Level != slog.LevelWarn || r.Message != "Sleeping" || r.Category != "physics" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if v, _ := r.Attr("body.id"); v != "3" {
+		t.Errorf("expected body.id to be 3, got %q", v)
+	}
+	if v, _ := r.Attr("body.pos.x"); v != "1.5" {
+		t.Errorf("expected body.pos.x to be 1.5, got %q", v)
+	}
+	if len(r.Trace) == 0 || !strings.Contains(r.Trace[0], "record_test.go") {
+		t.Errorf("expected warnings to have a trace from the logging call, got %v", r.Trace)
+	}
+}
+
+func TestLogFilterCategories(t *testing.T) {
+	stream := Initialize(&slog.HandlerOptions{Level: slog.LevelWarn})
+	count := 0
+	stream.OnRecord.Add(func(Record) { count++ })
+	slog.Info("Dropped")
+	stream.Filter.SetCategoryLevel("audio", slog.LevelDebug)
+	ForCategory("audio").Debug("Kept")
+	ForCategory("render").Info("Dropped")
+	slog.Error("Kept")
+	if count != 2 {
+		t.Errorf("expected 2 records to pass the filter, got %d", count)
+	}
+	stream.Filter.ClearCategoryLevel("audio")
+	ForCategory("audio").Debug("Dropped")
+	if count != 2 {
+		t.Errorf("expected the category override to be cleared")
+	}
+	if l := len(stream.Recent()); l != 2 {
+		t.Errorf("expected 2 recent records, got %d", l)
+	}
+}
+
+func TestWriteUsesFilter(t *testing.T) {
+	stream := Initialize(&slog.HandlerOptions{Level: slog.LevelWarn})
+	var got []Record
+	stream.OnRecord.Add(func(r Record) { got = append(got, r) })
+	stream.Write([]byte("time=2024-01-02T03:04:05.000Z level=INFO msg=Dropped\n" +
+		"time=2024-01-02T03:04:05.000Z level=ERROR msg=Kept\n"))
+	if len(got) != 1 || got[0].Message != "Kept" {
+		t.Fatalf("expected only the error to pass the filter, got %+v", got)
+	}
+	if len(got[0].Trace) == 0 || !strings.Contains(got[0].Trace[0], "record_test.go") {
+		t.Errorf("expected written errors to have a trace from the writer, got %v", got[0].Trace)
+	}
+}
+
+func TestRingBufferKeepsNewest(t *testing.T) {
+	b := NewRingBuffer(3)
+	for _, m := range []string{"a", "b", "c", "d", "e"} {
+		b.WriteRecord(Record{Message: m})
+	}
+	records := b.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for i, m := range []string{"c", "d", "e"} {
+		if records[i].Message != m {
+			t.Errorf("expected record %d to be %s, got %s", i, m, records[i].Message)
+		}
+	}
+}
+
+func TestJSONFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "game.jsonl")
+	sink, err := NewJSONFileSink(path, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := sink.WriteRecord(Record{Level: slog.LevelInfo, Message: "A message to fill up the file"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sink.Close()
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		stat, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", p, err)
+		}
+		if stat.Size() > 200 {
+			t.Errorf("expected %s to be rotated before exceeding 200 bytes, was %d", p, stat.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Errorf("expected at most 2 rotated files")
+	}
+	f, _ := os.Open(path + ".1")
+	defer f.Close()
+	scan := bufio.NewScanner(f)
+	for scan.Scan() {
+		var r Record
+		if err := json.Unmarshal(scan.Bytes(), &r); err != nil {
+			t.Fatalf("failed to read the JSON line: %v", err)
+		}
+		if r.Level != slog.LevelInfo || r.Message == "" {
+			t.Errorf("unexpected record %+v", r)
+		}
+	}
+}
+
+func TestRecordFromLine(t *testing.T) {
+	r, ok := RecordFromLine(`time=2024-01-02T03:04:05.000Z level=ERROR msg="Failed to load" category=assets file=a.png`)
+	if !ok {
+		t.Fatal("expected the line to be parsed")
+	}
+	if r.Level != slog.LevelError || r.Message != "Failed to load" || r.Category != "assets" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if v, _ := r.Attr("file"); v != "a.png" {
+		t.Errorf("expected file attribute, got %q", v)
+	}
+	if _, ok := RecordFromLine("plain output"); ok {
+		t.Errorf("expected plain output to not be a record")
+	}
+}
@@ -0,0 +1,117 @@
+/******************************************************************************/
+/* sink.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink receives every record that passes the filter of the log stream
+type Sink interface {
+	WriteRecord(record Record) error
+	Close() error
+}
+
+// RingBuffer is a sink that keeps the most recent records in memory, it is
+// primarily used to attach the latest logs to crash reports
+type RingBuffer struct {
+	records []Record
+	start   int
+	count   int
+	mutex   sync.Mutex
+}
+
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{records: make([]Record, max(1, capacity))}
+}
+
+func (b *RingBuffer) Capacity() int { return len(b.records) }
+
+func (b *RingBuffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.count
+}
+
+func (b *RingBuffer) WriteRecord(record Record) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	end := (b.start + b.count) % len(b.records)
+	b.records[end] = record
+	if b.count < len(b.records) {
+		b.count++
+	} else {
+		b.start = (b.start + 1) % len(b.records)
+	}
+	return nil
+}
+
+func (b *RingBuffer) Close() error { return nil }
+
+// Records returns a copy of the buffered records from oldest to newest
+func (b *RingBuffer) Records() []Record {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	res := make([]Record, b.count)
+	for i := range res {
+		res[i] = b.records[(b.start+i)%len(b.records)]
+	}
+	return res
+}
+
+// Clear drops all of the buffered records
+func (b *RingBuffer) Clear() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	clear(b.records)
+	b.start = 0
+	b.count = 0
+}
+
+// Dump writes the buffered records to the writer as JSON lines
+func (b *RingBuffer) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, r := range b.Records() {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
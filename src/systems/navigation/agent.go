@@ -0,0 +1,219 @@
+/******************************************************************************/
+/* agent.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package navigation
+
+import (
+	"kaiju/matrix"
+	"kaiju/systems/events"
+)
+
+// Agent moves a transform along a path found through #AStar on the grid of
+// the crowd it belongs to. The agent uses steering behaviors to follow the
+// path and local avoidance to keep from colliding with the other agents in
+// the crowd. If a cell along the remaining path becomes blocked (through
+// #Grid.BlockCell), the agent will find a new path.
+//
+// The agent is plain simulation and has no knowledge of the host, see the
+// nav_agent package for the entity data that drives an agent each frame.
+type Agent struct {
+	Crowd          *Crowd
+	MaxSpeed       matrix.Float
+	MaxForce       matrix.Float
+	Radius         matrix.Float
+	SlowRadius     matrix.Float
+	WaypointRadius matrix.Float
+	StopDistance   matrix.Float
+	NeighborRadius matrix.Float
+	TimeHorizon    matrix.Float
+	OnArrived      events.Event
+	OnRepath       events.Event
+	transform      *matrix.Transform
+	velocity       matrix.Vec3
+	goal           matrix.Vec3
+	path           []matrix.Vec3
+	cells          []matrix.Vec3i
+	obstacles      []Obstacle
+	pathIndex      int
+	moving         bool
+}
+
+// NewAgent creates a new agent with reasonable defaults for a human sized
+// character that will be a member of the given crowd
+func NewAgent(crowd *Crowd) *Agent {
+	return &Agent{
+		Crowd:          crowd,
+		MaxSpeed:       3,
+		MaxForce:       10,
+		Radius:         0.5,
+		SlowRadius:     1.5,
+		WaypointRadius: 0.5,
+		StopDistance:   0.05,
+		NeighborRadius: 3,
+		TimeHorizon:    2,
+		OnArrived:      events.New(),
+		OnRepath:       events.New(),
+		path:           make([]matrix.Vec3, 0),
+		cells:          make([]matrix.Vec3i, 0),
+		obstacles:      make([]Obstacle, 0),
+	}
+}
+
+// Attach will bind the agent to the transform it is to move and add it to
+// its crowd so that other agents can avoid it
+func (a *Agent) Attach(transform *matrix.Transform) {
+	a.transform = transform
+	if a.Crowd != nil {
+		a.Crowd.Add(a)
+	}
+}
+
+// Detach will stop the agent and remove it from its crowd
+func (a *Agent) Detach() {
+	a.Stop()
+	if a.Crowd != nil {
+		a.Crowd.Remove(a)
+	}
+	a.transform = nil
+}
+
+// Position returns the world position of the transform the agent is moving
+func (a *Agent) Position() matrix.Vec3 {
+	if a.transform == nil {
+		return matrix.Vec3Zero()
+	}
+	return a.transform.WorldPosition()
+}
+
+// Velocity returns the current velocity of the agent
+func (a *Agent) Velocity() matrix.Vec3 { return a.velocity }
+
+// Path returns the world points of the path the agent is following
+func (a *Agent) Path() []matrix.Vec3 { return a.path }
+
+// IsMoving returns true if the agent has a destination it has not reached
+func (a *Agent) IsMoving() bool { return a.moving }
+
+// SetDestination will find a path from the current position of the agent to
+// the target and start moving along it. If the target cell is blocked, the
+// nearest open cell will be used instead. Returns false if no path could be
+// found.
+func (a *Agent) SetDestination(target matrix.Vec3) bool {
+	a.goal = target
+	return a.repath()
+}
+
+// Stop will clear the current path and bring the agent to a stop
+func (a *Agent) Stop() {
+	a.moving = false
+	a.velocity = matrix.Vec3Zero()
+	a.path = a.path[:0]
+	a.cells = a.cells[:0]
+}
+
+// Step will move the agent along its path for the given time step
+func (a *Agent) Step(deltaTime float64) {
+	if a.transform == nil {
+		return
+	}
+	dt := matrix.Float(deltaTime)
+	if a.moving && a.pathBlocked() {
+		a.repath()
+	}
+	pos := a.transform.WorldPosition()
+	preferred := matrix.Vec3Zero()
+	if a.moving {
+		force := FollowPath(pos, a.velocity, a.path, &a.pathIndex,
+			a.MaxSpeed, a.WaypointRadius, a.SlowRadius)
+		force = Truncate(force, a.MaxForce)
+		preferred = Truncate(a.velocity.Add(force.Scale(dt)), a.MaxSpeed)
+	}
+	if a.Crowd != nil {
+		a.obstacles = a.Crowd.obstacles(a, pos, a.NeighborRadius, a.obstacles)
+	}
+	a.velocity = Avoid(pos, a.velocity, preferred, a.Radius,
+		a.MaxSpeed, a.TimeHorizon, a.obstacles)
+	pos.AddAssign(a.velocity.Scale(dt))
+	a.transform.SetWorldPosition(pos)
+	if a.moving && a.pathIndex == len(a.path)-1 &&
+		pos.Distance(a.path[a.pathIndex]) <= a.StopDistance {
+		a.Stop()
+		a.OnArrived.Execute()
+	}
+}
+
+func (a *Agent) pathBlocked() bool {
+	if a.Crowd == nil || a.Crowd.Grid == nil {
+		return false
+	}
+	for i := a.pathIndex; i < len(a.cells); i++ {
+		if a.Crowd.Grid.IsBlocked(a.cells[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Agent) repath() bool {
+	a.path = a.path[:0]
+	a.cells = a.cells[:0]
+	a.pathIndex = 0
+	a.moving = false
+	if a.transform == nil || a.Crowd == nil || a.Crowd.Grid == nil {
+		return false
+	}
+	grid := *a.Crowd.Grid
+	start := a.Crowd.WorldToCell(a.transform.WorldPosition())
+	end := a.Crowd.WorldToCell(a.goal)
+	nodes := AStar(grid, start, end)
+	if len(nodes) == 0 {
+		return false
+	}
+	// The first node is the cell the agent is currently standing in
+	for _, n := range nodes[1:] {
+		a.cells = append(a.cells, n.XYZ())
+		a.path = append(a.path, a.Crowd.CellToWorld(n.XYZ()))
+	}
+	if len(a.path) == 0 {
+		a.path = append(a.path, a.goal)
+	} else if a.cells[len(a.cells)-1] == end {
+		a.path[len(a.path)-1] = a.goal
+	}
+	a.moving = true
+	a.OnRepath.Execute()
+	return true
+}
@@ -0,0 +1,119 @@
+/******************************************************************************/
+/* agent_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package navigation
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func TestSteeringArrive(t *testing.T) {
+	pos := matrix.Vec3Zero()
+	vel := matrix.Vec3Zero()
+	target := matrix.Vec3{5, 0, 0}
+	for i := 0; i < 1200; i++ {
+		force := Truncate(Arrive(pos, vel, target, 2, 1), 10)
+		vel = Truncate(vel.Add(force.Scale(1.0/60.0)), 2)
+		pos.AddAssign(vel.Scale(1.0 / 60.0))
+	}
+	if pos.Distance(target) > 0.05 {
+		t.Errorf("expected to arrive at %v but was at %v", target, pos)
+	}
+}
+
+func TestAvoidHeadOn(t *testing.T) {
+	obstacles := []Obstacle{{
+		Position: matrix.Vec3{2, 0, 0},
+		Velocity: matrix.Vec3{-1, 0, 0},
+		Radius:   0.5,
+	}}
+	preferred := matrix.Vec3{1, 0, 0}
+	v := Avoid(matrix.Vec3Zero(), preferred, preferred, 0.5, 1, 3, obstacles)
+	if matrix.Vec3Approx(v, preferred) {
+		t.Error("expected the velocity to change to avoid a head on collision")
+	}
+	rvo := v.Scale(2).Subtract(preferred).Subtract(obstacles[0].Velocity)
+	if ttc := TimeToCollision(obstacles[0].Position, rvo, 1); ttc < 1 {
+		t.Errorf("expected the new velocity to avoid collision, time was %f", ttc)
+	}
+}
+
+func TestAgentFollowsPath(t *testing.T) {
+	grid := NewGrid(8, 1, 8)
+	crowd := NewCrowd(&grid, matrix.Vec3Zero(), 1)
+	tr := matrix.NewTransform()
+	tr.SetPosition(matrix.Vec3{0.5, 0.5, 0.5})
+	agent := NewAgent(crowd)
+	agent.Attach(&tr)
+	arrived := false
+	agent.OnArrived.Add(func() { arrived = true })
+	target := matrix.Vec3{6.5, 0.5, 6.5}
+	if !agent.SetDestination(target) {
+		t.FailNow()
+	}
+	for i := 0; i < 60*20 && !arrived; i++ {
+		agent.Step(1.0 / 60.0)
+	}
+	if !arrived {
+		t.Errorf("expected the agent to arrive, it is at %v", tr.WorldPosition())
+	}
+}
+
+func TestAgentRepathsOnBlockedCell(t *testing.T) {
+	grid := NewGrid(8, 1, 8)
+	crowd := NewCrowd(&grid, matrix.Vec3Zero(), 1)
+	tr := matrix.NewTransform()
+	tr.SetPosition(matrix.Vec3{0.5, 0.5, 0.5})
+	agent := NewAgent(crowd)
+	agent.Attach(&tr)
+	repaths := 0
+	agent.OnRepath.Add(func() { repaths++ })
+	if !agent.SetDestination(matrix.Vec3{7.5, 0.5, 0.5}) {
+		t.FailNow()
+	}
+	grid.BlockCell(agent.cells[len(agent.cells)-2], 1)
+	agent.Step(1.0 / 60.0)
+	if repaths != 2 {
+		t.Errorf("expected the agent to repath, repath count was %d", repaths)
+	}
+	for _, c := range agent.cells {
+		if grid.IsBlocked(c) {
+			t.Errorf("the new path goes through the blocked cell %v", c)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/******************************************************************************/
+/* avoidance.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package navigation
+
+import (
+	"kaiju/matrix"
+	"math"
+)
+
+const (
+	avoidanceAngleSamples = 16
+	avoidanceSpeedSamples = 3
+	avoidanceWeight       = 1.0
+)
+
+// Obstacle describes a moving body that should be avoided by #Avoid
+type Obstacle struct {
+	Position matrix.Vec3
+	Velocity matrix.Vec3
+	Radius   matrix.Float
+}
+
+// Avoid selects a velocity close to the preferred velocity which avoids the
+// supplied obstacles within the time horizon. Obstacles are treated as
+// reciprocal velocity obstacles (RVO), each side is expected to take half of
+// the responsibility of avoiding the other, which prevents the oscillation
+// that occurs when each agent assumes the other will keep its velocity.
+//
+// Candidate velocities are sampled on the plane perpendicular to up, so this
+// is intended for agents that walk along the ground.
+func Avoid(position, velocity, preferred matrix.Vec3, radius, maxSpeed,
+	timeHorizon matrix.Float, obstacles []Obstacle) matrix.Vec3 {
+	preferred = Truncate(preferred, maxSpeed)
+	if len(obstacles) == 0 {
+		return preferred
+	}
+	best := preferred
+	bestPenalty := avoidancePenalty(position, velocity, preferred,
+		preferred, radius, timeHorizon, obstacles)
+	if bestPenalty == 0 {
+		return best
+	}
+	check := func(candidate matrix.Vec3) {
+		p := avoidancePenalty(position, velocity, candidate,
+			preferred, radius, timeHorizon, obstacles)
+		if p < bestPenalty {
+			best = candidate
+			bestPenalty = p
+		}
+	}
+	check(matrix.Vec3Zero())
+	for s := 1; s <= avoidanceSpeedSamples; s++ {
+		speed := maxSpeed * matrix.Float(s) / avoidanceSpeedSamples
+		for a := 0; a < avoidanceAngleSamples; a++ {
+			angle := matrix.Float(a) / avoidanceAngleSamples * 2 * math.Pi
+			check(matrix.Vec3{matrix.Cos(angle) * speed, 0, matrix.Sin(angle) * speed})
+		}
+	}
+	return best
+}
+
+// TimeToCollision returns the time until two spheres moving with a relative
+// velocity will touch. The relative position is the position of the other
+// sphere relative to the first, and the relative velocity is the velocity of
+// the first relative to the other. If the spheres never touch, then
+// +Inf is returned, if they are already touching 0 is returned.
+func TimeToCollision(relPosition, relVelocity matrix.Vec3, radius matrix.Float) matrix.Float {
+	c := matrix.Vec3Dot(relPosition, relPosition) - radius*radius
+	if c < 0 {
+		return 0
+	}
+	a := matrix.Vec3Dot(relVelocity, relVelocity)
+	b := matrix.Vec3Dot(relPosition, relVelocity)
+	if a <= steeringEpsilon || b <= 0 {
+		return matrix.Inf(1)
+	}
+	disc := b*b - a*c
+	if disc < 0 {
+		return matrix.Inf(1)
+	}
+	return (b - matrix.Sqrt(disc)) / a
+}
+
+func avoidancePenalty(position, velocity, candidate, preferred matrix.Vec3,
+	radius, timeHorizon matrix.Float, obstacles []Obstacle) matrix.Float {
+	minTime := matrix.Inf(1)
+	// Reciprocal velocity: the candidate is only given half the responsibility
+	rvo := candidate.Scale(2).Subtract(velocity)
+	for i := range obstacles {
+		o := &obstacles[i]
+		t := TimeToCollision(o.Position.Subtract(position),
+			rvo.Subtract(o.Velocity), radius+o.Radius)
+		minTime = matrix.Min(minTime, t)
+	}
+	penalty := candidate.Distance(preferred)
+	if minTime < timeHorizon {
+		penalty += avoidanceWeight / matrix.Max(minTime, steeringEpsilon)
+	}
+	return penalty
+}
@@ -0,0 +1,119 @@
+/******************************************************************************/
+/* crowd.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package navigation
+
+import (
+	"kaiju/klib"
+	"kaiju/matrix"
+	"slices"
+)
+
+// Crowd is a collection of agents that share a navigation grid. The crowd is
+// responsible for converting between world positions and grid cells, and for
+// supplying agents with their neighbors for local collision avoidance.
+type Crowd struct {
+	Grid     *Grid
+	Origin   matrix.Vec3
+	CellSize matrix.Float
+	agents   []*Agent
+}
+
+// NewCrowd creates a new crowd for the given grid. The origin is the world
+// position of the corner of cell (0, 0, 0) and the cell size is the world size
+// of a single cell along each axis.
+func NewCrowd(grid *Grid, origin matrix.Vec3, cellSize matrix.Float) *Crowd {
+	return &Crowd{
+		Grid:     grid,
+		Origin:   origin,
+		CellSize: cellSize,
+		agents:   make([]*Agent, 0),
+	}
+}
+
+// Agents returns all of the agents that are currently part of this crowd
+func (c *Crowd) Agents() []*Agent { return c.agents }
+
+// Add will add the agent to the crowd if it isn't already a member
+func (c *Crowd) Add(agent *Agent) {
+	c.agents = klib.AppendUnique(c.agents, agent)
+}
+
+// Remove will remove the agent from the crowd if it is a member
+func (c *Crowd) Remove(agent *Agent) {
+	if idx := slices.Index(c.agents, agent); idx >= 0 {
+		c.agents = klib.RemoveUnordered(c.agents, idx)
+	}
+}
+
+// WorldToCell converts a world position into the grid cell that contains it
+func (c *Crowd) WorldToCell(position matrix.Vec3) matrix.Vec3i {
+	local := position.Subtract(c.Origin).Shrink(c.CellSize)
+	return matrix.Vec3i{
+		int32(matrix.Floor(local.X())),
+		int32(matrix.Floor(local.Y())),
+		int32(matrix.Floor(local.Z())),
+	}
+}
+
+// CellToWorld converts a grid cell into the world position of its center
+func (c *Crowd) CellToWorld(cell matrix.Vec3i) matrix.Vec3 {
+	return c.Origin.Add(matrix.Vec3{
+		(matrix.Float(cell.X()) + 0.5) * c.CellSize,
+		(matrix.Float(cell.Y()) + 0.5) * c.CellSize,
+		(matrix.Float(cell.Z()) + 0.5) * c.CellSize,
+	})
+}
+
+func (c *Crowd) obstacles(agent *Agent, position matrix.Vec3,
+	radius matrix.Float, out []Obstacle) []Obstacle {
+	out = out[:0]
+	for _, other := range c.agents {
+		if other == agent || other.transform == nil {
+			continue
+		}
+		otherPos := other.transform.WorldPosition()
+		if otherPos.Distance(position) <= radius+other.Radius {
+			out = append(out, Obstacle{
+				Position: otherPos,
+				Velocity: other.velocity,
+				Radius:   other.Radius,
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* nav_agent.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package nav_agent
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/systems/debug"
+	"kaiju/systems/navigation"
+
+	"github.com/KaijuEngine/uuid"
+)
+
+// Agent is the entity data that drives a #navigation.Agent each frame using
+// the transform of the entity it is attached to. When DebugDraw is enabled,
+// the path the agent is following is drawn and is redrawn any time the agent
+// has to find a new path.
+type Agent struct {
+	*navigation.Agent
+	DebugDraw  bool
+	DebugColor matrix.Color
+	host       *engine.Host
+	updateId   int
+	debugPath  *rendering.ShaderDataBasic
+	// debugKey names the mesh of the debug path, it is kept for the life of
+	// the agent so that each new path replaces the mesh of the last one
+	debugKey string
+}
+
+// New creates a new agent entity data that will be a member of the crowd
+func New(crowd *navigation.Crowd) *Agent {
+	return &Agent{
+		Agent:      navigation.NewAgent(crowd),
+		DebugColor: matrix.ColorYellow(),
+		debugKey:   "nav_agent_" + uuid.New().String(),
+	}
+}
+
+// Init will attach the agent to the entity transform and start updating it on
+// the host. The agent is detached from its crowd when the entity is destroyed.
+func (a *Agent) Init(entity *engine.Entity, host *engine.Host) {
+	a.host = host
+	a.Attach(&entity.Transform)
+	a.OnRepath.Add(a.drawPath)
+	a.OnArrived.Add(a.clearPath)
+	a.updateId = host.Updater.AddUpdate(a.Step)
+	entity.OnDestroy.Add(func() {
+		host.Updater.RemoveUpdate(a.updateId)
+		a.clearPath()
+		a.Detach()
+	})
+}
+
+// Stop will clear the current path, bring the agent to a stop, and remove the
+// debug drawing of the path if there is one
+func (a *Agent) Stop() {
+	a.Agent.Stop()
+	a.clearPath()
+}
+
+func (a *Agent) drawPath() {
+	a.clearPath()
+	if !a.DebugDraw || a.host == nil {
+		return
+	}
+	points := append([]matrix.Vec3{a.Position()}, a.Path()...)
+	a.debugPath = debug.DrawPath(a.host, a.debugKey, points, a.DebugColor)
+}
+
+func (a *Agent) clearPath() {
+	if a.debugPath != nil {
+		a.debugPath.Destroy()
+		a.debugPath = nil
+	}
+}
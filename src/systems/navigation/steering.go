@@ -0,0 +1,105 @@
+/******************************************************************************/
+/* steering.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package navigation
+
+import "kaiju/matrix"
+
+const steeringEpsilon = 0.0001
+
+// Seek returns the steering force required to turn the current velocity
+// toward the target at full speed
+func Seek(position, velocity, target matrix.Vec3, maxSpeed matrix.Float) matrix.Vec3 {
+	desired := target.Subtract(position)
+	if desired.Length() <= steeringEpsilon {
+		return velocity.Negative()
+	}
+	return desired.Normal().Scale(maxSpeed).Subtract(velocity)
+}
+
+// Flee returns the steering force required to turn the current velocity
+// directly away from the target at full speed
+func Flee(position, velocity, target matrix.Vec3, maxSpeed matrix.Float) matrix.Vec3 {
+	desired := position.Subtract(target)
+	if desired.Length() <= steeringEpsilon {
+		return velocity.Negative()
+	}
+	return desired.Normal().Scale(maxSpeed).Subtract(velocity)
+}
+
+// Arrive behaves like #Seek until the position is within the slow radius of
+// the target, at which point the desired speed ramps down to 0 at the target
+func Arrive(position, velocity, target matrix.Vec3, maxSpeed, slowRadius matrix.Float) matrix.Vec3 {
+	desired := target.Subtract(position)
+	dist := desired.Length()
+	if dist <= steeringEpsilon {
+		return velocity.Negative()
+	}
+	speed := maxSpeed
+	if dist < slowRadius {
+		speed = maxSpeed * (dist / slowRadius)
+	}
+	return desired.Scale(speed / dist).Subtract(velocity)
+}
+
+// FollowPath steers along the points of a path starting at the point found at
+// index. When the position is within waypointRadius of the current point, the
+// index is advanced to the next point. The last point of the path uses
+// #Arrive so that the follower comes to a stop at the end of the path.
+func FollowPath(position, velocity matrix.Vec3, path []matrix.Vec3, index *int,
+	maxSpeed, waypointRadius, slowRadius matrix.Float) matrix.Vec3 {
+	if len(path) == 0 {
+		return velocity.Negative()
+	}
+	last := len(path) - 1
+	for *index < last && position.Distance(path[*index]) <= waypointRadius {
+		*index++
+	}
+	if *index >= last {
+		*index = last
+		return Arrive(position, velocity, path[last], maxSpeed, slowRadius)
+	}
+	return Seek(position, velocity, path[*index], maxSpeed)
+}
+
+// Truncate will limit the length of the vector to the max length supplied
+func Truncate(v matrix.Vec3, max matrix.Float) matrix.Vec3 {
+	if l := v.Length(); l > max && l > 0 {
+		return v.Scale(max / l)
+	}
+	return v
+}
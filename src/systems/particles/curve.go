@@ -0,0 +1,127 @@
+/******************************************************************************/
+/* curve.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"math/rand"
+)
+
+// Range is a span of values that a random value is picked from, a range with
+// the same min and max always gives that value
+type Range struct {
+	Min matrix.Float
+	Max matrix.Float
+}
+
+// Constant creates a range that always gives the value
+func Constant(value matrix.Float) Range { return Range{value, value} }
+
+// Random picks a value between the min and max of the range
+func (r Range) Random(rng *rand.Rand) matrix.Float {
+	if r.Min == r.Max {
+		return r.Min
+	}
+	return r.Min + (r.Max-r.Min)*matrix.Float(rng.Float64())
+}
+
+// CurveKey is a value on a #Curve at a time between 0 and 1
+type CurveKey struct {
+	Time  matrix.Float
+	Value matrix.Float
+}
+
+// Curve is a set of keys that are linearly interpolated between, the keys
+// are expected to be ordered by time. A curve without any keys evaluates to
+// 1 so that it can be used as a multiplier.
+type Curve struct {
+	Keys []CurveKey
+}
+
+// Evaluate returns the value of the curve at the time, times before the
+// first key or after the last key are given the value of that key
+func (c Curve) Evaluate(t matrix.Float) matrix.Float {
+	if len(c.Keys) == 0 {
+		return 1
+	}
+	a, b, f := curveSpan(len(c.Keys), t, func(i int) matrix.Float {
+		return c.Keys[i].Time
+	})
+	return c.Keys[a].Value + (c.Keys[b].Value-c.Keys[a].Value)*f
+}
+
+// ColorKey is a color on a #ColorCurve at a time between 0 and 1
+type ColorKey struct {
+	Time  matrix.Float
+	Color matrix.Color
+}
+
+// ColorCurve is a gradient of colors, like #Curve a curve without any keys
+// evaluates to white so that it can be used as a multiplier
+type ColorCurve struct {
+	Keys []ColorKey
+}
+
+// Evaluate returns the color of the curve at the time
+func (c ColorCurve) Evaluate(t matrix.Float) matrix.Color {
+	if len(c.Keys) == 0 {
+		return matrix.ColorWhite()
+	}
+	a, b, f := curveSpan(len(c.Keys), t, func(i int) matrix.Float {
+		return c.Keys[i].Time
+	})
+	return matrix.ColorMix(c.Keys[a].Color, c.Keys[b].Color, f)
+}
+
+// curveSpan finds the two keys around the time and how far between them the
+// time is
+func curveSpan(count int, t matrix.Float, time func(i int) matrix.Float) (int, int, matrix.Float) {
+	if t <= time(0) {
+		return 0, 0, 0
+	}
+	for i := 1; i < count; i++ {
+		if t <= time(i) {
+			span := time(i) - time(i-1)
+			if span <= 0 {
+				return i, i, 0
+			}
+			return i - 1, i, (t - time(i-1)) / span
+		}
+	}
+	return count - 1, count - 1, 0
+}
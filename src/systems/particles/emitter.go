@@ -0,0 +1,372 @@
+/******************************************************************************/
+/* emitter.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"cmp"
+	"kaiju/matrix"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// DefaultMaxParticles is the limit of live particles for an emitter that
+// does not set its own limit
+const DefaultMaxParticles = 1000
+
+// Space is the space that the particles of an emitter are simulated in
+type Space int
+
+const (
+	// SpaceWorld particles are left where they were emitted when the
+	// emitter moves, like smoke trailing behind a moving object
+	SpaceWorld Space = iota
+	// SpaceLocal particles move along with the emitter, like the flame
+	// of a torch that is being carried
+	SpaceLocal
+)
+
+// Burst emits a number of particles at once at a time in the cycle of the
+// emitter, bursts are emitted again each time a looping emitter restarts.
+// The bursts of an emitter are put in order of their time when the emitter is
+// created and each time that it is played.
+type Burst struct {
+	Time  matrix.Float
+	Count int
+}
+
+// EmitterSettings describes how an emitter spawns its particles. Angles are
+// in degrees, times are in seconds, and Direction is in the local space of
+// the emitter.
+type EmitterSettings struct {
+	// MaxParticles limits how many particles can be alive at once
+	MaxParticles int
+	// Duration is the length of a cycle of the emitter, when it is 0 the
+	// emitter does not stop and the bursts are only emitted once
+	Duration matrix.Float
+	Loop     bool
+	// SpawnRate is how many particles are emitted each second
+	SpawnRate matrix.Float
+	Bursts    []Burst
+	Lifetime  Range
+	Speed     Range
+	Direction matrix.Vec3
+	// Spread is the angle of the cone around the direction that particles
+	// are emitted into, 180 emits into every direction
+	Spread matrix.Float
+	// Radius is the radius of the sphere around the emitter that particles
+	// are spawned within
+	Radius          matrix.Float
+	StartSize       Range
+	StartRotation   Range
+	AngularVelocity Range
+	StartColor      matrix.Color
+	Space           Space
+	Modules         []Module
+}
+
+// Particle is a single particle of an emitter. The position and velocity are
+// in the space the emitter simulates in, see #Emitter.WorldPosition.
+type Particle struct {
+	Position        matrix.Vec3
+	Velocity        matrix.Vec3
+	Rotation        matrix.Float
+	AngularVelocity matrix.Float
+	Age             matrix.Float
+	Lifetime        matrix.Float
+	StartSize       matrix.Float
+	StartColor      matrix.Color
+	Size            matrix.Float
+	Color           matrix.Color
+	UVs             matrix.Vec4
+	Frame           int
+	startFrame      int
+}
+
+// Progress returns how far the particle is through its lifetime, from 0 to 1
+func (p *Particle) Progress() matrix.Float {
+	if p.Lifetime <= 0 {
+		return 1
+	}
+	return matrix.Min(p.Age/p.Lifetime, 1)
+}
+
+// Emitter spawns and simulates particles. The simulation is plain Go and
+// does not depend on the renderer, it is stepped with #Emitter.Update using
+// the world matrix of the emitter for that step.
+type Emitter struct {
+	Settings  EmitterSettings
+	particles []Particle
+	rng       *rand.Rand
+	world     matrix.Mat4
+	inverse   matrix.Mat4
+	time      matrix.Float
+	spawnDebt matrix.Float
+	nextBurst int
+	playing   bool
+}
+
+// NewEmitter creates an emitter that starts playing, the seed is used for
+// the random values of the particles so that a simulation can be repeated
+func NewEmitter(settings EmitterSettings, seed int64) *Emitter {
+	settings.Bursts = sortedBursts(settings.Bursts)
+	return &Emitter{
+		Settings: settings,
+		rng:      rand.New(rand.NewSource(seed)),
+		world:    matrix.Mat4Identity(),
+		inverse:  matrix.Mat4Identity(),
+		playing:  true,
+	}
+}
+
+// NewEmitterSettings returns settings for a small fountain of white
+// particles that can be changed from there
+func NewEmitterSettings() EmitterSettings {
+	return EmitterSettings{
+		MaxParticles:  DefaultMaxParticles,
+		Duration:      5,
+		Loop:          true,
+		SpawnRate:     10,
+		Lifetime:      Constant(2),
+		Speed:         Constant(2),
+		Direction:     matrix.Vec3Up(),
+		Spread:        25,
+		StartSize:     Constant(0.25),
+		StartColor:    matrix.ColorWhite(),
+		StartRotation: Constant(0),
+	}
+}
+
+// Particles returns the live particles, the slice is only valid until the
+// next update of the emitter
+func (e *Emitter) Particles() []Particle { return e.particles }
+
+// IsPlaying returns true if the emitter is spawning particles
+func (e *Emitter) IsPlaying() bool { return e.playing }
+
+// IsAlive returns true if the emitter is playing or still has live particles
+func (e *Emitter) IsAlive() bool { return e.playing || len(e.particles) > 0 }
+
+// Play starts the emitter from the beginning of its cycle
+func (e *Emitter) Play() {
+	e.playing = true
+	e.time = 0
+	e.spawnDebt = 0
+	e.nextBurst = 0
+	e.Settings.Bursts = sortedBursts(e.Settings.Bursts)
+}
+
+// Stop stops spawning particles, the live particles finish their lifetime
+func (e *Emitter) Stop() { e.playing = false }
+
+// Clear removes all of the live particles
+func (e *Emitter) Clear() { e.particles = e.particles[:0] }
+
+// Emit spawns the number of particles right away, even if the emitter is not
+// playing, and returns how many were spawned within the particle limit
+func (e *Emitter) Emit(count int) int {
+	count = max(0, min(count, e.maxParticles()-len(e.particles)))
+	for i := 0; i < count; i++ {
+		e.spawn()
+	}
+	return count
+}
+
+// WorldPosition returns the position of the particle in world space
+func (e *Emitter) WorldPosition(p *Particle) matrix.Vec3 {
+	if e.Settings.Space == SpaceLocal {
+		return e.world.TransformPoint(p.Position)
+	}
+	return p.Position
+}
+
+// Update steps the simulation forward, the world matrix is the transform of
+// the emitter for this step. Particles that reach the end of their lifetime
+// are removed, live particles are run through the modules and moved, then
+// new particles are spawned.
+func (e *Emitter) Update(deltaTime matrix.Float, world matrix.Mat4) {
+	e.world = world
+	e.inverse = world
+	e.inverse.Inverse()
+	modules := e.Settings.Modules
+	for i := 0; i < len(e.particles); i++ {
+		p := &e.particles[i]
+		p.Age += deltaTime
+		if p.Age >= p.Lifetime {
+			last := len(e.particles) - 1
+			e.particles[i] = e.particles[last]
+			e.particles = e.particles[:last]
+			i--
+			continue
+		}
+		for _, m := range modules {
+			m.Update(e, p, deltaTime)
+		}
+		p.Position.AddAssign(p.Velocity.Scale(deltaTime))
+		p.Rotation += p.AngularVelocity * deltaTime
+	}
+	if e.playing {
+		e.advance(deltaTime)
+	}
+}
+
+// advance moves the time of the emitter forward, spawning the particles of
+// the spawn rate and any bursts that are passed along the way
+func (e *Emitter) advance(deltaTime matrix.Float) {
+	s := &e.Settings
+	e.spawnDebt += s.SpawnRate * deltaTime
+	spawns := int(e.spawnDebt)
+	e.spawnDebt -= matrix.Float(spawns)
+	end := e.time + deltaTime
+	for s.Duration > 0 && end >= s.Duration {
+		spawns += e.burstsUntil(s.Duration)
+		if !s.Loop {
+			e.playing = false
+			e.spawnDebt = 0
+			e.Emit(spawns)
+			return
+		}
+		end -= s.Duration
+		e.nextBurst = 0
+	}
+	spawns += e.burstsUntil(end)
+	e.time = end
+	e.Emit(spawns)
+}
+
+// burstsUntil counts the particles of the bursts in the cycle up to the time
+// that have not been emitted yet
+func (e *Emitter) burstsUntil(t matrix.Float) int {
+	count := 0
+	for i, b := range e.Settings.Bursts {
+		if i >= e.nextBurst && b.Time <= t {
+			count += b.Count
+			e.nextBurst = i + 1
+		}
+	}
+	return count
+}
+
+// sortedBursts returns the bursts in order of their time, the slice is only
+// copied when it is out of order so that the settings passed in are not changed
+func sortedBursts(bursts []Burst) []Burst {
+	byTime := func(a, b Burst) int { return cmp.Compare(a.Time, b.Time) }
+	if slices.IsSortedFunc(bursts, byTime) {
+		return bursts
+	}
+	sorted := slices.Clone(bursts)
+	slices.SortStableFunc(sorted, byTime)
+	return sorted
+}
+
+func (e *Emitter) maxParticles() int {
+	if e.Settings.MaxParticles <= 0 {
+		return DefaultMaxParticles
+	}
+	return e.Settings.MaxParticles
+}
+
+func (e *Emitter) spawn() {
+	s := &e.Settings
+	p := Particle{
+		Lifetime:        s.Lifetime.Random(e.rng),
+		StartSize:       s.StartSize.Random(e.rng),
+		StartColor:      s.StartColor,
+		Rotation:        s.StartRotation.Random(e.rng),
+		AngularVelocity: s.AngularVelocity.Random(e.rng),
+		UVs:             matrix.Vec4{0, 0, 1, 1},
+	}
+	p.Size = p.StartSize
+	p.Color = p.StartColor
+	p.Position = e.randomInSphere(s.Radius)
+	p.Velocity = e.randomInCone(s.Direction, s.Spread).Scale(s.Speed.Random(e.rng))
+	if s.Space == SpaceWorld {
+		p.Position = e.world.TransformPoint(p.Position)
+		p.Velocity = transformVector(e.world, p.Velocity)
+	}
+	e.particles = append(e.particles, p)
+	particle := &e.particles[len(e.particles)-1]
+	for _, m := range s.Modules {
+		m.Spawn(e, particle)
+	}
+}
+
+// simulationVector moves a world space vector into the space the particles
+// are simulated in
+func (e *Emitter) simulationVector(v matrix.Vec3) matrix.Vec3 {
+	if e.Settings.Space == SpaceLocal {
+		return transformVector(e.inverse, v)
+	}
+	return v
+}
+
+func (e *Emitter) randomInSphere(radius matrix.Float) matrix.Vec3 {
+	if radius <= 0 {
+		return matrix.Vec3Zero()
+	}
+	dir := e.randomInCone(matrix.Vec3Up(), 180)
+	r := radius * matrix.Float(math.Cbrt(e.rng.Float64()))
+	return dir.Scale(r)
+}
+
+// randomInCone picks a direction that is at most spread degrees away from
+// the direction, the directions are spread evenly over the cone
+func (e *Emitter) randomInCone(direction matrix.Vec3, spread matrix.Float) matrix.Vec3 {
+	if direction.Length() == 0 {
+		direction = matrix.Vec3Up()
+	}
+	direction.Normalize()
+	spread = matrix.Clamp(spread, 0, 180)
+	if spread == 0 {
+		return direction
+	}
+	cosSpread := matrix.Cos(matrix.Deg2Rad(spread))
+	z := 1 - matrix.Float(e.rng.Float64())*(1-cosSpread)
+	r := matrix.Sqrt(matrix.Max(0, 1-z*z))
+	phi := matrix.Float(e.rng.Float64()) * 2 * math.Pi
+	side := direction.Orthogonal().Normal()
+	up := matrix.Vec3Cross(direction, side)
+	return direction.Scale(z).
+		Add(side.Scale(r * matrix.Cos(phi))).
+		Add(up.Scale(r * matrix.Sin(phi)))
+}
+
+func transformVector(m matrix.Mat4, v matrix.Vec3) matrix.Vec3 {
+	r := matrix.Mat4MultiplyVec4(m, matrix.Vec4{v.X(), v.Y(), v.Z(), 0})
+	return matrix.Vec3{r.X(), r.Y(), r.Z()}
+}
@@ -0,0 +1,200 @@
+/******************************************************************************/
+/* emitter_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func testEmitterSettings() EmitterSettings {
+	return EmitterSettings{
+		Lifetime:   Constant(10),
+		Speed:      Constant(1),
+		Direction:  matrix.Vec3Up(),
+		StartSize:  Constant(1),
+		StartColor: matrix.ColorWhite(),
+	}
+}
+
+func testEmitterStep(e *Emitter, steps int, deltaTime matrix.Float) {
+	for i := 0; i < steps; i++ {
+		e.Update(deltaTime, matrix.Mat4Identity())
+	}
+}
+
+func TestEmitterSpawnRate(t *testing.T) {
+	s := testEmitterSettings()
+	s.SpawnRate = 10
+	e := NewEmitter(s, 0)
+	testEmitterStep(e, 10, 0.1)
+	if len(e.Particles()) != 10 {
+		t.Errorf("expected 10 particles after a second, got %d", len(e.Particles()))
+	}
+	e.Stop()
+	testEmitterStep(e, 10, 0.1)
+	if len(e.Particles()) != 10 {
+		t.Errorf("expected a stopped emitter to not spawn, got %d", len(e.Particles()))
+	}
+}
+
+func TestEmitterBurstsLoop(t *testing.T) {
+	s := testEmitterSettings()
+	s.Duration = 1
+	s.Loop = true
+	s.Bursts = []Burst{{Time: 0, Count: 5}, {Time: 0.5, Count: 2}}
+	e := NewEmitter(s, 0)
+	e.Update(0.1, matrix.Mat4Identity())
+	if len(e.Particles()) != 5 {
+		t.Errorf("expected the first burst, got %d", len(e.Particles()))
+	}
+	e.Update(0.5, matrix.Mat4Identity())
+	if len(e.Particles()) != 7 {
+		t.Errorf("expected the second burst, got %d", len(e.Particles()))
+	}
+	e.Update(0.5, matrix.Mat4Identity())
+	if len(e.Particles()) != 12 {
+		t.Errorf("expected the first burst again after looping, got %d", len(e.Particles()))
+	}
+}
+
+func TestEmitterBurstsOutOfOrder(t *testing.T) {
+	s := testEmitterSettings()
+	s.Duration = 1
+	s.Bursts = []Burst{{Time: 0.5, Count: 2}, {Time: 0, Count: 5}}
+	e := NewEmitter(s, 0)
+	e.Update(0.1, matrix.Mat4Identity())
+	if len(e.Particles()) != 5 {
+		t.Errorf("expected the earliest burst first, got %d", len(e.Particles()))
+	}
+	e.Update(0.5, matrix.Mat4Identity())
+	if len(e.Particles()) != 7 {
+		t.Errorf("expected the later burst, got %d", len(e.Particles()))
+	}
+	if s.Bursts[0].Time != 0.5 {
+		t.Errorf("expected the bursts of the settings to not be reordered")
+	}
+}
+
+func TestEmitterFinishes(t *testing.T) {
+	s := testEmitterSettings()
+	s.Duration = 1
+	s.Lifetime = Constant(0.5)
+	s.Bursts = []Burst{{Time: 0.25, Count: 3}}
+	e := NewEmitter(s, 0)
+	testEmitterStep(e, 4, 0.1)
+	if len(e.Particles()) != 3 {
+		t.Errorf("expected the burst, got %d", len(e.Particles()))
+	}
+	testEmitterStep(e, 10, 0.1)
+	if e.IsPlaying() || len(e.Particles()) != 0 || e.IsAlive() {
+		t.Errorf("expected the emitter to finish after its duration and the particle lifetime")
+	}
+	e.Play()
+	testEmitterStep(e, 4, 0.1)
+	if len(e.Particles()) != 3 {
+		t.Errorf("expected the emitter to play again, got %d", len(e.Particles()))
+	}
+}
+
+func TestEmitterMaxParticles(t *testing.T) {
+	s := testEmitterSettings()
+	s.MaxParticles = 4
+	e := NewEmitter(s, 0)
+	if n := e.Emit(10); n != 4 || len(e.Particles()) != 4 {
+		t.Errorf("expected the emit to be limited to 4, got %d", n)
+	}
+}
+
+func TestEmitterSimulationSpace(t *testing.T) {
+	for _, space := range []Space{SpaceWorld, SpaceLocal} {
+		s := testEmitterSettings()
+		s.Speed = Constant(0)
+		s.Space = space
+		e := NewEmitter(s, 0)
+		e.Emit(1)
+		moved := matrix.Mat4Identity()
+		moved.SetTranslation(matrix.Vec3{5, 0, 0})
+		e.Update(0.1, moved)
+		pos := e.WorldPosition(&e.Particles()[0])
+		expected := matrix.Vec3Zero()
+		if space == SpaceLocal {
+			expected = matrix.Vec3{5, 0, 0}
+		}
+		if !matrix.Vec3Approx(pos, expected) {
+			t.Errorf("expected the particle at %v in space %d, got %v", expected, space, pos)
+		}
+	}
+}
+
+func TestEmitterVelocity(t *testing.T) {
+	s := testEmitterSettings()
+	s.Speed = Constant(2)
+	e := NewEmitter(s, 0)
+	e.Emit(1)
+	e.Update(0.5, matrix.Mat4Identity())
+	p := e.Particles()[0]
+	if !matrix.Vec3Approx(p.Velocity, matrix.Vec3{0, 2, 0}) ||
+		!matrix.Vec3Approx(p.Position, matrix.Vec3{0, 1, 0}) {
+		t.Errorf("expected the particle to move up, got %v at %v", p.Velocity, p.Position)
+	}
+	s.Spread = 30
+	e = NewEmitter(s, 0)
+	e.Emit(100)
+	limit := matrix.Cos(matrix.Deg2Rad(30)) - 0.0001
+	for _, p := range e.Particles() {
+		if matrix.Vec3Dot(p.Velocity.Normal(), matrix.Vec3Up()) < limit {
+			t.Fatalf("expected the particles within the spread, got %v", p.Velocity)
+		}
+	}
+}
+
+func TestEmitterDeterministic(t *testing.T) {
+	s := testEmitterSettings()
+	s.Spread = 90
+	s.Radius = 2
+	s.Lifetime = Range{1, 5}
+	a, b := NewEmitter(s, 42), NewEmitter(s, 42)
+	a.Emit(20)
+	b.Emit(20)
+	for i := range a.Particles() {
+		if a.Particles()[i] != b.Particles()[i] {
+			t.Fatalf("expected the same seed to give the same particles")
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/******************************************************************************/
+/* module.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"encoding/gob"
+	"kaiju/matrix"
+)
+
+func init() {
+	gob.Register(&Gravity{})
+	gob.Register(&Drag{})
+	gob.Register(&SizeOverLifetime{})
+	gob.Register(&ColorOverLifetime{})
+	gob.Register(&SpriteAnimation{})
+}
+
+// Module changes the particles of an emitter over their lifetime. Spawn is
+// called once when a particle is emitted and Update is called each step of
+// the simulation before the particle is moved by its velocity. Modules are
+// run in the order they were added to the emitter settings.
+type Module interface {
+	Spawn(emitter *Emitter, particle *Particle)
+	Update(emitter *Emitter, particle *Particle, deltaTime matrix.Float)
+}
+
+// Gravity accelerates the particles, the acceleration is in world space
+// even when the particles are simulated in the local space of the emitter
+type Gravity struct {
+	Acceleration matrix.Vec3
+}
+
+func (m *Gravity) Spawn(*Emitter, *Particle) {}
+
+func (m *Gravity) Update(e *Emitter, p *Particle, deltaTime matrix.Float) {
+	p.Velocity.AddAssign(e.simulationVector(m.Acceleration).Scale(deltaTime))
+}
+
+// Drag slows the particles down, an amount of 1 removes about all of the
+// velocity of a particle within a second
+type Drag struct {
+	Amount matrix.Float
+}
+
+func (m *Drag) Spawn(*Emitter, *Particle) {}
+
+func (m *Drag) Update(_ *Emitter, p *Particle, deltaTime matrix.Float) {
+	p.Velocity.ScaleAssign(matrix.Max(0, 1-m.Amount*deltaTime))
+}
+
+// SizeOverLifetime multiplies the start size of the particles by the curve
+// over the lifetime of the particle
+type SizeOverLifetime struct {
+	Curve Curve
+}
+
+func (m *SizeOverLifetime) Spawn(_ *Emitter, p *Particle) {
+	p.Size = p.StartSize * m.Curve.Evaluate(0)
+}
+
+func (m *SizeOverLifetime) Update(_ *Emitter, p *Particle, _ matrix.Float) {
+	p.Size = p.StartSize * m.Curve.Evaluate(p.Progress())
+}
+
+// ColorOverLifetime multiplies the start color of the particles by the
+// curve over the lifetime of the particle
+type ColorOverLifetime struct {
+	Curve ColorCurve
+}
+
+func (m *ColorOverLifetime) Spawn(_ *Emitter, p *Particle) {
+	p.Color = multiplyColor(p.StartColor, m.Curve.Evaluate(0))
+}
+
+func (m *ColorOverLifetime) Update(_ *Emitter, p *Particle, _ matrix.Float) {
+	p.Color = multiplyColor(p.StartColor, m.Curve.Evaluate(p.Progress()))
+}
+
+// SpriteAnimation steps the particles through the frames of a sprite sheet.
+// Frames are the UV rectangles (x, y, width, height) of each frame. When the
+// frame rate is 0 the frames are spread over the lifetime of the particle,
+// otherwise the frames loop at the frame rate. RandomStart starts each
+// particle on a random frame.
+type SpriteAnimation struct {
+	Frames      []matrix.Vec4
+	FrameRate   matrix.Float
+	RandomStart bool
+}
+
+func (m *SpriteAnimation) Spawn(e *Emitter, p *Particle) {
+	if len(m.Frames) == 0 {
+		return
+	}
+	p.startFrame = 0
+	if m.RandomStart {
+		p.startFrame = e.rng.Intn(len(m.Frames))
+	}
+	p.Frame = p.startFrame
+	p.UVs = m.Frames[p.Frame]
+}
+
+func (m *SpriteAnimation) Update(_ *Emitter, p *Particle, _ matrix.Float) {
+	count := len(m.Frames)
+	if count == 0 {
+		return
+	}
+	var frame int
+	if m.FrameRate <= 0 {
+		frame = min(int(p.Progress()*matrix.Float(count)), count-1)
+	} else {
+		frame = int(p.Age * m.FrameRate)
+	}
+	p.Frame = (frame + p.startFrame) % count
+	p.UVs = m.Frames[p.Frame]
+}
+
+func multiplyColor(a, b matrix.Color) matrix.Color {
+	return matrix.Color{a.R() * b.R(), a.G() * b.G(), a.B() * b.B(), a.A() * b.A()}
+}
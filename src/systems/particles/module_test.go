@@ -0,0 +1,140 @@
+/******************************************************************************/
+/* module_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func TestCurveEvaluate(t *testing.T) {
+	c := Curve{Keys: []CurveKey{{0, 0}, {0.5, 1}, {1, 0.5}}}
+	tests := []struct{ t, expected matrix.Float }{
+		{-1, 0}, {0.25, 0.5}, {0.5, 1}, {0.75, 0.75}, {2, 0.5},
+	}
+	for _, test := range tests {
+		if v := c.Evaluate(test.t); matrix.Abs(v-test.expected) > 0.0001 {
+			t.Errorf("expected %f at %f, got %f", test.expected, test.t, v)
+		}
+	}
+	if (Curve{}).Evaluate(0.5) != 1 {
+		t.Errorf("expected an empty curve to evaluate to 1")
+	}
+	cc := ColorCurve{Keys: []ColorKey{{0, matrix.ColorWhite()}, {1, matrix.ColorClear()}}}
+	if c := cc.Evaluate(0.5); matrix.Abs(c.A()-0.5) > 0.0001 || matrix.Abs(c.R()-0.5) > 0.0001 {
+		t.Errorf("expected the color to be halfway, got %v", c)
+	}
+}
+
+func TestModulesOverLifetime(t *testing.T) {
+	s := testEmitterSettings()
+	s.Speed = Constant(0)
+	s.Lifetime = Constant(1)
+	s.StartSize = Constant(2)
+	s.Modules = []Module{
+		&Gravity{Acceleration: matrix.Vec3{0, -10, 0}},
+		&SizeOverLifetime{Curve: Curve{Keys: []CurveKey{{0, 1}, {1, 0}}}},
+		&ColorOverLifetime{Curve: ColorCurve{Keys: []ColorKey{
+			{0, matrix.ColorWhite()}, {1, matrix.ColorClear()}}}},
+	}
+	e := NewEmitter(s, 0)
+	e.Emit(1)
+	if p := e.Particles()[0]; p.Size != 2 || p.Color != matrix.ColorWhite() {
+		t.Errorf("expected the start size and color on spawn, got %f %v", p.Size, p.Color)
+	}
+	e.Update(0.5, matrix.Mat4Identity())
+	p := e.Particles()[0]
+	if matrix.Abs(p.Size-1) > 0.0001 || matrix.Abs(p.Color.A()-0.5) > 0.0001 {
+		t.Errorf("expected half the size and alpha, got %f %v", p.Size, p.Color)
+	}
+	if !matrix.Vec3Approx(p.Velocity, matrix.Vec3{0, -5, 0}) {
+		t.Errorf("expected gravity to accelerate the particle, got %v", p.Velocity)
+	}
+}
+
+func TestGravityLocalSpace(t *testing.T) {
+	s := testEmitterSettings()
+	s.Speed = Constant(0)
+	s.Space = SpaceLocal
+	s.Modules = []Module{&Gravity{Acceleration: matrix.Vec3{0, -1, 0}}}
+	e := NewEmitter(s, 0)
+	e.Emit(1)
+	world := matrix.Mat4Identity()
+	world.RotateZ(90)
+	e.Update(1, world)
+	down := e.WorldPosition(&e.Particles()[0]).Subtract(world.Position())
+	if !matrix.Vec3Approx(down, matrix.Vec3{0, -1, 0}) {
+		t.Errorf("expected gravity to pull down in world space, got %v", down)
+	}
+}
+
+func TestDrag(t *testing.T) {
+	s := testEmitterSettings()
+	s.Speed = Constant(4)
+	s.Modules = []Module{&Drag{Amount: 0.5}}
+	e := NewEmitter(s, 0)
+	e.Emit(1)
+	e.Update(1, matrix.Mat4Identity())
+	if v := e.Particles()[0].Velocity.Length(); matrix.Abs(v-2) > 0.0001 {
+		t.Errorf("expected drag to halve the speed, got %f", v)
+	}
+}
+
+func TestSpriteAnimation(t *testing.T) {
+	frames := []matrix.Vec4{{0, 0, 0.5, 1}, {0.5, 0, 0.5, 1}}
+	s := testEmitterSettings()
+	s.Lifetime = Constant(1)
+	s.Modules = []Module{&SpriteAnimation{Frames: frames}}
+	e := NewEmitter(s, 0)
+	e.Emit(1)
+	if e.Particles()[0].UVs != frames[0] {
+		t.Errorf("expected the first frame on spawn")
+	}
+	e.Update(0.6, matrix.Mat4Identity())
+	if p := e.Particles()[0]; p.Frame != 1 || p.UVs != frames[1] {
+		t.Errorf("expected the frames to be spread over the lifetime, got %d", p.Frame)
+	}
+	s.Lifetime = Constant(10)
+	s.Modules = []Module{&SpriteAnimation{Frames: frames, FrameRate: 4}}
+	e = NewEmitter(s, 0)
+	e.Emit(1)
+	testEmitterStep(e, 4, 0.125)
+	if p := e.Particles()[0]; p.Frame != 0 {
+		t.Errorf("expected the frames to loop at the frame rate, got %d", p.Frame)
+	}
+}
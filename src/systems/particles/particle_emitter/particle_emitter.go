@@ -0,0 +1,190 @@
+/******************************************************************************/
+/* particle_emitter.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particle_emitter
+
+import (
+	"kaiju/assets"
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"kaiju/systems/particles"
+	"kaiju/systems/visual2d/sprite"
+	"log/slog"
+	"slices"
+)
+
+// Emitter is the entity data that simulates a #particles.Emitter at the
+// transform of the entity it is attached to. Each live particle is drawn as a
+// camera facing quad, all of the particles of the emitter share the texture
+// so they are drawn together as instances of a single draw instance group.
+//
+// When a SpriteSheet is given, the frames of the clip in the sheet are added
+// as a #particles.SpriteAnimation module to animate the particles.
+type Emitter struct {
+	Settings    particles.EmitterSettings
+	Seed        int64
+	Texture     string
+	SpriteSheet string
+	Clip        string
+	FrameRate   matrix.Float
+	emitter     *particles.Emitter
+	entity      *engine.Entity
+	instances   []sprite.ShaderData
+	updateId    int
+}
+
+// New creates a new particle emitter entity data with the settings
+func New(settings particles.EmitterSettings) *Emitter {
+	return &Emitter{
+		Settings: settings,
+		Texture:  assets.TextureSquare,
+	}
+}
+
+// Simulation returns the emitter that was created when the entity data was
+// initialized, it can be used to play, stop, or change the settings
+func (e *Emitter) Simulation() *particles.Emitter { return e.emitter }
+
+// Init creates the simulation and the instances that draw the particles, the
+// simulation is stepped in the late update so that it uses the transform of
+// the entity after it has been moved for the frame
+func (e *Emitter) Init(entity *engine.Entity, host *engine.Host) {
+	e.entity = entity
+	texKey := e.Texture
+	if texKey == "" {
+		texKey = assets.TextureSquare
+	}
+	tex, err := host.TextureCache().Texture(texKey, rendering.TextureFilterLinear)
+	if err != nil {
+		slog.Error(err.Error(), slog.String("texture", texKey))
+		return
+	}
+	settings := e.Settings
+	if e.SpriteSheet != "" {
+		if frames, err := readClip(host, e.SpriteSheet, e.Clip, tex); err != nil {
+			slog.Error(err.Error(), slog.String("spriteSheet", e.SpriteSheet))
+		} else {
+			settings.Modules = append(slices.Clone(settings.Modules),
+				&particles.SpriteAnimation{Frames: frames, FrameRate: e.FrameRate})
+		}
+	}
+	if settings.MaxParticles <= 0 {
+		settings.MaxParticles = particles.DefaultMaxParticles
+	}
+	e.emitter = particles.NewEmitter(settings, e.Seed)
+	e.createInstances(host, tex)
+	e.updateId = host.LateUpdater.AddUpdate(e.update)
+	entity.OnDestroy.Add(func() {
+		host.LateUpdater.RemoveUpdate(e.updateId)
+		for i := range e.instances {
+			e.instances[i].Destroy()
+		}
+	})
+}
+
+// createInstances creates a drawing for as many particles as the emitter can
+// have alive, the instances of particles that are not alive are deactivated
+func (e *Emitter) createInstances(host *engine.Host, tex *rendering.Texture) {
+	shader := host.ShaderCache().ShaderFromDefinition(assets.ShaderDefinitionParticle)
+	mesh := rendering.NewMeshQuad(host.MeshCache())
+	e.instances = make([]sprite.ShaderData, e.emitter.Settings.MaxParticles)
+	drawings := make([]rendering.Drawing, len(e.instances))
+	for i := range e.instances {
+		e.instances[i] = sprite.ShaderData{
+			ShaderDataBase: rendering.NewShaderDataBase(),
+			UVs:            matrix.Vec4{0, 0, 1, 1},
+			FgColor:        matrix.ColorWhite(),
+		}
+		e.instances[i].Deactivate()
+		drawings[i] = rendering.Drawing{
+			Renderer:    host.Window.Renderer,
+			Shader:      shader,
+			Mesh:        mesh,
+			Textures:    []*rendering.Texture{tex},
+			ShaderData:  &e.instances[i],
+			UseBlending: true,
+		}
+	}
+	host.Drawings.AddDrawings(drawings, host.Window.Renderer.DefaultCanvas())
+}
+
+func (e *Emitter) update(deltaTime float64) {
+	live := 0
+	if e.entity.IsActive() {
+		e.emitter.Update(matrix.Float(deltaTime), e.entity.Transform.WorldMatrix())
+		live = len(e.emitter.Particles())
+	}
+	all := e.emitter.Particles()
+	for i := range e.instances {
+		sd := &e.instances[i]
+		if i >= live {
+			sd.Deactivate()
+			continue
+		}
+		p := &all[i]
+		sd.Activate()
+		sd.SetModel(particleModel(e.emitter.WorldPosition(p), p.Size, p.Rotation))
+		sd.UVs = p.UVs
+		sd.FgColor = p.Color
+	}
+}
+
+// particleModel packs the position, size, and roll of a particle into a
+// model matrix, the particle shader turns the quad to face the camera
+func particleModel(position matrix.Vec3, size, rotation matrix.Float) matrix.Mat4 {
+	r := matrix.Deg2Rad(rotation)
+	c, s := matrix.Cos(r)*size, matrix.Sin(r)*size
+	return matrix.Mat4{
+		c, s, 0, 0,
+		-s, c, 0, 0,
+		0, 0, size, 0,
+		position.X(), position.Y(), position.Z(), 1,
+	}
+}
+
+func readClip(host *engine.Host, key, clip string, tex *rendering.Texture) ([]matrix.Vec4, error) {
+	jsonStr, err := host.AssetDatabase().ReadText(key)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := sprite.ReadSpriteSheetData(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+	return sheet.ClipUVs(clip, tex.Size()), nil
+}
@@ -0,0 +1,108 @@
+/******************************************************************************/
+/* skybox.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package skybox
+
+import (
+	"kaiju/assets"
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"log/slog"
+)
+
+// Skybox is the entity data for a cube map that is drawn behind everything
+// else in the scene. The skybox can also be used as the environment that the
+// basic shader reflects, the strength of the reflections is the intensity.
+type Skybox struct {
+	// CubeMap is the key of the cube map definition to draw
+	CubeMap     string
+	Color       matrix.Color
+	Reflections bool
+	Intensity   matrix.Float
+	texture     *rendering.Texture
+	shaderData  rendering.ShaderDataBasic
+}
+
+func New(cubeMapKey string) *Skybox {
+	return &Skybox{
+		CubeMap:     cubeMapKey,
+		Color:       matrix.ColorWhite(),
+		Reflections: true,
+		Intensity:   1,
+	}
+}
+
+// Texture returns the cube map texture that was loaded when the entity data
+// was initialized, nil if the cube map failed to load
+func (s *Skybox) Texture() *rendering.Texture { return s.texture }
+
+func (s *Skybox) Init(entity *engine.Entity, host *engine.Host) {
+	tex, err := host.TextureCache().CubeMap(s.CubeMap, rendering.TextureFilterLinear)
+	if err != nil {
+		slog.Error("failed to load the skybox cube map",
+			slog.String("cubeMap", s.CubeMap), slog.String("error", err.Error()))
+		return
+	}
+	s.texture = tex
+	s.shaderData = rendering.ShaderDataBasic{
+		ShaderDataBase: rendering.NewShaderDataBase(),
+		Color:          s.Color,
+	}
+	renderer := host.Window.Renderer
+	host.Drawings.AddDrawings([]rendering.Drawing{{
+		Renderer:   renderer,
+		Shader:     host.ShaderCache().ShaderFromDefinition(assets.ShaderDefinitionSkybox),
+		Mesh:       rendering.NewMeshSkyboxCube(host.MeshCache()),
+		Textures:   []*rendering.Texture{tex},
+		ShaderData: &s.shaderData,
+	}}, renderer.DefaultCanvas())
+	if !entity.IsActive() {
+		s.shaderData.Deactivate()
+	}
+	if s.Reflections {
+		renderer.SetEnvironment(tex, s.Intensity)
+	}
+	entity.OnActivate.Add(func() { s.shaderData.Activate() })
+	entity.OnDeactivate.Add(func() { s.shaderData.Deactivate() })
+	entity.OnDestroy.Add(func() {
+		s.shaderData.Destroy()
+		if env, _ := renderer.Environment(); env == tex {
+			renderer.SetEnvironment(nil, 0)
+		}
+	})
+}
@@ -196,14 +196,7 @@ func (s *Sprite) update(deltaTime float64) {
 
 func (s *Sprite) setSheetFrame(frame int) {
 	s.clipIdx = frame
-	f := s.currentClip[frame]
-	h := float32(f.Frame.H) / s.texture.Size().Height()
-	s.shaderData.UVs = matrix.Vec4{
-		float32(f.Frame.X) / s.texture.Size().Width(),
-		1.0 - h - float32(f.Frame.Y)/s.texture.Size().Height(),
-		float32(f.Frame.W) / s.texture.Size().Width(),
-		h,
-	}
+	s.shaderData.UVs = s.currentClip[frame].uvs(s.texture.Size())
 }
 
 func (s *Sprite) Activate() {
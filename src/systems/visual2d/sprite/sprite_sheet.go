@@ -40,6 +40,7 @@ package sprite
 import (
 	"encoding/json"
 	"kaiju/klib"
+	"kaiju/matrix"
 	"strconv"
 	"strings"
 	"unicode"
@@ -82,6 +83,30 @@ type spriteSheet struct {
 	clips map[string][]spriteSheetFrameData
 }
 
+// uvs returns the rectangle of the frame within a texture of the given size
+// as (x, y, width, height) texture coordinates
+func (f spriteSheetFrameData) uvs(textureSize matrix.Vec2) matrix.Vec4 {
+	h := float32(f.Frame.H) / textureSize.Height()
+	return matrix.Vec4{
+		float32(f.Frame.X) / textureSize.Width(),
+		1.0 - h - float32(f.Frame.Y)/textureSize.Height(),
+		float32(f.Frame.W) / textureSize.Width(),
+		h,
+	}
+}
+
+// ClipUVs returns the texture coordinates of each frame of the clip for a
+// texture of the given size, this is the form the frames of a particle
+// sprite animation take
+func (s spriteSheet) ClipUVs(clipName string, textureSize matrix.Vec2) []matrix.Vec4 {
+	clip := s.clips[clipName]
+	uvs := make([]matrix.Vec4, len(clip))
+	for i := range clip {
+		uvs[i] = clip[i].uvs(textureSize)
+	}
+	return uvs
+}
+
 func ReadSpriteSheetData(jsonStr string) (spriteSheet, error) {
 	var data spriteSheetData
 	err := klib.JsonDecode(json.NewDecoder(strings.NewReader(jsonStr)), &data)
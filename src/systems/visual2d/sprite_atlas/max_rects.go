@@ -0,0 +1,125 @@
+/******************************************************************************/
+/* max_rects.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite_atlas
+
+import "math"
+
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) right() int  { return r.x + r.w }
+func (r rect) bottom() int { return r.y + r.h }
+
+func (r rect) intersects(o rect) bool {
+	return r.x < o.right() && o.x < r.right() && r.y < o.bottom() && o.y < r.bottom()
+}
+
+func (r rect) contains(o rect) bool {
+	return o.x >= r.x && o.y >= r.y && o.right() <= r.right() && o.bottom() <= r.bottom()
+}
+
+// maxRects is a bin that tracks all of the largest rectangles of free space
+// that are left in it, the free rectangles overlap each other
+type maxRects struct {
+	free []rect
+}
+
+func newMaxRects(area rect) maxRects {
+	return maxRects{free: []rect{area}}
+}
+
+// insert places a rectangle of the size into the free rectangle that leaves
+// the shortest side of left over space (best short side fit), false is
+// returned if the size does not fit anywhere
+func (m *maxRects) insert(w, h int) (rect, bool) {
+	best := rect{}
+	bestShort, bestLong := math.MaxInt, math.MaxInt
+	for _, f := range m.free {
+		if w > f.w || h > f.h {
+			continue
+		}
+		short := min(f.w-w, f.h-h)
+		long := max(f.w-w, f.h-h)
+		if short < bestShort || (short == bestShort && long < bestLong) {
+			best = rect{f.x, f.y, w, h}
+			bestShort, bestLong = short, long
+		}
+	}
+	if bestShort == math.MaxInt {
+		return best, false
+	}
+	m.place(best)
+	return best, true
+}
+
+// place splits every free rectangle that the used rectangle overlaps into
+// the largest rectangles around it, then drops the free rectangles that are
+// within another
+func (m *maxRects) place(used rect) {
+	next := make([]rect, 0, len(m.free)+4)
+	for _, f := range m.free {
+		if !f.intersects(used) {
+			next = append(next, f)
+			continue
+		}
+		if used.x > f.x {
+			next = append(next, rect{f.x, f.y, used.x - f.x, f.h})
+		}
+		if used.right() < f.right() {
+			next = append(next, rect{used.right(), f.y, f.right() - used.right(), f.h})
+		}
+		if used.y > f.y {
+			next = append(next, rect{f.x, f.y, f.w, used.y - f.y})
+		}
+		if used.bottom() < f.bottom() {
+			next = append(next, rect{f.x, used.bottom(), f.w, f.bottom() - used.bottom()})
+		}
+	}
+	m.free = m.free[:0]
+	for i := range next {
+		contained := false
+		for j := 0; j < len(next) && !contained; j++ {
+			// Of two equal rectangles only the first is kept
+			contained = i != j && next[j].contains(next[i]) && (next[j] != next[i] || j < i)
+		}
+		if !contained {
+			m.free = append(m.free, next[i])
+		}
+	}
+}
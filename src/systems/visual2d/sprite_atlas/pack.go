@@ -0,0 +1,346 @@
+/******************************************************************************/
+/* pack.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite_atlas
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Image is a source image of an atlas, the name is the path of the image
+// file relative to the folder of the atlas
+type Image struct {
+	Name  string
+	Image image.Image
+}
+
+// Page is an image of packed sprites and the sheet data of the sprites on it
+type Page struct {
+	Image *image.NRGBA
+	Data  SheetData
+}
+
+type sprite struct {
+	frame  string
+	clip   string
+	source *image.NRGBA
+	// bounds is the part of the source that is packed, it is the whole
+	// source unless the source was trimmed
+	bounds image.Rectangle
+	placed rect
+	page   int
+	// number is the number of the frame within its clip before the frames
+	// of the clip are renumbered
+	number int
+}
+
+// Pack packs the images into as few pages as fit the max size of the
+// definition using the max rects algorithm. An error is returned if an
+// image is too large for a page, if two images are the same frame, or if
+// the frames of a clip do not fit on the same page. The frames of a clip are
+// placed as a group so that they always end up on the same page.
+func Pack(images []Image, def Def) ([]Page, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	sprites, clips, err := readSprites(images, def.Trim)
+	if err != nil {
+		return nil, err
+	}
+	groups := spriteGroups(sprites, clips)
+	size, pad := def.pageSize(), def.Padding
+	pageCount := 0
+	for remaining := groups; len(remaining) > 0; pageCount++ {
+		bin := newMaxRects(rect{pad, pad, size - pad, size - pad})
+		left := remaining[:0:0]
+		for _, g := range remaining {
+			if !placeGroup(&bin, g, pad, pageCount) {
+				left = append(left, g)
+			}
+		}
+		if len(left) == len(remaining) {
+			return nil, groupError(left[0], size, pad)
+		}
+		remaining = left
+	}
+	pages := make([]Page, pageCount)
+	for i := range pages {
+		pages[i] = drawPage(sprites, i, &def)
+	}
+	for name, frames := range clips {
+		data := &pages[frames[0].page].Data
+		if data.Clips == nil {
+			data.Clips = make(map[string][]string)
+		}
+		for _, s := range frames {
+			data.Clips[name] = append(data.Clips[name], s.frame)
+		}
+	}
+	return pages, nil
+}
+
+// spriteGroups puts the frames of each clip into a group so that they are
+// placed on the same page, the other sprites are each a group of their own.
+// Placing the largest images first leaves the smaller images to fill in the
+// gaps, which packs far tighter than the order of the files, so the sprites
+// of a group and the groups themselves are sorted largest first.
+func spriteGroups(sprites []sprite, clips map[string][]*sprite) [][]*sprite {
+	largestFirst := func(a, b *sprite) int {
+		as, bs := a.bounds.Size(), b.bounds.Size()
+		if d := max(bs.X, bs.Y) - max(as.X, as.Y); d != 0 {
+			return d
+		}
+		if d := bs.X*bs.Y - as.X*as.Y; d != 0 {
+			return d
+		}
+		return strings.Compare(a.frame, b.frame)
+	}
+	groups := make([][]*sprite, 0, len(sprites))
+	for i := range sprites {
+		if sprites[i].clip == "" {
+			groups = append(groups, []*sprite{&sprites[i]})
+		}
+	}
+	for _, frames := range clips {
+		g := slices.Clone(frames)
+		slices.SortStableFunc(g, largestFirst)
+		groups = append(groups, g)
+	}
+	slices.SortStableFunc(groups, func(a, b []*sprite) int {
+		return largestFirst(a[0], b[0])
+	})
+	return groups
+}
+
+// placeGroup places all of the sprites of the group in the bin, the bin is
+// left as it was when any of them do not fit
+func placeGroup(bin *maxRects, group []*sprite, pad, page int) bool {
+	free := slices.Clone(bin.free)
+	placed := make([]rect, len(group))
+	for i, s := range group {
+		sz := s.bounds.Size()
+		r, ok := bin.insert(sz.X+pad, sz.Y+pad)
+		if !ok {
+			bin.free = free
+			return false
+		}
+		placed[i] = rect{r.x, r.y, sz.X, sz.Y}
+	}
+	for i, s := range group {
+		s.placed = placed[i]
+		s.page = page
+	}
+	return true
+}
+
+// groupError explains why the group does not fit on an empty page, either
+// its largest image is too large for a page or the frames of its clip do not
+// fit on one page together
+func groupError(group []*sprite, size, pad int) error {
+	s := group[0]
+	sz := s.bounds.Size()
+	bin := newMaxRects(rect{pad, pad, size - pad, size - pad})
+	if _, ok := bin.insert(sz.X+pad, sz.Y+pad); !ok || s.clip == "" {
+		return fmt.Errorf("the image %s (%dx%d) does not fit within a %dx%d page",
+			s.frame, sz.X, sz.Y, size, size)
+	}
+	return fmt.Errorf("the frames of the clip %s do not fit on one page, raise the max size", s.clip)
+}
+
+func drawPage(sprites []sprite, page int, def *Def) Page {
+	w, h := 0, 0
+	for i := range sprites {
+		if sprites[i].page == page {
+			w = max(w, sprites[i].placed.right()+def.Padding)
+			h = max(h, sprites[i].placed.bottom()+def.Padding)
+		}
+	}
+	if def.PowerOfTwo {
+		w, h = powerOfTwo(w), powerOfTwo(h)
+	}
+	p := Page{
+		Image: image.NewNRGBA(image.Rect(0, 0, w, h)),
+		Data: SheetData{
+			MirrorX: def.MirrorX,
+			Frames:  make(map[string]Frame),
+		},
+	}
+	for i := range sprites {
+		s := &sprites[i]
+		if s.page != page {
+			continue
+		}
+		// The rows are copied rather than drawn so that the colors of the
+		// transparent pixels are kept exactly
+		for y := 0; y < s.placed.h; y++ {
+			from := s.source.PixOffset(s.bounds.Min.X, s.bounds.Min.Y+y)
+			to := p.Image.PixOffset(s.placed.x, s.placed.y+y)
+			copy(p.Image.Pix[to:to+s.placed.w*4], s.source.Pix[from:from+s.placed.w*4])
+		}
+		src := s.source.Bounds()
+		p.Data.Frames[s.frame] = Frame{
+			Frame:   Rect{s.placed.x, s.placed.y, s.placed.w, s.placed.h},
+			Trimmed: s.bounds != src,
+			SpriteSourceSize: Rect{
+				X: s.bounds.Min.X - src.Min.X,
+				Y: s.bounds.Min.Y - src.Min.Y,
+				W: s.bounds.Dx(),
+				H: s.bounds.Dy(),
+			},
+			SourceSize: Size{src.Dx(), src.Dy()},
+			Pivot:      def.pivot(strings.TrimSuffix(s.frame, path.Ext(s.frame)), s.clip),
+		}
+	}
+	return p
+}
+
+func powerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p <<= 1
+	}
+	return p
+}
+
+// readSprites names the frames of the images and trims them, the frames of
+// each clip are returned in order
+func readSprites(images []Image, trim bool) ([]sprite, map[string][]*sprite, error) {
+	sprites := make([]sprite, len(images))
+	for i := range images {
+		s := &sprites[i]
+		s.frame = frameName(images[i].Name)
+		s.source = toNRGBA(images[i].Image)
+		s.bounds = s.source.Bounds()
+		if trim {
+			s.bounds = opaqueBounds(s.source)
+		}
+		if clip, number, ok := splitClip(s.frame); ok {
+			s.clip, s.number = clip, number
+		}
+	}
+	clips := map[string][]*sprite{}
+	for i := range sprites {
+		if sprites[i].clip != "" {
+			clips[sprites[i].clip] = append(clips[sprites[i].clip], &sprites[i])
+		}
+	}
+	for name, frames := range clips {
+		slices.SortFunc(frames, func(a, b *sprite) int { return a.number - b.number })
+		for i, s := range frames {
+			if i > 0 && frames[i-1].number == s.number {
+				return nil, nil, fmt.Errorf("two images are frame %d of the clip %s", s.number, name)
+			}
+		}
+		for i, s := range frames {
+			s.frame = name + "_" + strconv.Itoa(i) + path.Ext(s.frame)
+		}
+	}
+	seen := make(map[string]struct{}, len(sprites))
+	for i := range sprites {
+		if _, ok := seen[sprites[i].frame]; ok {
+			return nil, nil, errors.New("two images are named " + sprites[i].frame)
+		}
+		seen[sprites[i].frame] = struct{}{}
+	}
+	return sprites, clips, nil
+}
+
+// frameName converts the path of an image into the name of its frame, the
+// folders are joined to the name with underscores
+func frameName(name string) string {
+	return strings.ReplaceAll(path.Clean(filepath.ToSlash(name)), "/", "_")
+}
+
+// splitClip returns the clip and number of the frame if the name of the
+// frame ends in an underscore and a number
+func splitClip(frame string) (string, int, bool) {
+	base := strings.TrimSuffix(frame, path.Ext(frame))
+	i := strings.LastIndex(base, "_")
+	if i <= 0 || i == len(base)-1 {
+		return "", 0, false
+	}
+	for _, r := range base[i+1:] {
+		if !unicode.IsDigit(r) {
+			return "", 0, false
+		}
+	}
+	number, err := strconv.Atoi(base[i+1:])
+	return base[:i], number, err == nil
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok {
+		return n
+	}
+	n := image.NewNRGBA(img.Bounds())
+	draw.Draw(n, n.Bounds(), img, img.Bounds().Min, draw.Src)
+	return n
+}
+
+// opaqueBounds returns the bounds of the pixels of the image that are not
+// fully transparent, a fully transparent image keeps a single pixel so that
+// it still has a frame
+func opaqueBounds(img *image.NRGBA) image.Rectangle {
+	b := img.Bounds()
+	found := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.NRGBAAt(x, y).A == 0 {
+				continue
+			}
+			px := image.Rect(x, y, x+1, y+1)
+			if found.Empty() {
+				found = px
+			} else {
+				found = found.Union(px)
+			}
+		}
+	}
+	if found.Empty() {
+		return image.Rect(b.Min.X, b.Min.Y, min(b.Min.X+1, b.Max.X), min(b.Min.Y+1, b.Max.Y))
+	}
+	return found
+}
@@ -0,0 +1,178 @@
+/******************************************************************************/
+/* sprite_atlas.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite_atlas
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DefaultMaxSize is the width and height limit of the pages of an atlas
+// when the definition does not give one
+const DefaultMaxSize = 2048
+
+// Pivot is the point of a sprite that it is placed and rotated around, as a
+// fraction of the size of the sprite image before it was trimmed
+type Pivot struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// Def is the JSON asset format of a sprite atlas. The PNG images within the
+// folder, and its sub folders, are packed into pages of sprite sheet data in
+// the format that the sprite system reads. The folder is relative to the
+// folder of the atlas definition.
+//
+// The names of the frames come from the paths of the images, folders are
+// joined with an underscore. Images whose names end in an underscore and a
+// number are the frames of a clip, "walk_01.png" or "walk/01.png" are both a
+// frame of the clip "walk". The frames of a clip are ordered by their number
+// and renumbered from 0, so a clip can start at any number and skip numbers.
+type Def struct {
+	Folder string
+	// MaxSize is the largest width and height of a page, the images that do
+	// not fit on a page start another page, #DefaultMaxSize when 0
+	MaxSize int `json:",omitempty"`
+	// Padding is the number of empty pixels between the images and around
+	// the edges of the page
+	Padding int `json:",omitempty"`
+	// Trim cuts the fully transparent edges off of the images, the sprite
+	// system draws the trimmed frame so this is best left off for clips
+	// whose frames need to line up
+	Trim bool `json:",omitempty"`
+	// PowerOfTwo rounds the size of the pages up to a power of two
+	PowerOfTwo bool `json:",omitempty"`
+	// MirrorX has the sprite system create the "right" clips by mirroring
+	// the "left" clips
+	MirrorX bool `json:",omitempty"`
+	// Pivot is the pivot of all of the sprites, the center when not given
+	Pivot *Pivot `json:",omitempty"`
+	// Pivots are the pivots of specific clips or sprites by name, the name
+	// of a sprite does not include its extension
+	Pivots map[string]Pivot `json:",omitempty"`
+}
+
+func DefFromJson(jsonStr string) (Def, error) {
+	var def Def
+	if err := json.Unmarshal([]byte(jsonStr), &def); err != nil {
+		return def, err
+	}
+	return def, def.Validate()
+}
+
+func (d Def) ToJson() (string, error) {
+	data, err := json.MarshalIndent(d, "", "\t")
+	return string(data), err
+}
+
+// Validate returns an error if the definition has no folder or if its page
+// size can not hold any image
+func (d *Def) Validate() error {
+	if d.Folder == "" {
+		return errors.New("the sprite atlas has no folder of images")
+	}
+	if d.Padding < 0 {
+		return errors.New("the sprite atlas padding can not be negative")
+	}
+	size := d.pageSize()
+	if size <= d.Padding*2 {
+		return errors.New("the sprite atlas max size leaves no room for images")
+	}
+	if d.PowerOfTwo && size&(size-1) != 0 {
+		return errors.New("the sprite atlas max size must be a power of two when the pages are")
+	}
+	return nil
+}
+
+func (d *Def) pageSize() int {
+	if d.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+	return d.MaxSize
+}
+
+func (d *Def) pivot(names ...string) Pivot {
+	for _, name := range names {
+		if p, ok := d.Pivots[name]; ok {
+			return p
+		}
+	}
+	if d.Pivot != nil {
+		return *d.Pivot
+	}
+	return Pivot{0.5, 0.5}
+}
+
+// The sheet data types are the JSON format that the sprite system reads
+// with sprite.ReadSpriteSheetData, which is the JSON hash format of
+// TexturePacker
+
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Size struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Frame struct {
+	Frame            Rect  `json:"frame"`
+	Rotated          bool  `json:"rotated"`
+	Trimmed          bool  `json:"trimmed"`
+	SpriteSourceSize Rect  `json:"spriteSourceSize"`
+	SourceSize       Size  `json:"sourceSize"`
+	Pivot            Pivot `json:"pivot"`
+}
+
+type SheetData struct {
+	ClipStart int              `json:"clipStart"`
+	MirrorX   bool             `json:"mirrorX"`
+	Frames    map[string]Frame `json:"frames"`
+	// Clips lists the frames of each clip in order, the sprite system finds
+	// the clips from the frame names, this is for other tools
+	Clips map[string][]string `json:"clips,omitempty"`
+}
+
+func (s SheetData) ToJson() (string, error) {
+	data, err := json.MarshalIndent(s, "", "\t")
+	return string(data), err
+}
@@ -0,0 +1,248 @@
+/******************************************************************************/
+/* sprite_atlas_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite_atlas
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func testImage(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestMaxRectsNoOverlap(t *testing.T) {
+	bin := newMaxRects(rect{0, 0, 256, 256})
+	rng := rand.New(rand.NewSource(1))
+	placed := []rect{}
+	for range 200 {
+		r, ok := bin.insert(rng.Intn(40)+1, rng.Intn(40)+1)
+		if !ok {
+			continue
+		}
+		if !(rect{0, 0, 256, 256}).contains(r) {
+			t.Fatalf("%v is outside of the bin", r)
+		}
+		for _, o := range placed {
+			if r.intersects(o) {
+				t.Fatalf("%v overlaps %v", r, o)
+			}
+		}
+		placed = append(placed, r)
+	}
+	area := 0
+	for _, r := range placed {
+		area += r.w * r.h
+	}
+	if area < 256*256*3/4 {
+		t.Errorf("expected the bin to be mostly filled, only %d of %d is used", area, 256*256)
+	}
+}
+
+func TestPack(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	images := []Image{
+		{"walk/03.png", testImage(16, 16, red)},
+		{"walk/01.png", testImage(16, 16, red)},
+		{"idle.png", testImage(32, 8, red)},
+		{"jump_2.png", testImage(8, 8, red)},
+	}
+	def := Def{Folder: "sprites", Padding: 2, PowerOfTwo: true,
+		Pivots: map[string]Pivot{"walk": {0.5, 0}}}
+	pages, err := Pack(images, def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("expected a single page, got %d", len(pages))
+	}
+	p := pages[0]
+	b := p.Image.Bounds()
+	if b.Dx()&(b.Dx()-1) != 0 || b.Dy()&(b.Dy()-1) != 0 {
+		t.Errorf("expected a power of two page, got %v", b.Size())
+	}
+	walk := p.Data.Clips["walk"]
+	if len(walk) != 2 || walk[0] != "walk_0.png" || walk[1] != "walk_1.png" {
+		t.Errorf("expected the walk clip to be renumbered from 0, got %v", walk)
+	}
+	if jump := p.Data.Clips["jump"]; len(jump) != 1 || jump[0] != "jump_0.png" {
+		t.Errorf("unexpected jump clip %v", jump)
+	}
+	if p.Data.Frames["walk_0.png"].Pivot != (Pivot{0.5, 0}) || p.Data.Frames["idle.png"].Pivot != (Pivot{0.5, 0.5}) {
+		t.Errorf("unexpected pivots %+v", p.Data.Frames)
+	}
+	rects := []Rect{}
+	for name, f := range p.Data.Frames {
+		r := f.Frame
+		if r.X < def.Padding || r.Y < def.Padding || r.X+r.W+def.Padding > b.Dx() || r.Y+r.H+def.Padding > b.Dy() {
+			t.Errorf("frame %s %+v is not padded within the page", name, r)
+		}
+		for _, o := range rects {
+			if r.X < o.X+o.W+def.Padding && o.X < r.X+r.W+def.Padding &&
+				r.Y < o.Y+o.H+def.Padding && o.Y < r.Y+r.H+def.Padding {
+				t.Errorf("frame %s %+v is not padded from %+v", name, r, o)
+			}
+		}
+		rects = append(rects, r)
+		if p.Image.NRGBAAt(r.X, r.Y) != red || p.Image.NRGBAAt(r.X+r.W-1, r.Y+r.H-1) != red {
+			t.Errorf("frame %s was not drawn", name)
+		}
+	}
+}
+
+func TestPackTrim(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.SetNRGBA(3, 2, color.NRGBA{0, 255, 0, 255})
+	img.SetNRGBA(5, 6, color.NRGBA{0, 0, 255, 10})
+	pages, err := Pack([]Image{{"gem.png", img}}, Def{Folder: "f", Trim: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pages[0].Data.Frames["gem.png"]
+	if !f.Trimmed || f.SpriteSourceSize != (Rect{3, 2, 3, 5}) || f.SourceSize != (Size{10, 10}) {
+		t.Errorf("unexpected trimmed frame %+v", f)
+	}
+	if f.Frame.W != 3 || f.Frame.H != 5 {
+		t.Errorf("expected the packed frame to be trimmed, got %+v", f.Frame)
+	}
+	if c := pages[0].Image.NRGBAAt(f.Frame.X+2, f.Frame.Y+4); c != (color.NRGBA{0, 0, 255, 10}) {
+		t.Errorf("expected the translucent pixel to be copied exactly, got %v", c)
+	}
+}
+
+func TestPackPages(t *testing.T) {
+	images := []Image{}
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		images = append(images, Image{name, testImage(40, 40, color.NRGBA{A: 255})})
+	}
+	pages, err := Pack(images, Def{Folder: "f", MaxSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 {
+		t.Errorf("expected each image to need its own page, got %d pages", len(pages))
+	}
+	if _, err := Pack([]Image{{"big.png", testImage(80, 8, color.NRGBA{})}}, Def{Folder: "f", MaxSize: 64}); err == nil {
+		t.Error("expected an image larger than a page to fail")
+	}
+	clip := []Image{
+		{"run_0.png", testImage(40, 40, color.NRGBA{})},
+		{"run_1.png", testImage(40, 40, color.NRGBA{})},
+	}
+	if _, err := Pack(clip, Def{Folder: "f", MaxSize: 64}); err == nil {
+		t.Error("expected a clip split across pages to fail")
+	}
+	// The small frame of the clip fits beside the large image but the other
+	// frame does not, so the whole clip moves to the next page
+	split := []Image{
+		{"a.png", testImage(40, 40, color.NRGBA{A: 255})},
+		{"run_0.png", testImage(30, 30, color.NRGBA{A: 255})},
+		{"run_1.png", testImage(20, 20, color.NRGBA{A: 255})},
+	}
+	pages, err = Pack(split, Def{Folder: "f", MaxSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected the clip to be on its own page, got %d pages", len(pages))
+	}
+	if run := pages[1].Data.Clips["run"]; len(run) != 2 || len(pages[1].Data.Frames) != 2 {
+		t.Errorf("expected both frames of the clip on the second page, got %v", run)
+	}
+}
+
+func TestPackDuplicateFrames(t *testing.T) {
+	images := []Image{
+		{"walk_1.png", testImage(2, 2, color.NRGBA{})},
+		{"walk/01.png", testImage(2, 2, color.NRGBA{})},
+	}
+	if _, err := Pack(images, Def{Folder: "f"}); err == nil {
+		t.Error("expected two images of the same frame to fail")
+	}
+}
+
+func TestSheetDataJson(t *testing.T) {
+	pages, err := Pack([]Image{{"hero_1.png", testImage(4, 4, color.NRGBA{})}}, Def{Folder: "f", MirrorX: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	str, err := pages[0].Data.ToJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The keys must match what sprite.ReadSpriteSheetData reads
+	for _, key := range []string{`"clipStart"`, `"mirrorX": true`, `"frames"`, `"hero_0.png"`,
+		`"frame"`, `"spriteSourceSize"`, `"sourceSize"`, `"pivot"`} {
+		if !strings.Contains(str, key) {
+			t.Errorf("expected the json to contain %s\n%s", key, str)
+		}
+	}
+	var data SheetData
+	if err := json.Unmarshal([]byte(str), &data); err != nil || len(data.Frames) != 1 {
+		t.Errorf("failed to read the json back %v", err)
+	}
+}
+
+func TestDefValidate(t *testing.T) {
+	if _, err := DefFromJson(`{"MaxSize": 64}`); err == nil {
+		t.Error("expected a definition without a folder to fail")
+	}
+	if _, err := DefFromJson(`{"Folder": "f", "MaxSize": 100, "PowerOfTwo": true}`); err == nil {
+		t.Error("expected a max size that is not a power of two to fail")
+	}
+	if _, err := DefFromJson(`{"Folder": "f", "MaxSize": 8, "Padding": 4}`); err == nil {
+		t.Error("expected padding that fills the page to fail")
+	}
+	def, err := DefFromJson(`{"Folder": "f", "Pivot": {"x": 0, "y": 1}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.pageSize() != DefaultMaxSize || def.pivot("any") != (Pivot{0, 1}) {
+		t.Errorf("unexpected definition %+v", def)
+	}
+}
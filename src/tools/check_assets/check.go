@@ -0,0 +1,288 @@
+/******************************************************************************/
+/* check.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io/fs"
+	"kaiju/assets/asset_info"
+	"kaiju/assets/vfs"
+	"kaiju/editor/editor_config"
+	"kaiju/filesystem"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	problemUnreadableInfo   = "unreadable adi"
+	problemOrphanedInfo     = "orphaned adi"
+	problemDuplicateID      = "duplicate id"
+	problemUnreadableStage  = "unreadable stage"
+	problemMissingMesh      = "missing mesh"
+	problemMissingTexture   = "missing texture"
+	problemUnreadableImage  = "unreadable image"
+	problemUnsupportedImage = "unsupported format"
+)
+
+// astcMagic starts every .astc file
+var astcMagic = []byte{0x13, 0xAB, 0xA1, 0x5C}
+
+// builtinMeshKeys are the meshes that the engine creates in code rather than
+// reading from content, see the NewMesh functions of kaiju/rendering
+var builtinMeshKeys = []string{
+	"quad", "triangle", "unit_quad", "screen_quad", "plane", "cube",
+	"texturable_cube", "skybox", "cube_inverse",
+}
+
+// unsupportedImages are image formats that the texture loader can not read,
+// they need to be converted to PNG or ASTC before they can be used
+var unsupportedImages = []string{
+	".jpg", ".jpeg", ".gif", ".bmp", ".tga", ".tif", ".tiff", ".webp",
+	".psd", ".dds", ".ktx", ".hdr", ".exr",
+}
+
+type problem struct {
+	Path   string
+	Kind   string
+	Detail string
+}
+
+func (p problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Kind, p.Detail)
+}
+
+// checker finds the problems with the content of a project, the paths of
+// the problems are relative to the project folder
+type checker struct {
+	root     string
+	folders  []string
+	problems []problem
+}
+
+// newChecker creates a checker for the project folder, the keys referenced
+// by stages are searched for in the content folder, the textures split out
+// of models (see project_cache.TextureLayer), and the extra folders, which
+// is where the content of the engine is given
+func newChecker(root string, extraFolders []string) *checker {
+	return &checker{
+		root: root,
+		folders: append([]string{
+			filepath.Join(root, "content"),
+			filepath.Join(root, asset_info.ProjectCache, "textures"),
+		}, extraFolders...),
+	}
+}
+
+func (c *checker) report(path, kind, detail string) {
+	if rel, err := filepath.Rel(c.root, path); err == nil {
+		path = rel
+	}
+	c.problems = append(c.problems, problem{filepath.ToSlash(path), kind, detail})
+}
+
+// check walks the content folder of the project and returns the problems
+// sorted by path
+func (c *checker) check() ([]problem, error) {
+	c.problems = c.problems[:0]
+	files := []string{}
+	err := filepath.WalkDir(filepath.Join(c.root, "content"),
+		func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				files = append(files, path)
+			}
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	c.checkInfos(files)
+	for _, path := range files {
+		ext := strings.ToLower(filepath.Ext(path))
+		switch {
+		case ext == editor_config.FileExtensionStage:
+			c.checkStage(path)
+		case ext == editor_config.FileExtensionPng || ext == ".astc":
+			c.checkImage(path, ext)
+		case slices.Contains(unsupportedImages, ext):
+			c.report(path, problemUnsupportedImage,
+				"textures can only be read from PNG or ASTC files")
+		}
+	}
+	slices.SortStableFunc(c.problems, func(a, b problem) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return c.problems, nil
+}
+
+// checkInfos reports the ADI files that can not be read, that are left
+// behind after their file was removed, and the IDs that more than one asset
+// claims
+func (c *checker) checkInfos(files []string) {
+	owners := map[string][]string{}
+	var collect func(adi asset_info.AssetDatabaseInfo, path string)
+	collect = func(adi asset_info.AssetDatabaseInfo, path string) {
+		if adi.ID != "" && !slices.Contains(owners[adi.ID], path) {
+			owners[adi.ID] = append(owners[adi.ID], path)
+		}
+		for i := range adi.Children {
+			collect(adi.Children[i], path)
+		}
+	}
+	for _, path := range files {
+		if filepath.Ext(path) != asset_info.InfoExtension {
+			continue
+		}
+		asset := strings.TrimSuffix(path, asset_info.InfoExtension)
+		if !filesystem.FileExists(asset) {
+			c.report(path, problemOrphanedInfo, "the file it describes does not exist")
+		}
+		adi, err := asset_info.Read(asset)
+		if err != nil {
+			c.report(path, problemUnreadableInfo, err.Error())
+			continue
+		}
+		collect(adi, path)
+	}
+	ids := make([]string, 0, len(owners))
+	for id, paths := range owners {
+		if len(paths) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		for _, path := range owners[id] {
+			c.report(path, problemDuplicateID, fmt.Sprintf("%s is also used by %s", id,
+				strings.Join(c.relative(slices.DeleteFunc(slices.Clone(owners[id]),
+					func(p string) bool { return p == path })), ", ")))
+		}
+	}
+}
+
+func (c *checker) relative(paths []string) []string {
+	for i := range paths {
+		if rel, err := filepath.Rel(c.root, paths[i]); err == nil {
+			paths[i] = filepath.ToSlash(rel)
+		}
+	}
+	return paths
+}
+
+// checkStage reports the meshes and textures that the drawings of the stage
+// use which can not be found
+func (c *checker) checkStage(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.report(path, problemUnreadableStage, err.Error())
+		return
+	}
+	drawings, err := readStageDrawings(data)
+	if err != nil {
+		c.report(path, problemUnreadableStage, err.Error())
+		return
+	}
+	missing := map[string]struct{}{}
+	for _, d := range drawings {
+		if d.MeshKey != "" && !slices.Contains(builtinMeshKeys, d.MeshKey) && !c.exists(d.MeshKey) {
+			if _, ok := missing[d.MeshKey]; !ok {
+				missing[d.MeshKey] = struct{}{}
+				c.report(path, problemMissingMesh, d.MeshKey)
+			}
+		}
+		for _, t := range d.Textures {
+			if _, ok := missing[t]; ok || t == "" || c.exists(t) {
+				continue
+			}
+			missing[t] = struct{}{}
+			c.report(path, problemMissingTexture, t)
+		}
+	}
+}
+
+// exists checks if the key is the ID of an imported asset, or the key of a
+// file within one of the searched folders
+func (c *checker) exists(key string) bool {
+	if c.indexed(key) {
+		return true
+	}
+	key = vfs.NormalizeKey(key)
+	for _, folder := range c.folders {
+		if filesystem.FileExists(filepath.Join(folder, filepath.FromSlash(key))) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexed checks that the asset_info index has the ID and that the file it
+// points to still exists, like asset_info.Lookup but within the project
+// folder rather than the working directory
+func (c *checker) indexed(id string) bool {
+	if strings.ContainsAny(id, `/\`) {
+		return false
+	}
+	path, err := filesystem.ReadTextFile(filepath.Join(c.root, asset_info.ProjectCache, "index", id))
+	if err != nil {
+		return false
+	}
+	return filesystem.FileExists(filepath.Join(c.root, path))
+}
+
+// checkImage reports the images that the texture loader would fail to read
+func (c *checker) checkImage(path, ext string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.report(path, problemUnreadableImage, err.Error())
+		return
+	}
+	if ext == ".astc" {
+		if len(data) < 16 || !bytes.HasPrefix(data, astcMagic) {
+			c.report(path, problemUnreadableImage, "the ASTC header is missing")
+		}
+		return
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		c.report(path, problemUnreadableImage, err.Error())
+	} else if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		c.report(path, problemUnreadableImage, err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+/******************************************************************************/
+/* check_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"image"
+	"image/png"
+	"kaiju/assets/asset_info"
+	"kaiju/klib"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testEntity and testDrawing mirror the entity and drawing data that the
+// engine writes into stage files
+type testEntity struct {
+	Name string
+}
+
+type testDrawing struct {
+	CanvasId    string
+	MeshKey     string
+	Textures    []string
+	UseBlending bool
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestInfo(t *testing.T, root, path, id string) {
+	t.Helper()
+	data, err := json.Marshal(asset_info.AssetDatabaseInfo{ID: id, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, path+asset_info.InfoExtension), data)
+}
+
+func writeTestEntity(t *testing.T, stream *bytes.Buffer, drawings []testDrawing, children int) {
+	t.Helper()
+	enc := gob.NewEncoder(stream)
+	if err := enc.Encode(testEntity{"entity"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(drawings); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(map[string]any{"x": 1}); err != nil {
+		t.Fatal(err)
+	}
+	klib.BinaryWrite(stream, int32(children))
+}
+
+func TestReadStageDrawings(t *testing.T) {
+	stream := bytes.Buffer{}
+	klib.BinaryWrite(&stream, int32(1))
+	writeTestEntity(t, &stream, []testDrawing{{MeshKey: "quad", Textures: []string{"a.png"}}}, 1)
+	writeTestEntity(t, &stream, []testDrawing{{MeshKey: "child.msh"}}, 0)
+	drawings, err := readStageDrawings(stream.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drawings) != 2 || drawings[0].Textures[0] != "a.png" || drawings[1].MeshKey != "child.msh" {
+		t.Errorf("unexpected drawings %+v", drawings)
+	}
+	if _, err := readStageDrawings(stream.Bytes()[:stream.Len()/2]); err == nil {
+		t.Error("expected a cut off stage to fail")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	content := filepath.Join(root, "content")
+	img := bytes.Buffer{}
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(content, "textures", "good.png"), img.Bytes())
+	writeTestFile(t, filepath.Join(content, "textures", "bad.png"), []byte("not a png"))
+	writeTestFile(t, filepath.Join(content, "textures", "photo.jpg"), []byte{0xFF, 0xD8})
+	writeTestFile(t, filepath.Join(content, "meshes", "one.msh"), []byte{0})
+	writeTestFile(t, filepath.Join(content, "meshes", "two.msh"), []byte{0})
+	writeTestInfo(t, root, "content/meshes/one.msh", "same")
+	writeTestInfo(t, root, "content/meshes/two.msh", "same")
+	writeTestInfo(t, root, "content/meshes/gone.msh", "gone")
+	writeTestFile(t, filepath.Join(root, asset_info.ProjectCache, "index", "same"),
+		[]byte("content/meshes/one.msh"))
+	stage := bytes.Buffer{}
+	klib.BinaryWrite(&stage, int32(1))
+	writeTestEntity(t, &stage, []testDrawing{
+		{MeshKey: "quad", Textures: []string{"textures/good.png"}},
+		{MeshKey: "same", Textures: []string{"textures/missing.png"}},
+		{MeshKey: "meshes/missing.msh"},
+	}, 0)
+	writeTestFile(t, filepath.Join(content, "stages", "level.stg"), stage.Bytes())
+	writeTestFile(t, filepath.Join(content, "stages", "broken.stg"), []byte{1, 0})
+	problems, err := newChecker(root, nil).check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []problem{
+		{"content/meshes/gone.msh.adi", problemOrphanedInfo, ""},
+		{"content/meshes/one.msh.adi", problemDuplicateID, ""},
+		{"content/meshes/two.msh.adi", problemDuplicateID, ""},
+		{"content/stages/broken.stg", problemUnreadableStage, ""},
+		{"content/stages/level.stg", problemMissingTexture, "textures/missing.png"},
+		{"content/stages/level.stg", problemMissingMesh, "meshes/missing.msh"},
+		{"content/textures/bad.png", problemUnreadableImage, ""},
+		{"content/textures/photo.jpg", problemUnsupportedImage, ""},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems but got %d\n%v", len(expected), len(problems), problems)
+	}
+	for i, e := range expected {
+		p := problems[i]
+		if p.Path != e.Path || p.Kind != e.Kind || (e.Detail != "" && p.Detail != e.Detail) {
+			t.Errorf("problem %d expected %v but got %v", i, e, p)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/******************************************************************************/
+/* stage.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"kaiju/klib"
+	"reflect"
+)
+
+// stageDrawing holds the fields of the drawing definitions that the engine
+// writes into stage files (drawingDef in kaiju/engine). Gob matches fields by
+// name, so the fields that are not needed for checking are skipped.
+type stageDrawing struct {
+	MeshKey  string
+	Textures []string
+}
+
+// readStageDrawings reads the drawings of all of the entities in the stage
+// file. The stage is read without a host, the entities and their editor data
+// are skipped so the entity data types that the game registers are not
+// needed.
+func readStageDrawings(data []byte) ([]stageDrawing, error) {
+	stream := bytes.NewBuffer(data)
+	count, err := klib.BinaryReadLen(stream)
+	if err != nil {
+		return nil, err
+	}
+	drawings := []stageDrawing{}
+	for i := int32(0); i < count && err == nil; i++ {
+		err = readStageEntity(stream, &drawings)
+	}
+	return drawings, err
+}
+
+func readStageEntity(stream *bytes.Buffer, drawings *[]stageDrawing) error {
+	// Each entity is written with its own encoder, see #Entity.Serialize,
+	// decoding into an invalid value skips the entity and the editor data
+	dec := gob.NewDecoder(stream)
+	if err := dec.DecodeValue(reflect.Value{}); err != nil {
+		return err
+	}
+	var defs []stageDrawing
+	if err := dec.Decode(&defs); err != nil {
+		return err
+	}
+	*drawings = append(*drawings, defs...)
+	if err := dec.DecodeValue(reflect.Value{}); err != nil {
+		return err
+	}
+	children, err := klib.BinaryReadLen(stream)
+	if err != nil {
+		return err
+	} else if children < 0 {
+		return errors.New("negative child count read")
+	}
+	for i := int32(0); i < children && err == nil; i++ {
+		err = readStageEntity(stream, drawings)
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+/******************************************************************************/
+/* main.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"kaiju/assets/archive"
+	"kaiju/assets/vfs"
+	"kaiju/filesystem"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// passphraseEnvironmentVariable is read for the passphrase when it is not
+// given as a flag, so that it does not end up in the shell history
+const passphraseEnvironmentVariable = "KAIJU_ARCHIVE_PASSPHRASE"
+
+// processedTextureExtension matches rendering.ProcessedTextureExtension, the
+// game reads an image from the processed texture of the same key followed by
+// this extension when it is packed
+const processedTextureExtension = ".ktex"
+
+// contentKeys lists every file in the content folder as an asset key, the
+// asset database info files of the editor are skipped
+func contentKeys(content string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(content, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) == ".adi" {
+			return err
+		}
+		rel, err := filepath.Rel(content, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, vfs.NormalizeKey(rel))
+		return nil
+	})
+	slices.Sort(keys)
+	return keys, err
+}
+
+// listedKeys reads the keys of the referenced assets from a file, one per
+// line, blank lines and lines starting with # are skipped
+func listedKeys(listFile string) ([]string, error) {
+	f, err := os.Open(listFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, vfs.NormalizeKey(line))
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// withProcessedTextures adds the keys of the textures that the editor
+// processed at import time to the keys. All of the processed textures are
+// added when every content file is packed, otherwise only the ones for the
+// listed images are added.
+func withProcessedTextures(keys []string, textures string, all bool) ([]string, error) {
+	if textures == "" || !filesystem.DirectoryExists(textures) {
+		return keys, nil
+	}
+	var processed []string
+	if all {
+		var err error
+		if processed, err = contentKeys(textures); err != nil {
+			return keys, err
+		}
+	} else {
+		for _, k := range keys {
+			p := k + processedTextureExtension
+			if filesystem.FileExists(filepath.Join(textures, filepath.FromSlash(p))) {
+				processed = append(processed, p)
+			}
+		}
+	}
+	keys = append(keys, processed...)
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
+
+func main() {
+	fs := flag.NewFlagSet("Kaiju content packer", flag.ExitOnError)
+	content := fs.String("content", "content", "The folder of loose content to pack")
+	out := fs.String("out", "build/content", "The folder to write the archives into")
+	name := fs.String("name", "content", "The name the archives start with")
+	list := fs.String("list", "", "A file of the referenced asset keys to pack, one per line, all content is packed when empty")
+	textures := fs.String("textures", filepath.Join(".cache", "textures"),
+		"The folder of the textures that the editor processed at import time, they are packed so the game reads them instead of the images")
+	compress := fs.Bool("compress", true, "Compress the assets in the archives")
+	passphrase := fs.String("passphrase", os.Getenv(passphraseEnvironmentVariable),
+		"Encrypt the archives with this passphrase, the game must be built with the same kaiju/assets.ArchiveKey")
+	maxSize := fs.Int64("max-size", 0, "Start a new archive after this many megabytes, 0 for a single archive")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pack_content [flags]")
+		fmt.Fprintln(fs.Output(), "Packs the content of a game into archives for shipping")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	var keys []string
+	var err error
+	if *list != "" {
+		keys, err = listedKeys(*list)
+	} else {
+		keys, err = contentKeys(*content)
+	}
+	if err == nil {
+		keys, err = withProcessedTextures(keys, *textures, *list == "")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*out, os.ModePerm); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	options := archive.Options{
+		Compress: *compress,
+		MaxSize:  *maxSize * 1024 * 1024,
+	}
+	if *passphrase != "" {
+		options.Key = archive.KeyFromPassphrase(*passphrase)
+	}
+	read := func(key string) ([]byte, error) {
+		data, err := filesystem.ReadFile(filepath.Join(*content, filepath.FromSlash(key)))
+		if errors.Is(err, os.ErrNotExist) && *textures != "" {
+			return filesystem.ReadFile(filepath.Join(*textures, filepath.FromSlash(key)))
+		}
+		return data, err
+	}
+	paths, err := archive.Pack(*out, *name, keys, read, options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	fmt.Printf("Packed %d assets into %d archives\n", len(keys), len(paths))
+}
@@ -0,0 +1,172 @@
+/******************************************************************************/
+/* main.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"kaiju/systems/console/remote_protocol"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+type client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func connect(address, token string, timeout time.Duration) (*client, error) {
+	network, addr := remote_protocol.ParseAddress(address)
+	conn, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &client{conn: conn, reader: bufio.NewReader(conn)}
+	if token != "" {
+		if err := remote_protocol.WriteAuth(conn, token); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		res, err := remote_protocol.ReadResponse(c.reader)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if res.IsError() {
+			conn.Close()
+			return nil, remote_protocol.ErrUnauthorized
+		}
+	}
+	return c, nil
+}
+
+func (c *client) run(command string) (remote_protocol.Response, error) {
+	if err := remote_protocol.WriteRequest(c.conn, command); err != nil {
+		return remote_protocol.Response{}, err
+	}
+	return remote_protocol.ReadResponse(c.reader)
+}
+
+// runAll sends each line from the reader as a command and writes the output
+// of each command, returns the number of commands that failed
+func (c *client) runAll(in io.Reader, out, errOut io.Writer, prompt bool) (int, error) {
+	failed := 0
+	scanner := bufio.NewScanner(in)
+	if prompt {
+		fmt.Fprint(out, "> ")
+	}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			res, err := c.run(line)
+			if err != nil {
+				return failed, err
+			}
+			if res.IsError() {
+				failed++
+				fmt.Fprintln(errOut, res.Output)
+			} else if res.Output != "" {
+				fmt.Fprintln(out, res.Output)
+			}
+		}
+		if prompt {
+			fmt.Fprint(out, "> ")
+		}
+	}
+	return failed, scanner.Err()
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// run connects to the game and runs the commands, the returned exit code is
+// 2 when the commands could not be run and 1 when any command failed
+func run(args []string) int {
+	fs := flag.NewFlagSet("Kaiju remote console", flag.ExitOnError)
+	address := fs.String("addr", remote_protocol.DefaultAddress,
+		"The address of the game, prefix with unix: for a Unix socket")
+	token := fs.String("token", os.Getenv(remote_protocol.TokenEnvironmentVariable),
+		"The auth token if the game requires one")
+	script := fs.String("script", "", "A file of commands to run, one per line")
+	timeout := fs.Duration("timeout", 5*time.Second, "The time to wait to connect")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: remote_console [flags] [command]")
+		fmt.Fprintln(fs.Output(), "Runs the command, the script, or each line from stdin on a running game")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	c, err := connect(*address, *token, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer c.conn.Close()
+	failed := 0
+	if fs.NArg() > 0 {
+		failed, err = c.runAll(strings.NewReader(strings.Join(fs.Args(), " ")),
+			os.Stdout, os.Stderr, false)
+	} else if *script != "" {
+		f, openErr := os.Open(*script)
+		if openErr != nil {
+			fmt.Fprintln(os.Stderr, openErr)
+			return 2
+		}
+		defer f.Close()
+		failed, err = c.runAll(f, os.Stdout, os.Stderr, false)
+	} else {
+		failed, err = c.runAll(os.Stdin, os.Stdout, os.Stderr, isTerminal(os.Stdin))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
@@ -188,6 +188,7 @@ func (label *Label) renderText() {
 			label.runeShaderData = append(label.runeShaderData,
 				label.runeDrawings[i].ShaderData.(*rendering.TextShaderData))
 			label.runeDrawings[i].UseBlending = label.bgColor.A() < 1.0
+			label.runeDrawings[i].Layers = rendering.RenderLayerUI
 		}
 		for i := 0; i < len(label.colorRanges); i++ {
 			label.colorRange(label.colorRanges[i])
@@ -559,6 +559,7 @@ func (p *Panel) ensureBGExists(tex *rendering.Texture) {
 			ShaderData: &p.shaderData,
 			Transform:  &p.entity.Transform,
 			CanvasId:   "default",
+			Layers:     rendering.RenderLayerUI,
 		}
 		p.host.Drawings.AddDrawing(&p.drawing)
 	} else if tex != nil {